@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+// accessTokenPrefix makes the tokens easy to recognize, say, by secret scanners.
+const accessTokenPrefix = "betula_"
+
+func hashAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewAccessToken makes a personal access token and returns its value.
+// The value is not stored anywhere, so it has to be shown right away.
+func NewAccessToken(name string, scopes []types.TokenScope) (string, error) {
+	token := accessTokenPrefix + randomString(24)
+	id, err := sessionsRepo.AddAccessToken(context.Background(), name, hashAccessToken(token), scopes)
+	if err != nil {
+		return "", err
+	}
+	slog.Info("Made a new access token", "id", id, "name", name, "scopes", scopes)
+	return token, nil
+}
+
+// AccessTokens returns all personal access tokens.
+func AccessTokens() []types.AccessToken {
+	tokens, err := sessionsRepo.AccessTokens(context.Background())
+	if err != nil {
+		slog.Error("Failed to load access tokens", "err", err)
+		return nil
+	}
+	return tokens
+}
+
+// RevokeAccessToken deletes the access token, so it no longer works.
+func RevokeAccessToken(id int64) {
+	if err := sessionsRepo.RevokeAccessToken(context.Background(), id); err != nil {
+		slog.Error("Failed to revoke access token", "id", id, "err", err)
+	}
+}
+
+// AccessTokenFromRequest returns the access token from the
+// Authorization: Bearer header, if it is there and is valid.
+func AccessTokenFromRequest(rq *http.Request) (types.AccessToken, bool) {
+	token, found := strings.CutPrefix(rq.Header.Get("Authorization"), "Bearer ")
+	if !found || token == "" {
+		return types.AccessToken{}, false
+	}
+	accessToken, err := sessionsRepo.AccessTokenByHash(rq.Context(), hashAccessToken(strings.TrimSpace(token)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return accessToken, false
+	} else if err != nil {
+		slog.Error("Failed to check access token", "err", err)
+		return accessToken, false
+	}
+	return accessToken, true
+}
@@ -15,6 +15,7 @@ import (
 
 	"git.sr.ht/~bouncepaw/betula/auth"
 	"git.sr.ht/~bouncepaw/betula/db"
+	"git.sr.ht/~bouncepaw/betula/fediverse"
 	"git.sr.ht/~bouncepaw/betula/fediverse/signing"
 	apgw "git.sr.ht/~bouncepaw/betula/gateways/activitypub"
 	webfingergw "git.sr.ht/~bouncepaw/betula/gateways/webfinger"
@@ -25,15 +26,21 @@ import (
 	"git.sr.ht/~bouncepaw/betula/svc/activitypub/assembly"
 	"git.sr.ht/~bouncepaw/betula/svc/activitypub/parsing"
 	archivingsvc "git.sr.ht/~bouncepaw/betula/svc/archiving"
+	blockingsvc "git.sr.ht/~bouncepaw/betula/svc/blocking"
+	duplicatessvc "git.sr.ht/~bouncepaw/betula/svc/duplicates"
 	feedssvc "git.sr.ht/~bouncepaw/betula/svc/feeds"
 	helpingsvc "git.sr.ht/~bouncepaw/betula/svc/helping"
 	imexsvc "git.sr.ht/~bouncepaw/betula/svc/imex"
 	likingsvc "git.sr.ht/~bouncepaw/betula/svc/liking"
+	linkrotsvc "git.sr.ht/~bouncepaw/betula/svc/linkrot"
 	notifsvc "git.sr.ht/~bouncepaw/betula/svc/notif"
 	remarkingsvc "git.sr.ht/~bouncepaw/betula/svc/remarking"
 	remotebookmarkssvc "git.sr.ht/~bouncepaw/betula/svc/remotebookmarks"
 	searchsvc "git.sr.ht/~bouncepaw/betula/svc/searching"
 	settingssvc "git.sr.ht/~bouncepaw/betula/svc/settings"
+	subscriptionssvc "git.sr.ht/~bouncepaw/betula/svc/subscriptions"
+	webhookssvc "git.sr.ht/~bouncepaw/betula/svc/webhooks"
+	"git.sr.ht/~bouncepaw/betula/types"
 	"git.sr.ht/~bouncepaw/betula/web"
 	_ "git.sr.ht/~bouncepaw/betula/web" // For init()
 )
@@ -42,8 +49,11 @@ func main() {
 	slog.SetLogLoggerLevel(slog.LevelDebug)
 	var port uint
 	var versionFlag bool
+	var gcFlag bool
 
 	flag.BoolVar(&versionFlag, "version", false, "Print version and exit.")
+	flag.BoolVar(&gcFlag, "gc", false, "Remove archives of deleted bookmarks and unused artifacts, "+
+		"compact the database file and exit.")
 	flag.UintVar(&port, "port", 0, "Port number. "+
 		"The value gets written to a database file and is used immediately.")
 	flag.Usage = func() {
@@ -77,14 +87,37 @@ func main() {
 	db.Initialize(filename)
 	defer db.Finalize()
 	settings.Index()
+	if gcFlag {
+		collectGarbage()
+		return
+	}
 	auth.Initialize()
 	// If the user provided a non-zero port, use it. Write it to the DB. It will be picked up later by settings.Index(). If they did not provide such a port, whatever, settings.Index() will figure something out 🙏
 	if port > 0 {
 		settings.WritePort(port)
 	}
 	signing.EnsureKeysFromDatabase()
+	jobs.ScheduleLinkChecks(context.Background())
+	jobs.ScheduleArchiveIndexing(context.Background())
+	jobs.ScheduleFeedPolling(context.Background())
+	ctrl := newController()
+	jobs.UseWebhooks(ctrl.SvcWebhooks)
 	go jobs.ListenAndWhisper()
-	web.StartServer(newController())
+	web.StartServer(ctrl)
+}
+
+func collectGarbage() {
+	var svc = archivingsvc.New(
+		archivingsvc.NewFetchers(settings.UserAgent),
+		db.NewArchivesRepo(),
+		settings.ArchiveQuota)
+	collected, err := svc.CollectGarbage()
+	if err != nil {
+		// The service logged it already.
+		os.Exit(1)
+	}
+	fmt.Printf("Removed %d archives and %d artifacts, freed %s.\n",
+		collected.Archives, collected.Artifacts, types.HumanSize(collected.Freed))
 }
 
 func newController() web.Controller {
@@ -100,24 +133,32 @@ func newController() web.Controller {
 		repoSettings       = &db.SettingsRepo{}
 		repoTags           = db.NewTagsRepo()
 		repoSearch         = db.NewSearchRepo()
-
-		obeliskFetcher = archivingsvc.NewObeliskFetcher()
-		activityPub    = apgw.NewActivityPub(repoActor, repoRemoteBookmark)
-		www            = wwwgw.New(settings.UserAgent)
-		htmlSanitizer  = wwwgw.NewSanitizer()
-		webfinger      = webfingergw.New()
-		asm            = assembly.New(settings.SiteURL, settings.AdminUsername)
-		guesser        = parsing.NewGuesser(settings.SiteURL)
+		repoBlocks         = db.NewBlocksRepo()
+		repoLinkRot        = db.NewLinkRotRepo()
+		repoDuplicates     = db.NewDuplicatesRepo()
+		repoSubscriptions  = db.NewSubscriptionsRepo()
+		repoWebhooks       = db.NewWebhooksRepo()
+		repoNative         = db.NewNativeRepo()
+
+		fetchers      = archivingsvc.NewFetchers(settings.UserAgent)
+		activityPub   = apgw.NewActivityPub(repoActor, repoRemoteBookmark)
+		www           = wwwgw.New(settings.UserAgent)
+		htmlSanitizer = wwwgw.NewSanitizer()
+		webfinger     = webfingergw.New()
+		asm           = assembly.New(settings.SiteURL, settings.AdminUsername)
+		guesser       = parsing.NewGuesser(settings.SiteURL)
 
 		// One day, all shall be in services!
 		svcSettings  = settingssvc.New(repoSettings, "v1.8.1", settings.SiteDomain)
 		svcNotif     = notifsvc.New(repoNotif)
-		svcArchiving = archivingsvc.New(obeliskFetcher, repoArchives)
+		svcArchiving = archivingsvc.New(fetchers, repoArchives, settings.ArchiveQuota)
+		svcWebhooks  = webhookssvc.New(repoWebhooks, settings.SiteURL, settings.UserAgent, jobs.ScheduleWebhookDelivery)
 		svcLiking    = likingsvc.New(
 			repoLike,
 			repoLikeCollection,
 			repoLocalBookmark,
 			repoNotif,
+			svcWebhooks,
 			activityPub,
 			asm)
 		svcRemarking = remarkingsvc.New(
@@ -127,6 +168,7 @@ func newController() web.Controller {
 			repoActor,
 			asm,
 			repoNotif,
+			svcWebhooks,
 			settings.FederationEnabled,
 		)
 		svcRemoteBookmarks = remotebookmarkssvc.New(
@@ -138,11 +180,16 @@ func newController() web.Controller {
 			settings.AdminUsername,
 			settings.SiteDomain,
 		)
-		svcFeeds     = feedssvc.New(repoLocalBookmark)
-		svcSearching = searchsvc.New(repoSearch)
-		svcHelping   = helpingsvc.New()
-		svcImEx      = imexsvc.New(repoLocalBookmark, www, settings.SiteName)
-		svcFollow    = apsvc.NewFollowService(repoActor, www, activityPub, webfinger, asm)
+		svcSearching  = searchsvc.New(repoSearch)
+		svcFeeds      = feedssvc.New(repoLocalBookmark, svcSearching)
+		svcHelping    = helpingsvc.New()
+		svcImEx       = imexsvc.New(repoLocalBookmark, repoNative, www, settings.SiteName, settings.All, settings.SetSettings)
+		svcFollow     = apsvc.NewFollowService(repoActor, www, activityPub, webfinger, asm)
+		svcBlocking   = blockingsvc.New(repoBlocks, repoActor, activityPub, asm, fediverse.OurID)
+		svcLinkRot    = linkrotsvc.New(repoLinkRot, www)
+		svcDuplicates = duplicatessvc.New(repoDuplicates)
+
+		svcSubscriptions = subscriptionssvc.New(repoSubscriptions, www)
 	)
 
 	if err := svcSettings.ApplyLoggingSettings(context.Background()); err != nil {
@@ -150,18 +197,23 @@ func newController() web.Controller {
 	}
 
 	return web.Controller{
-		SvcNotif:     svcNotif,
-		SvcArchiving: svcArchiving,
-		SvcLiking:    svcLiking,
-		SvcRemarking: svcRemarking,
-		SvcFeeds:     svcFeeds,
-		SvcSearching: svcSearching,
-		SvcHelping:   svcHelping,
-		SvcSettings:  svcSettings,
-		SvcImEx:      svcImEx,
-		SvcFollow:    svcFollow,
+		SvcNotif:      svcNotif,
+		SvcArchiving:  svcArchiving,
+		SvcLiking:     svcLiking,
+		SvcRemarking:  svcRemarking,
+		SvcFeeds:      svcFeeds,
+		SvcSearching:  svcSearching,
+		SvcHelping:    svcHelping,
+		SvcSettings:   svcSettings,
+		SvcImEx:       svcImEx,
+		SvcFollow:     svcFollow,
+		SvcBlocking:   svcBlocking,
+		SvcLinkRot:    svcLinkRot,
+		SvcDuplicates: svcDuplicates,
 
 		SvcRemoteBookmarks: svcRemoteBookmarks,
+		SvcSubscriptions:   svcSubscriptions,
+		SvcWebhooks:        svcWebhooks,
 
 		ActivityPub:   activityPub,
 		WWW:           www,
@@ -173,5 +225,6 @@ func newController() web.Controller {
 		RepoActor:          repoActor,
 		RepoRemarks:        repoRemarks,
 		RepoTags:           repoTags,
+		RepoNotif:          repoNotif,
 	}
 }
@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package db
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+
+	"git.sr.ht/~bouncepaw/betula/pkg/bxstr"
+)
+
+var (
+	trackingParamsMu sync.RWMutex
+	trackingParams   = bxstr.DefaultTrackingParams
+	// canonicalized is true once the canonical URLs are made with
+	// trackingParams.
+	canonicalized bool
+)
+
+func canonicalURL(addr string) string {
+	trackingParamsMu.RLock()
+	defer trackingParamsMu.RUnlock()
+	return bxstr.CanonicalURL(addr, trackingParams)
+}
+
+// SetTrackingParams sets the query parameters left out of canonical URLs.
+// On the first call, or if they are different from the previous ones,
+// the canonical URLs of the bookmarks are made anew. Bookmarks made before
+// canonical URLs existed get theirs this way.
+func SetTrackingParams(ctx context.Context, params []string) error {
+	trackingParamsMu.Lock()
+	if canonicalized && slices.Equal(trackingParams, params) {
+		trackingParamsMu.Unlock()
+		return nil
+	}
+	trackingParams = params
+	canonicalized = false
+	trackingParamsMu.Unlock()
+
+	if err := canonicalizeURLs(ctx); err != nil {
+		return err
+	}
+	trackingParamsMu.Lock()
+	canonicalized = slices.Equal(trackingParams, params)
+	trackingParamsMu.Unlock()
+	return nil
+}
+
+// canonicalizeURLs updates the canonical URLs of the bookmarks that do not
+// have the right one, including the ones that have none yet.
+func canonicalizeURLs(ctx context.Context) error {
+	rows, err := db.QueryContext(ctx, `select ID, URL, coalesce(CanonicalURL, '') from Bookmarks`)
+	if err != nil {
+		return err
+	}
+
+	type update struct {
+		id        int
+		canonical string
+	}
+	var updates []update
+	for rows.Next() {
+		var (
+			id             int
+			addr, previous string
+		)
+		if err = rows.Scan(&id, &addr, &previous); err != nil {
+			return errors.Join(err, rows.Close())
+		}
+		if canonical := canonicalURL(addr); canonical != previous {
+			updates = append(updates, update{id, canonical})
+		}
+	}
+	if err = errors.Join(rows.Err(), rows.Close()); err != nil {
+		return err
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, u := range updates {
+		_, err = tx.ExecContext(ctx, `update Bookmarks set CanonicalURL = ? where ID = ?`, u.canonical, u.id)
+		if err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+	}
+	return tx.Commit()
+}
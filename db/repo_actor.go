@@ -12,6 +12,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -43,14 +44,14 @@ func (repo *ActorRepo) getActorByIDWithKey(
 	id string,
 ) (types.Actor, error) {
 	row := db.QueryRowContext(ctx, `
-select Actors.ID, PreferredUsername, Inbox, DisplayedName, Summary, Domain, PublicKeyPEM
+select Actors.ID, PreferredUsername, Inbox, DisplayedName, Summary, Domain, PublicKeyPEM, SharedInbox
 from Actors
 join PublicKeys on Owner = Actors.ID
 where Actors.ID = ?
 limit 1`, id)
 
 	var actor types.Actor
-	err := row.Scan(&actor.ID, &actor.PreferredUsername, &actor.Inbox, &actor.DisplayedName, &actor.Summary, &actor.Domain, &actor.PublicKey.PublicKeyPEM)
+	err := row.Scan(&actor.ID, &actor.PreferredUsername, &actor.Inbox, &actor.DisplayedName, &actor.Summary, &actor.Domain, &actor.PublicKey.PublicKeyPEM, &actor.Endpoints.SharedInbox)
 	return actor, err
 }
 
@@ -59,13 +60,13 @@ func (repo *ActorRepo) getActorByIDWithoutKey(
 	id string,
 ) (types.Actor, error) {
 	row := db.QueryRowContext(ctx, `
-select Actors.ID, PreferredUsername, Inbox, DisplayedName, Summary, Domain
+select Actors.ID, PreferredUsername, Inbox, DisplayedName, Summary, Domain, SharedInbox
 from Actors
 where Actors.ID = ?
 limit 1`, id)
 
 	var actor types.Actor
-	err := row.Scan(&actor.ID, &actor.PreferredUsername, &actor.Inbox, &actor.DisplayedName, &actor.Summary, &actor.Domain)
+	err := row.Scan(&actor.ID, &actor.PreferredUsername, &actor.Inbox, &actor.DisplayedName, &actor.Summary, &actor.Domain, &actor.Endpoints.SharedInbox)
 	return actor, err
 }
 
@@ -99,10 +100,10 @@ func (repo *ActorRepo) StoreActor(ctx context.Context, a types.Actor) error {
 
 	_, err = tx.ExecContext(ctx, `
 replace into Actors
-    (ID, PreferredUsername, Inbox, DisplayedName, summary, domain, LastCheckedAt)
+    (ID, PreferredUsername, Inbox, DisplayedName, summary, domain, SharedInbox, LastCheckedAt)
 values
-	(?, ?, ?, ?, ?, ?, current_timestamp)`,
-		a.ID, a.PreferredUsername, a.Inbox, a.DisplayedName, a.Summary, a.Domain)
+	(?, ?, ?, ?, ?, ?, ?, current_timestamp)`,
+		a.ID, a.PreferredUsername, a.Inbox, a.DisplayedName, a.Summary, a.Domain, a.Endpoints.SharedInbox)
 	if err != nil {
 		return errors.Join(err, tx.Rollback())
 	}
@@ -121,7 +122,7 @@ values
 
 func (repo *ActorRepo) GetFollowers(ctx context.Context) ([]types.Actor, error) {
 	rows, err := db.QueryContext(ctx, `
-		select ActorID, PreferredUsername, Inbox, DisplayedName, Summary, Domain, PublicKeyPEM
+		select ActorID, PreferredUsername, Inbox, DisplayedName, Summary, Domain, PublicKeyPEM, SharedInbox
 		from Followers
 		join Actors on ActorID = Actors.ID
 		join PublicKeys on Owner = ActorID
@@ -139,7 +140,7 @@ func (repo *ActorRepo) GetFollowers(ctx context.Context) ([]types.Actor, error)
 
 func (repo *ActorRepo) GetFollowing(ctx context.Context) ([]types.Actor, error) {
 	rows, err := db.QueryContext(ctx, `
-select ActorID, PreferredUsername, Inbox, DisplayedName, Summary, Domain, PublicKeyPEM
+select ActorID, PreferredUsername, Inbox, DisplayedName, Summary, Domain, PublicKeyPEM, SharedInbox
 from Following
 join Actors on ActorID = Actors.ID
 join PublicKeys on Owner = ActorID;`)
@@ -156,7 +157,7 @@ join PublicKeys on Owner = ActorID;`)
 
 func (repo *ActorRepo) GetMutuals(ctx context.Context) ([]types.Actor, error) {
 	rows, err := db.QueryContext(ctx, `
-select Following.ActorID, PreferredUsername, Inbox, DisplayedName, Summary, Domain, PublicKeyPEM
+select Following.ActorID, PreferredUsername, Inbox, DisplayedName, Summary, Domain, PublicKeyPEM, SharedInbox
 from Following
 join Actors on Following.ActorID = Actors.ID
 join PublicKeys on Owner = Following.ActorID
@@ -174,12 +175,12 @@ where Following.ActorID in (
 func (repo *ActorRepo) ActorByAcct(ctx context.Context, user, host string) (types.Actor, error) {
 	var actor types.Actor
 	err := db.QueryRowContext(ctx, `
-select Actors.ID, PreferredUsername, Inbox, DisplayedName, Summary, Domain, PublicKeyPEM
+select Actors.ID, PreferredUsername, Inbox, DisplayedName, Summary, Domain, PublicKeyPEM, SharedInbox
 from Actors
 join PublicKeys on Owner = Actors.ID
 where PreferredUsername = ? and Domain = ?
 limit 1`, user, host).Scan(
-		&actor.ID, &actor.PreferredUsername, &actor.Inbox, &actor.DisplayedName, &actor.Summary, &actor.Domain, &actor.PublicKey.PublicKeyPEM)
+		&actor.ID, &actor.PreferredUsername, &actor.Inbox, &actor.DisplayedName, &actor.Summary, &actor.Domain, &actor.PublicKey.PublicKeyPEM, &actor.Endpoints.SharedInbox)
 	return actor, err
 }
 
@@ -192,9 +193,30 @@ func (repo *ActorRepo) KeyPemByID(ctx context.Context, keyID string) (string, er
 	return pem, err
 }
 
-func (repo *ActorRepo) AddFollower(ctx context.Context, id string) error {
-	_, err := db.ExecContext(ctx, `replace into Followers (ActorID) values (?)`, id)
-	return err
+func (repo *ActorRepo) KeyOwnerByID(ctx context.Context, keyID string) (string, error) {
+	var owner string
+	err := db.QueryRowContext(ctx, `select Owner from PublicKeys where ID = ?`, keyID).Scan(&owner)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return owner, err
+}
+
+func (repo *ActorRepo) AddFollower(ctx context.Context, id string) (added bool, err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+
+	var had bool
+	err = tx.QueryRowContext(ctx, `select exists(select 1 from Followers where ActorID = ?)`, id).Scan(&had)
+	if err != nil {
+		return false, errors.Join(err, tx.Rollback())
+	}
+	if _, err = tx.ExecContext(ctx, `replace into Followers (ActorID) values (?)`, id); err != nil {
+		return false, errors.Join(err, tx.Rollback())
+	}
+	return !had, tx.Commit()
 }
 
 func (repo *ActorRepo) RemoveFollower(ctx context.Context, id string) error {
@@ -217,6 +239,52 @@ func (repo *ActorRepo) StopFollowing(ctx context.Context, id string) error {
 	return err
 }
 
+func (repo *ActorRepo) AddFollowRequest(ctx context.Context, actorID string, activity apports.Dict) error {
+	data, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx, `replace into FollowRequests (ActorID, Activity) values (?, ?)`, actorID, data)
+	return err
+}
+
+func (repo *ActorRepo) FollowRequest(ctx context.Context, actorID string) (apports.Dict, error) {
+	var data []byte
+	err := db.QueryRowContext(ctx, `select Activity from FollowRequests where ActorID = ?`, actorID).Scan(&data)
+	if err != nil {
+		return nil, err
+	}
+	var activity apports.Dict
+	if err = json.Unmarshal(data, &activity); err != nil {
+		return nil, err
+	}
+	return activity, nil
+}
+
+func (repo *ActorRepo) RemoveFollowRequest(ctx context.Context, actorID string) error {
+	_, err := db.ExecContext(ctx, `delete from FollowRequests where ActorID = ?`, actorID)
+	return err
+}
+
+func (repo *ActorRepo) GetFollowRequests(ctx context.Context) ([]types.Actor, error) {
+	rows, err := db.QueryContext(ctx, `
+		select ActorID, PreferredUsername, Inbox, DisplayedName, Summary, Domain, PublicKeyPEM, SharedInbox
+		from FollowRequests
+		join Actors on ActorID = Actors.ID
+		join PublicKeys on Owner = ActorID
+		order by ReceivedAt, ActorID
+`)
+	if err != nil {
+		return nil, err
+	}
+
+	actors, err := scanActorsWithKey(rows)
+	if err != nil {
+		return nil, err
+	}
+	return repo.withSubscriptionStati(ctx, actors)
+}
+
 func (repo *ActorRepo) CountFollowing(ctx context.Context) (uint, error) {
 	var count uint
 	err := db.QueryRowContext(ctx, `select count(*) from Following;`).Scan(&count)
@@ -270,7 +338,7 @@ func scanActorsWithKey(rows *sql.Rows) ([]types.Actor, error) {
 	var actors []types.Actor
 	for rows.Next() {
 		var a types.Actor
-		if err := rows.Scan(&a.ID, &a.PreferredUsername, &a.Inbox, &a.DisplayedName, &a.Summary, &a.Domain, &a.PublicKey.PublicKeyPEM); err != nil {
+		if err := rows.Scan(&a.ID, &a.PreferredUsername, &a.Inbox, &a.DisplayedName, &a.Summary, &a.Domain, &a.PublicKey.PublicKeyPEM, &a.Endpoints.SharedInbox); err != nil {
 			return nil, err
 		}
 		actors = append(actors, a)
@@ -6,10 +6,12 @@
 package db
 
 import (
+	"database/sql"
 	"testing"
 
 	"github.com/nalgeon/be"
 
+	apports "git.sr.ht/~bouncepaw/betula/ports/activitypub"
 	"git.sr.ht/~bouncepaw/betula/types"
 )
 
@@ -105,7 +107,9 @@ func TestSubscriptionStatus(t *testing.T) {
 		{
 			name: "they follow",
 			setup: func(t *testing.T, repo *ActorRepo) {
-				be.Err(t, repo.AddFollower(t.Context(), id), nil)
+				added, err := repo.AddFollower(t.Context(), id)
+				be.Err(t, err, nil)
+				be.True(t, added)
 			},
 			want: types.SubscriptionTheyFollow,
 		},
@@ -114,7 +118,9 @@ func TestSubscriptionStatus(t *testing.T) {
 			setup: func(t *testing.T, repo *ActorRepo) {
 				be.Err(t, repo.AddPendingFollowing(t.Context(), id), nil)
 				be.Err(t, repo.MarkAsSurelyFollowing(t.Context(), id), nil)
-				be.Err(t, repo.AddFollower(t.Context(), id), nil)
+				added, err := repo.AddFollower(t.Context(), id)
+				be.Err(t, err, nil)
+				be.True(t, added)
 			},
 			want: types.SubscriptionMutual,
 		},
@@ -122,7 +128,9 @@ func TestSubscriptionStatus(t *testing.T) {
 			name: "pending mutual",
 			setup: func(t *testing.T, repo *ActorRepo) {
 				be.Err(t, repo.AddPendingFollowing(t.Context(), id), nil)
-				be.Err(t, repo.AddFollower(t.Context(), id), nil)
+				added, err := repo.AddFollower(t.Context(), id)
+				be.Err(t, err, nil)
+				be.True(t, added)
 			},
 			want: types.SubscriptionPendingMutual,
 		},
@@ -138,4 +146,87 @@ func TestSubscriptionStatus(t *testing.T) {
 			be.Equal(t, got, tt.want)
 		})
 	}
-}
\ No newline at end of file
+}
+func TestFollowersSharedInbox(t *testing.T) {
+	InitInMemoryDB()
+	ctx := t.Context()
+	actorRepo := NewActorRepo()
+	actor1 := validActor("https://example.com/actor1", "actor1")
+	actor1.Endpoints.SharedInbox = "https://example.com/inbox"
+	actor2 := validActor("https://example.com/actor2", "actor2")
+	be.Err(t, actorRepo.StoreActor(ctx, actor1), nil)
+	be.Err(t, actorRepo.StoreActor(ctx, actor2), nil)
+	for _, id := range []string{actor1.ID, actor2.ID, actor1.ID} {
+		_, err := actorRepo.AddFollower(ctx, id)
+		be.Err(t, err, nil)
+	}
+	added, err := actorRepo.AddFollower(ctx, actor2.ID)
+	be.Err(t, err, nil)
+	be.True(t, !added)
+
+	followers, err := actorRepo.GetFollowers(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(followers), 2)
+	for _, follower := range followers {
+		switch follower.ID {
+		case actor1.ID:
+			be.Equal(t, follower.DeliveryInbox(), "https://example.com/inbox")
+		case actor2.ID:
+			be.Equal(t, follower.DeliveryInbox(), actor2.Inbox)
+		}
+	}
+}
+
+func TestKeyOwnerByID(t *testing.T) {
+	InitInMemoryDB()
+	ctx := t.Context()
+	actorRepo := NewActorRepo()
+	actor := validActor("https://example.com/actor1", "actor1")
+	be.Err(t, actorRepo.StoreActor(ctx, actor), nil)
+
+	owner, err := actorRepo.KeyOwnerByID(ctx, actor.PublicKey.ID)
+	be.Err(t, err, nil)
+	be.Equal(t, owner, actor.ID)
+
+	owner, err = actorRepo.KeyOwnerByID(ctx, "https://example.com/unknown#main-key")
+	be.Err(t, err, nil)
+	be.Equal(t, owner, "")
+}
+
+func TestFollowRequests(t *testing.T) {
+	InitInMemoryDB()
+	ctx := t.Context()
+	actorRepo := NewActorRepo()
+	actor := validActor("https://example.com/actor1", "actor1")
+	be.Err(t, actorRepo.StoreActor(ctx, actor), nil)
+
+	_, err := actorRepo.FollowRequest(ctx, actor.ID)
+	be.Err(t, err, sql.ErrNoRows)
+
+	follow := apports.Dict{
+		"id":     "https://example.com/follows/1",
+		"type":   "Follow",
+		"actor":  actor.ID,
+		"object": "https://betula.example/@admin",
+	}
+	be.Err(t, actorRepo.AddFollowRequest(ctx, actor.ID, follow), nil)
+
+	requests, err := actorRepo.GetFollowRequests(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(requests), 1)
+	be.Equal(t, requests[0].ID, actor.ID)
+
+	activity, err := actorRepo.FollowRequest(ctx, actor.ID)
+	be.Err(t, err, nil)
+	be.Equal(t, activity, follow)
+
+	// A pending request does not make a follower.
+	count, err := actorRepo.CountFollowers(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, count, uint(0))
+
+	be.Err(t, actorRepo.RemoveFollowRequest(ctx, actor.ID), nil)
+	requests, err = actorRepo.GetFollowRequests(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(requests), 0)
+}
@@ -6,10 +6,12 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"git.sr.ht/~bouncepaw/betula/ports/archiving"
 	"git.sr.ht/~bouncepaw/betula/types"
 	"log/slog"
+	"strings"
 )
 
 type dbArtifactsRepo struct{}
@@ -24,8 +26,8 @@ func (repo *dbArtifactsRepo) Fetch(id string) (*types.Artifact, error) {
 	var artifact = types.Artifact{
 		ID: id,
 	}
-	var row = db.QueryRow(`select MimeType, Data, IsGzipped, length(Data) from Artifacts where ID = ?`, id)
-	var err = row.Scan(&artifact.MimeType, &artifact.Data, &artifact.IsGzipped, &artifact.Size)
+	var row = db.QueryRow(`select MimeType, Data, IsGzipped, length(Data), Kind from Artifacts where ID = ?`, id)
+	var err = row.Scan(&artifact.MimeType, &artifact.Data, &artifact.IsGzipped, &artifact.Size, &artifact.Kind)
 	return &artifact, err
 }
 
@@ -39,9 +41,9 @@ func (repo *dbArchivesRepo) Store(bookmarkID int64, artifact *types.Artifact) (i
 	// artifact in our database. OK, whatever, that's why we `ignore`
 	// in the request below. The archive will reuse the old artifact then.
 	_, err = tx.Exec(`
-		insert or ignore into Artifacts (ID, MimeType, Data, IsGzipped)
-		values (?, ?, ?, ?)`,
-		artifact.ID, artifact.MimeType, artifact.Data, artifact.IsGzipped)
+		insert or ignore into Artifacts (ID, MimeType, Data, IsGzipped, Kind)
+		values (?, ?, ?, ?, ?)`,
+		artifact.ID, artifact.MimeType, artifact.Data, artifact.IsGzipped, artifact.Kind)
 	if err != nil {
 		return 0, errors.Join(err, tx.Rollback())
 	}
@@ -66,7 +68,7 @@ func (repo *dbArchivesRepo) DeleteArchive(archiveID int64) error {
 
 	// Artifacts might be reused, so after deleting the archive,
 	// the corresponding artifact is to be deleted only if
-	// no other archives refer it.
+	// no other archives or bookmark images refer it.
 
 	var artifactID string
 	var row = tx.QueryRow(
@@ -76,22 +78,21 @@ func (repo *dbArchivesRepo) DeleteArchive(archiveID int64) error {
 		return errors.Join(err, tx.Rollback())
 	}
 
-	var artifactUsageCount int64
-	row = tx.QueryRow(
-		`select count(*) from Archives where ArtifactID = ?`,
-		artifactID)
-	if err = row.Scan(&artifactUsageCount); err != nil {
+	if err = deleteUnusedArtifact(tx, artifactID); err != nil {
 		return errors.Join(err, tx.Rollback())
 	}
+	return tx.Commit()
+}
 
-	if artifactUsageCount == 0 {
-		_, err = tx.Exec(`delete from Artifacts where ID = ?`, artifactID)
-		if err != nil {
-			return errors.Join(err, tx.Rollback())
-		}
-	}
+// usedArtifacts are the artifacts referred by archives or bookmark images.
+const usedArtifacts = `
+	select ArtifactID from Archives
+	union
+	select ArtifactID from BookmarkImages`
 
-	return tx.Commit()
+func deleteUnusedArtifact(tx *sql.Tx, artifactID string) error {
+	_, err := tx.Exec(`delete from Artifacts where ID = ? and ID not in (`+usedArtifacts+`)`, artifactID)
+	return err
 }
 
 func (repo *dbArchivesRepo) FetchForBookmark(bookmarkID int64) ([]types.Archive, error) {
@@ -100,7 +101,7 @@ func (repo *dbArchivesRepo) FetchForBookmark(bookmarkID int64) ([]types.Archive,
 	var rows, err = db.Query(`
 		select
 		    arc.ID, arc.SavedAt,
-		    art.ID, art.MimeType, art.IsGzipped, length(art.Data)
+		    art.ID, art.MimeType, art.IsGzipped, length(art.Data), art.Kind
 		from 
 		    Archives arc
 		join
@@ -122,7 +123,7 @@ func (repo *dbArchivesRepo) FetchForBookmark(bookmarkID int64) ([]types.Archive,
 			artifact types.Artifact
 		)
 		err = rows.Scan(&archive.ID, &archive.SavedAt,
-			&artifact.ID, &artifact.MimeType, &artifact.IsGzipped, &artifact.Size)
+			&artifact.ID, &artifact.MimeType, &artifact.IsGzipped, &artifact.Size, &artifact.Kind)
 		if err != nil {
 			return nil, err
 		}
@@ -137,6 +138,374 @@ func (repo *dbArchivesRepo) FetchForBookmark(bookmarkID int64) ([]types.Archive,
 	return archives, nil
 }
 
+func (repo *dbArchivesRepo) BookmarksWithoutArchives() ([]types.Bookmark, error) {
+	var rows, err = db.Query(`
+		select ID, URL
+		from Bookmarks
+		where DeletionTime is null and ID not in (select BookmarkID from Archives)
+		order by ID
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookmarks []types.Bookmark
+	for rows.Next() {
+		var bookmark types.Bookmark
+		if err = rows.Scan(&bookmark.ID, &bookmark.URL); err != nil {
+			return nil, err
+		}
+		bookmarks = append(bookmarks, bookmark)
+	}
+	return bookmarks, rows.Err()
+}
+
+func (repo *dbArchivesRepo) SetArchivesPublic(bookmarkID int64, public bool) error {
+	var err error
+	if public {
+		_, err = db.Exec(`insert or ignore into PublicArchives (BookmarkID) values (?)`, bookmarkID)
+	} else {
+		_, err = db.Exec(`delete from PublicArchives where BookmarkID = ?`, bookmarkID)
+	}
+	return err
+}
+
+func (repo *dbArchivesRepo) ArchivesPublic(bookmarkID int64) (bool, error) {
+	var public bool
+	var row = db.QueryRow(`select exists (select 1 from PublicArchives where BookmarkID = ?)`, bookmarkID)
+	return public, row.Scan(&public)
+}
+
+func (repo *dbArchivesRepo) ArtifactPublic(artifactID string) (bool, error) {
+	var public bool
+	var row = db.QueryRow(`
+		select exists (
+			select 1
+			from Archives arc
+			join PublicArchives pub on pub.BookmarkID = arc.BookmarkID
+			join Bookmarks b on b.ID = arc.BookmarkID
+			where arc.ArtifactID = ? and b.DeletionTime is null and b.Visibility <> ?
+		) or exists (
+			select 1
+			from BookmarkImages img
+			join Bookmarks b on b.ID = img.BookmarkID
+			where img.ArtifactID = ? and b.DeletionTime is null and b.Visibility <> ?
+		)`, artifactID, types.Private, artifactID, types.Private)
+	return public, row.Scan(&public)
+}
+
+// EachArchivedPage reads the IDs first and then the pages one by one, so
+// that fn runs with no query open. There is only one connection: fn might
+// need it, and it might take long, like writing to a slow client.
+func (repo *dbArchivesRepo) EachArchivedPage(archiveID int64, fn func(types.ArchivedPage) error) error {
+	var rows, err = db.Query(`
+		select arc.ID
+		from Archives arc
+		join Bookmarks b on arc.BookmarkID = b.ID
+		where b.DeletionTime is null and (? = 0 or arc.ID = ?)
+		order by arc.SavedAt, arc.ID
+	`, archiveID, archiveID)
+	if err != nil {
+		return err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return errors.Join(err, rows.Close())
+		}
+		ids = append(ids, id)
+	}
+	if err = errors.Join(rows.Err(), rows.Close()); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		var page types.ArchivedPage
+		err = db.QueryRow(`
+			select
+				arc.ID, arc.SavedAt, arc.BookmarkID, b.URL,
+				art.ID, art.MimeType, art.IsGzipped, art.Data, length(art.Data), art.Kind
+			from
+				Archives arc
+			join
+				Artifacts art on arc.ArtifactID = art.ID
+			join
+				Bookmarks b on arc.BookmarkID = b.ID
+			where
+				arc.ID = ?
+		`, id).Scan(&page.ID, &page.SavedAt, &page.BookmarkID, &page.URL,
+			&page.Artifact.ID, &page.Artifact.MimeType, &page.Artifact.IsGzipped,
+			&page.Artifact.Data, &page.Artifact.Size, &page.Artifact.Kind)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Deleted in the meantime.
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err = fn(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bookmarksUsageLimit is how many bookmarks are listed in Usage.ByBookmark.
+const bookmarksUsageLimit = 50
+
+// liveArtifacts are the artifacts of archives and images of bookmarks
+// that are not deleted.
+const liveArtifacts = `
+	select arc.ArtifactID
+	from Archives arc
+	join Bookmarks b on b.ID = arc.BookmarkID
+	where b.DeletionTime is null
+	union
+	select img.ArtifactID
+	from BookmarkImages img
+	join Bookmarks b on b.ID = img.BookmarkID
+	where b.DeletionTime is null`
+
+// archivedSize is the size of the artifacts of archives, each counted once.
+// Bookmark images and garbage are not counted: evicting archives would
+// not free them.
+const archivedSize = `
+	select coalesce(sum(length(Data)), 0)
+	from Artifacts
+	where ID in (select ArtifactID from Archives)`
+
+func (repo *dbArchivesRepo) Usage() (archivingports.Usage, error) {
+	var usage archivingports.Usage
+	var row = db.QueryRow(`
+		select
+			(select count(*) from Archives),
+			(select count(*) from Artifacts),
+			(select coalesce(sum(length(Data)), 0) from Artifacts),
+			(` + archivedSize + `),
+			(select coalesce(sum(length(art.Data)), 0)
+			 from Archives arc join Artifacts art on art.ID = arc.ArtifactID),
+			(select coalesce(sum(length(Data)), 0)
+			 from Artifacts where ID not in (` + liveArtifacts + `))`)
+	err := row.Scan(&usage.Archives, &usage.Artifacts, &usage.Stored, &usage.Quoted, &usage.Archived, &usage.Garbage)
+	if err != nil {
+		return usage, err
+	}
+
+	rows, err := db.Query(`
+		select MimeType, count(*), sum(length(Data))
+		from Artifacts
+		group by MimeType
+		order by sum(length(Data)) desc`)
+	if err != nil {
+		return usage, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var mime archivingports.MimeTypeUsage
+		if err = rows.Scan(&mime.MimeType, &mime.Artifacts, &mime.Size); err != nil {
+			return usage, err
+		}
+		usage.ByMimeType = append(usage.ByMimeType, mime)
+	}
+	if err = rows.Err(); err != nil {
+		return usage, err
+	}
+
+	rows, err = db.Query(`
+		select b.ID, b.Title, count(*), sum(length(art.Data))
+		from Archives arc
+		join Artifacts art on art.ID = arc.ArtifactID
+		join Bookmarks b on b.ID = arc.BookmarkID
+		where b.DeletionTime is null
+		group by b.ID
+		order by sum(length(art.Data)) desc
+		limit ?`, bookmarksUsageLimit)
+	if err != nil {
+		return usage, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var bookmark archivingports.BookmarkUsage
+		err = rows.Scan(&bookmark.BookmarkID, &bookmark.Title, &bookmark.Archives, &bookmark.Size)
+		if err != nil {
+			return usage, err
+		}
+		usage.ByBookmark = append(usage.ByBookmark, bookmark)
+	}
+	return usage, rows.Err()
+}
+
+func (repo *dbArchivesRepo) TotalSize() (int64, error) {
+	var size int64
+	var row = db.QueryRow(archivedSize)
+	return size, row.Scan(&size)
+}
+
+func (repo *dbArchivesRepo) OldestArchive() (int64, error) {
+	var archiveID int64
+	var row = db.QueryRow(`select ID from Archives order by SavedAt, ID limit 1`)
+	return archiveID, row.Scan(&archiveID)
+}
+
+func (repo *dbArchivesRepo) CollectGarbage() (archivingports.Collected, error) {
+	var collected archivingports.Collected
+	var tx, err = db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return collected, err
+	}
+
+	res, err := tx.Exec(`
+		delete from Archives
+		where BookmarkID not in (select ID from Bookmarks where DeletionTime is null)`)
+	if err != nil {
+		return collected, errors.Join(err, tx.Rollback())
+	}
+	archives, err := res.RowsAffected()
+	if err != nil {
+		return collected, errors.Join(err, tx.Rollback())
+	}
+	collected.Archives = int(archives)
+
+	_, err = tx.Exec(`
+		delete from BookmarkImages
+		where BookmarkID not in (select ID from Bookmarks where DeletionTime is null)`)
+	if err != nil {
+		return collected, errors.Join(err, tx.Rollback())
+	}
+
+	var row = tx.QueryRow(`
+		select count(*), coalesce(sum(length(Data)), 0)
+		from Artifacts
+		where ID not in (` + usedArtifacts + `)`)
+	if err = row.Scan(&collected.Artifacts, &collected.Freed); err != nil {
+		return collected, errors.Join(err, tx.Rollback())
+	}
+
+	_, err = tx.Exec(`delete from Artifacts where ID not in (` + usedArtifacts + `)`)
+	if err != nil {
+		return collected, errors.Join(err, tx.Rollback())
+	}
+	return collected, tx.Commit()
+}
+
+func (repo *dbArchivesRepo) Vacuum() error {
+	_, err := db.Exec(`vacuum`)
+	return err
+}
+
+func (repo *dbArchivesRepo) StoreText(archiveID int64, text string) error {
+	_, err := db.Exec(`
+		insert into ArchivesText (ArchiveID, Text) values (?, ?)
+		on conflict (ArchiveID) do update set Text = excluded.Text`,
+		archiveID, text)
+	return err
+}
+
+func (repo *dbArchivesRepo) ArchivesWithoutText(limit int) ([]int64, error) {
+	var rows, err = db.Query(`
+		select arc.ID
+		from Archives arc
+		join Bookmarks b on b.ID = arc.BookmarkID
+		where b.DeletionTime is null and arc.ID not in (select ArchiveID from ArchivesText)
+		order by arc.ID
+		limit ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (repo *dbArchivesRepo) StoreImage(bookmarkID int64, kind types.ImageKind, artifact *types.Artifact) error {
+	var tx, err = db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		insert or ignore into Artifacts (ID, MimeType, Data, IsGzipped, Kind)
+		values (?, ?, ?, ?, ?)`,
+		artifact.ID, artifact.MimeType, artifact.Data, artifact.IsGzipped, artifact.Kind)
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	// The old image is not needed anymore, unless something else uses it.
+	var oldArtifactID string
+	var row = tx.QueryRow(
+		`select ArtifactID from BookmarkImages where BookmarkID = ? and Kind = ?`,
+		bookmarkID, kind)
+	if err = row.Scan(&oldArtifactID); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	_, err = tx.Exec(`
+		insert into BookmarkImages (BookmarkID, Kind, ArtifactID) values (?, ?, ?)
+		on conflict (BookmarkID, Kind) do update set ArtifactID = excluded.ArtifactID`,
+		bookmarkID, kind, artifact.ID)
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	if oldArtifactID != "" && oldArtifactID != artifact.ID {
+		if err = deleteUnusedArtifact(tx, oldArtifactID); err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+	}
+	return tx.Commit()
+}
+
+func (repo *dbArchivesRepo) ImagesOf(bookmarkIDs []int) (map[int]types.BookmarkImages, error) {
+	var images = make(map[int]types.BookmarkImages)
+	if len(bookmarkIDs) == 0 {
+		return images, nil
+	}
+
+	var args = make([]any, len(bookmarkIDs))
+	for i, id := range bookmarkIDs {
+		args[i] = id
+	}
+	var rows, err = db.Query(`
+		select BookmarkID, Kind, ArtifactID
+		from BookmarkImages
+		where BookmarkID in (`+strings.Repeat("?, ", len(args)-1)+`?)`,
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			bookmarkID int
+			kind       types.ImageKind
+			artifactID string
+		)
+		if err = rows.Scan(&bookmarkID, &kind, &artifactID); err != nil {
+			return nil, err
+		}
+		var image = images[bookmarkID]
+		switch kind {
+		case types.ImageIcon:
+			image.IconID = artifactID
+		case types.ImagePreview:
+			image.PreviewID = artifactID
+		}
+		images[bookmarkID] = image
+	}
+	return images, rows.Err()
+}
+
 func NewArchivesRepo() archivingports.ArchivesRepo {
 	return &dbArchivesRepo{}
 }
@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package db
+
+import (
+	"testing"
+
+	"github.com/nalgeon/be"
+
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+func TestPublicArchives(t *testing.T) {
+	InitInMemoryDB()
+	repo := NewArchivesRepo()
+
+	private, err := types.NewCompressedDocumentArtifact([]byte("<p>Private</p>"), "text/html")
+	be.Err(t, err, nil)
+	_, err = repo.Store(1, private)
+	be.Err(t, err, nil)
+	public, err := types.NewCompressedDocumentArtifact([]byte("<p>Wiki</p>"), "text/html")
+	be.Err(t, err, nil)
+	archiveID, err := repo.Store(2, public)
+	be.Err(t, err, nil)
+
+	ok, err := repo.ArtifactPublic(public.ID)
+	be.Err(t, err, nil)
+	be.True(t, !ok)
+
+	be.Err(t, repo.SetArchivesPublic(2, true), nil)
+	be.Err(t, repo.SetArchivesPublic(2, true), nil)
+	ok, err = repo.ArchivesPublic(2)
+	be.Err(t, err, nil)
+	be.True(t, ok)
+	ok, err = repo.ArtifactPublic(public.ID)
+	be.Err(t, err, nil)
+	be.True(t, ok)
+
+	// Archives of private bookmarks stay hidden.
+	be.Err(t, repo.SetArchivesPublic(1, true), nil)
+	ok, err = repo.ArtifactPublic(private.ID)
+	be.Err(t, err, nil)
+	be.True(t, !ok)
+
+	be.Err(t, repo.SetArchivesPublic(2, false), nil)
+	ok, err = repo.ArtifactPublic(public.ID)
+	be.Err(t, err, nil)
+	be.True(t, !ok)
+
+	var pages []types.ArchivedPage
+	collect := func(page types.ArchivedPage) error {
+		pages = append(pages, page)
+		// The only connection is free.
+		return repo.StoreText(page.ID, "")
+	}
+	be.Err(t, repo.EachArchivedPage(0, collect), nil)
+	be.Equal(t, len(pages), 2)
+
+	pages = nil
+	be.Err(t, repo.EachArchivedPage(archiveID, collect), nil)
+	be.Equal(t, len(pages), 1)
+	be.Equal(t, pages[0].URL, "https://mycorrhiza.wiki")
+	contents, err := pages[0].Artifact.Contents()
+	be.Err(t, err, nil)
+	be.Equal(t, string(contents), "<p>Wiki</p>")
+}
+
+func TestArchiveGarbage(t *testing.T) {
+	InitInMemoryDB()
+	repo := NewArchivesRepo()
+
+	page, err := types.NewCompressedDocumentArtifact([]byte("<p>Page</p>"), "text/html")
+	be.Err(t, err, nil)
+	pdf, err := types.NewCompressedDocumentArtifact([]byte("%PDF-1.7"), "application/pdf")
+	be.Err(t, err, nil)
+	firstID, err := repo.Store(1, page)
+	be.Err(t, err, nil)
+	_, err = repo.Store(2, page)
+	be.Err(t, err, nil)
+	// Bookmark 3 is deleted.
+	_, err = repo.Store(3, pdf)
+	be.Err(t, err, nil)
+
+	oldestID, err := repo.OldestArchive()
+	be.Err(t, err, nil)
+	be.Equal(t, oldestID, firstID)
+
+	usage, err := repo.Usage()
+	be.Err(t, err, nil)
+	be.Equal(t, usage.Archives, 3)
+	be.Equal(t, usage.Artifacts, 2)
+	be.Equal(t, usage.Stored, int64(len(page.Data)+len(pdf.Data)))
+	be.Equal(t, usage.Saved(), int64(len(page.Data)))
+	be.Equal(t, usage.Garbage, int64(len(pdf.Data)))
+	be.Equal(t, len(usage.ByMimeType), 2)
+	be.Equal(t, len(usage.ByBookmark), 2)
+
+	// The archive of the deleted bookmark is still there.
+	total, err := repo.TotalSize()
+	be.Err(t, err, nil)
+	be.Equal(t, total, usage.Stored)
+	be.Equal(t, usage.Quoted, total)
+
+	collected, err := repo.CollectGarbage()
+	be.Err(t, err, nil)
+	be.Equal(t, collected.Archives, 1)
+	be.Equal(t, collected.Artifacts, 1)
+	be.Equal(t, collected.Freed, int64(len(pdf.Data)))
+	be.Err(t, repo.Vacuum(), nil)
+
+	usage, err = repo.Usage()
+	be.Err(t, err, nil)
+	be.Equal(t, usage.Archives, 2)
+	be.Equal(t, usage.Garbage, int64(0))
+}
+
+func TestBookmarkImages(t *testing.T) {
+	InitInMemoryDB()
+	repo := NewArchivesRepo()
+
+	icon, err := types.NewArtifact([]byte("icon"), "image/png")
+	be.Err(t, err, nil)
+	newIcon, err := types.NewArtifact([]byte("new icon"), "image/png")
+	be.Err(t, err, nil)
+	preview, err := types.NewArtifact([]byte("preview"), "image/jpeg")
+	be.Err(t, err, nil)
+
+	be.Err(t, repo.StoreImage(1, types.ImageIcon, icon), nil)
+	be.Err(t, repo.StoreImage(2, types.ImageIcon, icon), nil)
+	be.Err(t, repo.StoreImage(2, types.ImagePreview, preview), nil)
+	// Bookmark 3 is deleted.
+	be.Err(t, repo.StoreImage(3, types.ImagePreview, preview), nil)
+
+	images, err := repo.ImagesOf([]int{1, 2, 4})
+	be.Err(t, err, nil)
+	be.Equal(t, images, map[int]types.BookmarkImages{
+		1: {IconID: icon.ID},
+		2: {IconID: icon.ID, PreviewID: preview.ID},
+	})
+
+	// Once bookmark 2 has another preview, only the deleted bookmark
+	// has this one, and visitors do not see it.
+	public, err := repo.ArtifactPublic(preview.ID)
+	be.Err(t, err, nil)
+	be.True(t, public)
+	be.Err(t, repo.StoreImage(2, types.ImagePreview, newIcon), nil)
+	public, err = repo.ArtifactPublic(preview.ID)
+	be.Err(t, err, nil)
+	be.True(t, !public)
+
+	// The old icon is still used by bookmark 2, so it stays.
+	be.Err(t, repo.StoreImage(1, types.ImageIcon, newIcon), nil)
+	stored, err := NewArtifactsRepo().Fetch(icon.ID)
+	be.Err(t, err, nil)
+	be.Equal(t, string(stored.Data), "icon")
+
+	usage, err := repo.Usage()
+	be.Err(t, err, nil)
+	be.Equal(t, usage.Garbage, int64(len(preview.Data)))
+	// Images are not archives, the quota does not count them.
+	total, err := repo.TotalSize()
+	be.Err(t, err, nil)
+	be.Equal(t, total, int64(0))
+
+	collected, err := repo.CollectGarbage()
+	be.Err(t, err, nil)
+	be.Equal(t, collected.Artifacts, 1)
+	be.Equal(t, collected.Freed, int64(len(preview.Data)))
+
+	images, err = repo.ImagesOf([]int{3})
+	be.Err(t, err, nil)
+	be.Equal(t, len(images), 0)
+}
@@ -0,0 +1,142 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package db
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	blockingports "git.sr.ht/~bouncepaw/betula/ports/blocking"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+type BlocksRepo struct{}
+
+var _ blockingports.Repository = (*BlocksRepo)(nil)
+
+func NewBlocksRepo() *BlocksRepo {
+	return &BlocksRepo{}
+}
+
+func (repo *BlocksRepo) AddBlock(ctx context.Context, block blockingports.Block) error {
+	_, err := db.ExecContext(ctx, `
+replace into Blocks (Target, Domain, Reason) values (?, ?, ?)`,
+		block.Target, block.Domain, block.Reason)
+	return err
+}
+
+func (repo *BlocksRepo) RemoveBlock(ctx context.Context, target string) error {
+	_, err := db.ExecContext(ctx, `delete from Blocks where Target = ?`, target)
+	return err
+}
+
+func (repo *BlocksRepo) Blocks(ctx context.Context) ([]blockingports.Block, error) {
+	rows, err := db.QueryContext(ctx, `
+select Target, Domain, Reason, CreatedAt from Blocks order by CreatedAt desc, Target`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blocks []blockingports.Block
+	for rows.Next() {
+		var (
+			block     blockingports.Block
+			createdAt string
+		)
+		if err := rows.Scan(&block.Target, &block.Domain, &block.Reason, &createdAt); err != nil {
+			return nil, err
+		}
+		block.CreatedAt, _ = time.Parse(types.TimeLayout, createdAt)
+		blocks = append(blocks, block)
+	}
+	return blocks, rows.Err()
+}
+
+func (repo *BlocksRepo) IsBlocked(ctx context.Context, actorID string) (bool, error) {
+	var (
+		domains = parentDomains(actorHost(actorID))
+		args    = []any{actorID}
+		q       = `select exists (select 1 from Blocks where (Domain = 0 and Target = ?)`
+	)
+	if len(domains) > 0 {
+		q += ` or (Domain = 1 and Target in (` + strings.Repeat("?, ", len(domains)-1) + `?))`
+		for _, domain := range domains {
+			args = append(args, domain)
+		}
+	}
+	q += `)`
+
+	var blocked bool
+	err := db.QueryRowContext(ctx, q, args...).Scan(&blocked)
+	return blocked, err
+}
+
+func (repo *BlocksRepo) DeleteTracesOf(ctx context.Context, block blockingports.Block) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	actorMatch, actorArgs := blockMatch(block, "ActorID")
+	remarkMatch, remarkArgs := blockMatch(block, "RepostURL")
+	if !block.Domain {
+		// Legacy Announce remarks are not in Timeline, but their IDs start with the actor ID.
+		remarkMatch = `substr(RepostURL, 1, length(?) + 1) = ? || '/'`
+		remarkArgs = []any{block.Target, block.Target}
+	}
+	notifMatch, notifArgs := blockMatch(block, `json_extract(cast(Payload as text), '$.actor_id')`)
+
+	var queries = []struct {
+		q    string
+		args []any
+	}{
+		{`delete from Likes where ActorID is not null and ` + actorMatch, actorArgs},
+		{`delete from KnownReposts where RepostURL in (select ID from Timeline where RemarkedID is not null and ` + actorMatch + `) or ` + remarkMatch,
+			append(actorArgs, remarkArgs...)},
+		{`delete from Timeline where RemarkedID is not null and ` + actorMatch, actorArgs},
+		{`delete from FollowRequests where ` + actorMatch, actorArgs},
+		{`delete from Notifications where json_valid(cast(Payload as text)) and ` + notifMatch, notifArgs},
+	}
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query.q, query.args...); err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+	}
+	return tx.Commit()
+}
+
+// blockMatch returns a condition that is true if the URL in column falls
+// under the block.
+func blockMatch(block blockingports.Block, column string) (string, []any) {
+	if !block.Domain {
+		return column + ` = ?`, []any{block.Target}
+	}
+	var host = `lower(substr(substr(` + column + `, instr(` + column + `, '://') + 3), 1,
+	instr(substr(` + column + `, instr(` + column + `, '://') + 3) || '/', '/') - 1))`
+	return `(` + host + ` = ? or substr(` + host + `, -?) = ?)`,
+		[]any{block.Target, len(block.Target) + 1, "." + block.Target}
+}
+
+// actorHost returns the lowercase host of the actor ID, without the port.
+func actorHost(actorID string) string {
+	u, err := url.Parse(actorID)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// parentDomains returns the domain and all its parent domains.
+func parentDomains(domain string) (domains []string) {
+	for domain != "" {
+		domains = append(domains, domain)
+		_, domain, _ = strings.Cut(domain, ".")
+	}
+	return domains
+}
@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package db
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/nalgeon/be"
+
+	blockingports "git.sr.ht/~bouncepaw/betula/ports/blocking"
+	likingports "git.sr.ht/~bouncepaw/betula/ports/liking"
+	notiftypes "git.sr.ht/~bouncepaw/betula/types/notif"
+)
+
+func TestIsBlocked(t *testing.T) {
+	InitInMemoryDB()
+	ctx := t.Context()
+	repo := NewBlocksRepo()
+
+	be.Err(t, repo.AddBlock(ctx, blockingports.Block{Target: "https://a.example/@troll"}), nil)
+	be.Err(t, repo.AddBlock(ctx, blockingports.Block{Target: "spam.example", Domain: true}), nil)
+
+	for actorID, expected := range map[string]bool{
+		"https://a.example/@troll":        true,
+		"https://a.example/@friend":       false,
+		"https://spam.example/@anyone":    true,
+		"https://sub.spam.example/@a":     true,
+		"https://notspam.example/@a":      false,
+		"https://spam.example.org/@a":     false,
+		"https://SPAM.example:8080/users": true,
+		"":                                false,
+	} {
+		blocked, err := repo.IsBlocked(ctx, actorID)
+		be.Err(t, err, nil)
+		be.Equal(t, blocked, expected)
+	}
+
+	be.Err(t, repo.RemoveBlock(ctx, "spam.example"), nil)
+	blocked, err := repo.IsBlocked(ctx, "https://spam.example/@anyone")
+	be.Err(t, err, nil)
+	be.Equal(t, blocked, false)
+
+	blocks, err := repo.Blocks(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(blocks), 1)
+}
+
+func TestDeleteTracesOf(t *testing.T) {
+	InitInMemoryDB()
+	ctx := t.Context()
+	repo := NewBlocksRepo()
+	likes := NewLikeRepo()
+	notifs := New()
+
+	for _, actorID := range []string{"https://spam.example/@a", "https://good.example/@b"} {
+		be.Err(t, likes.InsertLike(ctx, likingports.LikeModel{
+			ID:       sql.NullString{String: actorID + "/likes/1", Valid: true},
+			ActorID:  sql.NullString{String: actorID, Valid: true},
+			ObjectID: "1",
+		}), nil)
+		be.Err(t, notifs.Store(ctx, notiftypes.KindFollow, notiftypes.FollowPayload{ActorID: actorID}), nil)
+	}
+
+	be.Err(t, repo.DeleteTracesOf(ctx, blockingports.Block{Target: "spam.example", Domain: true}), nil)
+
+	actors, _, err := likes.ActorsThatLiked(ctx, "1")
+	be.Err(t, err, nil)
+	be.Equal(t, actors, []string{"https://good.example/@b"})
+
+	count, err := notifs.Count(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, count, int64(1))
+}
@@ -0,0 +1,142 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+
+	duplicatesports "git.sr.ht/~bouncepaw/betula/ports/duplicates"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+type DuplicatesRepo struct{}
+
+var _ duplicatesports.Repository = &DuplicatesRepo{}
+
+func NewDuplicatesRepo() *DuplicatesRepo {
+	return &DuplicatesRepo{}
+}
+
+func (repo *DuplicatesRepo) Candidates(ctx context.Context) ([]duplicatesports.Candidate, error) {
+	rows, err := db.QueryContext(ctx, `
+select ID, URL, Title, Description, Visibility, CreationTime, coalesce(CanonicalURL, URL)
+from Bookmarks
+where DeletionTime is null
+order by CreationTime, ID`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []duplicatesports.Candidate
+	for rows.Next() {
+		var c duplicatesports.Candidate
+		if err = rows.Scan(&c.ID, &c.URL, &c.Title, &c.Description, &c.Visibility, &c.CreationTime, &c.CanonicalURL); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+func (repo *DuplicatesRepo) BookmarksByIDs(ctx context.Context, ids []int) ([]types.Bookmark, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := db.QueryContext(ctx, `
+select ID, URL, Title, Description, Visibility, CreationTime, RemarkedID, OriginalAuthorID, RemarkText
+from Bookmarks
+where DeletionTime is null and ID in (`+strings.Repeat("?, ", len(ids)-1)+`?)
+order by CreationTime, ID`, args...)
+	if err != nil {
+		return nil, err
+	}
+	bookmarks, err := scanBookmarks(rows)
+	if err != nil {
+		return nil, err
+	}
+	for i, bm := range bookmarks {
+		if bookmarks[i].Tags, err = tagsForBookmarkByID(ctx, db, bm.ID); err != nil {
+			return nil, err
+		}
+	}
+	return bookmarks, nil
+}
+
+func (repo *DuplicatesRepo) Merge(ctx context.Context, survivor types.Bookmark, removedIDs []int) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+update Bookmarks
+set Title = ?, Description = ?, CreationTime = ?, EditTime = current_timestamp
+where ID = ? and DeletionTime is null`,
+		survivor.Title, survivor.Description, survivor.CreationTime, survivor.ID)
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	if _, err = tx.ExecContext(ctx, `delete from TagsToPosts where PostID = ?`, survivor.ID); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	for _, tag := range survivor.Tags {
+		if tag.Name == "" {
+			continue
+		}
+		_, err = tx.ExecContext(ctx, `insert into TagsToPosts (TagName, PostID) values (?, ?)`, tag.Name, survivor.ID)
+		if err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+	}
+
+	for _, id := range removedIDs {
+		if err = mergeInto(ctx, tx, survivor.ID, id); err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+	}
+	return tx.Commit()
+}
+
+// mergeInto moves everything attached to the bookmark removedID to the
+// survivor and deletes the bookmark.
+func mergeInto(ctx context.Context, tx *sql.Tx, survivorID, removedID int) error {
+	survivorObject, removedObject := strconv.Itoa(survivorID), strconv.Itoa(removedID)
+	queries := []struct {
+		query string
+		args  []any
+	}{
+		{`update Archives set BookmarkID = ? where BookmarkID = ?`, []any{survivorID, removedID}},
+		// The survivor's own images win.
+		{`insert or ignore into BookmarkImages (BookmarkID, Kind, ArtifactID)
+		  select ?, Kind, ArtifactID from BookmarkImages where BookmarkID = ?`, []any{survivorID, removedID}},
+		{`delete from BookmarkImages where BookmarkID = ?`, []any{removedID}},
+		{`update Likes set ObjectID = ? where ObjectID = ?`, []any{survivorObject, removedObject}},
+		// Somebody could have liked several of the duplicates. One like is enough.
+		{`delete from Likes
+		  where ObjectID = ? and ActorID is not null and rowid not in (
+		      select min(rowid) from Likes where ObjectID = ? and ActorID is not null group by ActorID)`,
+			[]any{survivorObject, survivorObject}},
+		{`update KnownReposts set PostID = ? where PostID = ?`, []any{survivorID, removedID}},
+		{`delete from LinkChecks where BookmarkID = ?`, []any{removedID}},
+		{`delete from PublicArchives where BookmarkID = ?`, []any{removedID}},
+		{`update Bookmarks set DeletionTime = current_timestamp where ID = ?`, []any{removedID}},
+	}
+	for _, q := range queries {
+		if _, err := tx.ExecContext(ctx, q.query, q.args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package db
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/nalgeon/be"
+
+	likingports "git.sr.ht/~bouncepaw/betula/ports/liking"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: true}
+}
+
+func TestMergeDuplicates(t *testing.T) {
+	InitInMemoryDB()
+	ctx := t.Context()
+	repo := NewDuplicatesRepo()
+	localBookmarks := NewLocalBookmarksRepo()
+
+	id, err := localBookmarks.InsertBookmark(ctx, types.Bookmark{
+		URL:          "https://www.mycorrhiza.wiki/?utm_source=feed",
+		Title:        "Mycorrhiza",
+		Visibility:   types.Public,
+		CreationTime: "2022-01-01 10:00:00",
+		Tags:         []types.Tag{{Name: "wiki"}},
+	})
+	be.Err(t, err, nil)
+	removed := int(id)
+
+	candidates, err := repo.Candidates(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(candidates), 3)
+	be.Equal(t, candidates[0].ID, removed)
+	be.Equal(t, candidates[0].CanonicalURL, candidates[2].CanonicalURL)
+
+	artifact, err := types.NewCompressedDocumentArtifact([]byte("<p>Wiki</p>"), "text/html")
+	be.Err(t, err, nil)
+	_, err = NewArchivesRepo().Store(int64(removed), artifact)
+	be.Err(t, err, nil)
+	likes := NewLikeRepo()
+	for _, like := range []likingports.LikeModel{
+		{ID: nullString("https://example.org/like/1"), ActorID: nullString("https://example.org/bob"), ObjectID: "2"},
+		{ID: nullString("https://example.org/like/2"), ActorID: nullString("https://example.org/bob"), ObjectID: "4"},
+		{ID: nullString("https://example.org/like/3"), ActorID: nullString("https://example.org/eve"), ObjectID: "4"},
+	} {
+		be.Err(t, likes.InsertLike(ctx, like), nil)
+	}
+
+	bookmarks, err := repo.BookmarksByIDs(ctx, []int{2, removed, 3})
+	be.Err(t, err, nil)
+	be.Equal(t, len(bookmarks), 2) // 3 is deleted
+	be.Equal(t, bookmarks[0].Tags, []types.Tag{{Name: "wiki"}})
+
+	survivor := bookmarks[1]
+	survivor.CreationTime = bookmarks[0].CreationTime
+	survivor.Description = "A wiki engine. Great one"
+	survivor.Tags = []types.Tag{{Name: "software"}, {Name: "wiki"}}
+	be.Err(t, repo.Merge(ctx, survivor, []int{removed}), nil)
+
+	_, err = localBookmarks.GetBookmarkByID(ctx, removed)
+	be.True(t, err != nil)
+	merged, err := localBookmarks.GetBookmarkByID(ctx, 2)
+	be.Err(t, err, nil)
+	be.Equal(t, merged.CreationTime, "2022-01-01 10:00:00")
+	be.Equal(t, merged.Description, "A wiki engine. Great one")
+	tags, err := tagsForBookmarkByID(ctx, db, 2)
+	be.Err(t, err, nil)
+	be.Equal(t, len(tags), 2)
+
+	archives, err := NewArchivesRepo().FetchForBookmark(2)
+	be.Err(t, err, nil)
+	be.Equal(t, len(archives), 1)
+
+	statuses, err := likes.StatiFor(ctx, []string{"2", "4"})
+	be.Err(t, err, nil)
+	be.Equal(t, statuses["2"].Count, 2)
+	be.Equal(t, statuses["4"].Count, 0)
+}
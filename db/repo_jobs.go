@@ -8,9 +8,14 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
 
 	"git.sr.ht/~bouncepaw/betula/jobs/jobtype"
 	jobsports "git.sr.ht/~bouncepaw/betula/ports/jobs"
+	"git.sr.ht/~bouncepaw/betula/types"
 )
 
 type JobsRepo struct {
@@ -23,7 +28,16 @@ func NewJobsRepo() *JobsRepo {
 }
 
 func (repo *JobsRepo) PlanJob(ctx context.Context, job jobtype.Job) (int64, error) {
-	res, err := db.ExecContext(ctx, `insert into Jobs (Category, Payload) values (?, ?)`, job.Category, job.Payload)
+	var (
+		res sql.Result
+		err error
+	)
+	if job.Due.IsZero() {
+		res, err = db.ExecContext(ctx, `insert into Jobs (Category, Payload) values (?, ?)`, job.Category, job.Payload)
+	} else {
+		res, err = db.ExecContext(ctx, `insert into Jobs (Category, Payload, Due) values (?, ?, ?)`,
+			job.Category, job.Payload, job.Due.UTC().Format(types.TimeLayout))
+	}
 	if err != nil {
 		return 0, err
 	}
@@ -39,19 +53,76 @@ func (repo *JobsRepo) DropJob(ctx context.Context, id int64) error {
 	return err
 }
 
-func (repo *JobsRepo) LoadAllJobs(ctx context.Context) ([]jobtype.Job, error) {
-	rows, err := db.QueryContext(ctx, `select id, category, payload from Jobs`)
+const jobColumns = `ID, Category, Payload, Due, Attempts, LastError, Failed`
+
+func (repo *JobsRepo) LoadDueJobs(ctx context.Context) ([]jobtype.Job, error) {
+	rows, err := db.QueryContext(ctx, `
+select `+jobColumns+`
+from Jobs
+where Failed = 0 and Due <= current_timestamp
+order by Due, ID`)
 	if err != nil {
 		return nil, err
 	}
+	return scanJobs(rows)
+}
+
+func (repo *JobsRepo) PostponeJob(ctx context.Context, id int64, delay time.Duration, lastError string) error {
+	_, err := db.ExecContext(ctx, `
+update Jobs
+set Attempts = Attempts + 1,
+    LastError = ?,
+    Due = datetime(current_timestamp, ?)
+where ID = ?`,
+		lastError, fmt.Sprintf("+%d seconds", int64(delay.Seconds())), id)
+	return err
+}
+
+func (repo *JobsRepo) DelayJob(ctx context.Context, id int64, delay time.Duration) error {
+	_, err := db.ExecContext(ctx, `update Jobs set Due = datetime(current_timestamp, ?) where ID = ?`,
+		fmt.Sprintf("+%d seconds", int64(math.Ceil(delay.Seconds()))), id)
+	return err
+}
+
+func (repo *JobsRepo) FailJob(ctx context.Context, id int64, lastError string) error {
+	_, err := db.ExecContext(ctx, `
+update Jobs
+set Attempts = Attempts + 1, LastError = ?, Failed = 1
+where ID = ?`,
+		lastError, id)
+	return err
+}
+
+func (repo *JobsRepo) RetryJob(ctx context.Context, id int64) error {
+	_, err := db.ExecContext(ctx, `
+update Jobs
+set Attempts = 0, Failed = 0, Due = current_timestamp
+where ID = ?`,
+		id)
+	return err
+}
+
+func (repo *JobsRepo) Jobs(ctx context.Context) ([]jobtype.Job, error) {
+	rows, err := db.QueryContext(ctx, `select `+jobColumns+` from Jobs order by Failed, Due, ID`)
+	if err != nil {
+		return nil, err
+	}
+	return scanJobs(rows)
+}
+
+func scanJobs(rows *sql.Rows) ([]jobtype.Job, error) {
 	defer rows.Close()
 
 	var jobs []jobtype.Job
 	for rows.Next() {
-		var job jobtype.Job
-		if err := rows.Scan(&job.ID, &job.Category, &job.Payload); err != nil {
+		var (
+			job jobtype.Job
+			due string
+		)
+		if err := rows.Scan(&job.ID, &job.Category, &job.Payload, &due, &job.Attempts, &job.LastError, &job.Failed); err != nil {
 			return nil, err
 		}
+		job.Due, _ = time.Parse(types.TimeLayout, due)
 		jobs = append(jobs, job)
 	}
 	return jobs, rows.Err()
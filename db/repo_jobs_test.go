@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+
+	"git.sr.ht/~bouncepaw/betula/jobs/jobtype"
+)
+
+// testing PlanJob, LoadDueJobs, PostponeJob, FailJob, RetryJob, DropJob.
+func TestJobLifecycle(t *testing.T) {
+	InitInMemoryDB()
+	ctx := t.Context()
+	repo := NewJobsRepo()
+
+	id, err := repo.PlanJob(ctx, jobtype.Job{
+		Category: jobtype.SendCreateNote,
+		Payload:  []byte(`{}`),
+	})
+	be.Err(t, err, nil)
+
+	due, err := repo.LoadDueJobs(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(due), 1)
+	be.Equal(t, due[0].ID, id)
+	be.Equal(t, due[0].Category, jobtype.SendCreateNote)
+	be.Equal(t, due[0].Attempts, uint(0))
+
+	// Postponed jobs are not due until their time comes.
+	be.Err(t, repo.PostponeJob(ctx, id, time.Hour, "connection refused"), nil)
+	due, err = repo.LoadDueJobs(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(due), 0)
+
+	all, err := repo.Jobs(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(all), 1)
+	be.Equal(t, all[0].Attempts, uint(1))
+	be.Equal(t, all[0].LastError, "connection refused")
+	be.True(t, all[0].Due.After(time.Now().Add(50*time.Minute)))
+
+	// Failed jobs are never due.
+	be.Err(t, repo.FailJob(ctx, id, "gone"), nil)
+	all, err = repo.Jobs(ctx)
+	be.Err(t, err, nil)
+	be.True(t, all[0].Failed)
+	be.Equal(t, all[0].Attempts, uint(2))
+
+	// Retried jobs are due right away.
+	be.Err(t, repo.RetryJob(ctx, id), nil)
+	due, err = repo.LoadDueJobs(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(due), 1)
+	be.Equal(t, due[0].Failed, false)
+	be.Equal(t, due[0].Attempts, uint(0))
+
+	be.Err(t, repo.DropJob(ctx, id), nil)
+	all, err = repo.Jobs(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(all), 0)
+}
+
+func TestPlanJobWithDue(t *testing.T) {
+	InitInMemoryDB()
+	ctx := t.Context()
+	repo := NewJobsRepo()
+
+	later := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	_, err := repo.PlanJob(ctx, jobtype.Job{
+		Category: jobtype.ArchiveBookmark,
+		Payload:  []byte(`1`),
+		Due:      later,
+	})
+	be.Err(t, err, nil)
+
+	due, err := repo.LoadDueJobs(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(due), 0)
+
+	all, err := repo.Jobs(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(all), 1)
+	be.True(t, all[0].Due.Equal(later))
+}
@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package db
+
+import (
+	"context"
+	"time"
+
+	linkrotports "git.sr.ht/~bouncepaw/betula/ports/linkrot"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+type LinkRotRepo struct{}
+
+var _ linkrotports.Repository = &LinkRotRepo{}
+
+func NewLinkRotRepo() *LinkRotRepo {
+	return &LinkRotRepo{}
+}
+
+func (repo *LinkRotRepo) StaleBookmarks(ctx context.Context, checkedBefore time.Time, limit int) ([]types.Bookmark, error) {
+	rows, err := db.QueryContext(ctx, `
+select ID, URL
+from Bookmarks
+left join LinkChecks on BookmarkID = ID
+where DeletionTime is null and (CheckedAt is null or CheckedAt < ?)
+order by CheckedAt is not null, CheckedAt, ID
+limit ?`,
+		checkedBefore.UTC().Format(types.TimeLayout), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookmarks []types.Bookmark
+	for rows.Next() {
+		var bookmark types.Bookmark
+		if err = rows.Scan(&bookmark.ID, &bookmark.URL); err != nil {
+			return nil, err
+		}
+		bookmarks = append(bookmarks, bookmark)
+	}
+	return bookmarks, rows.Err()
+}
+
+func (repo *LinkRotRepo) StoreCheck(ctx context.Context, check linkrotports.LinkCheck) error {
+	_, err := db.ExecContext(ctx, `
+replace into LinkChecks (BookmarkID, Status, Error, FinalURL)
+values (?, ?, ?, ?)`,
+		check.BookmarkID, check.Status, check.Error, check.FinalURL)
+	return err
+}
+
+func (repo *LinkRotRepo) Checks(ctx context.Context) ([]linkrotports.LinkCheck, error) {
+	rows, err := db.QueryContext(ctx, `
+select
+	Bookmarks.ID, URL, Title, Status, Error, FinalURL, CheckedAt,
+	coalesce((select ArtifactID from Archives where Archives.BookmarkID = Bookmarks.ID order by SavedAt desc, Archives.ID desc limit 1), '')
+from LinkChecks
+join Bookmarks on LinkChecks.BookmarkID = Bookmarks.ID
+where DeletionTime is null
+order by Bookmarks.ID desc`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var checks []linkrotports.LinkCheck
+	for rows.Next() {
+		var (
+			check     linkrotports.LinkCheck
+			checkedAt string
+		)
+		err = rows.Scan(&check.BookmarkID, &check.URL, &check.Title, &check.Status, &check.Error,
+			&check.FinalURL, &checkedAt, &check.ArtifactID)
+		if err != nil {
+			return nil, err
+		}
+		check.CheckedAt, _ = time.Parse(types.TimeLayout, checkedAt)
+		checks = append(checks, check)
+	}
+	return checks, rows.Err()
+}
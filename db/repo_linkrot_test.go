@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+
+	linkrotports "git.sr.ht/~bouncepaw/betula/ports/linkrot"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+func TestLinkChecks(t *testing.T) {
+	InitInMemoryDB()
+	ctx := t.Context()
+	repo := NewLinkRotRepo()
+
+	// The deleted bookmark 3 is never checked.
+	stale, err := repo.StaleBookmarks(ctx, time.Now(), 10)
+	be.Err(t, err, nil)
+	be.Equal(t, len(stale), 2)
+	be.Equal(t, stale[0].ID, 1)
+	be.Equal(t, stale[0].URL, "https://bouncepaw.com")
+
+	be.Err(t, repo.StoreCheck(ctx, linkrotports.LinkCheck{
+		BookmarkID: 1,
+		Status:     200,
+		FinalURL:   "https://bouncepaw.com/",
+	}), nil)
+	checks, err := repo.Checks(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(checks), 1)
+	be.True(t, !checks[0].Redirected())
+	be.Err(t, repo.StoreCheck(ctx, linkrotports.LinkCheck{
+		BookmarkID: 1,
+		Status:     200,
+		FinalURL:   "https://bouncepaw.com/en",
+	}), nil)
+
+	// Checked bookmarks go after the never checked ones.
+	stale, err = repo.StaleBookmarks(ctx, time.Now().Add(time.Hour), 10)
+	be.Err(t, err, nil)
+	be.Equal(t, len(stale), 2)
+	be.Equal(t, stale[0].ID, 2)
+	stale, err = repo.StaleBookmarks(ctx, time.Now().Add(-time.Hour), 10)
+	be.Err(t, err, nil)
+	be.Equal(t, len(stale), 1)
+
+	be.Err(t, repo.StoreCheck(ctx, linkrotports.LinkCheck{
+		BookmarkID: 2,
+		Error:      "no such host",
+	}), nil)
+	artifact, err := types.NewCompressedDocumentArtifact([]byte("<p>Wiki</p>"), "text/html")
+	be.Err(t, err, nil)
+	_, err = NewArchivesRepo().Store(2, artifact)
+	be.Err(t, err, nil)
+
+	checks, err = repo.Checks(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(checks), 2)
+	be.Equal(t, checks[0].BookmarkID, 2)
+	be.Equal(t, checks[0].URL, "https://mycorrhiza.wiki")
+	be.Equal(t, checks[0].ArtifactID, artifact.ID)
+	be.True(t, checks[0].Dead())
+	be.Equal(t, checks[1].ArtifactID, "")
+	be.True(t, checks[1].Redirected())
+}
@@ -14,7 +14,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	feedsports "git.sr.ht/~bouncepaw/betula/ports/feeds"
 	likingports "git.sr.ht/~bouncepaw/betula/ports/liking"
 	"git.sr.ht/~bouncepaw/betula/types"
 )
@@ -22,6 +25,7 @@ import (
 type RepoLocalBookmarks struct{}
 
 var _ likingports.LocalBookmarkRepository = &RepoLocalBookmarks{}
+var _ feedsports.Repository = &RepoLocalBookmarks{}
 
 func NewLocalBookmarksRepo() *RepoLocalBookmarks {
 	return &RepoLocalBookmarks{}
@@ -90,14 +94,14 @@ func (repo *RepoLocalBookmarks) InsertBookmark(
 	var res sql.Result
 	if bm.CreationTime == "" {
 		res, err = tx.ExecContext(ctx, `
-insert into Bookmarks (URL, Title, Description, Visibility, RemarkedID, OriginalAuthorID, RemarkText)
-values (?, ?, ?, ?, ?, ?, ?);
-`, bm.URL, bm.Title, bm.Description, bm.Visibility, bm.RemarkedID, bm.OriginalAuthor, bm.RemarkText)
+insert into Bookmarks (URL, CanonicalURL, Title, Description, Visibility, RemarkedID, OriginalAuthorID, RemarkText)
+values (?, ?, ?, ?, ?, ?, ?, ?);
+`, bm.URL, canonicalURL(bm.URL), bm.Title, bm.Description, bm.Visibility, bm.RemarkedID, bm.OriginalAuthor, bm.RemarkText)
 	} else {
 		res, err = tx.ExecContext(ctx, `
-insert into Bookmarks (URL, Title, Description, Visibility, RemarkedID, OriginalAuthorID, RemarkText, CreationTime)
-values (?, ?, ?, ?, ?, ?, ?, ?);
-`, bm.URL, bm.Title, bm.Description, bm.Visibility, bm.RemarkedID, bm.OriginalAuthor, bm.RemarkText, bm.CreationTime)
+insert into Bookmarks (URL, CanonicalURL, Title, Description, Visibility, RemarkedID, OriginalAuthorID, RemarkText, CreationTime)
+values (?, ?, ?, ?, ?, ?, ?, ?, ?);
+`, bm.URL, canonicalURL(bm.URL), bm.Title, bm.Description, bm.Visibility, bm.RemarkedID, bm.OriginalAuthor, bm.RemarkText, bm.CreationTime)
 	}
 	if err != nil {
 		return 0, errors.Join(err, tx.Rollback())
@@ -119,13 +123,14 @@ values (?, ?, ?, ?, ?, ?, ?, ?);
 	return id, tx.Commit()
 }
 
+// GetBookmarkIDByURL finds a bookmark of the same page, see bxstr.CanonicalURL.
 func (repo *RepoLocalBookmarks) GetBookmarkIDByURL(
 	ctx context.Context,
 	url string,
 ) (int, error) {
 	row := db.QueryRowContext(ctx, `
-select ID from Bookmarks where URL = ? and DeletionTime is null limit 1;
-`, url)
+select ID from Bookmarks where CanonicalURL = ? and DeletionTime is null order by ID limit 1;
+`, canonicalURL(url))
 	var id int
 	err := row.Scan(&id)
 	return id, err
@@ -277,15 +282,17 @@ func (repo *RepoLocalBookmarks) EditBookmark(
 update Bookmarks
 set
 	URL = ?,
+	CanonicalURL = ?,
 	Title = ?,
 	Description = ?,
 	Visibility = ?,
 	RemarkedID = ?,
 	OriginalAuthorID = ?,
-	RemarkText = ?
+	RemarkText = ?,
+	EditTime = current_timestamp
 where
 	ID = ? and DeletionTime is null;
-`, bm.URL, bm.Title, bm.Description, bm.Visibility, bm.RemarkedID, bm.OriginalAuthor, bm.RemarkText, bm.ID)
+`, bm.URL, canonicalURL(bm.URL), bm.Title, bm.Description, bm.Visibility, bm.RemarkedID, bm.OriginalAuthor, bm.RemarkText, bm.ID)
 	if err != nil {
 		return errors.Join(err, tx.Rollback())
 	}
@@ -347,6 +354,30 @@ func (repo *RepoLocalBookmarks) DeleteBookmark(
 	return err
 }
 
+// LastChange returns when the bookmarks with the ids were last saved or
+// edited, or when any bookmark was last deleted, whichever is later. It
+// is zero if there were no such times.
+func (repo *RepoLocalBookmarks) LastChange(ctx context.Context, ids []int) (time.Time, error) {
+	args := make([]any, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	var last sql.NullString
+	err := db.QueryRowContext(ctx, `
+select max(T) from (
+	select max(CreationTime, coalesce(EditTime, '')) as T
+	from Bookmarks
+	where ID in (`+strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")+`)
+	union all
+	select max(DeletionTime) from Bookmarks
+);
+`, args...).Scan(&last)
+	if err != nil || !last.Valid {
+		return time.Time{}, err
+	}
+	return time.Parse(types.TimeLayout, last.String)
+}
+
 func (repo *RepoLocalBookmarks) BookmarkCount(
 	ctx context.Context,
 	authorized bool,
@@ -357,8 +388,8 @@ with
 		-- Ignore deleted bookmarks always
 		select ID from Bookmarks where DeletionTime is not null
 		union
-		-- Ignore private bookmarks if so desired
-		select ID from Bookmarks where Visibility = 0 and not ?
+		-- Ignore private and unlisted bookmarks if so desired
+		select ID from Bookmarks where Visibility <> 1 and not ?
 	)
 select
 	count(ID)
@@ -7,10 +7,13 @@
 package db
 
 import (
+	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/nalgeon/be"
 
+	"git.sr.ht/~bouncepaw/betula/pkg/bxstr"
 	"git.sr.ht/~bouncepaw/betula/types"
 )
 
@@ -27,6 +30,28 @@ func TestBookmarkCount(t *testing.T) {
 	be.Equal(t, count, 1)
 }
 
+func TestLastChange(t *testing.T) {
+	InitInMemoryDB()
+	repo := NewLocalBookmarksRepo()
+	deleted := time.Date(2023, 3, 18, 12, 45, 4, 0, time.UTC)
+
+	last, err := repo.LastChange(t.Context(), nil)
+	be.Err(t, err, nil)
+	be.Equal(t, last, deleted)
+
+	before := time.Now().UTC().Add(-time.Minute)
+	bm, err := repo.GetBookmarkByID(t.Context(), 2)
+	be.Err(t, err, nil)
+	be.Err(t, repo.EditBookmark(t.Context(), bm), nil)
+
+	last, err = repo.LastChange(t.Context(), []int{1})
+	be.Err(t, err, nil)
+	be.Equal(t, last, deleted)
+	last, err = repo.LastChange(t.Context(), []int{1, 2})
+	be.Err(t, err, nil)
+	be.True(t, last.After(before))
+}
+
 func TestAddBookmark(t *testing.T) {
 	InitInMemoryDB()
 	repo := NewLocalBookmarksRepo()
@@ -71,3 +96,65 @@ func TestRandomBookmarks(t *testing.T) {
 		}
 	}
 }
+
+func TestUnlistedBookmarks(t *testing.T) {
+	InitInMemoryDB()
+	repo := NewLocalBookmarksRepo()
+	id, err := repo.InsertBookmark(t.Context(), types.Bookmark{
+		URL:         "https://joinbetula.org",
+		Title:       "Betula",
+		Description: "",
+		Visibility:  types.Unlisted,
+	})
+	be.Err(t, err, nil)
+
+	bookmark, err := repo.GetBookmarkByID(t.Context(), int(id))
+	be.Err(t, err, nil)
+	be.Equal(t, bookmark.Visibility, types.Unlisted)
+
+	count, err := repo.BookmarkCount(t.Context(), false)
+	be.Err(t, err, nil)
+	be.Equal(t, count, 1)
+
+	_, total, err := repo.Bookmarks(t.Context(), false, 1)
+	be.Err(t, err, nil)
+	be.Equal(t, total, 1)
+
+	_, total, err = repo.Bookmarks(t.Context(), true, 1)
+	be.Err(t, err, nil)
+	be.Equal(t, total, 3)
+}
+
+func TestBookmarkIDByCanonicalURL(t *testing.T) {
+	InitInMemoryDB()
+	repo := NewLocalBookmarksRepo()
+
+	// Bookmark 2 is https://mycorrhiza.wiki, bookmark 3 is deleted.
+	for _, addr := range []string{
+		"https://mycorrhiza.wiki",
+		"http://www.mycorrhiza.wiki/",
+		"https://mycorrhiza.wiki/?utm_source=fediverse#top",
+	} {
+		id, err := repo.GetBookmarkIDByURL(t.Context(), addr)
+		be.Err(t, err, nil)
+		be.Equal(t, id, 2)
+	}
+	_, err := repo.GetBookmarkIDByURL(t.Context(), "https://lesarbr.es")
+	be.Err(t, err, sql.ErrNoRows)
+
+	id, err := repo.InsertBookmark(t.Context(), types.Bookmark{
+		URL:        "https://joinbetula.org/?ref=feed",
+		Title:      "Betula",
+		Visibility: types.Public,
+	})
+	be.Err(t, err, nil)
+	_, err = repo.GetBookmarkIDByURL(t.Context(), "https://joinbetula.org")
+	be.Err(t, err, sql.ErrNoRows)
+
+	// Changing the parameters updates the old bookmarks.
+	be.Err(t, SetTrackingParams(t.Context(), []string{"ref"}), nil)
+	defer SetTrackingParams(t.Context(), bxstr.DefaultTrackingParams)
+	found, err := repo.GetBookmarkIDByURL(t.Context(), "https://joinbetula.org")
+	be.Err(t, err, nil)
+	be.Equal(t, int64(found), id)
+}
@@ -0,0 +1,310 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	imexports "git.sr.ht/~bouncepaw/betula/ports/imex"
+	settingsports "git.sr.ht/~bouncepaw/betula/ports/settings"
+)
+
+// NativeRepo backs the native export format. It reads and writes the tables
+// directly, so the archive keeps what the other repositories would lose,
+// like the creation times of likes and follows.
+type NativeRepo struct{}
+
+var _ imexports.NativeRepository = &NativeRepo{}
+
+func NewNativeRepo() *NativeRepo {
+	return &NativeRepo{}
+}
+
+// queryAll runs the query and scans every row with scan.
+func queryAll[T any](
+	ctx context.Context,
+	scan func(row interface{ Scan(...any) error }, t *T) error,
+	query string,
+	args ...any,
+) ([]T, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ts []T
+	for rows.Next() {
+		var t T
+		if err = scan(rows, &t); err != nil {
+			return nil, err
+		}
+		ts = append(ts, t)
+	}
+	return ts, rows.Err()
+}
+
+func (repo *NativeRepo) NativeBookmarks(ctx context.Context, includePrivate bool) ([]imexports.NativeBookmark, error) {
+	bookmarks, err := queryAll(ctx, func(row interface{ Scan(...any) error }, bm *imexports.NativeBookmark) error {
+		return row.Scan(&bm.ID, &bm.CreationTime, &bm.URL, &bm.Title, &bm.Description, &bm.Visibility,
+			&bm.RemarkedID, &bm.RemarkText, &bm.OriginalAuthor, &bm.ArchivesPublic)
+	}, `
+select ID, CreationTime, URL, Title, Description, Visibility,
+       RemarkedID, RemarkText, OriginalAuthorID,
+       exists(select 1 from PublicArchives where BookmarkID = Bookmarks.ID)
+from Bookmarks
+where DeletionTime is null and (? or Visibility <> 0)
+order by ID`, includePrivate)
+	if err != nil {
+		return nil, err
+	}
+
+	type tagging struct {
+		postID  int
+		tagName string
+	}
+	taggings, err := queryAll(ctx, func(row interface{ Scan(...any) error }, t *tagging) error {
+		return row.Scan(&t.postID, &t.tagName)
+	}, `select PostID, TagName from TagsToPosts order by TagName`)
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[int][]string)
+	for _, t := range taggings {
+		tags[t.postID] = append(tags[t.postID], t.tagName)
+	}
+	for i, bm := range bookmarks {
+		bookmarks[i].Tags = tags[bm.ID]
+	}
+	return bookmarks, nil
+}
+
+func (repo *NativeRepo) TagDescriptions(ctx context.Context) ([]imexports.NativeTag, error) {
+	return queryAll(ctx, func(row interface{ Scan(...any) error }, tag *imexports.NativeTag) error {
+		return row.Scan(&tag.Name, &tag.Description)
+	}, `select TagName, Description from TagDescriptions where Description <> '' order by TagName`)
+}
+
+func (repo *NativeRepo) Archives(ctx context.Context) ([]imexports.NativeArchive, error) {
+	return queryAll(ctx, func(row interface{ Scan(...any) error }, archive *imexports.NativeArchive) error {
+		return row.Scan(&archive.BookmarkID, &archive.ArtifactID, &archive.SavedAt)
+	}, `select BookmarkID, ArtifactID, SavedAt from Archives order by ID`)
+}
+
+func (repo *NativeRepo) Images(ctx context.Context) ([]imexports.NativeImage, error) {
+	return queryAll(ctx, func(row interface{ Scan(...any) error }, image *imexports.NativeImage) error {
+		return row.Scan(&image.BookmarkID, &image.Kind, &image.ArtifactID)
+	}, `select BookmarkID, Kind, ArtifactID from BookmarkImages order by BookmarkID, Kind`)
+}
+
+func (repo *NativeRepo) Artifact(ctx context.Context, id string) (imexports.NativeArtifact, []byte, error) {
+	var (
+		artifact = imexports.NativeArtifact{ID: id}
+		data     []byte
+	)
+	err := db.QueryRowContext(ctx, `
+select MimeType, IsGzipped, Kind, Data from Artifacts where ID = ?`, id).
+		Scan(&artifact.MimeType, &artifact.IsGzipped, &artifact.Kind, &data)
+	return artifact, data, err
+}
+
+func (repo *NativeRepo) Likes(ctx context.Context) ([]imexports.NativeLike, error) {
+	return queryAll(ctx, func(row interface{ Scan(...any) error }, like *imexports.NativeLike) error {
+		var source sql.NullString
+		if err := row.Scan(&like.ID, &like.ActorID, &like.ObjectID, &like.SavedAt, &source); err != nil {
+			return err
+		}
+		if source.Valid && json.Valid([]byte(source.String)) {
+			like.SourceJSON = json.RawMessage(source.String)
+		}
+		return nil
+	}, `select ID, ActorID, ObjectID, SavedAt, SourceJSON from Likes order by SavedAt`)
+}
+
+func (repo *NativeRepo) Actors(ctx context.Context) ([]imexports.NativeActor, error) {
+	return queryAll(ctx, func(row interface{ Scan(...any) error }, actor *imexports.NativeActor) error {
+		var keyID, keyPEM sql.NullString
+		err := row.Scan(&actor.ID, &actor.PreferredUsername, &actor.Inbox, &actor.SharedInbox,
+			&actor.DisplayedName, &actor.Summary, &actor.Domain, &keyID, &keyPEM)
+		actor.PublicKeyID, actor.PublicKeyPEM = keyID.String, keyPEM.String
+		return err
+	}, `
+select Actors.ID, PreferredUsername, Inbox, SharedInbox, DisplayedName, Summary, Domain,
+       PublicKeys.ID, PublicKeyPEM
+from Actors
+left join PublicKeys on Owner = Actors.ID
+group by Actors.ID
+order by Actors.ID`)
+}
+
+func (repo *NativeRepo) Followers(ctx context.Context) ([]imexports.NativeFollow, error) {
+	return queryAll(ctx, func(row interface{ Scan(...any) error }, follow *imexports.NativeFollow) error {
+		return row.Scan(&follow.ActorID, &follow.SubscribedAt)
+	}, `select ActorID, SubscribedAt from Followers order by ActorID`)
+}
+
+func (repo *NativeRepo) Following(ctx context.Context) ([]imexports.NativeFollow, error) {
+	return queryAll(ctx, func(row interface{ Scan(...any) error }, follow *imexports.NativeFollow) error {
+		return row.Scan(&follow.ActorID, &follow.SubscribedAt, &follow.Accepted)
+	}, `select ActorID, SubscribedAt, AcceptedStatus from Following order by ActorID`)
+}
+
+func (repo *NativeRepo) Blocks(ctx context.Context) ([]imexports.NativeBlock, error) {
+	return queryAll(ctx, func(row interface{ Scan(...any) error }, block *imexports.NativeBlock) error {
+		return row.Scan(&block.Target, &block.Domain, &block.Reason, &block.CreatedAt)
+	}, `select Target, Domain, Reason, CreatedAt from Blocks order by Target`)
+}
+
+func (repo *NativeRepo) PrivateKey(ctx context.Context) (string, error) {
+	pem, err := metaEntry[sql.NullString](ctx, settingsports.BetulaMetaPrivateKey)
+	return pem.String, err
+}
+
+func (repo *NativeRepo) RestoreBookmark(ctx context.Context, bm imexports.NativeBookmark) (int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	// Deleted bookmarks keep their IDs, so they count as taken too.
+	var id sql.NullInt64
+	err = tx.QueryRowContext(ctx, `select ID from Bookmarks where ID = ?`, bm.ID).Scan(&id)
+	switch {
+	case errors.Is(err, sql.ErrNoRows) && bm.ID > 0:
+		id = sql.NullInt64{Int64: int64(bm.ID), Valid: true}
+	case err == nil || errors.Is(err, sql.ErrNoRows):
+		id = sql.NullInt64{} // Let SQLite pick.
+	default:
+		return 0, errors.Join(err, tx.Rollback())
+	}
+
+	err = tx.QueryRowContext(ctx, `
+insert into Bookmarks (ID, URL, CanonicalURL, Title, Description, Visibility, CreationTime, RemarkedID, OriginalAuthorID, RemarkText)
+values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+returning ID`,
+		id, bm.URL, canonicalURL(bm.URL), bm.Title, bm.Description, bm.Visibility, bm.CreationTime,
+		bm.RemarkedID, bm.OriginalAuthor, bm.RemarkText,
+	).Scan(&id)
+	if err != nil {
+		return 0, errors.Join(err, tx.Rollback())
+	}
+
+	for _, tag := range bm.Tags {
+		if tag == "" {
+			continue
+		}
+		_, err = tx.ExecContext(ctx, `insert into TagsToPosts (TagName, PostID) values (?, ?)`, tag, id)
+		if err != nil {
+			return 0, errors.Join(err, tx.Rollback())
+		}
+	}
+	if bm.ArchivesPublic {
+		_, err = tx.ExecContext(ctx, `insert or ignore into PublicArchives (BookmarkID) values (?)`, id)
+		if err != nil {
+			return 0, errors.Join(err, tx.Rollback())
+		}
+	}
+	return int(id.Int64), tx.Commit()
+}
+
+func (repo *NativeRepo) RestoreTagDescription(ctx context.Context, tag imexports.NativeTag) error {
+	_, err := db.ExecContext(ctx, `
+insert or ignore into TagDescriptions (TagName, Description) values (?, ?)`,
+		tag.Name, tag.Description)
+	return err
+}
+
+func (repo *NativeRepo) RestoreArtifact(ctx context.Context, artifact imexports.NativeArtifact, data []byte) error {
+	_, err := db.ExecContext(ctx, `
+insert or ignore into Artifacts (ID, MimeType, Data, IsGzipped, Kind) values (?, ?, ?, ?, ?)`,
+		artifact.ID, artifact.MimeType, data, artifact.IsGzipped, artifact.Kind)
+	return err
+}
+
+func (repo *NativeRepo) RestoreArchive(ctx context.Context, archive imexports.NativeArchive) error {
+	_, err := db.ExecContext(ctx, `
+insert into Archives (BookmarkID, ArtifactID, SavedAt)
+select ?, ?, ?
+where not exists (select 1 from Archives where BookmarkID = ? and ArtifactID = ?)`,
+		archive.BookmarkID, archive.ArtifactID, archive.SavedAt,
+		archive.BookmarkID, archive.ArtifactID)
+	return err
+}
+
+func (repo *NativeRepo) RestoreImage(ctx context.Context, image imexports.NativeImage) error {
+	_, err := db.ExecContext(ctx, `
+insert or ignore into BookmarkImages (BookmarkID, Kind, ArtifactID) values (?, ?, ?)`,
+		image.BookmarkID, image.Kind, image.ArtifactID)
+	return err
+}
+
+func (repo *NativeRepo) RestoreLike(ctx context.Context, like imexports.NativeLike) error {
+	var source sql.NullString
+	if len(like.SourceJSON) > 0 {
+		source = sql.NullString{String: string(like.SourceJSON), Valid: true}
+	}
+	// Our likes have no ID, so they are told apart by the object.
+	_, err := db.ExecContext(ctx, `
+insert or ignore into Likes (ID, ActorID, ObjectID, SavedAt, SourceJSON)
+select ?, ?, ?, ?, ?
+where not exists (select 1 from Likes where ObjectID = ? and ActorID is ?)`,
+		like.ID, like.ActorID, like.ObjectID, like.SavedAt, source,
+		like.ObjectID, like.ActorID)
+	return err
+}
+
+func (repo *NativeRepo) RestoreActor(ctx context.Context, actor imexports.NativeActor) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+insert or ignore into Actors (ID, PreferredUsername, Inbox, SharedInbox, DisplayedName, Summary, Domain)
+values (?, ?, ?, ?, ?, ?, ?)`,
+		actor.ID, actor.PreferredUsername, actor.Inbox, actor.SharedInbox,
+		actor.DisplayedName, actor.Summary, actor.Domain)
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	if actor.PublicKeyID != "" {
+		_, err = tx.ExecContext(ctx, `
+insert or ignore into PublicKeys (ID, Owner, PublicKeyPEM) values (?, ?, ?)`,
+			actor.PublicKeyID, actor.ID, actor.PublicKeyPEM)
+		if err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+	}
+	return tx.Commit()
+}
+
+func (repo *NativeRepo) RestoreFollower(ctx context.Context, follow imexports.NativeFollow) error {
+	_, err := db.ExecContext(ctx, `
+insert or ignore into Followers (ActorID, SubscribedAt) values (?, ?)`,
+		follow.ActorID, follow.SubscribedAt)
+	return err
+}
+
+func (repo *NativeRepo) RestoreFollowing(ctx context.Context, follow imexports.NativeFollow) error {
+	_, err := db.ExecContext(ctx, `
+insert or ignore into Following (ActorID, SubscribedAt, AcceptedStatus) values (?, ?, ?)`,
+		follow.ActorID, follow.SubscribedAt, follow.Accepted)
+	return err
+}
+
+func (repo *NativeRepo) RestoreBlock(ctx context.Context, block imexports.NativeBlock) error {
+	_, err := db.ExecContext(ctx, `
+insert or ignore into Blocks (Target, Domain, Reason, CreatedAt) values (?, ?, ?, ?)`,
+		block.Target, block.Domain, block.Reason, block.CreatedAt)
+	return err
+}
+
+func (repo *NativeRepo) RestorePrivateKey(ctx context.Context, pem string) error {
+	return setMetaEntry(ctx, settingsports.BetulaMetaPrivateKey, pem)
+}
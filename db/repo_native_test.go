@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package db
+
+import (
+	"testing"
+
+	"github.com/nalgeon/be"
+
+	imexports "git.sr.ht/~bouncepaw/betula/ports/imex"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+func TestNativeBookmarks(t *testing.T) {
+	InitInMemoryDB()
+	ctx := t.Context()
+	repo := NewNativeRepo()
+
+	remarked := "https://remote.example/bookmarks/7"
+	author := "https://remote.example/@alice"
+	// 3 is deleted, but the ID is still taken.
+	for _, bm := range []imexports.NativeBookmark{
+		{ID: 3, URL: "https://taken.example", Title: "Taken", CreationTime: "2024-01-01 10:00:00", Visibility: types.Public},
+		{ID: 10, URL: "https://free.example", Title: "Free", CreationTime: "2024-01-02 10:00:00", Visibility: types.Private,
+			Tags: []string{"a", "b"}, RemarkedID: &remarked, OriginalAuthor: &author, ArchivesPublic: true},
+	} {
+		_, err := repo.RestoreBookmark(ctx, bm)
+		be.Err(t, err, nil)
+	}
+
+	bookmarks, err := repo.NativeBookmarks(ctx, true)
+	be.Err(t, err, nil)
+	be.Equal(t, len(bookmarks), 4)
+	be.Equal(t, bookmarks[2].ID, 4)
+	be.Equal(t, bookmarks[2].Title, "Taken")
+	be.Equal(t, bookmarks[2].CreationTime, "2024-01-01 10:00:00")
+	be.Equal(t, bookmarks[3].ID, 10)
+	be.Equal(t, bookmarks[3].Tags, []string{"a", "b"})
+	be.Equal(t, *bookmarks[3].RemarkedID, remarked)
+	be.Equal(t, *bookmarks[3].OriginalAuthor, author)
+	be.True(t, bookmarks[3].ArchivesPublic)
+
+	public, err := repo.NativeBookmarks(ctx, false)
+	be.Err(t, err, nil)
+	be.Equal(t, len(public), 2)
+}
+
+func TestNativeRestoreKeepsExisting(t *testing.T) {
+	InitInMemoryDB()
+	ctx := t.Context()
+	repo := NewNativeRepo()
+
+	actor := "https://remote.example/@alice"
+	likeID := "https://remote.example/likes/1"
+	likes := []imexports.NativeLike{
+		{ObjectID: "https://remote.example/bookmarks/7", SavedAt: "2024-01-01 10:00:00"},
+		{ID: &likeID, ActorID: &actor, ObjectID: "1", SavedAt: "2024-01-01 11:00:00"},
+	}
+	for range 2 {
+		for _, like := range likes {
+			be.Err(t, repo.RestoreLike(ctx, like), nil)
+		}
+		be.Err(t, repo.RestoreActor(ctx, imexports.NativeActor{
+			ID: actor, PreferredUsername: "alice", Inbox: actor + "/inbox", Domain: "remote.example",
+			PublicKeyID: actor + "#main-key", PublicKeyPEM: "PEM",
+		}), nil)
+		be.Err(t, repo.RestoreFollower(ctx, imexports.NativeFollow{ActorID: actor, SubscribedAt: "2024-01-01 12:00:00"}), nil)
+		be.Err(t, repo.RestoreTagDescription(ctx, imexports.NativeTag{Name: "a", Description: "Restored"}), nil)
+	}
+
+	gotLikes, err := repo.Likes(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, gotLikes, likes)
+
+	actors, err := repo.Actors(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(actors), 1)
+	be.Equal(t, actors[0].PublicKeyPEM, "PEM")
+
+	followers, err := repo.Followers(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, followers, []imexports.NativeFollow{{ActorID: actor, SubscribedAt: "2024-01-01 12:00:00"}})
+
+	be.Err(t, NewTagsRepo().SetTagDescription(ctx, "a", "Edited"), nil)
+	be.Err(t, repo.RestoreTagDescription(ctx, imexports.NativeTag{Name: "a", Description: "Restored"}), nil)
+	tags, err := repo.TagDescriptions(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, tags, []imexports.NativeTag{{Name: "a", Description: "Edited"}})
+}
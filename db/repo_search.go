@@ -10,9 +10,13 @@ package db
 
 import (
 	"context"
-	"slices"
-	"sort"
+	"database/sql"
+	"errors"
+	"html/template"
+	"log/slog"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	searchingports "git.sr.ht/~bouncepaw/betula/ports/searching"
 	"git.sr.ht/~bouncepaw/betula/types"
@@ -28,151 +32,273 @@ func NewSearchRepo() *SearchRepo {
 }
 
 func (repo *SearchRepo) SearchOffset(ctx context.Context, query searchingports.OffsetQuery) (results []types.Bookmark, totalResults uint, err error) {
-	text := strings.ToLower(query.Text)
-	sort.Strings(query.IncludedTags)
-	sort.Strings(query.ExcludedTags)
-
-	rows, err := db.QueryContext(ctx, `
-select ID, URL, Title, Description, Visibility, CreationTime, RemarkedID, OriginalAuthorID, RemarkText
-from Bookmarks
-where DeletionTime is null and Visibility = 1
-order by CreationTime desc
-`)
-	if err != nil {
-		return nil, 0, err
-	}
+	var f = newSearchFilter(query.Expr, false)
+	f.where("b.Visibility = ?", types.Public)
+	f.where("b.RemarkedID is null") // for now
+	return f.run(ctx, query.Offset, query.Limit)
+}
 
-	unfilteredBookmarks, err := scanBookmarks(rows)
-	if err != nil {
-		return nil, 0, err
-	}
+func (repo *SearchRepo) Search(ctx context.Context, query searchingports.Query) (results []types.Bookmark, totalResults uint, err error) {
+	var f = newSearchFilter(query.Expr, query.Authorized)
+	f.where("(b.Visibility = ? or ?)", types.Public, query.Authorized)
+	return f.run(ctx, (query.Page-1)*types.BookmarksPerPage, types.BookmarksPerPage)
+}
 
-	var i uint = 0
-	var ignoredBookmarks uint = 0
-	bookmarksToIgnore := query.Offset
+// searchFilter accumulates the where clause of a search query.
+//
+// Text is matched against the BookmarksSearch full-text index. The index
+// uses the trigram tokenizer, which cannot match terms shorter than three
+// characters, so such terms fall back to LIKE. Archive text is matched
+// against the ArchivesSearch index the same way.
+type searchFilter struct {
+	rank         string // Full-text query the results are ranked by.
+	archiveMatch string // Full-text query for archive snippets.
+	allArchives  bool   // Whether to look in archives not shown to visitors.
+	conditions   []string
+	args         []any
+}
 
-	for _, bookmark := range unfilteredBookmarks {
-		if !textOK(bookmark, text) {
-			continue
-		}
+// Bookmark columns weights for ranking: Title, Description, URL, RemarkText.
+const searchRanking = `bm25(BookmarksSearch, 10.0, 4.0, 2.0, 4.0)`
 
-		bookmark.Tags, err = tagsForBookmarkByID(ctx, db, bookmark.ID)
-		if err != nil {
-			return nil, 0, err
+// urlHost is the lowercase host part of the bookmark URL.
+const urlHost = `lower(substr(substr(b.URL, instr(b.URL, '://') + 3), 1,
+	instr(substr(b.URL, instr(b.URL, '://') + 3) || '/', '/') - 1))`
+
+func newSearchFilter(expr searchingports.Expr, allArchives bool) *searchFilter {
+	var f = &searchFilter{allArchives: allArchives}
+	if expr != nil {
+		var condition, args = f.compile(expr, false)
+		f.where(condition, args...)
+	}
+	return f
+}
+
+func (f *searchFilter) where(condition string, args ...any) {
+	f.conditions = append(f.conditions, condition)
+	f.args = append(f.args, args...)
+}
+
+// compile turns the expression into an SQL condition over Bookmarks b.
+// Positive text terms are collected for ranking along the way.
+func (f *searchFilter) compile(expr searchingports.Expr, negated bool) (string, []any) {
+	switch expr := expr.(type) {
+	case searchingports.And:
+		return f.compileMany(expr, " and ", "1", negated)
+	case searchingports.Or:
+		return f.compileMany(expr, " or ", "0", negated)
+	case searchingports.Not:
+		var condition, args = f.compile(expr.Expr, !negated)
+		return "not (" + condition + ")", args
+	case searchingports.Text:
+		return f.compileText(expr, negated)
+	case searchingports.Tag:
+		return "exists (select 1 from TagsToPosts where PostID = b.ID and TagName = ?)", []any{string(expr)}
+	case searchingports.Site:
+		var host = string(expr)
+		return "(" + urlHost + " = ? or " + urlHost + ` like ? escape '\')`, []any{host, "%." + escapeLike(host)}
+	case searchingports.Before:
+		return "b.CreationTime < ?", []any{time.Time(expr).Format(time.DateOnly)}
+	case searchingports.After:
+		return "b.CreationTime >= ?", []any{time.Time(expr).AddDate(0, 0, 1).Format(time.DateOnly)}
+	case searchingports.Is:
+		return "b.Visibility = ?", []any{types.Visibility(expr)}
+	case searchingports.Has:
+		switch searchingports.Feature(expr) {
+		case searchingports.FeatureArchive:
+			var hasArchive = "exists (select 1 from Archives where BookmarkID = b.ID)"
+			if !f.allArchives {
+				hasArchive += " and b.ID in (select BookmarkID from PublicArchives)"
+			}
+			return "(" + hasArchive + ")", nil
+		case searchingports.FeatureDescription:
+			return "b.Description <> ''", nil
 		}
-		if !tagsOK(bookmark.Tags, query.IncludedTags, query.ExcludedTags) {
-			continue
+	case searchingports.Remark:
+		if expr.URL == "" {
+			return "b.RemarkedID is not null", nil
 		}
+		return "(b.RemarkedID = ? or (b.RemarkedID is not null and b.OriginalAuthorID = ?))", []any{expr.URL, expr.URL}
+	}
+	slog.Error("Unknown search expression, ignoring", "expr", expr)
+	return "1", nil
+}
+
+func (f *searchFilter) compileMany(exprs []searchingports.Expr, sep, empty string, negated bool) (string, []any) {
+	if len(exprs) == 0 {
+		return empty, nil
+	}
+	var (
+		conditions = make([]string, len(exprs))
+		args       []any
+	)
+	for i, expr := range exprs {
+		var condition, exprArgs = f.compile(expr, negated)
+		conditions[i] = condition
+		args = append(args, exprArgs...)
+	}
+	return "(" + strings.Join(conditions, sep) + ")", args
+}
 
-		isRemark := bookmark.RemarkedID != nil
-		if isRemark {
-			continue // for now
+func (f *searchFilter) compileText(text searchingports.Text, negated bool) (string, []any) {
+	if text.Field == searchingports.FieldArchive {
+		return f.compileArchiveText(text, negated)
+	}
+
+	var columns []string
+	switch text.Field {
+	case searchingports.FieldTitle:
+		columns = []string{"Title"}
+	case searchingports.FieldURL:
+		columns = []string{"URL"}
+	default:
+		columns = []string{"Title", "Description", "URL", "RemarkText"}
+	}
+
+	if utf8.RuneCountInString(text.Text) < 3 {
+		var (
+			pattern    = "%" + escapeLike(text.Text) + "%"
+			conditions = make([]string, len(columns))
+			args       = make([]any, len(columns))
+		)
+		for i, column := range columns {
+			conditions[i] = "b." + column + ` like ? escape '\'`
+			args[i] = pattern
 		}
+		return "(" + strings.Join(conditions, " or ") + ")", args
+	}
 
-		totalResults++
-		if ignoredBookmarks >= bookmarksToIgnore && i < query.Limit {
-			results = append(results, bookmark)
-			i++
-		} else {
-			ignoredBookmarks++
+	var match = `"` + strings.ReplaceAll(text.Text, `"`, `""`) + `"`
+	if len(columns) == 1 {
+		match = columns[0] + " : " + match
+	}
+	if !negated {
+		if f.rank != "" {
+			f.rank += " OR "
 		}
+		f.rank += match
 	}
-	return results, totalResults, nil
+	return "b.ID in (select rowid from BookmarksSearch where BookmarksSearch match ?)", []any{match}
 }
 
-func (repo *SearchRepo) Search(ctx context.Context, query searchingports.Query) (results []types.Bookmark, totalResults uint, err error) {
-	text := strings.ToLower(query.Text)
-	sort.Strings(query.IncludedTags)
-	sort.Strings(query.ExcludedTags)
-
-	rows, err := db.QueryContext(ctx, `
-select ID, URL, Title, Description, Visibility, CreationTime, RemarkedID, OriginalAuthorID, RemarkText
-from Bookmarks
-where DeletionTime is null and (Visibility = 1 or ?)
-order by CreationTime desc
-`, query.Authorized)
-	if err != nil {
-		return nil, 0, err
+func (f *searchFilter) compileArchiveText(text searchingports.Text, negated bool) (string, []any) {
+	var visible = ""
+	if !f.allArchives {
+		visible = " and arc.BookmarkID in (select BookmarkID from PublicArchives)"
 	}
 
-	unfilteredBookmarks, err := scanBookmarks(rows)
-	if err != nil {
-		return nil, 0, err
+	if utf8.RuneCountInString(text.Text) < 3 {
+		return `b.ID in (
+			select arc.BookmarkID
+			from Archives arc
+			join ArchivesText t on t.ArchiveID = arc.ID
+			where t.Text like ? escape '\'` + visible + `)`, []any{"%" + escapeLike(text.Text) + "%"}
 	}
 
-	var i uint = 0
-	var ignoredBookmarks uint = 0
-	bookmarksToIgnore := (query.Page - 1) * types.BookmarksPerPage
-
-	// ‘Say, Bouncepaw, why did not you implement tag inclusion/exclusion
-	//  part in SQL directly?’, some may ask.
-	// ‘I did, and it was not worth it’, so I would respond.
-	//
-	// Addendum: I tried to make case-insensitive search in SQL too, and
-	// failed loudly. Now all the search is done in Go. Per aspera ad
-	// astra.
-	//
-	// We can't even parallelize it.
-	for _, bookmark := range unfilteredBookmarks {
-		if !textOK(bookmark, text) {
-			continue
+	var match = `"` + strings.ReplaceAll(text.Text, `"`, `""`) + `"`
+	if !negated {
+		if f.archiveMatch != "" {
+			f.archiveMatch += " OR "
 		}
+		f.archiveMatch += match
+	}
+	return `b.ID in (
+		select arc.BookmarkID
+		from ArchivesSearch s
+		join Archives arc on arc.ID = s.rowid
+		where ArchivesSearch match ?` + visible + `)`, []any{match}
+}
 
-		bookmark.Tags, err = tagsForBookmarkByID(ctx, db, bookmark.ID)
-		if err != nil {
-			return nil, 0, err
-		}
-		if !tagsOK(bookmark.Tags, query.IncludedTags, query.ExcludedTags) {
-			continue
-		}
+// Snippet markers, replaced with <mark> after escaping.
+const (
+	snippetMarkStart = "\x02"
+	snippetMarkEnd   = "\x03"
+)
+
+// snippetTokens is about how many trigrams a snippet has.
+const snippetTokens = 32
+
+func (repo *SearchRepo) ArchiveMatches(ctx context.Context, query searchingports.Query, bookmarkIDs []int) (map[int]types.ArchiveMatch, error) {
+	var f = newSearchFilter(query.Expr, query.Authorized)
+	if f.archiveMatch == "" || len(bookmarkIDs) == 0 {
+		return nil, nil
+	}
 
-		isRemark := bookmark.RemarkedID != nil
-		if !isRemark && query.RemarksOnly {
+	var visible = ""
+	if !query.Authorized {
+		visible = "and arc.BookmarkID in (select BookmarkID from PublicArchives)"
+	}
+	var matches = make(map[int]types.ArchiveMatch)
+	for _, bookmarkID := range bookmarkIDs {
+		var (
+			match      types.ArchiveMatch
+			rawSnippet string
+		)
+		err := db.QueryRowContext(ctx, `
+			select arc.ID, arc.ArtifactID, snippet(ArchivesSearch, 0, ?, ?, '…', ?)
+			from ArchivesSearch s
+			join Archives arc on arc.ID = s.rowid
+			where ArchivesSearch match ? and arc.BookmarkID = ? `+visible+`
+			order by arc.SavedAt desc, arc.ID desc
+			limit 1`,
+			snippetMarkStart, snippetMarkEnd, snippetTokens, f.archiveMatch, bookmarkID,
+		).Scan(&match.ArchiveID, &match.ArtifactID, &rawSnippet)
+		if errors.Is(err, sql.ErrNoRows) {
 			continue
+		} else if err != nil {
+			return nil, err
 		}
 
-		totalResults++
-		if ignoredBookmarks >= bookmarksToIgnore && i < types.BookmarksPerPage {
-			results = append(results, bookmark)
-			i++
-		} else {
-			ignoredBookmarks++
-		}
+		match.Snippet = template.HTML(strings.NewReplacer(
+			snippetMarkStart, "<mark>",
+			snippetMarkEnd, "</mark>",
+		).Replace(template.HTMLEscapeString(rawSnippet)))
+		matches[bookmarkID] = match
 	}
-	return results, totalResults, nil
+	return matches, nil
 }
 
-// true if keep, false if discard.
-func textOK(bookmark types.Bookmark, text string) bool {
-	return strings.Contains(strings.ToLower(bookmark.Title), text) ||
-		strings.Contains(strings.ToLower(bookmark.Description), text) ||
-		strings.Contains(strings.ToLower(bookmark.URL), text)
-}
+// run counts all matching bookmarks and returns the ones in the requested
+// window, with tags populated. Deleted bookmarks are never returned.
+func (f *searchFilter) run(ctx context.Context, offset, limit uint) (results []types.Bookmark, totalResults uint, err error) {
+	var filter = "from Bookmarks b\nwhere " + strings.Join(append([]string{"b.DeletionTime is null"}, f.conditions...), "\n  and ")
 
-// true if keep, false if discard. All slices are sorted.
-func tagsOK(bookmarkTags []types.Tag, includedTags, excludedTags []string) bool {
-	J, K := len(includedTags), len(excludedTags)
-	j, k := 0, 0
-	includeMask := make([]bool, J)
-	for _, bookmarkTag := range bookmarkTags {
-		name := bookmarkTag.Name
-		switch {
-		case k < K && excludedTags[k] == name:
-			return false
-		case j < J && includedTags[j] == name:
-			includeMask[j] = true
-			j++
-			continue
-		}
+	if err = db.QueryRowContext(ctx, "select count(*)\n"+filter, f.args...).Scan(&totalResults); err != nil {
+		return nil, 0, err
+	}
+	if totalResults == 0 || limit == 0 {
+		return nil, totalResults, nil
+	}
 
-		for j < J && includedTags[j] < name {
-			j++
-		}
+	var (
+		q    = "select b.ID, b.URL, b.Title, b.Description, b.Visibility, b.CreationTime, b.RemarkedID, b.OriginalAuthorID, b.RemarkText\n" + filter
+		args = f.args
+	)
+	if f.rank != "" {
+		q += "\norder by coalesce((select " + searchRanking + " from BookmarksSearch where BookmarksSearch match ? and rowid = b.ID), 0), b.CreationTime desc"
+		args = append(args, f.rank)
+	} else {
+		q += "\norder by b.CreationTime desc"
+	}
+	rows, err := db.QueryContext(ctx, q+"\nlimit ? offset ?", append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	results, err = scanBookmarks(rows)
+	if err != nil {
+		return nil, 0, err
+	}
 
-		for k < K && excludedTags[k] < name {
-			k++
+	for i, bookmark := range results {
+		results[i].Tags, err = tagsForBookmarkByID(ctx, db, bookmark.ID)
+		if err != nil {
+			return nil, 0, err
 		}
 	}
+	return results, totalResults, nil
+}
 
-	return !slices.Contains(includeMask, false)
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
 }
@@ -0,0 +1,188 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package db
+
+import (
+	"context"
+	"html/template"
+	"strings"
+	"testing"
+	"time"
+
+	searchingports "git.sr.ht/~bouncepaw/betula/ports/searching"
+	"git.sr.ht/~bouncepaw/betula/types"
+	"github.com/nalgeon/be"
+)
+
+func text(s string) searchingports.Expr {
+	return searchingports.Text{Text: s}
+}
+
+func TestSearchText(t *testing.T) {
+	initInMemoryTags()
+	ctx := context.Background()
+	repo := NewSearchRepo()
+
+	// Substring, case-insensitive.
+	results, total, err := repo.Search(ctx, searchingports.Query{Expr: text("WIKI"), Authorized: true, Page: 1})
+	be.Err(t, err, nil)
+	be.Equal(t, total, uint(1))
+	be.Equal(t, results[0].ID, 2)
+	be.Equal(t, len(results[0].Tags), 1)
+
+	// Private bookmarks need authorization.
+	_, total, err = repo.Search(ctx, searchingports.Query{Expr: text("cute"), Page: 1})
+	be.Err(t, err, nil)
+	be.Equal(t, total, uint(0))
+
+	// Deleted bookmarks are never found.
+	_, total, err = repo.Search(ctx, searchingports.Query{Expr: text("arbres"), Authorized: true, Page: 1})
+	be.Err(t, err, nil)
+	be.Equal(t, total, uint(0))
+
+	// Terms too short for the index still match.
+	_, total, err = repo.Search(ctx, searchingports.Query{Expr: text("wi"), Authorized: true, Page: 1})
+	be.Err(t, err, nil)
+	be.Equal(t, total, uint(1))
+
+	// Quotes do not break the query.
+	_, total, err = repo.Search(ctx, searchingports.Query{Expr: text(`"wiki`), Authorized: true, Page: 1})
+	be.Err(t, err, nil)
+	be.Equal(t, total, uint(0))
+}
+
+func TestSearchIndexInSync(t *testing.T) {
+	InitInMemoryDB()
+	ctx := context.Background()
+	bookmarks := NewLocalBookmarksRepo()
+	repo := NewSearchRepo()
+	search := func(s string) uint {
+		_, total, err := repo.Search(ctx, searchingports.Query{Expr: text(s), Authorized: true, Page: 1})
+		be.Err(t, err, nil)
+		return total
+	}
+
+	id, err := bookmarks.InsertBookmark(ctx, types.Bookmark{
+		URL:         "https://example.org",
+		Title:       "Pufferfish facts",
+		Description: "They inflate",
+		Visibility:  types.Public,
+	})
+	be.Err(t, err, nil)
+	be.Equal(t, search("pufferfish"), uint(1))
+
+	bm, err := bookmarks.GetBookmarkByID(ctx, int(id))
+	be.Err(t, err, nil)
+	bm.Title = "Octopus facts"
+	be.Err(t, bookmarks.EditBookmark(ctx, bm), nil)
+	be.Equal(t, search("pufferfish"), uint(0))
+	be.Equal(t, search("octopus"), uint(1))
+
+	be.Err(t, bookmarks.DeleteBookmark(ctx, int(id)), nil)
+	be.Equal(t, search("octopus"), uint(0))
+}
+
+func TestSearchTagsAndPagination(t *testing.T) {
+	initInMemoryTags()
+	MoreTestingBookmarks()
+	ctx := context.Background()
+	repo := NewSearchRepo()
+
+	results, total, err := repo.Search(ctx, searchingports.Query{Expr: searchingports.Tag("flounder"), Authorized: true, Page: 1})
+	be.Err(t, err, nil)
+	be.Equal(t, total, uint(1)) // Bookmark 3 is deleted
+	be.Equal(t, results[0].ID, 2)
+
+	_, total, err = repo.Search(ctx, searchingports.Query{Expr: searchingports.Not{Expr: searchingports.Tag("flounder")}, Authorized: true, Page: 1})
+	be.Err(t, err, nil)
+	be.Equal(t, total, uint(4))
+
+	results, total, err = repo.SearchOffset(ctx, searchingports.OffsetQuery{Expr: text("bouncepaw"), Offset: 1, Limit: 1})
+	be.Err(t, err, nil)
+	be.Equal(t, total, uint(3)) // Private one is not there
+	be.Equal(t, len(results), 1)
+	be.Equal(t, results[0].Title, "Tres")
+}
+
+func TestSearchExpressions(t *testing.T) {
+	initInMemoryTags()
+	MoreTestingBookmarks()
+	ctx := context.Background()
+	repo := NewSearchRepo()
+	search := func(expr searchingports.Expr) uint {
+		_, total, err := repo.Search(ctx, searchingports.Query{Expr: expr, Authorized: true, Page: 1})
+		be.Err(t, err, nil)
+		return total
+	}
+
+	be.Equal(t, search(searchingports.Text{Field: searchingports.FieldTitle, Text: "wiki"}), uint(1))
+	be.Equal(t, search(searchingports.Text{Field: searchingports.FieldURL, Text: "wiki engine"}), uint(0))
+	be.Equal(t, search(searchingports.Text{Text: "a wiki", Phrase: true}), uint(1))
+	be.Equal(t, search(searchingports.Or{text("uno"), text("tres")}), uint(2))
+	be.Equal(t, search(searchingports.And{text("uno"), text("tres")}), uint(0))
+	be.Equal(t, search(searchingports.Site("bouncepaw.com")), uint(1))
+	be.Equal(t, search(searchingports.Site("bouncepaw")), uint(3))
+	be.Equal(t, search(searchingports.Site("paw")), uint(0))
+	be.Equal(t, search(searchingports.Before(time.Date(2023, 3, 19, 0, 0, 0, 0, time.UTC))), uint(2))
+	be.Equal(t, search(searchingports.After(time.Date(2023, 3, 19, 0, 0, 0, 0, time.UTC))), uint(2))
+	be.Equal(t, search(searchingports.Is(types.Private)), uint(1))
+	be.Equal(t, search(searchingports.Has(searchingports.FeatureDescription)), uint(2))
+	be.Equal(t, search(searchingports.Has(searchingports.FeatureArchive)), uint(0))
+	be.Equal(t, search(searchingports.Remark{}), uint(0))
+}
+
+func TestSearchArchives(t *testing.T) {
+	InitInMemoryDB()
+	ctx := context.Background()
+	archives := NewArchivesRepo()
+	repo := NewSearchRepo()
+	inArchive := func(s string) searchingports.Expr {
+		return searchingports.Text{Field: searchingports.FieldArchive, Text: s}
+	}
+
+	for bookmarkID, text := range map[int64]string{
+		1: "Bouncepaw likes <b>mushrooms</b>",
+		2: "Mycorrhiza is a wiki engine. Mushrooms use mycorrhiza to talk.",
+	} {
+		artifact, err := types.NewCompressedDocumentArtifact([]byte(text), "text/plain")
+		be.Err(t, err, nil)
+		archiveID, err := archives.Store(bookmarkID, artifact)
+		be.Err(t, err, nil)
+		be.Err(t, archives.StoreText(archiveID, text), nil)
+	}
+
+	results, total, err := repo.Search(ctx, searchingports.Query{Expr: inArchive("mushroom"), Authorized: true, Page: 1})
+	be.Err(t, err, nil)
+	be.Equal(t, total, uint(2))
+
+	// Titles and descriptions are not looked at.
+	_, total, err = repo.Search(ctx, searchingports.Query{Expr: inArchive("cute website"), Authorized: true, Page: 1})
+	be.Err(t, err, nil)
+	be.Equal(t, total, uint(0))
+
+	// Visitors only see archives shown to them.
+	hasArchive := searchingports.Has(searchingports.FeatureArchive)
+	for _, expr := range []searchingports.Expr{inArchive("talk"), hasArchive} {
+		_, total, err = repo.Search(ctx, searchingports.Query{Expr: expr, Page: 1})
+		be.Err(t, err, nil)
+		be.Equal(t, total, uint(0))
+	}
+	be.Err(t, archives.SetArchivesPublic(2, true), nil)
+	for _, expr := range []searchingports.Expr{inArchive("talk"), hasArchive} {
+		_, total, err = repo.Search(ctx, searchingports.Query{Expr: expr, Page: 1})
+		be.Err(t, err, nil)
+		be.Equal(t, total, uint(1))
+	}
+
+	matches, err := repo.ArchiveMatches(ctx, searchingports.Query{Expr: inArchive("mushroom"), Authorized: true}, []int{results[0].ID, results[1].ID})
+	be.Err(t, err, nil)
+	be.Equal(t, len(matches), 2)
+	be.Equal(t, matches[1].Snippet, template.HTML("Bouncepaw likes &lt;b&gt;<mark>mushroom</mark>s&lt;/b&gt;"))
+
+	matches, err = repo.ArchiveMatches(ctx, searchingports.Query{Expr: inArchive("mushroom")}, []int{1, 2})
+	be.Err(t, err, nil)
+	be.Equal(t, len(matches), 1)
+	be.True(t, strings.Contains(string(matches[2].Snippet), "<mark>Mushroom</mark>s"))
+}
@@ -9,6 +9,8 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"strings"
 	"time"
 
 	ua "github.com/mileusna/useragent"
@@ -79,3 +81,70 @@ func (repo *SessionsRepo) Sessions(ctx context.Context) ([]types.Session, error)
 	}
 	return sessions, rows.Err()
 }
+
+func (repo *SessionsRepo) AddAccessToken(ctx context.Context, name, tokenHash string, scopes []types.TokenScope) (int64, error) {
+	res, err := db.ExecContext(ctx, `insert into AccessTokens (Name, TokenHash, Scopes) values (?, ?, ?);`,
+		name, tokenHash, joinScopes(scopes))
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (repo *SessionsRepo) AccessTokenByHash(ctx context.Context, tokenHash string) (types.AccessToken, error) {
+	row := db.QueryRowContext(ctx, `
+update AccessTokens set LastUsed = current_timestamp
+where TokenHash = ?
+returning ID, Name, Scopes, CreationTime, LastUsed;`, tokenHash)
+	return scanAccessToken(row)
+}
+
+func (repo *SessionsRepo) AccessTokens(ctx context.Context) ([]types.AccessToken, error) {
+	rows, err := db.QueryContext(ctx, `
+select ID, Name, Scopes, CreationTime, LastUsed
+from AccessTokens
+order by ID desc;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []types.AccessToken
+	for rows.Next() {
+		token, err := scanAccessToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+func (repo *SessionsRepo) RevokeAccessToken(ctx context.Context, id int64) error {
+	_, err := db.ExecContext(ctx, `delete from AccessTokens where ID = ?;`, id)
+	return err
+}
+
+func scanAccessToken(row interface{ Scan(...any) error }) (types.AccessToken, error) {
+	var (
+		token    types.AccessToken
+		scopes   string
+		lastUsed sql.NullString
+	)
+	if err := row.Scan(&token.ID, &token.Name, &scopes, &token.CreationTime, &lastUsed); err != nil {
+		return token, err
+	}
+	for _, scope := range strings.Fields(scopes) {
+		token.Scopes = append(token.Scopes, types.TokenScope(scope))
+	}
+	token.LastUsed = lastUsed.String
+	return token, nil
+}
+
+func joinScopes(scopes []types.TokenScope) string {
+	var names []string
+	for _, scope := range scopes {
+		names = append(names, string(scope))
+	}
+	return strings.Join(names, " ")
+}
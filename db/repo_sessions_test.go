@@ -9,9 +9,12 @@
 package db
 
 import (
+	"database/sql"
 	"testing"
 
 	"github.com/nalgeon/be"
+
+	"git.sr.ht/~bouncepaw/betula/types"
 )
 
 // testing AddSession, SessionExists, StopSession.
@@ -33,3 +36,31 @@ func TestSessionOps(t *testing.T) {
 	be.Err(t, err, nil)
 	be.True(t, !exists)
 }
+
+func TestAccessTokens(t *testing.T) {
+	InitInMemoryDB()
+	ctx := t.Context()
+	repo := NewSessionsRepo()
+
+	id, err := repo.AddAccessToken(ctx, "Scripts", pufferfish, []types.TokenScope{types.ScopeRead, types.ScopeWrite})
+	be.Err(t, err, nil)
+	_, err = repo.AddAccessToken(ctx, "Reader", tropicfish, []types.TokenScope{types.ScopeRead})
+	be.Err(t, err, nil)
+
+	tokens, err := repo.AccessTokens(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(tokens), 2)
+	be.Equal(t, tokens[0].Name, "Reader")
+	be.Equal(t, tokens[0].LastUsed, "")
+	be.True(t, !tokens[0].Allows(types.ScopeWrite))
+
+	token, err := repo.AccessTokenByHash(ctx, pufferfish)
+	be.Err(t, err, nil)
+	be.Equal(t, token.ID, id)
+	be.True(t, token.Allows(types.ScopeWrite))
+	be.True(t, token.LastUsed != "")
+
+	be.Err(t, repo.RevokeAccessToken(ctx, id), nil)
+	_, err = repo.AccessTokenByHash(ctx, pufferfish)
+	be.Err(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,186 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	subscriptionsports "git.sr.ht/~bouncepaw/betula/ports/subscriptions"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+type SubscriptionsRepo struct{}
+
+var _ subscriptionsports.Repository = &SubscriptionsRepo{}
+
+func NewSubscriptionsRepo() *SubscriptionsRepo {
+	return &SubscriptionsRepo{}
+}
+
+func (repo *SubscriptionsRepo) AddSubscription(ctx context.Context, sub subscriptionsports.Subscription) (int64, error) {
+	var id int64
+	err := db.QueryRowContext(ctx, `
+insert into Subscriptions (URL, Title, SiteURL) values (?, ?, ?)
+on conflict (URL) do nothing
+returning ID`,
+		sub.URL, sub.Title, sub.SiteURL).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, subscriptionsports.ErrAlreadySubscribed
+	}
+	return id, err
+}
+
+func (repo *SubscriptionsRepo) Subscriptions(ctx context.Context) ([]subscriptionsports.Subscription, error) {
+	rows, err := db.QueryContext(ctx, `
+select
+	ID, URL, Title, SiteURL, CreationTime, LastPolled, LastError,
+	(select count(*) from Suggestions where SubscriptionID = Subscriptions.ID and State = 'unread')
+from Subscriptions
+order by lower(Title), ID`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []subscriptionsports.Subscription
+	for rows.Next() {
+		var (
+			sub          subscriptionsports.Subscription
+			creationTime string
+			lastPolled   sql.NullString
+		)
+		err = rows.Scan(&sub.ID, &sub.URL, &sub.Title, &sub.SiteURL, &creationTime, &lastPolled,
+			&sub.LastError, &sub.Unread)
+		if err != nil {
+			return nil, err
+		}
+		sub.CreationTime, _ = time.Parse(types.TimeLayout, creationTime)
+		sub.LastPolled, _ = time.Parse(types.TimeLayout, lastPolled.String)
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+func (repo *SubscriptionsRepo) RemoveSubscription(ctx context.Context, id int64) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `delete from Suggestions where SubscriptionID = ?`, id); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `delete from Subscriptions where ID = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (repo *SubscriptionsRepo) StorePoll(ctx context.Context, id int64, title, siteURL, pollErr string) error {
+	_, err := db.ExecContext(ctx, `
+update Subscriptions
+set
+	Title = coalesce(nullif(?, ''), Title),
+	SiteURL = coalesce(nullif(?, ''), SiteURL),
+	LastPolled = current_timestamp,
+	LastError = ?
+where ID = ?`,
+		title, siteURL, pollErr, id)
+	return err
+}
+
+func (repo *SubscriptionsRepo) AddSuggestions(ctx context.Context, suggestions []subscriptionsports.Suggestion) (int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var added int64
+	for _, suggestion := range suggestions {
+		var published sql.NullString
+		if !suggestion.Published.IsZero() {
+			published = sql.NullString{String: suggestion.Published.UTC().Format(types.TimeLayout), Valid: true}
+		}
+		res, err := tx.ExecContext(ctx, `
+insert or ignore into Suggestions (SubscriptionID, EntryID, URL, Title, Tags, Published)
+values (?, ?, ?, ?, ?, ?)`,
+			suggestion.SubscriptionID, suggestion.EntryID, suggestion.URL, suggestion.Title,
+			types.JoinTags(suggestion.Tags), published)
+		if err != nil {
+			return 0, err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		added += affected
+	}
+	return int(added), tx.Commit()
+}
+
+const selectSuggestions = `
+select
+	Suggestions.ID, SubscriptionID, Subscriptions.Title, EntryID,
+	Suggestions.URL, Suggestions.Title, Tags, Published, State
+from Suggestions
+join Subscriptions on Subscriptions.ID = SubscriptionID`
+
+func (repo *SubscriptionsRepo) UnreadSuggestions(ctx context.Context) ([]subscriptionsports.Suggestion, error) {
+	rows, err := db.QueryContext(ctx, selectSuggestions+`
+where State = 'unread'
+order by coalesce(Published, Suggestions.CreationTime) desc, Suggestions.ID desc`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suggestions []subscriptionsports.Suggestion
+	for rows.Next() {
+		suggestion, err := scanSuggestion(rows)
+		if err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, rows.Err()
+}
+
+func (repo *SubscriptionsRepo) SuggestionByID(ctx context.Context, id int64) (subscriptionsports.Suggestion, error) {
+	return scanSuggestion(db.QueryRowContext(ctx, selectSuggestions+`
+where Suggestions.ID = ?`, id))
+}
+
+func (repo *SubscriptionsRepo) SetSuggestionState(ctx context.Context, id int64, state subscriptionsports.SuggestionState) error {
+	_, err := db.ExecContext(ctx, `update Suggestions set State = ? where ID = ?`, state, id)
+	return err
+}
+
+func (repo *SubscriptionsRepo) DismissAll(ctx context.Context) error {
+	_, err := db.ExecContext(ctx, `update Suggestions set State = 'dismissed' where State = 'unread'`)
+	return err
+}
+
+func scanSuggestion(row interface{ Scan(...any) error }) (subscriptionsports.Suggestion, error) {
+	var (
+		suggestion subscriptionsports.Suggestion
+		tags       string
+		published  sql.NullString
+	)
+	err := row.Scan(&suggestion.ID, &suggestion.SubscriptionID, &suggestion.FeedTitle, &suggestion.EntryID,
+		&suggestion.URL, &suggestion.Title, &tags, &published, &suggestion.State)
+	if err != nil {
+		return suggestion, err
+	}
+	if tags != "" {
+		suggestion.Tags = types.SplitTags(tags)
+	}
+	suggestion.Published, _ = time.Parse(types.TimeLayout, published.String)
+	return suggestion, nil
+}
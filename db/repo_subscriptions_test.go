@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package db
+
+import (
+	"testing"
+
+	"github.com/nalgeon/be"
+
+	subscriptionsports "git.sr.ht/~bouncepaw/betula/ports/subscriptions"
+)
+
+func TestSuggestions(t *testing.T) {
+	InitInMemoryDB()
+	ctx := t.Context()
+	repo := NewSubscriptionsRepo()
+
+	id, err := repo.AddSubscription(ctx, subscriptionsports.Subscription{URL: "https://example.org/feed", Title: "Example"})
+	be.Err(t, err, nil)
+	be.Err(t, repo.StorePoll(ctx, id, "", "", "feed returned status 500"), nil)
+	subs, err := repo.Subscriptions(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, subs[0].Title, "Example")
+	be.Equal(t, subs[0].LastError, "feed returned status 500")
+	be.True(t, !subs[0].LastPolled.IsZero())
+
+	suggestions := []subscriptionsports.Suggestion{
+		{SubscriptionID: id, EntryID: "a", URL: "https://example.org/a", Title: "A"},
+		{SubscriptionID: id, EntryID: "b", URL: "https://example.org/b", Title: "B"},
+		{SubscriptionID: id, EntryID: "c", URL: "https://example.org/c", Title: "C"},
+	}
+	added, err := repo.AddSuggestions(ctx, suggestions)
+	be.Err(t, err, nil)
+	be.Equal(t, added, 3)
+	added, err = repo.AddSuggestions(ctx, suggestions[:1])
+	be.Err(t, err, nil)
+	be.Equal(t, added, 0)
+
+	be.Err(t, repo.SetSuggestionState(ctx, 1, subscriptionsports.SuggestionSaved), nil)
+	suggestion, err := repo.SuggestionByID(ctx, 1)
+	be.Err(t, err, nil)
+	be.Equal(t, suggestion.State, subscriptionsports.SuggestionSaved)
+	be.Equal(t, len(suggestion.Tags), 0)
+
+	unread, err := repo.UnreadSuggestions(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(unread), 2)
+	be.Err(t, repo.DismissAll(ctx), nil)
+	unread, err = repo.UnreadSuggestions(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(unread), 0)
+	suggestion, err = repo.SuggestionByID(ctx, 1)
+	be.Err(t, err, nil)
+	be.Equal(t, suggestion.State, subscriptionsports.SuggestionSaved)
+}
@@ -117,12 +117,30 @@ func (repo *TagsRepo) TagExists(ctx context.Context, tagName string) (bool, erro
 }
 
 func (repo *TagsRepo) RenameTag(ctx context.Context, oldTagName, newTagName string) error {
-	_, err := db.ExecContext(ctx, `
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// The bookmarks look different with the new name, so they count as edited.
+	_, err = tx.ExecContext(ctx, `
+update Bookmarks
+set EditTime = current_timestamp
+where ID in (select PostID from TagsToPosts where TagName = ?);
+`, oldTagName)
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	_, err = tx.ExecContext(ctx, `
 update TagsToPosts
 set TagName = ?
 where TagName = ?;
 `, newTagName, oldTagName)
-	return err
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	return tx.Commit()
 }
 
 func (repo *TagsRepo) SetTagsFor(ctx context.Context, bookmarkID int, tags []types.Tag) error {
@@ -134,6 +152,9 @@ func (repo *TagsRepo) SetTagsFor(ctx context.Context, bookmarkID int, tags []typ
 	if _, err := tx.ExecContext(ctx, `delete from TagsToPosts where PostID = ?;`, bookmarkID); err != nil {
 		return errors.Join(err, tx.Rollback())
 	}
+	if _, err := tx.ExecContext(ctx, `update Bookmarks set EditTime = current_timestamp where ID = ?;`, bookmarkID); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
 
 	for _, tag := range tags {
 		if tag.Name == "" {
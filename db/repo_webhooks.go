@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	webhooksports "git.sr.ht/~bouncepaw/betula/ports/webhooks"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+type WebhooksRepo struct{}
+
+var _ webhooksports.Repository = &WebhooksRepo{}
+
+func NewWebhooksRepo() *WebhooksRepo {
+	return &WebhooksRepo{}
+}
+
+func (repo *WebhooksRepo) AddWebhook(ctx context.Context, hook webhooksports.Webhook) (int64, error) {
+	res, err := db.ExecContext(ctx, `insert into Webhooks (Name, URL, Secret, Events, IncludePrivate) values (?, ?, ?, ?, ?)`,
+		hook.Name, hook.URL, hook.Secret, joinEvents(hook.Events), hook.IncludePrivate)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+const selectWebhooks = `select ID, Name, URL, Secret, Events, IncludePrivate, CreationTime from Webhooks`
+
+func (repo *WebhooksRepo) Webhooks(ctx context.Context) ([]webhooksports.Webhook, error) {
+	rows, err := db.QueryContext(ctx, selectWebhooks+` order by ID`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hooks []webhooksports.Webhook
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, rows.Err()
+}
+
+func (repo *WebhooksRepo) WebhookByID(ctx context.Context, id int64) (webhooksports.Webhook, error) {
+	return scanWebhook(db.QueryRowContext(ctx, selectWebhooks+` where ID = ?`, id))
+}
+
+func (repo *WebhooksRepo) RemoveWebhook(ctx context.Context, id int64) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `delete from WebhookDeliveries where WebhookID = ?`, id); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `delete from Webhooks where ID = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (repo *WebhooksRepo) AddDelivery(ctx context.Context, webhookID int64, event webhooksports.Event, payload []byte) (int64, error) {
+	res, err := db.ExecContext(ctx, `insert into WebhookDeliveries (WebhookID, Event, Payload) values (?, ?, ?)`,
+		webhookID, event, payload)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+const selectDeliveries = `
+select
+	WebhookDeliveries.ID, WebhookID, coalesce(Webhooks.Name, ''), Event, Payload, State, Attempts,
+	LastStatus, LastError, WebhookDeliveries.CreationTime, LastAttempt
+from WebhookDeliveries
+left join Webhooks on Webhooks.ID = WebhookID`
+
+func (repo *WebhooksRepo) DeliveryByID(ctx context.Context, id int64) (webhooksports.Delivery, error) {
+	return scanDelivery(db.QueryRowContext(ctx, selectDeliveries+` where WebhookDeliveries.ID = ?`, id))
+}
+
+func (repo *WebhooksRepo) StoreAttempt(ctx context.Context, id int64, state webhooksports.DeliveryState, status int, attemptErr string) error {
+	_, err := db.ExecContext(ctx, `
+update WebhookDeliveries
+set State = ?, Attempts = Attempts + 1, LastStatus = ?, LastError = ?, LastAttempt = current_timestamp
+where ID = ?`,
+		state, status, attemptErr, id)
+	return err
+}
+
+func (repo *WebhooksRepo) SetDeliveryState(ctx context.Context, id int64, state webhooksports.DeliveryState) error {
+	_, err := db.ExecContext(ctx, `update WebhookDeliveries set State = ? where ID = ?`, state, id)
+	return err
+}
+
+func (repo *WebhooksRepo) Deliveries(ctx context.Context, limit int) ([]webhooksports.Delivery, error) {
+	rows, err := db.QueryContext(ctx, selectDeliveries+`
+order by WebhookDeliveries.ID desc
+limit ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []webhooksports.Delivery
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func (repo *WebhooksRepo) BookmarkByID(ctx context.Context, id int) (types.Bookmark, error) {
+	bookmark, err := NewLocalBookmarksRepo().GetBookmarkByID(ctx, id)
+	if err != nil {
+		return bookmark, err
+	}
+	bookmark.Tags, err = tagsForBookmarkByID(ctx, db, id)
+	return bookmark, err
+}
+
+func scanWebhook(row interface{ Scan(...any) error }) (webhooksports.Webhook, error) {
+	var (
+		hook         webhooksports.Webhook
+		events       string
+		creationTime string
+	)
+	if err := row.Scan(&hook.ID, &hook.Name, &hook.URL, &hook.Secret, &events, &hook.IncludePrivate, &creationTime); err != nil {
+		return hook, err
+	}
+	for _, event := range strings.Fields(events) {
+		hook.Events = append(hook.Events, webhooksports.Event(event))
+	}
+	hook.CreationTime, _ = time.Parse(types.TimeLayout, creationTime)
+	return hook, nil
+}
+
+func scanDelivery(row interface{ Scan(...any) error }) (webhooksports.Delivery, error) {
+	var (
+		delivery     webhooksports.Delivery
+		creationTime string
+		lastAttempt  sql.NullString
+	)
+	err := row.Scan(&delivery.ID, &delivery.WebhookID, &delivery.WebhookName, &delivery.Event, &delivery.Payload,
+		&delivery.State, &delivery.Attempts, &delivery.LastStatus, &delivery.LastError, &creationTime, &lastAttempt)
+	if err != nil {
+		return delivery, err
+	}
+	delivery.CreationTime, _ = time.Parse(types.TimeLayout, creationTime)
+	delivery.LastAttempt, _ = time.Parse(types.TimeLayout, lastAttempt.String)
+	return delivery, nil
+}
+
+func joinEvents(events []webhooksports.Event) string {
+	var names []string
+	for _, event := range events {
+		names = append(names, string(event))
+	}
+	return strings.Join(names, " ")
+}
@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package db
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/nalgeon/be"
+
+	webhooksports "git.sr.ht/~bouncepaw/betula/ports/webhooks"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+func TestWebhooks(t *testing.T) {
+	InitInMemoryDB()
+	ctx := t.Context()
+	repo := NewWebhooksRepo()
+
+	id, err := repo.AddWebhook(ctx, webhooksports.Webhook{
+		Name:           "Example",
+		URL:            "https://example.org/hook",
+		Secret:         "secret",
+		Events:         []webhooksports.Event{webhooksports.EventBookmarkCreated, webhooksports.EventTagRenamed},
+		IncludePrivate: true,
+	})
+	be.Err(t, err, nil)
+	hook, err := repo.WebhookByID(ctx, id)
+	be.Err(t, err, nil)
+	be.Equal(t, hook.Events, []webhooksports.Event{webhooksports.EventBookmarkCreated, webhooksports.EventTagRenamed})
+	be.True(t, hook.IncludePrivate)
+	be.True(t, !hook.CreationTime.IsZero())
+
+	deliveryID, err := repo.AddDelivery(ctx, id, webhooksports.EventTagRenamed, []byte(`{"event":"tag.renamed"}`))
+	be.Err(t, err, nil)
+	delivery, err := repo.DeliveryByID(ctx, deliveryID)
+	be.Err(t, err, nil)
+	be.Equal(t, delivery.State, webhooksports.DeliveryPending)
+	be.Equal(t, string(delivery.Payload), `{"event":"tag.renamed"}`)
+	be.True(t, delivery.LastAttempt.IsZero())
+
+	be.Err(t, repo.StoreAttempt(ctx, deliveryID, webhooksports.DeliveryFailed, 410, "gone"), nil)
+	deliveries, err := repo.Deliveries(ctx, 10)
+	be.Err(t, err, nil)
+	be.Equal(t, len(deliveries), 1)
+	be.Equal(t, deliveries[0].WebhookName, "Example")
+	be.Equal(t, deliveries[0].State, webhooksports.DeliveryFailed)
+	be.Equal(t, deliveries[0].Attempts, 1)
+	be.Equal(t, deliveries[0].LastStatus, 410)
+	be.Equal(t, deliveries[0].LastError, "gone")
+	be.True(t, !deliveries[0].LastAttempt.IsZero())
+
+	be.Err(t, repo.RemoveWebhook(ctx, id), nil)
+	_, err = repo.WebhookByID(ctx, id)
+	be.Err(t, err, sql.ErrNoRows)
+	_, err = repo.DeliveryByID(ctx, deliveryID)
+	be.Err(t, err, sql.ErrNoRows)
+}
+
+func TestWebhooksBookmarkByID(t *testing.T) {
+	InitInMemoryDB()
+	ctx := t.Context()
+	be.Err(t, NewTagsRepo().SetTagsFor(ctx, 2, []types.Tag{{Name: "wiki"}}), nil)
+
+	bookmark, err := NewWebhooksRepo().BookmarkByID(ctx, 2)
+	be.Err(t, err, nil)
+	be.Equal(t, bookmark.Title, "Mycorrhiza Wiki")
+	be.Equal(t, bookmark.Tags, []types.Tag{{Name: "wiki"}})
+}
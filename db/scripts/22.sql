@@ -0,0 +1,37 @@
+-- SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+--
+-- SPDX-License-Identifier: AGPL-3.0-only
+
+-- Full-text index over local bookmarks. The content lives in Bookmarks,
+-- the index is kept in sync with the triggers below. Trigram tokenizer
+-- keeps the old substring semantics of search.
+create virtual table BookmarksSearch using fts5 (
+    Title,
+    Description,
+    URL,
+    RemarkText,
+    content = 'Bookmarks',
+    content_rowid = 'ID',
+    tokenize = 'trigram'
+);
+
+create trigger BookmarksSearchInsert after insert on Bookmarks begin
+    insert into BookmarksSearch (rowid, Title, Description, URL, RemarkText)
+    values (new.ID, new.Title, new.Description, new.URL, new.RemarkText);
+end;
+
+create trigger BookmarksSearchDelete after delete on Bookmarks begin
+    insert into BookmarksSearch (BookmarksSearch, rowid, Title, Description, URL, RemarkText)
+    values ('delete', old.ID, old.Title, old.Description, old.URL, old.RemarkText);
+end;
+
+-- Only the indexed columns matter. Soft deletions, visibility changes and
+-- canonical URL rewrites leave the index alone.
+create trigger BookmarksSearchUpdate after update of URL, Title, Description, RemarkText on Bookmarks begin
+    insert into BookmarksSearch (BookmarksSearch, rowid, Title, Description, URL, RemarkText)
+    values ('delete', old.ID, old.Title, old.Description, old.URL, old.RemarkText);
+    insert into BookmarksSearch (rowid, Title, Description, URL, RemarkText)
+    values (new.ID, new.Title, new.Description, new.URL, new.RemarkText);
+end;
+
+insert into BookmarksSearch (BookmarksSearch) values ('rebuild');
@@ -0,0 +1,9 @@
+-- SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+--
+-- SPDX-License-Identifier: AGPL-3.0-only
+
+alter table Jobs add column Attempts integer not null default 0;
+alter table Jobs add column LastError text not null default '';
+alter table Jobs add column Failed integer not null default 0;
+
+create index JobsDue on Jobs (Failed, Due);
@@ -0,0 +1,5 @@
+-- SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+--
+-- SPDX-License-Identifier: AGPL-3.0-only
+
+alter table Actors add column SharedInbox text not null default '';
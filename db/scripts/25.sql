@@ -0,0 +1,13 @@
+-- SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+--
+-- SPDX-License-Identifier: AGPL-3.0-only
+
+-- Blocks lists actors and domains we do not talk to.
+create table Blocks (
+    -- Target is an actor ID (a URL) or a lowercase domain.
+    Target    text primary key,
+    -- Domain is 1 if Target is a domain.
+    Domain    integer not null default 0,
+    Reason    text not null default '',
+    CreatedAt text not null default current_timestamp
+);
@@ -0,0 +1,11 @@
+-- SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+--
+-- SPDX-License-Identifier: AGPL-3.0-only
+
+-- FollowRequests holds incoming follows that wait for the admin's approval.
+create table FollowRequests (
+    ActorID    text primary key,
+    -- Activity is the original Follow activity, needed to Accept or Reject it.
+    Activity   blob not null,
+    ReceivedAt text not null default current_timestamp
+);
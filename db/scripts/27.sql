@@ -0,0 +1,15 @@
+-- SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+--
+-- SPDX-License-Identifier: AGPL-3.0-only
+
+-- LinkChecks holds the result of the last check of every bookmark's URL.
+create table LinkChecks (
+    BookmarkID integer primary key,
+    -- Status is the HTTP status, 0 if there was no response.
+    Status     integer not null default 0,
+    -- Error is why there was no response.
+    Error      text not null default '',
+    -- FinalURL is where the URL led after redirects.
+    FinalURL   text not null default '',
+    CheckedAt  text not null default current_timestamp
+);
@@ -0,0 +1,9 @@
+-- SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+--
+-- SPDX-License-Identifier: AGPL-3.0-only
+
+-- PublicArchives lists bookmarks whose archives are shown to visitors.
+-- Archives of private bookmarks are never shown, even if listed here.
+create table PublicArchives (
+    BookmarkID integer primary key
+);
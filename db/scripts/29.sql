@@ -0,0 +1,7 @@
+-- SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+--
+-- SPDX-License-Identifier: AGPL-3.0-only
+
+-- Kind tells how the artifact was made: full (everything inlined by Obelisk),
+-- readable (the article text only) or raw (the document as served).
+alter table Artifacts add column Kind text not null default 'full';
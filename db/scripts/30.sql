@@ -0,0 +1,36 @@
+-- SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+--
+-- SPDX-License-Identifier: AGPL-3.0-only
+
+-- ArchivesText is the plain text of archives, extracted by Betula.
+-- Archives without text, like pictures, have an empty one, so they
+-- are not extracted again.
+create table ArchivesText (
+    ArchiveID integer primary key,
+    Text      text not null
+);
+
+create trigger ArchivesTextArchiveDelete after delete on Archives begin
+    delete from ArchivesText where ArchiveID = old.ID;
+end;
+
+-- Full-text index over archive contents, kept in sync like BookmarksSearch.
+create virtual table ArchivesSearch using fts5 (
+    Text,
+    content = 'ArchivesText',
+    content_rowid = 'ArchiveID',
+    tokenize = 'trigram'
+);
+
+create trigger ArchivesSearchInsert after insert on ArchivesText begin
+    insert into ArchivesSearch (rowid, Text) values (new.ArchiveID, new.Text);
+end;
+
+create trigger ArchivesSearchDelete after delete on ArchivesText begin
+    insert into ArchivesSearch (ArchivesSearch, rowid, Text) values ('delete', old.ArchiveID, old.Text);
+end;
+
+create trigger ArchivesSearchUpdate after update on ArchivesText begin
+    insert into ArchivesSearch (ArchivesSearch, rowid, Text) values ('delete', old.ArchiveID, old.Text);
+    insert into ArchivesSearch (rowid, Text) values (new.ArchiveID, new.Text);
+end;
@@ -0,0 +1,13 @@
+-- SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+--
+-- SPDX-License-Identifier: AGPL-3.0-only
+
+-- BookmarkImages are the site icons and preview images of bookmarks.
+-- The images themselves are artifacts, shared with archives if they
+-- happen to be the same.
+create table BookmarkImages (
+    BookmarkID integer not null,
+    Kind       text    not null check (Kind in ('icon', 'preview')),
+    ArtifactID text    not null,
+    primary key (BookmarkID, Kind)
+);
@@ -0,0 +1,9 @@
+-- SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+--
+-- SPDX-License-Identifier: AGPL-3.0-only
+
+-- CanonicalURL is the URL in the form used to find duplicates, see
+-- bxstr.CanonicalURL. Betula fills it for old bookmarks on start.
+alter table Bookmarks add column CanonicalURL text;
+
+create index BookmarksCanonicalURL on Bookmarks (CanonicalURL);
@@ -0,0 +1,15 @@
+-- SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+--
+-- SPDX-License-Identifier: AGPL-3.0-only
+
+-- AccessTokens are personal access tokens for the JSON API.
+-- Only a hash of the token is stored, the token itself is shown once.
+create table AccessTokens (
+    ID           integer primary key autoincrement,
+    Name         text not null,
+    TokenHash    text not null unique,
+    -- Scopes are separated by spaces.
+    Scopes       text not null,
+    CreationTime text not null default current_timestamp,
+    LastUsed     text
+);
@@ -0,0 +1,36 @@
+-- SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+--
+-- SPDX-License-Identifier: AGPL-3.0-only
+
+-- Subscriptions are external RSS and Atom feeds Betula polls for
+-- bookmark suggestions.
+create table Subscriptions (
+    ID           integer primary key autoincrement,
+    URL          text not null unique,
+    Title        text not null default '',
+    -- SiteURL is the site the feed is about.
+    SiteURL      text not null default '',
+    CreationTime text not null default current_timestamp,
+    LastPolled   text,
+    -- LastError is why the last poll failed, empty if it did not.
+    LastError    text not null default ''
+);
+
+-- Suggestions are feed entries waiting to be saved or dismissed. They are
+-- kept after that, so they are not suggested again.
+create table Suggestions (
+    ID             integer primary key autoincrement,
+    SubscriptionID integer not null,
+    -- EntryID is the guid or id of the entry in its feed.
+    EntryID        text not null,
+    URL            text not null,
+    Title          text not null default '',
+    -- Tags are separated by commas.
+    Tags           text not null default '',
+    Published      text,
+    State          text not null default 'unread' check (State in ('unread', 'saved', 'dismissed')),
+    CreationTime   text not null default current_timestamp,
+    unique (SubscriptionID, EntryID)
+);
+
+create index SuggestionsState on Suggestions (State);
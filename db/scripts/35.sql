@@ -0,0 +1,35 @@
+-- SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+--
+-- SPDX-License-Identifier: AGPL-3.0-only
+
+-- Webhooks are addresses Betula posts events to.
+create table Webhooks (
+    ID           integer primary key autoincrement,
+    Name         text not null,
+    URL          text not null,
+    -- Secret is the key of the HMAC signature of the payloads.
+    Secret       text not null,
+    -- Events are separated by spaces.
+    Events       text not null,
+    -- IncludePrivate is 1 if private bookmarks are sent too.
+    IncludePrivate integer not null default 0,
+    CreationTime text not null default current_timestamp
+);
+
+-- WebhookDeliveries is the delivery log. The attempts themselves are made
+-- by DeliverWebhook jobs.
+create table WebhookDeliveries (
+    ID           integer primary key autoincrement,
+    WebhookID    integer not null,
+    Event        text not null,
+    Payload      blob not null,
+    State        text not null default 'pending' check (State in ('pending', 'delivered', 'failed')),
+    Attempts     integer not null default 0,
+    -- LastStatus is the HTTP status of the last attempt, 0 if there was no answer.
+    LastStatus   integer not null default 0,
+    LastError    text not null default '',
+    CreationTime text not null default current_timestamp,
+    LastAttempt  text
+);
+
+create index WebhookDeliveriesWebhookID on WebhookDeliveries (WebhookID);
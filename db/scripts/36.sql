@@ -0,0 +1,7 @@
+-- SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+--
+-- SPDX-License-Identifier: AGPL-3.0-only
+
+-- EditTime is when the bookmark was last edited. Null if it never was.
+-- Feeds use it for Last-Modified.
+alter table Bookmarks add column EditTime text;
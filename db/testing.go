@@ -7,6 +7,8 @@
 package db
 
 import (
+	"context"
+
 	_ "github.com/ncruces/go-sqlite3"
 )
 
@@ -46,6 +48,13 @@ values
 	)
 `
 	mustExec(q)
+	mustCanonicalizeURLs()
+}
+
+func mustCanonicalizeURLs() {
+	if err := canonicalizeURLs(context.Background()); err != nil {
+		panic(err)
+	}
 }
 
 func MoreTestingBookmarks() {
@@ -57,4 +66,5 @@ values
 ('https://3.bouncepaw', 'Tres', '', 1, '2023-03-20 19:19:19', null),
 ('https://4.bouncepaw', 'Cuatro', '', 1, '2023-03-20 20:20:20', null);
 `)
+	mustCanonicalizeURLs()
 }
@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package fediverse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"git.sr.ht/~bouncepaw/betula/fediverse/signing"
+	apports "git.sr.ht/~bouncepaw/betula/ports/activitypub"
+	"git.sr.ht/~bouncepaw/betula/settings"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+var ErrNoOutbox = errors.New("fediverse: actor has no outbox")
+
+// FetchOutboxBookmarks walks the outbox of the actor with the given ID,
+// newest first, and returns bookmarks found in at most maxPages pages.
+// Anything but Create{Note} by the actor themselves is skipped.
+func FetchOutboxBookmarks(actorID string, maxPages uint) ([]types.RemoteBookmark, error) {
+	actor, err := fetchObject(actorID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching actor %s: %w", actorID, err)
+	}
+	outboxID, ok := actor["outbox"].(string)
+	if !ok || outboxID == "" {
+		return nil, ErrNoOutbox
+	}
+
+	outbox, err := fetchObject(outboxID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching outbox %s: %w", outboxID, err)
+	}
+
+	// Small outboxes might have the items right in the collection.
+	page := outbox
+	if outbox["first"] != nil {
+		if page, err = objectOrFetch(outbox["first"]); err != nil {
+			return nil, fmt.Errorf("fetching first page of outbox %s: %w", outboxID, err)
+		}
+	}
+
+	var bookmarks []types.RemoteBookmark
+	for i := uint(0); i < maxPages && page != nil; i++ {
+		items, _ := page["orderedItems"].([]any)
+		if items == nil {
+			items, _ = page["items"].([]any)
+		}
+		for _, rawItem := range items {
+			if bookmark, ok := bookmarkFromOutboxItem(actorID, rawItem); ok {
+				bookmarks = append(bookmarks, bookmark)
+			}
+		}
+
+		if page["next"] == nil {
+			break
+		}
+		if page, err = objectOrFetch(page["next"]); err != nil {
+			slog.Warn("Failed to fetch next page of outbox, stopping", "outboxID", outboxID, "err", err)
+			break
+		}
+	}
+	return bookmarks, nil
+}
+
+func bookmarkFromOutboxItem(actorID string, rawItem any) (types.RemoteBookmark, bool) {
+	item, ok := rawItem.(apports.Dict)
+	if !ok || item["type"] != "Create" {
+		return types.RemoteBookmark{}, false
+	}
+	object, ok := item["object"].(apports.Dict)
+	if !ok {
+		return types.RemoteBookmark{}, false
+	}
+
+	bookmark, err := noteParser.BookmarkFromNote(object)
+	if err != nil || bookmark.ActorID != actorID {
+		return types.RemoteBookmark{}, false
+	}
+	if bookmark.Activity, err = json.Marshal(item); err != nil {
+		return types.RemoteBookmark{}, false
+	}
+	return *bookmark, true
+}
+
+// objectOrFetch returns the object if it is embedded, or fetches it if it
+// is a link.
+func objectOrFetch(v any) (apports.Dict, error) {
+	switch v := v.(type) {
+	case apports.Dict:
+		return v, nil
+	case string:
+		return fetchObject(v)
+	default:
+		return nil, fmt.Errorf("unexpected %T instead of object or link", v)
+	}
+}
+
+func fetchObject(uri string) (apports.Dict, error) {
+	req, err := http.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", settings.UserAgent())
+	req.Header.Set("Accept", types.ActivityType)
+	signing.SignRequest(req, nil)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status not 200, id est %d", resp.StatusCode)
+	}
+
+	// Outbox pages are heavier than single objects.
+	var object apports.Dict
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 2_000_000)).Decode(&object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
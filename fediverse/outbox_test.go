@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package fediverse
+
+import (
+	"testing"
+
+	"github.com/nalgeon/be"
+
+	apports "git.sr.ht/~bouncepaw/betula/ports/activitypub"
+)
+
+func TestBookmarkFromOutboxItem(t *testing.T) {
+	const actorID = "https://links.example.org/@bob"
+	note := func(attributedTo string) apports.Dict {
+		return apports.Dict{
+			"type":         "Note",
+			"id":           "https://links.example.org/1",
+			"attributedTo": attributedTo,
+			"name":         "Example",
+			"content":      "<p>Hi</p>",
+			"published":    "2026-01-02T03:04:05Z",
+			"attachment": []any{
+				apports.Dict{"type": "Link", "href": "https://example.org"},
+			},
+		}
+	}
+
+	bookmark, ok := bookmarkFromOutboxItem(actorID, apports.Dict{
+		"type":   "Create",
+		"object": note(actorID),
+	})
+	be.True(t, ok)
+	be.Equal(t, bookmark.ID, "https://links.example.org/1")
+	be.Equal(t, bookmark.URL, "https://example.org")
+	be.True(t, len(bookmark.Activity) > 0)
+
+	_, ok = bookmarkFromOutboxItem(actorID, apports.Dict{
+		"type":   "Announce",
+		"object": note(actorID),
+	})
+	be.Equal(t, ok, false)
+
+	_, ok = bookmarkFromOutboxItem(actorID, apports.Dict{
+		"type":   "Create",
+		"object": note("https://links.example.org/@alice"),
+	})
+	be.Equal(t, ok, false)
+
+	_, ok = bookmarkFromOutboxItem(actorID, apports.Dict{
+		"type":   "Create",
+		"object": "https://links.example.org/1",
+	})
+	be.Equal(t, ok, false)
+}
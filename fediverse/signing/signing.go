@@ -11,6 +11,8 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/rsa"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
@@ -88,24 +90,47 @@ func EnsureKeysFromDatabase() {
 	}
 }
 
+// ErrWrongKeyOwner is returned when a request is signed with a key of
+// an actor other than the one expected.
+var ErrWrongKeyOwner = errors.New("signing: key belongs to another actor")
+
 // VerifyRequestSignature returns true if the request has correct signature. This function makes HTTP requests on your behalf to retrieve the public key.
 func VerifyRequestSignature(rq *http.Request, content []byte) bool {
-	_, err := httpsig.VerifyRequest(rq, content, func(keyID string) (httpsig.PublicKey, error) {
-		pem, err := actorRepo.KeyPemByID(context.Background(), keyID)
-		if err != nil {
-			return httpsig.PublicKey{}, err
-		}
-		if pem == "" {
-			// The zero PublicKey has a None key type, which the underlying VerifyRequest handles well.
-			return httpsig.PublicKey{}, nil
-		}
-
-		_, pub, err := httpsig.DecodeKey(pem)
-		return pub, err
-	})
+	_, err := httpsig.VerifyRequest(rq, content, lookupPublicKey)
 	if err != nil {
 		slog.Error("Failed to verify request signature", "uri", rq.URL.RequestURI(), "err", err)
 		return false
 	}
 	return true
 }
+
+// VerifyActorSignature checks that the request is signed with a key of the actor
+// with the given ID. The signature has to cover the body digest and a fresh
+// date. The actor's key must be stored beforehand.
+func VerifyActorSignature(rq *http.Request, content []byte, actorID string) error {
+	keyID, err := httpsig.VerifyRequest(rq, content, lookupPublicKey)
+	if err != nil {
+		return err
+	}
+	owner, err := actorRepo.KeyOwnerByID(rq.Context(), keyID)
+	if err != nil {
+		return err
+	}
+	if owner != actorID {
+		return fmt.Errorf("%w: key %s is owned by %q", ErrWrongKeyOwner, keyID, owner)
+	}
+	return nil
+}
+
+func lookupPublicKey(keyID string) (httpsig.PublicKey, error) {
+	pem, err := actorRepo.KeyPemByID(context.Background(), keyID)
+	if err != nil {
+		return httpsig.PublicKey{}, err
+	}
+	if pem == "" {
+		// The zero PublicKey has a None key type, which the underlying VerifyRequest handles well.
+		return httpsig.PublicKey{}, nil
+	}
+	_, pub, err := httpsig.DecodeKey(pem)
+	return pub, err
+}
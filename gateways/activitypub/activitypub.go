@@ -16,11 +16,10 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
-	"sync"
-	"sync/atomic"
 	"time"
 
 	"git.sr.ht/~bouncepaw/betula/fediverse/signing"
+	"git.sr.ht/~bouncepaw/betula/jobs"
 	"git.sr.ht/~bouncepaw/betula/pkg/bxstr"
 	apports "git.sr.ht/~bouncepaw/betula/ports/activitypub"
 	"git.sr.ht/~bouncepaw/betula/settings"
@@ -63,35 +62,9 @@ func (ap *ActivityPub) BroadcastToFollowers(
 	if err != nil {
 		return err
 	}
-	ap.logger.Info("Broadcasting to followers",
-		"count", len(followers), "activity", string(activity))
-
-	var (
-		wg        sync.WaitGroup
-		succSends atomic.Int32
-		sema      = make(chan struct{}, 10)
-	)
-	for i, follower := range followers {
-		wg.Add(1)
-		go func() {
-			sema <- struct{}{}
-			defer wg.Done()
-			defer func() { <-sema }()
-
-			actor := newActor(follower)
-			err := actor.sendActivityQuiet(activity)
-			if err != nil {
-				slog.Error("Failed to send activity to follower",
-					"err", err, "i", i, "follower", follower)
-				return
-			}
-			succSends.Add(1)
-		}()
-	}
-	wg.Wait()
-
-	ap.logger.Info("Broadcasted to followers",
-		"success", succSends.Load(), "total", len(followers))
+	inboxes := jobs.ScheduleDeliveries(followers, activity)
+	ap.logger.Info("Scheduled broadcast to followers",
+		"followers", len(followers), "inboxes", inboxes, "activity", string(activity))
 	return nil
 }
 
@@ -0,0 +1,38 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package wwwgw
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"git.sr.ht/~bouncepaw/betula/pkg/feedparse"
+)
+
+// feedLimit is how much of a feed is read. Feeds with full texts of
+// posts get big.
+const feedLimit = 8 * 1024 * 1024
+
+func (www *WWW) FetchFeed(addr string) (feedparse.Feed, error) {
+	req, err := http.NewRequest(http.MethodGet, addr, nil)
+	if err != nil {
+		return feedparse.Feed{}, err
+	}
+
+	req.Header.Set("User-Agent", www.userAgentFn())
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8")
+	// Feeds are not in a hurry, so the patient client does.
+	resp, err := linkClient.Do(req)
+	if err != nil {
+		return feedparse.Feed{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return feedparse.Feed{}, fmt.Errorf("feed %s returned status %d", addr, resp.StatusCode)
+	}
+	return feedparse.Parse(io.LimitReader(resp.Body, feedLimit))
+}
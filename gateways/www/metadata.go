@@ -0,0 +1,181 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package wwwgw
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+
+	wwwports "git.sr.ht/~bouncepaw/betula/ports/www"
+)
+
+// metadataLimit is how much of the page is read in search of the end of
+// <head>. Some heads are bloated with inline scripts and styles.
+const metadataLimit = 512 * 1024
+
+func (www *WWW) MetadataOfPage(addr string) (wwwports.PageMetadata, error) {
+	resp, err := www.get(addr)
+	if err != nil {
+		return wwwports.PageMetadata{}, err
+	}
+	defer resp.Body.Close()
+
+	r, err := charset.NewReader(resp.Body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		r = resp.Body
+	}
+
+	var tags = readHead(io.LimitReader(r, metadataLimit))
+	return tags.metadata(resp.Request.URL), nil
+}
+
+// headTags are the <head> values of interest. Meta tags are keyed by
+// their lowercased name, property or itemprop.
+type headTags struct {
+	title string
+	base  string
+	meta  map[string]string
+	links map[string]string
+}
+
+// readHead collects the tags until the body starts. The first value
+// of each kind wins.
+func readHead(r io.Reader) headTags {
+	var (
+		tags = headTags{
+			meta:  map[string]string{},
+			links: map[string]string{},
+		}
+		z       = html.NewTokenizer(r)
+		inTitle bool
+	)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return tags
+		case html.TextToken:
+			if inTitle && tags.title == "" {
+				tags.title = strings.TrimSpace(string(z.Text()))
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.Title:
+				inTitle = false
+			case atom.Head:
+				return tags
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			var token = z.Token()
+			switch token.DataAtom {
+			case atom.Body:
+				return tags
+			case atom.Title:
+				inTitle = true
+			case atom.Base:
+				if tags.base == "" {
+					tags.base = attrOf(token, "href")
+				}
+			case atom.Meta:
+				var content = strings.TrimSpace(attrOf(token, "content"))
+				if content == "" {
+					continue
+				}
+				for _, key := range []string{"property", "name", "itemprop"} {
+					var name = strings.ToLower(attrOf(token, key))
+					if _, seen := tags.meta[name]; name != "" && !seen {
+						tags.meta[name] = content
+					}
+				}
+			case atom.Link:
+				var href = strings.TrimSpace(attrOf(token, "href"))
+				if href == "" {
+					continue
+				}
+				for rel := range strings.FieldsSeq(strings.ToLower(attrOf(token, "rel"))) {
+					if _, seen := tags.links[rel]; !seen {
+						tags.links[rel] = href
+					}
+				}
+			}
+		}
+	}
+}
+
+// first returns the first of the meta values present.
+func (tags headTags) first(names ...string) string {
+	for _, name := range names {
+		if val := tags.meta[name]; val != "" {
+			return val
+		}
+	}
+	return ""
+}
+
+func (tags headTags) metadata(pageURL *url.URL) wwwports.PageMetadata {
+	var base = pageURL
+	if tags.base != "" {
+		if u, err := pageURL.Parse(tags.base); err == nil {
+			base = u
+		}
+	}
+
+	var meta = wwwports.PageMetadata{
+		Title:        tags.first("og:title", "twitter:title"),
+		Description:  tags.first("og:description", "twitter:description", "description"),
+		CanonicalURL: resolve(base, tags.links["canonical"]),
+		Author:       tags.first("author", "article:author", "twitter:creator"),
+		Published:    tags.first("article:published_time", "datepublished", "date", "dcterms.created", "dc.date"),
+		IconURL:      resolve(base, tags.links["icon"]),
+		ImageURL:     resolve(base, tags.first("og:image", "og:image:url", "twitter:image", "twitter:image:src")),
+	}
+	if meta.Title == "" {
+		meta.Title = tags.title
+	}
+	if meta.CanonicalURL == "" {
+		meta.CanonicalURL = resolve(base, tags.meta["og:url"])
+	}
+	// article:author is often a link to the author's profile. A name is
+	// more useful, and a link is still better than nothing.
+	if strings.HasPrefix(meta.Author, "http") {
+		if name := tags.first("author", "twitter:creator"); name != "" {
+			meta.Author = name
+		}
+	}
+	if meta.IconURL == "" {
+		meta.IconURL = resolve(base, tags.links["apple-touch-icon"])
+	}
+	if meta.IconURL == "" {
+		meta.IconURL = resolve(pageURL, "/favicon.ico")
+	}
+	return meta
+}
+
+// resolve makes the address absolute. Addresses that are not web
+// addresses turn into an empty string.
+func resolve(base *url.URL, addr string) string {
+	if addr == "" {
+		return ""
+	}
+	u, err := base.Parse(addr)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return u.String()
+}
+
+func attrOf(token html.Token, key string) string {
+	for _, attr := range token.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
@@ -45,18 +45,27 @@ var client = http.Client{
 	Timeout: 2 * time.Second,
 }
 
-func (www *WWW) fetch(addr string) (r io.Reader, closeBody func(), err error) {
+// get requests the page. The caller closes the body.
+func (www *WWW) get(addr string) (*http.Response, error) {
 	req, err := http.NewRequest(http.MethodGet, addr, nil)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	req.Header.Set("User-Agent", www.userAgentFn())
-	resp, err := client.Do(req) //nolint:bodyclose // resp.Body is closed with closeBody func.
+	resp, err := client.Do(req)
 	if err != nil {
 		if err.(*url.Error).Timeout() {
-			return nil, nil, wwwports.ErrTimeout
+			return nil, wwwports.ErrTimeout
 		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (www *WWW) fetch(addr string) (r io.Reader, closeBody func(), err error) {
+	resp, err := www.get(addr) //nolint:bodyclose // resp.Body is closed with closeBody func.
+	if err != nil {
 		return nil, nil, err
 	}
 
@@ -68,6 +77,40 @@ func (www *WWW) fetch(addr string) (r io.Reader, closeBody func(), err error) {
 	return r, func() { resp.Body.Close() }, nil
 }
 
+// linkClient is patient, because slow pages are not dead pages.
+var linkClient = http.Client{
+	Timeout: 15 * time.Second,
+}
+
+func (www *WWW) CheckLink(addr string) (wwwports.LinkStatus, error) {
+	// HEAD is cheap, but some servers do not support it properly.
+	// Trust only good news from it.
+	status, err := www.checkLink(http.MethodHead, addr)
+	if err == nil && status.StatusCode < 400 {
+		return status, nil
+	}
+	return www.checkLink(http.MethodGet, addr)
+}
+
+func (www *WWW) checkLink(method, addr string) (wwwports.LinkStatus, error) {
+	req, err := http.NewRequest(method, addr, nil)
+	if err != nil {
+		return wwwports.LinkStatus{}, err
+	}
+
+	req.Header.Set("User-Agent", www.userAgentFn())
+	resp, err := linkClient.Do(req)
+	if err != nil {
+		return wwwports.LinkStatus{}, err
+	}
+	defer resp.Body.Close()
+
+	return wwwports.LinkStatus{
+		StatusCode: resp.StatusCode,
+		FinalURL:   resp.Request.URL.String(),
+	}, nil
+}
+
 func (www *WWW) TitleOfPage(addr string) (string, error) {
 	r, closeBody, err := www.fetch(addr)
 	if err != nil {
@@ -156,3 +156,89 @@ func TestTitleOfPageWithDefaultLimit(t *testing.T) {
 	be.Err(t, err, nil)
 	be.Equal(t, title, "Late Title")
 }
+
+func TestCheckLink(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, rq *http.Request) {
+		http.Redirect(w, rq, "/new", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, rq *http.Request) {})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, rq *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+	mux.HandleFunc("/no-head", func(w http.ResponseWriter, rq *http.Request) {
+		if rq.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	www := New(testUserAgent)
+
+	status, err := www.CheckLink(server.URL + "/old")
+	be.Err(t, err, nil)
+	be.Equal(t, status, wwwports.LinkStatus{StatusCode: http.StatusOK, FinalURL: server.URL + "/new"})
+
+	status, err = www.CheckLink(server.URL + "/gone")
+	be.Err(t, err, nil)
+	be.Equal(t, status.StatusCode, http.StatusGone)
+
+	status, err = www.CheckLink(server.URL + "/no-head")
+	be.Err(t, err, nil)
+	be.Equal(t, status.StatusCode, http.StatusOK)
+
+	_, err = www.CheckLink("http://betula.invalid/")
+	be.True(t, err != nil)
+}
+
+func TestMetadataOfPage(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/post", func(w http.ResponseWriter, rq *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><head>
+<title>Post | Blog</title>
+<meta name="description" content="Plain description">
+<meta property="og:title" content="Post">
+<meta property="og:description" content="Social description">
+<meta property="og:image" content="/images/post.png">
+<meta property="article:author" content="https://blog.example/about">
+<meta name="twitter:creator" content="@blogger">
+<meta property="article:published_time" content="2026-03-01T10:00:00Z">
+<link rel="canonical" href="/post?id=1">
+<link rel="shortcut icon" href="/icon.png">
+</head><body><meta property="og:title" content="Not in head"></body></html>`))
+	})
+	mux.HandleFunc("/bare", func(w http.ResponseWriter, rq *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><head><title>Bare</title><meta property="og:url" content="https://bare.example/"></head></html>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	www := New(testUserAgent)
+
+	meta, err := www.MetadataOfPage(server.URL + "/post")
+	be.Err(t, err, nil)
+	be.Equal(t, meta, wwwports.PageMetadata{
+		Title:        "Post",
+		Description:  "Social description",
+		CanonicalURL: server.URL + "/post?id=1",
+		Author:       "@blogger",
+		Published:    "2026-03-01T10:00:00Z",
+		IconURL:      server.URL + "/icon.png",
+		ImageURL:     server.URL + "/images/post.png",
+	})
+
+	meta, err = www.MetadataOfPage(server.URL + "/bare")
+	be.Err(t, err, nil)
+	be.Equal(t, meta, wwwports.PageMetadata{
+		Title:        "Bare",
+		CanonicalURL: "https://bare.example/",
+		IconURL:      server.URL + "/favicon.ico",
+	})
+}
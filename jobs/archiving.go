@@ -0,0 +1,202 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"git.sr.ht/~bouncepaw/betula/db"
+	"git.sr.ht/~bouncepaw/betula/jobs/jobtype"
+	archivingports "git.sr.ht/~bouncepaw/betula/ports/archiving"
+	"git.sr.ht/~bouncepaw/betula/settings"
+	"git.sr.ht/~bouncepaw/betula/svc/archiving"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+const (
+	// archiveHostInterval is how long to wait between archiving two pages
+	// from the same host, so we do not hammer it.
+	archiveHostInterval = 30 * time.Second
+	// archiveInterval is how long to wait between archiving any two pages,
+	// so archiving everything does not keep the worker busy for the whole run.
+	archiveInterval = 5 * time.Second
+	// archiveIndexBatch is how many archives one IndexArchives job indexes.
+	archiveIndexBatch = 50
+)
+
+var (
+	repoArchives                        = db.NewArchivesRepo()
+	svcArchiving archivingports.Service = archivingsvc.New(archivingsvc.NewFetchers(settings.UserAgent), repoArchives, settings.ArchiveQuota)
+	archiveSlots                        = newHostSlots()
+)
+
+// hostSlots hands out times when the hosts may be visited next, and keeps
+// track of the actual visits. The planned times are only a wish: jobs are
+// late when the worker is busy or Betula was down, so visit has the final say.
+type hostSlots struct {
+	mu   sync.Mutex
+	next map[string]time.Time
+	// nextAny is when any host may be visited next.
+	nextAny time.Time
+	visited map[string]time.Time
+	// visitedAny is when any host was visited last.
+	visitedAny time.Time
+}
+
+func newHostSlots() *hostSlots {
+	return &hostSlots{
+		next:    make(map[string]time.Time),
+		visited: make(map[string]time.Time),
+	}
+}
+
+// take returns the earliest time not earlier than now when the host may be
+// visited, and books it.
+func (s *hostSlots) take(host string, now time.Time) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	slot := now
+	if next, ok := s.next[host]; ok && next.After(slot) {
+		slot = next
+	}
+	if s.nextAny.After(slot) {
+		slot = s.nextAny
+	}
+	s.next[host] = slot.Add(archiveHostInterval)
+	s.nextAny = slot.Add(archiveInterval)
+	return slot
+}
+
+// visit records a visit of the host now and returns 0 if it is not too early
+// for it. Otherwise, it returns how long to wait, and records nothing.
+func (s *hostSlots) visit(host string, now time.Time) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var wait time.Duration
+	if last, ok := s.visited[host]; ok {
+		wait = last.Add(archiveHostInterval).Sub(now)
+	}
+	if !s.visitedAny.IsZero() {
+		wait = max(wait, s.visitedAny.Add(archiveInterval).Sub(now))
+	}
+	if wait > 0 {
+		return wait
+	}
+	s.visited[host] = now
+	s.visitedAny = now
+	return 0
+}
+
+// autoArchives is true if the new bookmark is to be archived according to
+// the auto-archiving settings.
+func autoArchives(bookmark types.Bookmark, tags string, sharedOnly bool) bool {
+	if sharedOnly && bookmark.Visibility == types.Private {
+		return false
+	}
+	if strings.TrimSpace(tags) == "" {
+		return true
+	}
+	for _, wanted := range types.SplitTags(tags) {
+		if wanted.Name == "" {
+			continue
+		}
+		if slices.ContainsFunc(bookmark.Tags, func(tag types.Tag) bool {
+			return types.CanonicalTagName(tag.Name) == wanted.Name
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+// ArchiveNewBookmark schedules archiving the bookmark if the admin wants new
+// bookmarks archived. The bookmark must have its ID and tags set.
+func ArchiveNewBookmark(bookmark types.Bookmark) {
+	if !settings.AutoArchive() ||
+		!autoArchives(bookmark, settings.AutoArchiveTags(), settings.AutoArchiveSharedOnly()) {
+		return
+	}
+	ScheduleArchiving([]types.Bookmark{bookmark})
+}
+
+// ArchiveEverything schedules archiving all bookmarks that have no archive
+// yet. Returns how many were scheduled.
+func ArchiveEverything(ctx context.Context) (int, error) {
+	bookmarks, err := repoArchives.BookmarksWithoutArchives()
+	if err != nil {
+		return 0, err
+	}
+
+	// Do not queue the bookmarks that are queued already.
+	queued := make(map[int]bool)
+	pending, err := jobsRepo.Jobs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, job := range pending {
+		var id int
+		if data, ok := job.Payload.([]byte); ok && job.Category == jobtype.ArchiveBookmark &&
+			json.Unmarshal(data, &id) == nil {
+			queued[id] = true
+		}
+	}
+	bookmarks = slices.DeleteFunc(bookmarks, func(bookmark types.Bookmark) bool {
+		return queued[bookmark.ID]
+	})
+
+	ScheduleArchiving(bookmarks)
+	return len(bookmarks), nil
+}
+
+// ScheduleArchiving schedules archiving the bookmarks, one job per bookmark.
+// Pages from the same host are spread in time by archiveHostInterval, all
+// pages by archiveInterval.
+func ScheduleArchiving(bookmarks []types.Bookmark) {
+	now := time.Now()
+	for _, bookmark := range bookmarks {
+		var host string
+		if addr, err := url.Parse(bookmark.URL); err == nil {
+			host = addr.Hostname()
+		}
+
+		data, err := json.Marshal(bookmark.ID)
+		if err != nil {
+			slog.Error("Failed to schedule archiving", "bookmarkID", bookmark.ID, "err", err)
+			continue
+		}
+		job := jobtype.Job{
+			Category: jobtype.ArchiveBookmark,
+			Payload:  data,
+		}
+		if due := archiveSlots.take(host, now); due.After(now) {
+			job.Due = due
+		}
+		plan(job)
+	}
+}
+
+// ScheduleArchiveIndexing makes sure the archives made before search in
+// archives existed get indexed. Call it on start.
+func ScheduleArchiveIndexing(ctx context.Context) {
+	unindexed, err := repoArchives.ArchivesWithoutText(1)
+	if err != nil {
+		slog.Error("Failed to find archives without text", "err", err)
+		return
+	}
+	if len(unindexed) == 0 {
+		return
+	}
+
+	planOnce(ctx, jobtype.IndexArchives)
+}
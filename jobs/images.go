@@ -0,0 +1,26 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package jobs
+
+import (
+	wwwgw "git.sr.ht/~bouncepaw/betula/gateways/www"
+	"git.sr.ht/~bouncepaw/betula/jobs/jobtype"
+	wwwports "git.sr.ht/~bouncepaw/betula/ports/www"
+	"git.sr.ht/~bouncepaw/betula/settings"
+)
+
+var www wwwports.WorldWideWeb = wwwgw.New(settings.UserAgent)
+
+// SaveBookmarkImages schedules saving the site icon and the preview image
+// of the new bookmark. If iconURL is empty, the page is asked for it.
+// If previewURL is empty, there is no preview image.
+func SaveBookmarkImages(bookmarkID int, url, iconURL, previewURL string) {
+	ScheduleJSON(jobtype.SaveBookmarkImages, jobtype.ImagesRequest{
+		BookmarkID: bookmarkID,
+		URL:        url,
+		IconURL:    iconURL,
+		PreviewURL: previewURL,
+	})
+}
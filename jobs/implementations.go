@@ -9,48 +9,61 @@ package jobs
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
+	"time"
 
 	"git.sr.ht/~bouncepaw/betula/db"
 	"git.sr.ht/~bouncepaw/betula/fediverse"
 	"git.sr.ht/~bouncepaw/betula/jobs/jobtype"
 	"git.sr.ht/~bouncepaw/betula/pkg/bxstr"
 	apports "git.sr.ht/~bouncepaw/betula/ports/activitypub"
+	archivingports "git.sr.ht/~bouncepaw/betula/ports/archiving"
+	webhooksports "git.sr.ht/~bouncepaw/betula/ports/webhooks"
 	"git.sr.ht/~bouncepaw/betula/settings"
 	"git.sr.ht/~bouncepaw/betula/svc/activitypub/assembly"
+	"git.sr.ht/~bouncepaw/betula/svc/subscriptions"
+	"git.sr.ht/~bouncepaw/betula/types"
 	notiftypes "git.sr.ht/~bouncepaw/betula/types/notif"
 )
 
 // TODO: all shall be in services one day...
 var (
-	repoNotif                           = db.New()
-	repoLocalBookmarks                  = db.NewLocalBookmarksRepo()
-	repoActor                           = db.NewActorRepo()
-	asm                apports.Assembly = assembly.New(settings.SiteURL, settings.AdminUsername)
+	repoNotif                            = db.New()
+	repoLocalBookmarks                   = db.NewLocalBookmarksRepo()
+	repoActor                            = db.NewActorRepo()
+	repoRemoteBookmarks                  = db.NewRemoteBookmarkRepo()
+	repoBlocks                           = db.NewBlocksRepo()
+	asm                 apports.Assembly = assembly.New(settings.SiteURL, settings.AdminUsername)
 )
 
-func callForJSON[T any](jobcat jobtype.JobCategory, next func(T)) func(jobtype.Job) {
-	return func(job jobtype.Job) {
+func callForJSON[T any](jobcat jobtype.JobCategory, next func(T) error) func(jobtype.Job) error {
+	return func(job jobtype.Job) error {
 		data, ok := job.Payload.([]byte)
 		if !ok {
 			slog.Error("Unexpected payload for job", "category", jobcat, "payloadType", fmt.Sprintf("%T", job.Payload), "payload", job.Payload)
-			return
+			return permanent(fmt.Errorf("unexpected payload type %T", job.Payload))
 		}
 
 		var report T
 		err := json.Unmarshal(data, &report)
 		if err != nil {
 			slog.Error("Failed to unmarshal payload for job", "category", jobcat, "err", err)
-			return
+			return permanent(err)
 		}
 
-		next(report)
+		return next(report)
 	}
 }
 
-var catmap = map[jobtype.JobCategory]func(job jobtype.Job){
+// catmap maps job categories to their implementations. An implementation
+// returns an error if the job is to be retried later. Wrap the error with
+// permanent if retrying will not help.
+var catmap = map[jobtype.JobCategory]func(job jobtype.Job) error{
 	jobtype.SendAcceptFollow:    callForJSON[apports.FollowReport](jobtype.SendAcceptFollow, sendAcceptFollow),
 	jobtype.SendRejectFollow:    callForJSON[apports.FollowReport](jobtype.SendRejectFollow, sendRejectFollow),
 	jobtype.ReceiveAcceptFollow: callForJSON[apports.FollowReport](jobtype.ReceiveAcceptFollow, receiveAcceptFollow),
@@ -58,6 +71,14 @@ var catmap = map[jobtype.JobCategory]func(job jobtype.Job){
 	jobtype.SendCreateNote:      broadcastToFollowers,
 	jobtype.SendDeleteNote:      broadcastToFollowers,
 	jobtype.SendUpdateNote:      broadcastToFollowers,
+	jobtype.BackfillTimeline:    callForJSON[apports.FollowReport](jobtype.BackfillTimeline, backfillTimeline),
+	jobtype.DeliverToInbox:      callForJSON[jobtype.Delivery](jobtype.DeliverToInbox, deliverToInbox),
+	jobtype.ArchiveBookmark:     callForJSON[int](jobtype.ArchiveBookmark, archiveBookmark),
+	jobtype.CheckLinks:          checkLinks,
+	jobtype.IndexArchives:       indexArchives,
+	jobtype.SaveBookmarkImages:  callForJSON[jobtype.ImagesRequest](jobtype.SaveBookmarkImages, saveBookmarkImages),
+	jobtype.PollFeeds:           pollFeeds,
+	jobtype.DeliverWebhook:      deliverWebhook,
 }
 
 func byteCast(raw any) ([]byte, error) {
@@ -73,110 +94,296 @@ func byteCast(raw any) ([]byte, error) {
 	return nil, fmt.Errorf("unexpected type for byte cast: %T", raw)
 }
 
-func broadcastToFollowers(job jobtype.Job) {
+func broadcastToFollowers(job jobtype.Job) error {
 	// The payload is a []byte we have to send to every follower.
 	payload, err := byteCast(job.Payload)
 	if err != nil {
 		slog.Error("Unexpected payload for broadcast",
 			"category", job.Category, "payloadType", fmt.Sprintf("%T", payload), "err", err)
-		return
+		return permanent(err)
 	}
 
 	followers, err := repoActor.GetFollowers(context.Background())
 	if err != nil {
 		slog.Error("Failed to fetch followers for broadcast", "category", job.Category, "err", err)
-		return
+		return err
 	}
 	if len(followers) == 0 {
 		slog.Info("Nobody to broadcast to :-(")
-		return
+		return nil
 	}
 
-	slog.Info("Broadcasting to followers", "payload", job.Payload)
-
-	succSends := len(followers)
-
-	// This loop might take some time (n = len(followers)) because we don't parallelize it.
-	// I don't we should parallelize it.
-	for _, follower := range followers {
-		err := SendQuietActivityToInbox(payload, follower.Inbox)
-		if err != nil {
-			slog.Error("Failed to send to a follower", "inbox", follower.Inbox, "err", err)
-			succSends--
-		}
-	}
+	inboxes := ScheduleDeliveries(followers, payload)
+	slog.Info("Scheduled broadcast to followers", "category", job.Category, "followers", len(followers), "inboxes", inboxes)
+	return nil
+}
 
-	slog.Info("Sent to followers", "category", job.Category, "success", succSends, "total", len(followers))
+func deliverToInbox(delivery jobtype.Delivery) error {
+	return SendQuietActivityToInbox(delivery.Activity, delivery.Inbox)
 }
 
-func receiveAcceptFollow(report apports.FollowReport) {
+func receiveAcceptFollow(report apports.FollowReport) error {
 	// We assume that they are actually talking about us, because we filtered out wrong activities in the inbox.
 
 	ctx := context.Background()
 	status, err := repoActor.SubscriptionStatus(ctx, report.ObjectID)
 	if err != nil {
 		slog.Error("Failed to get subscription status", "objectID", report.ObjectID, "err", err)
-		return
+		return err
 	}
 	if status.IsPending() {
 		slog.Info("Received Accept{Follow}", "objectID", report.ObjectID)
 		if err := repoActor.MarkAsSurelyFollowing(ctx, report.ObjectID); err != nil {
 			slog.Error("Failed to mark as surely following", "objectID", report.ObjectID, "err", err)
+			return err
 		}
+		ScheduleJSON(jobtype.BackfillTimeline, report)
 	} else {
 		slog.Warn("Received invalid Accept{Follow}, ignoring", "status", status, "activity", report.OriginalActivity)
 	}
+	return nil
 }
 
-func receiveRejectFollow(report apports.FollowReport) {
+// backfillTimeline fetches older bookmarks of the actor we have just
+// followed into the timeline.
+func backfillTimeline(report apports.FollowReport) error {
+	pages := settings.BackfillPages()
+	if pages == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	status, err := repoActor.SubscriptionStatus(ctx, report.ObjectID)
+	if err != nil {
+		slog.Error("Failed to get subscription status", "objectID", report.ObjectID, "err", err)
+		return err
+	}
+	if !status.WeFollowThem() {
+		slog.Info("Not following anymore, not backfilling", "objectID", report.ObjectID)
+		return nil
+	}
+
+	bookmarks, err := fediverse.FetchOutboxBookmarks(report.ObjectID, pages)
+	if errors.Is(err, fediverse.ErrNoOutbox) {
+		slog.Info("Nothing to backfill from", "objectID", report.ObjectID, "err", err)
+		return nil
+	}
+	if err != nil {
+		slog.Error("Failed to fetch outbox for backfilling", "objectID", report.ObjectID, "err", err)
+		return err
+	}
+
+	inserted := 0
+	for _, bookmark := range bookmarks {
+		exists, err := repoRemoteBookmarks.Exists(bookmark.ID)
+		if err != nil {
+			slog.Error("Failed to check remote bookmark existence", "bookmarkID", bookmark.ID, "err", err)
+			continue
+		}
+		if exists {
+			continue
+		}
+		repoRemoteBookmarks.InsertRemoteBookmark(bookmark)
+		inserted++
+	}
+	slog.Info("Backfilled timeline", "objectID", report.ObjectID, "pages", pages, "found", len(bookmarks), "inserted", inserted)
+	return nil
+}
+
+func receiveRejectFollow(report apports.FollowReport) error {
 	// We assume that they are actually talking about us, because we filtered out wrong activities in the inbox.
 
 	ctx := context.Background()
 	status, err := repoActor.SubscriptionStatus(ctx, report.ObjectID)
 	if err != nil {
 		slog.Error("Failed to get subscription status", "objectID", report.ObjectID, "err", err)
-		return
+		return err
 	}
 	if status.IsPending() {
 		slog.Info("Received Reject{Follow}", "objectID", report.ObjectID)
 		if err := repoActor.StopFollowing(ctx, report.ObjectID); err != nil {
 			slog.Error("Failed to stop following", "objectID", report.ObjectID, "err", err)
+			return err
 		}
 	} else {
 		slog.Warn("Received invalid Reject{Follow}, ignoring", "status", status, "activity", report.OriginalActivity)
 	}
+	return nil
 }
 
-func sendRejectFollow(report apports.FollowReport) {
+func sendRejectFollow(report apports.FollowReport) error {
 	if !bxstr.IsValidURL(report.ActorID) {
 		slog.Error("Invalid actor ID, dropping activity", "actorID", report.ActorID)
+		return permanent(fmt.Errorf("invalid actor ID %q", report.ActorID))
 	}
 
 	activity, err := asm.NewReject(report.OriginalActivity)
+	if err != nil {
+		slog.Error("Failed to make Reject activity", "err", err)
+		return permanent(err)
+	}
 	if err = SendActivityToInbox(activity, fediverse.RequestActorInboxByID(report.ActorID)); err != nil {
 		slog.Error("Failed to send Reject activity", "err", err)
+		return err
 	}
+	return nil
 }
 
-func sendAcceptFollow(report apports.FollowReport) {
+func sendAcceptFollow(report apports.FollowReport) error {
 	if !bxstr.IsValidURL(report.ActorID) {
 		slog.Error("Dropping activity", "reason", "invalid actor ID", "actorID", report.ActorID)
-		return
+		return permanent(fmt.Errorf("invalid actor ID %q", report.ActorID))
 	}
 
 	activity, err := asm.NewAccept(report.OriginalActivity)
+	if err != nil {
+		slog.Error("Failed to make Accept activity", "err", err)
+		return permanent(err)
+	}
 	if err = SendActivityToInbox(activity, fediverse.RequestActorInboxByID(report.ActorID)); err != nil {
 		slog.Error("Failed to send activity", "err", err, "recipient", report.ActorID)
-	} else {
-		if err := repoActor.AddFollower(context.Background(), report.ActorID); err != nil {
-			slog.Error("Failed to add follower", "actorID", report.ActorID, "err", err)
-		}
+		return err
+	}
 
-		err = repoNotif.Store(context.Background(), notiftypes.KindFollow, notiftypes.FollowPayload{
+	// The Accept is sent, so the job is done even if we fail below.
+	// Retrying would send it again.
+	added, err := repoActor.AddFollower(context.Background(), report.ActorID)
+	if err != nil {
+		slog.Error("Failed to add follower", "actorID", report.ActorID, "err", err)
+	}
+
+	err = repoNotif.Store(context.Background(), notiftypes.KindFollow, notiftypes.FollowPayload{
+		ActorID: report.ActorID,
+	})
+	if err != nil {
+		slog.Error("Failed to store follow notification", "err", err)
+	}
+	// A repeated Follow from a follower gains nothing.
+	if added {
+		svcWebhooks.Fire(context.Background(), webhooksports.Payload{
+			Event:   webhooksports.EventFollowerGained,
 			ActorID: report.ActorID,
 		})
+	}
+	return nil
+}
+
+// archiveBookmark makes an archive copy of the bookmark, unless it has one.
+func archiveBookmark(bookmarkID int) error {
+	bookmark, err := repoLocalBookmarks.GetBookmarkByID(context.Background(), bookmarkID)
+	if errors.Is(err, sql.ErrNoRows) {
+		slog.Info("Bookmark to archive is gone, skipping", "bookmarkID", bookmarkID)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	archives, err := repoArchives.FetchForBookmark(int64(bookmarkID))
+	if err != nil {
+		return err
+	}
+	if len(archives) > 0 {
+		slog.Info("Bookmark is archived already, skipping", "bookmarkID", bookmarkID)
+		return nil
+	}
+
+	var host string
+	if addr, err := url.Parse(bookmark.URL); err == nil {
+		host = addr.Hostname()
+	}
+	if wait := archiveSlots.visit(host, time.Now()); wait > 0 {
+		return tooEarly(wait)
+	}
+
+	archiveID, err := svcArchiving.Archive(bookmark, types.ArchiveAuto)
+	if errors.Is(err, archivingports.ErrQuotaExceeded) {
+		return permanent(err)
+	} else if err != nil {
+		return err
+	}
+	slog.Info("Archived bookmark", "bookmarkID", bookmarkID, "archiveID", archiveID)
+	return nil
+}
+
+// checkLinks checks a batch of bookmark links and plans the next batch.
+func checkLinks(jobtype.Job) error {
+	checked, err := svcLinkRot.CheckStale(context.Background(), linkCheckBatch)
+	if err != nil {
+		slog.Error("Failed to check links", "err", err)
+		return err
+	}
+	slog.Info("Checked links", "count", checked)
+
+	plan(jobtype.Job{
+		Category: jobtype.CheckLinks,
+		Due:      nextLinkCheck(checked, time.Now()),
+	})
+	return nil
+}
+
+// indexArchives extracts the text of a batch of old archives for search
+// and plans the next batch if there might be more.
+func indexArchives(jobtype.Job) error {
+	indexed, err := svcArchiving.IndexArchives(archiveIndexBatch)
+	if err != nil {
+		slog.Error("Failed to index archives", "err", err)
+		return err
+	}
+	slog.Info("Indexed archives", "count", indexed)
+
+	if indexed == archiveIndexBatch {
+		plan(jobtype.Job{Category: jobtype.IndexArchives})
+	}
+	return nil
+}
+
+func saveBookmarkImages(rq jobtype.ImagesRequest) error {
+	_, err := repoLocalBookmarks.GetBookmarkByID(context.Background(), rq.BookmarkID)
+	if errors.Is(err, sql.ErrNoRows) {
+		slog.Info("Bookmark to save images for is gone, skipping", "bookmarkID", rq.BookmarkID)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if rq.IconURL == "" {
+		meta, err := www.MetadataOfPage(rq.URL)
 		if err != nil {
-			slog.Error("Failed to store follow notification", "err", err)
+			return err
 		}
+		rq.IconURL = meta.IconURL
 	}
+
+	// Many sites have no icon at all, trying again will not find one.
+	// The failures are logged, and that is enough.
+	_ = svcArchiving.SaveImages(int64(rq.BookmarkID), rq.IconURL, rq.PreviewURL)
+	return nil
+}
+
+// pollFeeds polls the subscribed feeds and plans the next poll.
+func pollFeeds(jobtype.Job) error {
+	added, err := svcSubscriptions.PollAll(context.Background())
+	if err != nil {
+		slog.Error("Failed to poll feeds", "err", err)
+		return err
+	}
+	slog.Info("Polled feeds", "newSuggestions", added)
+
+	plan(jobtype.Job{
+		Category: jobtype.PollFeeds,
+		Due:      time.Now().Add(subscriptionssvc.RepollAfter),
+	})
+	return nil
+}
+
+// deliverWebhook makes an attempt to deliver an event to a webhook. The
+// last attempt marks the delivery as failed, so the log says so.
+func deliverWebhook(job jobtype.Job) error {
+	return callForJSON[int64](jobtype.DeliverWebhook, func(deliveryID int64) error {
+		err := svcWebhooks.Deliver(context.Background(), deliveryID, job.Attempts+1 >= MaxAttempts)
+		if errors.Is(err, webhooksports.ErrRejected) {
+			return permanent(err)
+		}
+		return err
+	})(job)
 }
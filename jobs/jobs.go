@@ -15,6 +15,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -28,7 +29,25 @@ import (
 	"git.sr.ht/~bouncepaw/betula/types"
 )
 
-var jobch = make(chan jobtype.Job)
+// MaxAttempts is how many times a job is tried before it is parked as failed.
+const MaxAttempts = 8
+
+// pollInterval is how often the database is checked for postponed jobs.
+const pollInterval = time.Minute
+
+// worker runs due jobs of some categories one by one. There are two of them,
+// see jobtype.JobCategory.Urgent.
+type worker struct {
+	urgent bool
+	// wakech wakes the worker up when a job is planned. A pending wake-up
+	// is enough, so sending never blocks.
+	wakech chan struct{}
+}
+
+var workers = []worker{
+	{urgent: true, wakech: make(chan struct{}, 1)},
+	{urgent: false, wakech: make(chan struct{}, 1)},
+}
 
 var jobsRepo = db.NewJobsRepo()
 
@@ -40,17 +59,19 @@ var client = http.Client{
 //
 // TODO: get rid of it.
 func ScheduleDatum(category jobtype.JobCategory, data any) {
-	job := jobtype.Job{
+	plan(jobtype.Job{
 		Category: category,
 		Payload:  data,
-	}
-	id, err := jobsRepo.PlanJob(context.Background(), job)
-	if err != nil {
-		slog.Error("Failed to plan job", "category", category, "err", err)
+	})
+}
+
+// plan puts the job into the database and wakes the worker up.
+func plan(job jobtype.Job) {
+	if _, err := jobsRepo.PlanJob(context.Background(), job); err != nil {
+		slog.Error("Failed to plan job", "category", job.Category, "err", err)
 		return
 	}
-	job.ID = id
-	jobch <- job
+	wake()
 }
 
 // ScheduleJSON schedules a job with the given category and data, which will be marshaled into JSON before saving to database. This is the one you should use, unlike ScheduleDatum.
@@ -63,73 +84,260 @@ func ScheduleJSON(category jobtype.JobCategory, dataJSON any) {
 	ScheduleDatum(category, data)
 }
 
-func ListenAndWhisper() {
-	lateJobs, err := jobsRepo.LoadAllJobs(context.Background())
+// ScheduleDeliveries schedules delivering the activity to the actors, one job
+// per inbox. Actors that share an inbox get the activity once. Blocked actors
+// get nothing. Returns how many deliveries were scheduled.
+func ScheduleDeliveries(actors []types.Actor, activity []byte) int {
+	var recipients []types.Actor
+	for _, actor := range actors {
+		blocked, err := repoBlocks.IsBlocked(context.Background(), actor.ID)
+		if err != nil {
+			slog.Error("Failed to check if actor is blocked", "actorID", actor.ID, "err", err)
+			continue
+		}
+		if !blocked {
+			recipients = append(recipients, actor)
+		}
+	}
+
+	inboxes := deliveryInboxes(recipients)
+	for _, inbox := range inboxes {
+		ScheduleJSON(jobtype.DeliverToInbox, jobtype.Delivery{
+			Inbox:    inbox,
+			Activity: activity,
+		})
+	}
+	return len(inboxes)
+}
+
+// deliveryInboxes returns the distinct inboxes to deliver to the actors,
+// preferring shared inboxes.
+func deliveryInboxes(actors []types.Actor) []string {
+	var (
+		inboxes []string
+		seen    = make(map[string]bool)
+	)
+	for _, actor := range actors {
+		inbox := actor.DeliveryInbox()
+		if inbox == "" || seen[inbox] {
+			continue
+		}
+		seen[inbox] = true
+		inboxes = append(inboxes, inbox)
+	}
+	return inboxes
+}
+
+// planOnce plans a job of the category unless one is pending already.
+// It is for jobs that plan their next run by themselves.
+func planOnce(ctx context.Context, category jobtype.JobCategory) {
+	allJobs, err := jobsRepo.Jobs(ctx)
 	if err != nil {
 		slog.Error("Failed to load jobs", "err", err)
+		return
 	}
-	go func() {
-		for job := range jobch {
-			slog.Info("Received job", "id", job.ID, "category", job.Category)
-			if jobber, ok := catmap[job.Category]; !ok {
-				fmt.Printf("An unhandled job category came in: %s\n", job.Category)
-			} else {
-				jobber(job)
-			}
-			if err := jobsRepo.DropJob(context.Background(), job.ID); err != nil {
-				slog.Error("Failed to drop job", "id", job.ID, "err", err)
-			}
+	for _, job := range allJobs {
+		if job.Category == category && !job.Failed {
+			return
 		}
-	}()
-	for _, job := range lateJobs {
-		jobch <- job
 	}
+	plan(jobtype.Job{Category: category})
 }
 
-// TODO: Move to a proper place
-func SendActivityToInbox(activity []byte, inbox string) error {
-	rq, err := http.NewRequest(http.MethodPost, inbox, bytes.NewReader(activity))
-	if err != nil {
-		slog.Error("Failed to create request for SendActivityToInbox", "err", err)
-		return err
+func wake() {
+	for _, w := range workers {
+		select {
+		case w.wakech <- struct{}{}:
+		default:
+		}
 	}
+}
 
-	rq.Header.Set("User-Agent", settings.UserAgent())
-	rq.Header.Set("Content-Type", types.ActivityType)
-	signing.SignRequest(rq, activity)
+// ListenAndWhisper runs due jobs forever. Urgent jobs and the rest are run
+// by separate workers, each one by one. Jobs left from the previous run are
+// picked up too.
+func ListenAndWhisper() {
+	for _, w := range workers[1:] {
+		go w.listen()
+	}
+	workers[0].listen()
+}
 
-	slog.Info("Sending activity to inbox", "inbox", inbox, "activity", string(activity))
-	resp, err := client.Do(rq)
+func (w worker) listen() {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		w.runDueJobs()
+		select {
+		case <-w.wakech:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w worker) runDueJobs() {
+	dueJobs, err := jobsRepo.LoadDueJobs(context.Background())
 	if err != nil {
-		slog.Error("Failed to send activity to inbox", "err", err, "inbox", inbox)
-		return err
+		slog.Error("Failed to load jobs", "err", err)
+		return
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		slog.Warn("Sent activity returned non-OK status", "inbox", inbox, "status", resp.StatusCode)
+	for _, job := range dueJobs {
+		if job.Category.Urgent() == w.urgent {
+			runJob(job)
+		}
 	}
+}
+
+// runJob runs the job and drops it if it succeeded. Otherwise, it is
+// postponed or, if it is hopeless, parked as failed.
+func runJob(job jobtype.Job) {
+	ctx := context.Background()
+	slog.Info("Running job", "id", job.ID, "category", job.Category, "attempts", job.Attempts)
+
+	var err error
+	if jobber, ok := catmap[job.Category]; !ok {
+		err = permanent(fmt.Errorf("unhandled job category %q", job.Category))
+	} else {
+		err = jobber(job)
+	}
+
+	var early tooEarlyError
+	switch {
+	case err == nil:
+		if err := jobsRepo.DropJob(ctx, job.ID); err != nil {
+			slog.Error("Failed to drop job", "id", job.ID, "err", err)
+		}
+	case errors.As(err, &early):
+		slog.Info("Job is too early, delaying", "id", job.ID, "category", job.Category, "in", early.wait)
+		if err := jobsRepo.DelayJob(ctx, job.ID, early.wait); err != nil {
+			slog.Error("Failed to delay job", "id", job.ID, "err", err)
+		}
+	case isPermanent(err) || job.Attempts+1 >= MaxAttempts:
+		slog.Error("Job failed for good", "id", job.ID, "category", job.Category, "err", err)
+		if err := jobsRepo.FailJob(ctx, job.ID, err.Error()); err != nil {
+			slog.Error("Failed to mark job as failed", "id", job.ID, "err", err)
+		}
+	default:
+		delay := backoff(job.Attempts + 1)
+		slog.Warn("Job failed, will retry", "id", job.ID, "category", job.Category, "in", delay, "err", err)
+		if err := jobsRepo.PostponeJob(ctx, job.ID, delay, err.Error()); err != nil {
+			slog.Error("Failed to postpone job", "id", job.ID, "err", err)
+		}
+	}
+}
+
+// backoff returns the delay before the next attempt: a minute after the
+// first failure, doubling with each next one.
+func backoff(attempts uint) time.Duration {
+	return time.Minute << min(attempts-1, 16)
+}
+
+// permanentError is an error that retrying will not fix.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+
+func (e permanentError) Unwrap() error { return e.err }
+
+// permanent marks the error as such that the job should not be retried.
+func permanent(err error) error {
+	return permanentError{err}
+}
+
+// tooEarlyError is returned by jobs that are not to run yet. It is not a
+// failure, the job is tried again after wait.
+type tooEarlyError struct {
+	wait time.Duration
+}
+
+func (e tooEarlyError) Error() string { return fmt.Sprintf("too early, wait %s", e.wait) }
+
+func tooEarly(wait time.Duration) error {
+	return tooEarlyError{wait}
+}
+
+func isPermanent(err error) bool {
+	var pe permanentError
+	if errors.As(err, &pe) {
+		return true
+	}
+	var se statusError
+	if errors.As(err, &se) {
+		// The inbox does not want it and will not want it later. Unauthorized
+		// might be about our key not fetched yet, so it is worth retrying.
+		return se.status >= 400 && se.status < 500 &&
+			se.status != http.StatusUnauthorized &&
+			se.status != http.StatusRequestTimeout &&
+			se.status != http.StatusTooManyRequests
+	}
+	return false
+}
+
+// Jobs returns all pending and failed jobs.
+func Jobs(ctx context.Context) ([]jobtype.Job, error) {
+	return jobsRepo.Jobs(ctx)
+}
+
+// RetryJob makes the job run again as soon as possible, with all its
+// attempts restored.
+func RetryJob(ctx context.Context, id int64) error {
+	if err := jobsRepo.RetryJob(ctx, id); err != nil {
+		return err
+	}
+	wake()
 	return nil
 }
 
+// DiscardJob removes the job without running it.
+func DiscardJob(ctx context.Context, id int64) error {
+	return jobsRepo.DropJob(ctx, id)
+}
+
+// statusError is returned when an inbox answers with a non-2xx status.
+type statusError struct {
+	inbox  string
+	status int
+}
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("inbox %s returned status %d", e.inbox, e.status)
+}
+
+// TODO: Move to a proper place
+func SendActivityToInbox(activity []byte, inbox string) error {
+	slog.Info("Sending activity to inbox", "inbox", inbox, "activity", string(activity))
+	return postActivity(activity, inbox)
+}
+
+// SendQuietActivityToInbox is SendActivityToInbox that does not log the activity.
 func SendQuietActivityToInbox(activity []byte, inbox string) error {
+	slog.Info("Sending activity to inbox", "inbox", inbox)
+	return postActivity(activity, inbox)
+}
+
+// postActivity posts the signed activity to the inbox.
+func postActivity(activity []byte, inbox string) error {
 	rq, err := http.NewRequest(http.MethodPost, inbox, bytes.NewReader(activity))
 	if err != nil {
-		slog.Error("Failed to create request for SendQuietActivityToInbox", "err", err)
+		slog.Error("Failed to create request for activity", "inbox", inbox, "err", err)
 		return err
 	}
 
+	rq.Header.Set("User-Agent", settings.UserAgent())
 	rq.Header.Set("Content-Type", types.ActivityType)
 	signing.SignRequest(rq, activity)
 
-	slog.Info("Sending activity to inbox", "inbox", inbox)
 	resp, err := client.Do(rq)
 	if err != nil {
 		slog.Error("Failed to send activity to inbox", "err", err, "inbox", inbox)
 		return err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		slog.Warn("Sent activity returned non-OK status", "inbox", inbox, "status", resp.StatusCode)
+		return statusError{inbox: inbox, status: resp.StatusCode}
 	}
 	return nil
 }
@@ -0,0 +1,169 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package jobs
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+
+	"git.sr.ht/~bouncepaw/betula/db"
+	"git.sr.ht/~bouncepaw/betula/jobs/jobtype"
+	archivingports "git.sr.ht/~bouncepaw/betula/ports/archiving"
+	imexports "git.sr.ht/~bouncepaw/betula/ports/imex"
+	"git.sr.ht/~bouncepaw/betula/settings"
+	"git.sr.ht/~bouncepaw/betula/svc/archiving"
+	"git.sr.ht/~bouncepaw/betula/svc/imex"
+	"git.sr.ht/~bouncepaw/betula/svc/searching"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+func TestDeliveryInboxes(t *testing.T) {
+	actor := func(inbox, sharedInbox string) types.Actor {
+		var a types.Actor
+		a.Inbox = inbox
+		a.Endpoints.SharedInbox = sharedInbox
+		return a
+	}
+	inboxes := deliveryInboxes([]types.Actor{
+		actor("https://masto.example/users/a/inbox", "https://masto.example/inbox"),
+		actor("https://masto.example/users/b/inbox", "https://masto.example/inbox"),
+		actor("https://links.example/inbox", ""),
+		actor("https://other.example/users/c/inbox", "not a url"),
+	})
+	be.Equal(t, inboxes, []string{
+		"https://masto.example/inbox",
+		"https://links.example/inbox",
+		"https://other.example/users/c/inbox",
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	be.Equal(t, backoff(1), time.Minute)
+	be.Equal(t, backoff(2), 2*time.Minute)
+	be.Equal(t, backoff(4), 8*time.Minute)
+}
+
+func TestIsPermanent(t *testing.T) {
+	be.True(t, isPermanent(permanent(http.ErrNoLocation)))
+	be.True(t, isPermanent(statusError{status: http.StatusGone}))
+	be.Equal(t, isPermanent(statusError{status: http.StatusTooManyRequests}), false)
+	be.Equal(t, isPermanent(statusError{status: http.StatusBadGateway}), false)
+	be.Equal(t, isPermanent(http.ErrHandlerTimeout), false)
+}
+
+func TestAutoArchives(t *testing.T) {
+	bookmark := types.Bookmark{
+		Visibility: types.Private,
+		Tags:       []types.Tag{{Name: "read_later"}, {Name: "go"}},
+	}
+	be.True(t, autoArchives(bookmark, "", false))
+	be.True(t, !autoArchives(bookmark, "", true))
+	be.True(t, autoArchives(bookmark, "news, Read later", false))
+	be.True(t, !autoArchives(bookmark, "news", false))
+
+	bookmark.Visibility = types.Unlisted
+	be.True(t, autoArchives(bookmark, "go", true))
+}
+
+func TestHostSlots(t *testing.T) {
+	slots := newHostSlots()
+	now := time.Now()
+	be.Equal(t, slots.take("example.org", now), now)
+	be.Equal(t, slots.take("example.org", now), now.Add(archiveHostInterval))
+	// Other hosts wait for the pages booked before them.
+	be.Equal(t, slots.take("example.com", now), now.Add(archiveHostInterval+archiveInterval))
+	be.Equal(t, slots.take("example.net", now), now.Add(archiveHostInterval+2*archiveInterval))
+	// Once the host is rested, it may be visited right away.
+	later := now.Add(time.Hour)
+	be.Equal(t, slots.take("example.org", later), later)
+}
+
+// fakeArchiving archives nothing, but remembers what it was asked to.
+type fakeArchiving struct {
+	archivingports.Service
+	archived []string
+}
+
+func (svc *fakeArchiving) Archive(bookmark types.Bookmark, _ types.ArchiveKind) (int64, error) {
+	svc.archived = append(svc.archived, bookmark.URL)
+	return int64(len(svc.archived)), nil
+}
+
+func TestArchiveDueJobsSpaced(t *testing.T) {
+	db.InitInMemoryDB()
+	ctx := t.Context()
+	fake := &fakeArchiving{}
+	svcArchiving, archiveSlots = fake, newHostSlots()
+
+	// Both jobs are due at once, like after a restart.
+	for _, addr := range []string{"https://example.org/a", "https://example.org/b"} {
+		id, err := repoLocalBookmarks.InsertBookmark(ctx, types.Bookmark{URL: addr, Title: addr, Visibility: types.Public})
+		be.Err(t, err, nil)
+		data, err := json.Marshal(id)
+		be.Err(t, err, nil)
+		_, err = jobsRepo.PlanJob(ctx, jobtype.Job{Category: jobtype.ArchiveBookmark, Payload: data})
+		be.Err(t, err, nil)
+	}
+	worker{urgent: false}.runDueJobs()
+	be.Equal(t, fake.archived, []string{"https://example.org/a"})
+
+	// The other one waits for its turn without losing an attempt.
+	pending, err := jobsRepo.Jobs(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(pending), 1)
+	be.Equal(t, pending[0].Attempts, 0)
+	be.True(t, pending[0].Due.After(time.Now().Add(archiveHostInterval/2)))
+}
+
+func TestImportedArchivesGetIndexed(t *testing.T) {
+	db.InitInMemoryDB()
+	ctx := t.Context()
+	svcArchiving = archivingsvc.New(archivingsvc.Fetchers{}, repoArchives, settings.ArchiveQuota)
+	svcImEx := imexsvc.New(repoLocalBookmarks, db.NewNativeRepo(), nil, settings.SiteName, settings.All, settings.SetSettings)
+	search := searchingsvc.New(db.NewSearchRepo())
+
+	id, err := repoLocalBookmarks.InsertBookmark(ctx, types.Bookmark{
+		URL: "https://fungi.example", Title: "Fungi", Visibility: types.Public,
+	})
+	be.Err(t, err, nil)
+	artifact, err := types.NewCompressedDocumentArtifact([]byte("<p>Mycelium networks</p>"), "text/html")
+	be.Err(t, err, nil)
+	_, err = repoArchives.Store(id, artifact)
+	be.Err(t, err, nil)
+	var buf bytes.Buffer
+	be.Err(t, svcImEx.Export(ctx, imexports.ExportParams{Format: imexports.ExportFormatBetula}, &buf), nil)
+
+	// The archive comes without its text, so it is found only after indexing.
+	db.InitInMemoryDB()
+	count, err := svcImEx.Import(ctx, imexports.ImportParams{}, bytes.NewReader(buf.Bytes()))
+	be.Err(t, err, nil)
+	be.Equal(t, count, uint(1))
+	_, total := search.For("in:archive mycelium", true, 1)
+	be.Equal(t, total, uint(0))
+
+	ScheduleArchiveIndexing(ctx)
+	done := make(chan struct{})
+	go func() {
+		worker{urgent: false}.runDueJobs()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("IndexArchives job did not return")
+	}
+
+	found, total := search.For("in:archive mycelium", true, 1)
+	be.Equal(t, total, uint(1))
+	be.Equal(t, found[0].URL, "https://fungi.example")
+	pending, err := jobsRepo.Jobs(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(pending), 0)
+}
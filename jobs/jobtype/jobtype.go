@@ -6,6 +6,11 @@
 // Package jobtype holds types for jobs and their categories.
 package jobtype
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // If you make something drastic to this file, reflect the changes in Adding a new job.md
 
 type JobCategory string
@@ -18,8 +23,45 @@ const (
 	SendCreateNote      JobCategory = "Send Create{Note}"
 	SendUpdateNote      JobCategory = "Send Update{Note}"
 	SendDeleteNote      JobCategory = "Send Delete{Note}"
+	BackfillTimeline    JobCategory = "Backfill timeline"
+	DeliverToInbox      JobCategory = "Deliver to inbox"
+	ArchiveBookmark     JobCategory = "Archive bookmark"
+	CheckLinks          JobCategory = "Check links"
+	IndexArchives       JobCategory = "Index archives"
+	SaveBookmarkImages  JobCategory = "Save bookmark images"
+	PollFeeds           JobCategory = "Poll feeds"
+	DeliverWebhook      JobCategory = "Deliver webhook"
 )
 
+// Urgent reports whether jobs of the category talk to other servers on the
+// admin's behalf right now: federation messages and webhook deliveries. They
+// are run apart from the rest, so a slow archiving or a feed poll does not
+// hold them up.
+func (c JobCategory) Urgent() bool {
+	switch c {
+	case SendAcceptFollow, SendRejectFollow, ReceiveAcceptFollow, ReceiveRejectFollow,
+		SendCreateNote, SendUpdateNote, SendDeleteNote, DeliverToInbox, DeliverWebhook:
+		return true
+	}
+	return false
+}
+
+// Delivery is the payload of DeliverToInbox jobs.
+type Delivery struct {
+	Inbox    string
+	Activity json.RawMessage
+}
+
+// ImagesRequest is the payload of SaveBookmarkImages jobs.
+type ImagesRequest struct {
+	BookmarkID int
+	// URL is the bookmarked page. It is asked for the icon address
+	// if IconURL is empty.
+	URL        string
+	IconURL    string
+	PreviewURL string
+}
+
 // Job is a task for Betula to do later.
 type Job struct {
 	// ID is a unique identifier for the Job. You get it when reading from the database. Do not set it when issuing a new job.
@@ -27,4 +69,17 @@ type Job struct {
 	Category JobCategory
 	// Payload is some data.
 	Payload any
+
+	// The fields below are filled when reading from the database.
+
+	// Due is when the job is to be run next. Set it when issuing a new job
+	// to postpone its first run.
+	Due time.Time
+	// Attempts is how many times the job has failed so far.
+	Attempts uint
+	// LastError is the error of the last failed attempt.
+	LastError string
+	// Failed jobs have run out of attempts and are not run anymore,
+	// unless retried manually.
+	Failed bool
 }
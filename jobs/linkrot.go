@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"git.sr.ht/~bouncepaw/betula/db"
+	wwwgw "git.sr.ht/~bouncepaw/betula/gateways/www"
+	"git.sr.ht/~bouncepaw/betula/jobs/jobtype"
+	linkrotports "git.sr.ht/~bouncepaw/betula/ports/linkrot"
+	"git.sr.ht/~bouncepaw/betula/settings"
+	"git.sr.ht/~bouncepaw/betula/svc/linkrot"
+)
+
+const (
+	// linkCheckBatch is how many links one CheckLinks job checks.
+	linkCheckBatch = 20
+	// linkCheckPause is the pause between batches while there are links
+	// to check.
+	linkCheckPause = 10 * time.Minute
+	// linkCheckIdle is the pause when all links were checked recently.
+	linkCheckIdle = 6 * time.Hour
+)
+
+var svcLinkRot linkrotports.Service = linkrotsvc.New(db.NewLinkRotRepo(), wwwgw.New(settings.UserAgent))
+
+// ScheduleLinkChecks makes sure link checking goes on. Call it on start.
+// The CheckLinks job plans its next run by itself.
+func ScheduleLinkChecks(ctx context.Context) {
+	planOnce(ctx, jobtype.CheckLinks)
+}
+
+// nextLinkCheck returns when to check links again after a batch where
+// checked links were checked.
+func nextLinkCheck(checked int, now time.Time) time.Time {
+	if checked < linkCheckBatch {
+		return now.Add(linkCheckIdle)
+	}
+	return now.Add(linkCheckPause)
+}
@@ -0,0 +1,22 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package jobs
+
+import (
+	"context"
+
+	"git.sr.ht/~bouncepaw/betula/db"
+	"git.sr.ht/~bouncepaw/betula/jobs/jobtype"
+	subscriptionsports "git.sr.ht/~bouncepaw/betula/ports/subscriptions"
+	"git.sr.ht/~bouncepaw/betula/svc/subscriptions"
+)
+
+var svcSubscriptions subscriptionsports.Service = subscriptionssvc.New(db.NewSubscriptionsRepo(), www)
+
+// ScheduleFeedPolling makes sure the subscribed feeds are polled. Call it
+// on start. The PollFeeds job plans its next run by itself.
+func ScheduleFeedPolling(ctx context.Context) {
+	planOnce(ctx, jobtype.PollFeeds)
+}
@@ -0,0 +1,25 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package jobs
+
+import (
+	"git.sr.ht/~bouncepaw/betula/jobs/jobtype"
+	webhooksports "git.sr.ht/~bouncepaw/betula/ports/webhooks"
+)
+
+// svcWebhooks is the same service the web handlers use. See UseWebhooks.
+var svcWebhooks webhooksports.Service
+
+// UseWebhooks makes the jobs fire and deliver webhooks with the service.
+// Call it before ListenAndWhisper.
+func UseWebhooks(svc webhooksports.Service) {
+	svcWebhooks = svc
+}
+
+// ScheduleWebhookDelivery schedules an attempt of the webhook delivery.
+// Failed attempts are retried by the job queue.
+func ScheduleWebhookDelivery(deliveryID int64) {
+	ScheduleJSON(jobtype.DeliverWebhook, deliveryID)
+}
@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+// Package atom has types for making an Atom 1.0 feed, see RFC 4287.
+package atom
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+type Feed struct {
+	XMLName  xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string   `xml:"id"`
+	Title    string   `xml:"title"`
+	Subtitle string   `xml:"subtitle,omitempty"`
+	Updated  string   `xml:"updated"`
+	Author   *Person  `xml:"author,omitempty"`
+	Links    []Link   `xml:"link"`
+	Entries  []*Entry `xml:"entry"`
+}
+
+type Person struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type Link struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type Entry struct {
+	ID         string     `xml:"id"`
+	Title      string     `xml:"title"`
+	Updated    string     `xml:"updated"`
+	Published  string     `xml:"published,omitempty"`
+	Author     *Person    `xml:"author,omitempty"`
+	Links      []Link     `xml:"link"`
+	Categories []Category `xml:"category"`
+	Content    *Content   `xml:"content,omitempty"`
+}
+
+type Category struct {
+	Term string `xml:"term,attr"`
+}
+
+type Content struct {
+	// Type is text, html or xhtml.
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// Write the Feed as XML.
+func (fd *Feed) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(fd); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
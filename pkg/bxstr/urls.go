@@ -5,7 +5,10 @@
 
 package bxstr
 
-import "net/url"
+import (
+	"net/url"
+	"strings"
+)
 
 func IsValidURL(s string) bool {
 	_, err := url.ParseRequestURI(s)
@@ -35,3 +38,92 @@ func ValidURLWithQuery(s string, m map[string]string) string {
 	u.RawQuery = q.Encode()
 	return u.String()
 }
+
+// DefaultTrackingParams are the query parameters that tell where a visitor
+// came from and nothing about the page. A name ending with * stands for
+// all names that start like it.
+var DefaultTrackingParams = []string{
+	"utm_*", "fbclid", "gclid", "gclsrc", "dclid", "gbraid", "wbraid",
+	"msclkid", "yclid", "twclid", "igshid", "mc_cid", "mc_eid",
+	"_hsenc", "_hsmi", "mkt_tok", "oly_anon_id", "oly_enc_id", "vero_id",
+	"_ga", "_gl", "ref_src", "ref_url",
+}
+
+// CanonicalURL returns the form of the address used to tell if two
+// addresses lead to the same page. It is not meant to be shown or visited.
+//
+//   - http and https are the same,
+//   - host names are lowercased, www. and default ports are dropped,
+//   - trailing slashes are dropped,
+//   - the tracking parameters are dropped and the rest are sorted,
+//   - fragments are dropped, unless they look like routes of a web
+//     application, like #!/page or #/page.
+//
+// Addresses that are not web addresses are returned as they are.
+func CanonicalURL(addr string, trackingParams []string) string {
+	addr = strings.TrimSpace(addr)
+	u, err := url.Parse(addr)
+	if err != nil || u.Host == "" {
+		return addr
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+	default:
+		return addr
+	}
+
+	var host = strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	host = strings.TrimPrefix(host, "www.")
+	if strings.Contains(host, ":") {
+		// IPv6 addresses lose their brackets in Hostname.
+		host = "[" + host + "]"
+	}
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+
+	var canonical strings.Builder
+	canonical.WriteString("https://")
+	if u.User != nil {
+		canonical.WriteString(u.User.String() + "@")
+	}
+	canonical.WriteString(host)
+	canonical.WriteString(strings.TrimRight(u.EscapedPath(), "/"))
+
+	if u.RawQuery != "" {
+		query, err := url.ParseQuery(u.RawQuery)
+		if err != nil {
+			canonical.WriteString("?" + u.RawQuery)
+		} else {
+			for name := range query {
+				if isTrackingParam(name, trackingParams) {
+					delete(query, name)
+				}
+			}
+			if len(query) > 0 {
+				// Encode sorts by name.
+				canonical.WriteString("?" + query.Encode())
+			}
+		}
+	}
+
+	if strings.HasPrefix(u.Fragment, "!") || strings.HasPrefix(u.Fragment, "/") {
+		canonical.WriteString("#" + u.EscapedFragment())
+	}
+	return canonical.String()
+}
+
+func isTrackingParam(name string, trackingParams []string) bool {
+	name = strings.ToLower(name)
+	for _, param := range trackingParams {
+		param = strings.ToLower(param)
+		if prefix, ok := strings.CutSuffix(param, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == param {
+			return true
+		}
+	}
+	return false
+}
@@ -34,3 +34,41 @@ func TestValidURLWithQuery(t *testing.T) {
 		be.Equal(t, got, want)
 	})
 }
+
+func TestCanonicalURL(t *testing.T) {
+	t.Parallel()
+
+	same := []string{
+		"https://example.org/a",
+		"https://example.org/a?utm_source=x",
+		"http://www.example.org/a/",
+		"HTTPS://Example.ORG:443/a#section",
+		"https://example.org./a?fbclid=123&utm_medium=email",
+	}
+	for _, addr := range same {
+		be.Equal(t, CanonicalURL(addr, DefaultTrackingParams), "https://example.org/a")
+	}
+
+	cases := []struct {
+		addr, want string
+	}{
+		{"https://example.org/", "https://example.org"},
+		{"https://example.org:8080/a", "https://example.org:8080/a"},
+		{"https://example.org/a?b=2&a=1&utm_campaign=z", "https://example.org/a?a=1&b=2"},
+		{"https://example.org/app#!/inbox", "https://example.org/app#!/inbox"},
+		{"https://example.org/app#/inbox/", "https://example.org/app#/inbox/"},
+		{"https://www.example.org/a%20b", "https://example.org/a%20b"},
+		{"http://[::1]:80/a", "https://[::1]/a"},
+		{"http://[::1]:8080/a", "https://[::1]:8080/a"},
+		{"gemini://example.org/a/", "gemini://example.org/a/"},
+		{"not a url", "not a url"},
+		{" https://example.org/a ", "https://example.org/a"},
+	}
+	for _, tc := range cases {
+		be.Equal(t, CanonicalURL(tc.addr, DefaultTrackingParams), tc.want)
+	}
+
+	// The blocklist is up to the caller.
+	be.Equal(t, CanonicalURL("https://example.org/?ref=feed", []string{"ref"}), "https://example.org")
+	be.Equal(t, CanonicalURL("https://example.org/?utm_source=x", nil), "https://example.org?utm_source=x")
+}
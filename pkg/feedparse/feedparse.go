@@ -0,0 +1,242 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+// Package feedparse reads RSS 2.0, RSS 1.0 and Atom feeds into one shape.
+// It is forgiving, because feeds in the wild often are not valid.
+package feedparse
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// ErrNotFeed is returned when the document is not a feed, like a web page.
+var ErrNotFeed = errors.New("feedparse: not an RSS or Atom feed")
+
+type Feed struct {
+	Title string
+	// Link is the site the feed is about.
+	Link    string
+	Entries []Entry
+}
+
+type Entry struct {
+	// ID identifies the entry in its feed. It is the guid or the id of
+	// the entry, or its link if it has neither.
+	ID         string
+	Title      string
+	Link       string
+	Categories []string
+	// Published is zero if the feed does not say when.
+	Published time.Time
+}
+
+// document covers the three formats. The root is rss, rdf:RDF or feed.
+type document struct {
+	XMLName xml.Name
+	// Channel is there in RSS 2.0 and RSS 1.0.
+	Channel channel `xml:"channel"`
+	// Items are next to the channel in RSS 1.0.
+	Items []item `xml:"item"`
+	// The rest is Atom.
+	Title   string      `xml:"title"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type channel struct {
+	Title string `xml:"title"`
+	// Links is a slice, because there might be atom:link next to the RSS link.
+	Links []rssLink `xml:"link"`
+	Items []item    `xml:"item"`
+}
+
+type rssLink struct {
+	Value string `xml:",chardata"`
+}
+
+type item struct {
+	About      string    `xml:"about,attr"`
+	Title      string    `xml:"title"`
+	Links      []rssLink `xml:"link"`
+	GUID       string    `xml:"guid"`
+	PubDate    string    `xml:"pubDate"`
+	Date       string    `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Categories []string  `xml:"category"`
+	Subjects   []string  `xml:"http://purl.org/dc/elements/1.1/ subject"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID         string     `xml:"id"`
+	Title      string     `xml:"title"`
+	Links      []atomLink `xml:"link"`
+	Published  string     `xml:"published"`
+	Updated    string     `xml:"updated"`
+	Categories []struct {
+		Term string `xml:"term,attr"`
+	} `xml:"category"`
+}
+
+// Parse reads the feed from r.
+func Parse(r io.Reader) (Feed, error) {
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	dec.Entity = xml.HTMLEntity
+	dec.CharsetReader = charset.NewReaderLabel
+
+	var doc document
+	if err := dec.Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return Feed{}, ErrNotFeed
+		}
+		var syntaxErr *xml.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return Feed{}, ErrNotFeed
+		}
+		return Feed{}, err
+	}
+
+	switch strings.ToLower(doc.XMLName.Local) {
+	case "rss", "rdf":
+		return doc.rss(), nil
+	case "feed":
+		return doc.atom(), nil
+	default:
+		return Feed{}, ErrNotFeed
+	}
+}
+
+func (doc document) rss() Feed {
+	feed := Feed{
+		Title: strings.TrimSpace(doc.Channel.Title),
+		Link:  firstLink(doc.Channel.Links),
+	}
+	for _, it := range append(doc.Channel.Items, doc.Items...) {
+		entry := Entry{
+			ID:        strings.TrimSpace(it.GUID),
+			Title:     strings.TrimSpace(it.Title),
+			Link:      firstLink(it.Links),
+			Published: parseTime(it.PubDate, it.Date),
+		}
+		if entry.ID == "" {
+			entry.ID = strings.TrimSpace(it.About)
+		}
+		if entry.ID == "" {
+			entry.ID = entry.Link
+		}
+		entry.Categories = categories(append(it.Categories, it.Subjects...))
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return feed
+}
+
+func (doc document) atom() Feed {
+	feed := Feed{
+		Title: strings.TrimSpace(doc.Title),
+		Link:  alternateLink(doc.Links),
+	}
+	for _, e := range doc.Entries {
+		entry := Entry{
+			ID:        strings.TrimSpace(e.ID),
+			Title:     strings.TrimSpace(e.Title),
+			Link:      alternateLink(e.Links),
+			Published: parseTime(e.Published, e.Updated),
+		}
+		if entry.ID == "" {
+			entry.ID = entry.Link
+		}
+		var terms []string
+		for _, category := range e.Categories {
+			terms = append(terms, category.Term)
+		}
+		entry.Categories = categories(terms)
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return feed
+}
+
+// firstLink returns the first non-empty RSS link. An atom:link has no text,
+// so it is skipped.
+func firstLink(links []rssLink) string {
+	for _, link := range links {
+		if value := strings.TrimSpace(link.Value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// alternateLink returns the first alternate Atom link, or the first link
+// at all if there is no alternate one.
+func alternateLink(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return strings.TrimSpace(link.Href)
+		}
+	}
+	if len(links) > 0 {
+		return strings.TrimSpace(links[0].Href)
+	}
+	return ""
+}
+
+// categories returns the non-empty categories without duplicates.
+func categories(raw []string) []string {
+	var (
+		result []string
+		seen   = make(map[string]bool)
+	)
+	for _, category := range raw {
+		category = strings.TrimSpace(category)
+		if category == "" || seen[category] {
+			continue
+		}
+		seen[category] = true
+		result = append(result, category)
+	}
+	return result
+}
+
+// timeLayouts are the layouts seen in feeds. RSS wants RFC 822 dates,
+// but everything happens.
+var timeLayouts = []string{
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	time.RFC822Z,
+	time.RFC822,
+	"2006-01-02T15:04:05",
+	time.DateTime,
+	time.DateOnly,
+}
+
+// parseTime returns the first of the values that parses, or the zero time.
+func parseTime(values ...string) time.Time {
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
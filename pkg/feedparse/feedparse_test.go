@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package feedparse
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+)
+
+const rss2 = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
+<channel>
+	<title>Bouncepaw</title>
+	<atom:link href="https://bouncepaw.com/feed.xml" rel="self" type="application/rss+xml"/>
+	<link>https://bouncepaw.com</link>
+	<item>
+		<title>Betula 1.5</title>
+		<link>https://bouncepaw.com/betula-1.5</link>
+		<guid isPermaLink="false">post-15</guid>
+		<pubDate>Sat, 18 Mar 2023 10:00:00 +0000</pubDate>
+		<category>betula</category>
+		<category>release</category>
+		<category>betula</category>
+	</item>
+	<item>
+		<title>No guid&nbsp;here</title>
+		<link>https://bouncepaw.com/no-guid</link>
+	</item>
+</channel>
+</rss>`
+
+const rss1 = `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+	<channel rdf:about="https://example.org/">
+		<title>Example</title>
+		<link>https://example.org/</link>
+	</channel>
+	<item rdf:about="https://example.org/1">
+		<title>First</title>
+		<link>https://example.org/1</link>
+		<dc:date>2023-03-18T10:00:00Z</dc:date>
+		<dc:subject>wiki</dc:subject>
+	</item>
+</rdf:RDF>`
+
+const atom = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<title>Mycorrhiza</title>
+	<link rel="self" href="https://mycorrhiza.wiki/atom"/>
+	<link href="https://mycorrhiza.wiki"/>
+	<entry>
+		<id>tag:mycorrhiza.wiki,2023:help</id>
+		<title type="html">Help</title>
+		<link rel="alternate" href="https://mycorrhiza.wiki/help"/>
+		<updated>2023-03-18T10:00:00Z</updated>
+		<category term="docs"/>
+	</entry>
+</feed>`
+
+func TestParse(t *testing.T) {
+	published := time.Date(2023, 3, 18, 10, 0, 0, 0, time.UTC)
+
+	feed, err := Parse(strings.NewReader(rss2))
+	be.Err(t, err, nil)
+	be.Equal(t, feed.Title, "Bouncepaw")
+	be.Equal(t, feed.Link, "https://bouncepaw.com")
+	be.Equal(t, len(feed.Entries), 2)
+	be.Equal(t, feed.Entries[0].ID, "post-15")
+	be.Equal(t, feed.Entries[0].Link, "https://bouncepaw.com/betula-1.5")
+	be.Equal(t, feed.Entries[0].Categories, []string{"betula", "release"})
+	be.True(t, feed.Entries[0].Published.Equal(published))
+	be.Equal(t, feed.Entries[1].ID, "https://bouncepaw.com/no-guid")
+	be.Equal(t, feed.Entries[1].Title, "No guid here")
+	be.True(t, feed.Entries[1].Published.IsZero())
+
+	feed, err = Parse(strings.NewReader(rss1))
+	be.Err(t, err, nil)
+	be.Equal(t, feed.Title, "Example")
+	be.Equal(t, len(feed.Entries), 1)
+	be.Equal(t, feed.Entries[0].ID, "https://example.org/1")
+	be.Equal(t, feed.Entries[0].Categories, []string{"wiki"})
+	be.True(t, feed.Entries[0].Published.Equal(published))
+
+	feed, err = Parse(strings.NewReader(atom))
+	be.Err(t, err, nil)
+	be.Equal(t, feed.Title, "Mycorrhiza")
+	be.Equal(t, feed.Link, "https://mycorrhiza.wiki")
+	be.Equal(t, len(feed.Entries), 1)
+	be.Equal(t, feed.Entries[0].ID, "tag:mycorrhiza.wiki,2023:help")
+	be.Equal(t, feed.Entries[0].Link, "https://mycorrhiza.wiki/help")
+	be.Equal(t, feed.Entries[0].Categories, []string{"docs"})
+	be.True(t, feed.Entries[0].Published.Equal(published))
+}
+
+func TestParseNotFeed(t *testing.T) {
+	for _, doc := range []string{
+		`<!DOCTYPE html><html><head><title>Page</title></head><body><p>Hi<br></p></body></html>`,
+		`{"version": "https://jsonfeed.org/version/1.1"}`,
+		``,
+	} {
+		_, err := Parse(strings.NewReader(doc))
+		be.Err(t, err, ErrNotFeed)
+	}
+}
@@ -20,12 +20,16 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"golang.org/x/crypto/ed25519"
 )
 
+// ClockSkew is how far the signature time might be from ours.
+const ClockSkew = 30 * time.Minute
+
 type KeyType int
 
 const (
@@ -218,10 +222,22 @@ func VerifyRequest(req *http.Request, content []byte, lookupPubkey func(string)
 			}
 		case "(created)":
 			s = created
-			// TODO: handle the time one day
+			t, err := unixTime(created)
+			if err != nil {
+				return "", fmt.Errorf("error parsing created: %s", err)
+			}
+			if t.After(time.Now().Add(ClockSkew)) {
+				return "", fmt.Errorf("created '%s' is in the future", created)
+			}
 		case "(expires)":
 			s = expires
-			// TODO: handle the time one day
+			t, err := unixTime(expires)
+			if err != nil {
+				return "", fmt.Errorf("error parsing expires: %s", err)
+			}
+			if t.Before(time.Now().Add(-ClockSkew)) {
+				return "", fmt.Errorf("signature expired at '%s'", expires)
+			}
 		case "date":
 			s = req.Header.Get(h)
 			d, err := time.Parse(http.TimeFormat, s)
@@ -229,7 +245,7 @@ func VerifyRequest(req *http.Request, content []byte, lookupPubkey func(string)
 				return "", fmt.Errorf("error parsing date header: %s", err)
 			}
 			now := time.Now()
-			if d.Before(now.Add(-30*time.Minute)) || d.After(now.Add(30*time.Minute)) {
+			if d.Before(now.Add(-ClockSkew)) || d.After(now.Add(ClockSkew)) {
 				return "", fmt.Errorf("date header '%s' out of range", s)
 			}
 		default:
@@ -256,6 +272,14 @@ func VerifyRequest(req *http.Request, content []byte, lookupPubkey func(string)
 	return keyname, nil
 }
 
+func unixTime(s string) (time.Time, error) {
+	secs, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(secs, 0), nil
+}
+
 // Unmarshall an ASCII string into (optional) private and public keys.
 func DecodeKey(s string) (pri PrivateKey, pub PublicKey, err error) {
 	block, _ := pem.Decode([]byte(s))
@@ -0,0 +1,201 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+// Package betula implements the container of Betula archives.
+//
+// A Betula archive is a zip file. It starts with manifest.json, then come
+// JSON Lines files, one record per line, and binary blobs. What records are
+// there is up to the caller, this package only knows the layout.
+package betula
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"time"
+)
+
+const (
+	// Format is the Format of every manifest.
+	Format = "betula"
+	// Version is the newest version of the archive this package knows.
+	// Readers refuse newer archives.
+	Version = 1
+
+	manifestName = "manifest.json"
+)
+
+var (
+	ErrNotArchive = errors.New("betula: not a Betula archive")
+	ErrTooNew     = errors.New("betula: the archive is made by a newer Betula")
+)
+
+// Manifest describes the archive.
+type Manifest struct {
+	Format  string    `json:"format"`
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	// Site is the address of the Betula the archive is made from.
+	Site string `json:"site"`
+}
+
+// Probe reports whether r contains a Betula archive by checking that it is
+// a zip file starting with the manifest. It seeks r back to the start before
+// returning, so the caller can pass the same reader to Open.
+func Probe(r io.ReadSeeker) (bool, error) {
+	// The name of the first file is at the offset 30 of a zip file.
+	const nameOffset = 30
+	buf := make([]byte, nameOffset+len(manifestName))
+	n, err := io.ReadFull(r, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && err != io.EOF {
+		return false, err
+	}
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	return n == len(buf) &&
+		bytes.HasPrefix(buf, []byte("PK\x03\x04")) &&
+		string(buf[nameOffset:]) == manifestName, nil
+}
+
+// Writer writes a Betula archive. Files are written one after another,
+// so the write functions must not be called while another one runs.
+type Writer struct {
+	zw *zip.Writer
+}
+
+// NewWriter starts an archive with the manifest. Format and Version are filled in.
+func NewWriter(w io.Writer, manifest Manifest) (*Writer, error) {
+	manifest.Format, manifest.Version = Format, Version
+	zw := zip.NewWriter(w)
+	f, err := zw.Create(manifestName)
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "\t")
+	if err = enc.Encode(manifest); err != nil {
+		return nil, err
+	}
+	return &Writer{zw: zw}, nil
+}
+
+// WriteLines writes a JSON Lines file. each is called once, it calls
+// write for every record.
+func (w *Writer) WriteLines(name string, each func(write func(record any) error) error) error {
+	f, err := w.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	if err = each(enc.Encode); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// WriteBlob writes a binary file. Blobs are stored as they are, they are
+// usually compressed already.
+func (w *Writer) WriteBlob(name string, data []byte) error {
+	f, err := w.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+// Close finishes the archive. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	return w.zw.Close()
+}
+
+// Reader reads a Betula archive.
+type Reader struct {
+	zr       *zip.Reader
+	manifest Manifest
+}
+
+// Open opens the archive and reads the manifest. Returns ErrNotArchive if
+// r is not a Betula archive and ErrTooNew if it is of a newer version.
+func Open(r io.ReadSeeker) (*Reader, error) {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	readerAt, ok := r.(io.ReaderAt)
+	if !ok {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		readerAt = bytes.NewReader(data)
+	}
+
+	zr, err := zip.NewReader(readerAt, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotArchive, err)
+	}
+	reader := &Reader{zr: zr}
+	f, err := zr.Open(manifestName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotArchive, err)
+	}
+	defer f.Close()
+	if err = json.NewDecoder(f).Decode(&reader.manifest); err != nil || reader.manifest.Format != Format {
+		return nil, ErrNotArchive
+	}
+	if reader.manifest.Version > Version {
+		return nil, ErrTooNew
+	}
+	return reader, nil
+}
+
+func (r *Reader) Manifest() Manifest {
+	return r.manifest
+}
+
+// ReadLines reads a JSON Lines file. fn is called for every record, it
+// calls decode to unmarshal the record. A missing file has no records.
+func (r *Reader) ReadLines(name string, fn func(decode func(record any) error) error) error {
+	f, err := r.zr.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	for dec.More() {
+		var raw json.RawMessage
+		if err = dec.Decode(&raw); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err = fn(func(record any) error { return json.Unmarshal(raw, record) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadBlob reads a binary file.
+func (r *Reader) ReadBlob(name string) ([]byte, error) {
+	f, err := r.zr.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package betula
+
+import (
+	"bytes"
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+)
+
+type record struct {
+	Name string `json:"name"`
+	N    int    `json:"n"`
+}
+
+func TestRoundtrip(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	created := time.Date(2026, 4, 5, 20, 36, 9, 0, time.UTC)
+	w, err := NewWriter(&buf, Manifest{Created: created, Site: "https://links.example.org"})
+	be.Err(t, err, nil)
+	be.Err(t, w.WriteLines("records.jsonl", func(write func(any) error) error {
+		for i, name := range []string{"a", "<b>"} {
+			if err := write(record{Name: name, N: i}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}), nil)
+	be.Err(t, w.WriteBlob("blobs/1", []byte{0, 1, 2}), nil)
+	be.Err(t, w.Close(), nil)
+
+	archive := bytes.NewReader(buf.Bytes())
+	ok, err := Probe(archive)
+	be.Err(t, err, nil)
+	be.True(t, ok)
+
+	r, err := Open(archive)
+	be.Err(t, err, nil)
+	be.Equal(t, r.Manifest(), Manifest{Format: Format, Version: Version, Created: created, Site: "https://links.example.org"})
+
+	var records []record
+	be.Err(t, r.ReadLines("records.jsonl", func(decode func(any) error) error {
+		var rec record
+		records = append(records, rec)
+		return decode(&records[len(records)-1])
+	}), nil)
+	be.Equal(t, records, []record{{Name: "a", N: 0}, {Name: "<b>", N: 1}})
+
+	be.Err(t, r.ReadLines("missing.jsonl", func(func(any) error) error {
+		t.Fatal("no records expected")
+		return nil
+	}), nil)
+
+	blob, err := r.ReadBlob("blobs/1")
+	be.Err(t, err, nil)
+	be.Equal(t, blob, []byte{0, 1, 2})
+	_, err = r.ReadBlob("blobs/2")
+	be.Err(t, err, fs.ErrNotExist)
+}
+
+func TestProbe(t *testing.T) {
+	t.Parallel()
+
+	ok, err := Probe(strings.NewReader("PK"))
+	be.Err(t, err, nil)
+	be.True(t, !ok)
+
+	_, err = Open(strings.NewReader("not a zip"))
+	be.Err(t, err, ErrNotArchive)
+}
@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+// Package chrome implements the Bookmarks JSON file of Chrome and other Chromium-based browsers.
+package chrome
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Types of nodes.
+const (
+	TypeURL    = "url"
+	TypeFolder = "folder"
+)
+
+// Node is a bookmark or a folder. Its Type tells which.
+type Node struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	URL  string `json:"url"`
+	// DateAdded is in microseconds since 1601-01-01 UTC, written as a string.
+	DateAdded string `json:"date_added"`
+	Children  []Node `json:"children"`
+}
+
+// Added returns the time the node was added.
+func (n Node) Added() time.Time {
+	return parseWebKitTime(n.DateAdded)
+}
+
+// File is the whole Bookmarks file.
+type File struct {
+	// Roots are the built-in folders, like bookmark_bar and other.
+	Roots map[string]Node `json:"roots"`
+}
+
+// Chromium counts time from the start of 1601, like Windows does.
+var epoch = time.Date(1601, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// parseWebKitTime parses the Chromium timestamp. Returns zero time on parse failure.
+func parseWebKitTime(s string) time.Time {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n == 0 {
+		return time.Time{}
+	}
+	return time.UnixMicro(epoch.UnixMicro() + n)
+}
+
+// Probe reports whether r contains a Chromium Bookmarks file by checking that
+// it is a JSON object with roots. It seeks r back to the start before
+// returning, so the caller can pass the same reader to Read.
+func Probe(r io.ReadSeeker) (bool, error) {
+	buf := make([]byte, 512)
+	n, err := r.Read(buf)
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	s := strings.TrimSpace(string(buf[:n]))
+	return strings.HasPrefix(s, "{") && strings.Contains(s, `"roots"`), nil
+}
+
+// Read parses a Chromium Bookmarks file from r.
+func Read(r io.Reader) (File, error) {
+	var file File
+	return file, json.NewDecoder(r).Decode(&file)
+}
@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package chrome
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+)
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("testdata/Bookmarks")
+	be.Equal(t, err, nil)
+	defer f.Close()
+
+	file, err := Read(f)
+	be.Equal(t, err, nil)
+	be.Equal(t, len(file.Roots), 3)
+
+	bar := file.Roots["bookmark_bar"]
+	be.Equal(t, bar.Name, "Bookmarks bar")
+	be.Equal(t, len(bar.Children), 2)
+
+	bouncepaw := bar.Children[0]
+	be.Equal(t, bouncepaw.Type, TypeURL)
+	be.Equal(t, bouncepaw.URL, "https://bouncepaw.com/")
+	be.Equal(t, bouncepaw.Name, "Bouncepaw")
+	be.Equal(t, bouncepaw.Added(), time.Unix(1775421246, 0))
+
+	engines := bar.Children[1].Children[0]
+	be.Equal(t, engines.Type, TypeFolder)
+	be.Equal(t, engines.Name, "Engines")
+	be.Equal(t, engines.Children[0].URL, "https://mycorrhiza.wiki/")
+
+	be.Equal(t, file.Roots["other"].Children[0].Added(), time.Unix(1775421369, 0))
+	be.Equal(t, Node{DateAdded: "0"}.Added(), time.Time{})
+}
+
+func TestProbe(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("testdata/Bookmarks")
+	be.Equal(t, err, nil)
+	defer f.Close()
+
+	ok, err := Probe(f)
+	be.Equal(t, err, nil)
+	be.True(t, ok)
+
+	ok, err = Probe(strings.NewReader(`[{"href": "https://example.org"}]`))
+	be.Equal(t, err, nil)
+	be.True(t, !ok)
+}
@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+// Package firefox implements the Firefox bookmark backup JSON format.
+package firefox
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+)
+
+// Types of nodes.
+const (
+	TypeBookmark  = "text/x-moz-place"
+	TypeFolder    = "text/x-moz-place-container"
+	TypeSeparator = "text/x-moz-place-separator"
+)
+
+const descriptionAnno = "bookmarkProperties/description"
+
+// Node is a bookmark, a folder or a separator. Its Type tells which.
+type Node struct {
+	GUID  string `json:"guid"`
+	Title string `json:"title"`
+	Type  string `json:"type"`
+	// Root is set for the built-in folders, like toolbarFolder.
+	Root string `json:"root"`
+	// DateAdded and LastModified are in microseconds since the Unix epoch.
+	DateAdded    int64  `json:"dateAdded"`
+	LastModified int64  `json:"lastModified"`
+	URI          string `json:"uri"`
+	// Tags are comma-separated.
+	Tags     string `json:"tags"`
+	Annos    []Anno `json:"annos"`
+	Children []Node `json:"children"`
+}
+
+// Anno is an annotation of a node. Old Firefox versions kept descriptions in them.
+type Anno struct {
+	Name  string `json:"name"`
+	Value any    `json:"value"`
+}
+
+// Added returns the time the node was added.
+func (n Node) Added() time.Time {
+	if n.DateAdded == 0 {
+		return time.Time{}
+	}
+	return time.UnixMicro(n.DateAdded)
+}
+
+// Description returns the description of the node from its annotations.
+func (n Node) Description() string {
+	for _, anno := range n.Annos {
+		if anno.Name == descriptionAnno {
+			if s, ok := anno.Value.(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// Probe reports whether r contains a Firefox bookmark backup by checking that
+// it is a JSON object with Firefox's node types. It seeks r back to the start
+// before returning, so the caller can pass the same reader to Read.
+func Probe(r io.ReadSeeker) (bool, error) {
+	buf := make([]byte, 512)
+	n, err := r.Read(buf)
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	s := strings.TrimSpace(string(buf[:n]))
+	return strings.HasPrefix(s, "{") && strings.Contains(s, `"text/x-moz-place`), nil
+}
+
+// Read parses a Firefox bookmark backup from r and returns the root node.
+func Read(r io.Reader) (Node, error) {
+	var root Node
+	return root, json.NewDecoder(r).Decode(&root)
+}
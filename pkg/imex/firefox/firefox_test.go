@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package firefox
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+)
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("testdata/firefox1.json")
+	be.Equal(t, err, nil)
+	defer f.Close()
+
+	root, err := Read(f)
+	be.Equal(t, err, nil)
+	be.Equal(t, root.Root, "placesRoot")
+	be.Equal(t, len(root.Children), 3)
+
+	menu := root.Children[0]
+	be.Equal(t, menu.Root, "bookmarksMenuFolder")
+	be.Equal(t, len(menu.Children), 3)
+
+	betula := menu.Children[0]
+	be.Equal(t, betula.Type, TypeBookmark)
+	be.Equal(t, betula.URI, "https://joinbetula.org/")
+	be.Equal(t, betula.Title, "Betula")
+	be.Equal(t, betula.Added(), time.Unix(1775421369, 0))
+	be.Equal(t, betula.Description(), "")
+
+	be.Equal(t, menu.Children[1].Type, TypeSeparator)
+
+	engines := menu.Children[2].Children[0]
+	be.Equal(t, engines.Type, TypeFolder)
+	be.Equal(t, engines.Title, "Engines")
+
+	mycorrhiza := engines.Children[0]
+	be.Equal(t, mycorrhiza.URI, "https://mycorrhiza.wiki/")
+	be.Equal(t, mycorrhiza.Tags, "wiki,software")
+	be.Equal(t, mycorrhiza.Description(), "A wiki engine.")
+
+	be.Equal(t, len(root.Children[2].Children), 0)
+}
+
+func TestProbe(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("testdata/firefox1.json")
+	be.Equal(t, err, nil)
+	defer f.Close()
+
+	ok, err := Probe(f)
+	be.Equal(t, err, nil)
+	be.True(t, ok)
+
+	ok, err = Probe(strings.NewReader(`[{"href": "https://example.org"}]`))
+	be.Equal(t, err, nil)
+	be.True(t, !ok)
+}
@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+// Package linkding implements the bookmark JSON of linkding's REST API.
+//
+// linkding's own HTML export is a Netscape Bookmark File, see package netscape.
+package linkding
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+)
+
+// Bookmark represents a single bookmark as the API returns it.
+type Bookmark struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Notes       string `json:"notes"`
+	// WebsiteTitle and WebsiteDescription are taken from the page by linkding.
+	WebsiteTitle       string    `json:"website_title"`
+	WebsiteDescription string    `json:"website_description"`
+	IsArchived         bool      `json:"is_archived"`
+	Unread             bool      `json:"unread"`
+	Shared             bool      `json:"shared"`
+	TagNames           []string  `json:"tag_names"`
+	DateAdded          time.Time `json:"date_added"`
+}
+
+// page is a page of the bookmark list.
+type page struct {
+	Results []Bookmark `json:"results"`
+}
+
+// Probe reports whether r contains linkding bookmarks, either a page of the
+// list or an array of them. It seeks r back to the start before returning,
+// so the caller can pass the same reader to Read.
+func Probe(r io.ReadSeeker) (bool, error) {
+	buf := make([]byte, 512)
+	n, err := r.Read(buf)
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	s := strings.TrimSpace(string(buf[:n]))
+	switch {
+	case strings.HasPrefix(s, "{"):
+		return strings.Contains(s, `"results"`) && strings.Contains(s, `"count"`), nil
+	case strings.HasPrefix(s, "["):
+		return strings.Contains(s, `"tag_names"`), nil
+	default:
+		return false, nil
+	}
+}
+
+// Read parses linkding bookmarks from r. Both a page of the list and an
+// array of bookmarks are accepted.
+func Read(r io.Reader) ([]Bookmark, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		var bookmarks []Bookmark
+		return bookmarks, json.Unmarshal(data, &bookmarks)
+	}
+	var p page
+	return p.Results, json.Unmarshal(data, &p)
+}
@@ -0,0 +1,59 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package linkding
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+)
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("testdata/linkding1.json")
+	be.Equal(t, err, nil)
+	defer f.Close()
+
+	ok, err := Probe(f)
+	be.Equal(t, err, nil)
+	be.True(t, ok)
+
+	bookmarks, err := Read(f)
+	be.Equal(t, err, nil)
+	be.Equal(t, len(bookmarks), 3)
+
+	betula := bookmarks[0]
+	be.Equal(t, betula.URL, "https://joinbetula.org/")
+	be.Equal(t, betula.Title, "")
+	be.Equal(t, betula.WebsiteTitle, "Betula")
+	be.Equal(t, betula.Notes, "Federated too.")
+	be.Equal(t, betula.TagNames, []string{"bookmarks", "software"})
+	be.True(t, betula.Unread)
+	be.True(t, betula.Shared)
+	be.True(t, betula.DateAdded.Equal(time.Unix(1775421369, 0)))
+
+	mycorrhiza := bookmarks[1]
+	be.Equal(t, mycorrhiza.Description, "A wiki engine.")
+	be.True(t, mycorrhiza.IsArchived)
+	be.True(t, !mycorrhiza.Unread)
+}
+
+func TestReadArray(t *testing.T) {
+	t.Parallel()
+
+	const array = `[{"url": "https://example.org", "title": "Example", "tag_names": ["a"]}]`
+	ok, err := Probe(strings.NewReader(array))
+	be.Equal(t, err, nil)
+	be.True(t, ok)
+
+	bookmarks, err := Read(strings.NewReader(array))
+	be.Equal(t, err, nil)
+	be.Equal(t, len(bookmarks), 1)
+	be.Equal(t, bookmarks[0].TagNames, []string{"a"})
+}
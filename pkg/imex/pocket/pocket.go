@@ -0,0 +1,166 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+// Package pocket implements the Pocket export formats, both the HTML and the CSV one.
+package pocket
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+
+	"git.sr.ht/~bouncepaw/betula/pkg/bxstr"
+	"git.sr.ht/~bouncepaw/betula/pkg/bxtime"
+)
+
+type Bookmark struct {
+	URL   string
+	Title string
+	Tags  []string
+	Added time.Time
+	// Archived is true for the bookmarks in the archive, that is, read ones.
+	Archived bool
+}
+
+const (
+	htmlMarker = "<title>Pocket Export</title>"
+	csvHeader  = "title,url,time_added"
+)
+
+// Probe reports whether r contains a Pocket export, the HTML or the CSV one.
+// It seeks r back to the start before returning, so the caller can pass the
+// same reader to Read.
+func Probe(r io.ReadSeeker) (bool, error) {
+	buf := make([]byte, 512)
+	n, err := r.Read(buf)
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	s := strings.TrimSpace(string(buf[:n]))
+	return strings.Contains(s, htmlMarker) || strings.HasPrefix(s, csvHeader), nil
+}
+
+// Read parses a Pocket export from r and returns the bookmarks.
+func Read(r io.Reader) ([]Bookmark, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(csvHeader)) {
+		return readCSV(bytes.NewReader(data))
+	}
+	return readHTML(bytes.NewReader(data))
+}
+
+// readHTML reads the HTML export. It has a list of unread bookmarks and
+// a list of archived ones, each after a heading.
+func readHTML(r io.Reader) ([]Bookmark, error) {
+	var (
+		bookmarks []Bookmark
+		current   *Bookmark
+		inHeading bool
+		heading   strings.Builder
+		archived  bool
+		textBuf   strings.Builder
+		tokenizer = html.NewTokenizer(r)
+	)
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if tokenizer.Err() == io.EOF {
+				return bookmarks, nil
+			}
+			return nil, tokenizer.Err()
+
+		case html.StartTagToken:
+			tok := tokenizer.Token()
+			switch tok.Data {
+			case "h1":
+				inHeading = true
+				heading.Reset()
+			case "a":
+				current = &Bookmark{Archived: archived}
+				for _, a := range tok.Attr {
+					switch a.Key {
+					case "href":
+						current.URL = a.Val
+					case "time_added":
+						current.Added = bxtime.ParseUnixTimestamp(a.Val)
+					case "tags":
+						current.Tags = bxstr.CommaSeparated(a.Val)
+					}
+				}
+				textBuf.Reset()
+			}
+
+		case html.TextToken:
+			if inHeading {
+				heading.Write(tokenizer.Text())
+			} else if current != nil {
+				textBuf.Write(tokenizer.Text())
+			}
+
+		case html.EndTagToken:
+			switch tokenizer.Token().Data {
+			case "h1":
+				inHeading = false
+				archived = strings.Contains(strings.ToLower(heading.String()), "archive")
+			case "a":
+				if current != nil {
+					current.Title = strings.TrimSpace(textBuf.String())
+					bookmarks = append(bookmarks, *current)
+					current = nil
+				}
+			}
+		}
+	}
+}
+
+// readCSV reads the CSV export. Tags are separated by |, the status is
+// unread or archive.
+func readCSV(r io.Reader) ([]Bookmark, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	idx := make(map[string]int, len(records[0]))
+	for i, name := range records[0] {
+		idx[strings.TrimSpace(name)] = i
+	}
+	get := func(rec []string, name string) string {
+		i, ok := idx[name]
+		if !ok || i >= len(rec) {
+			return ""
+		}
+		return rec[i]
+	}
+
+	bookmarks := make([]Bookmark, 0, len(records)-1)
+	for _, rec := range records[1:] {
+		b := Bookmark{
+			URL:      get(rec, "url"),
+			Title:    get(rec, "title"),
+			Added:    bxtime.ParseUnixTimestamp(get(rec, "time_added")),
+			Archived: get(rec, "status") == "archive",
+		}
+		for tag := range strings.SplitSeq(get(rec, "tags"), "|") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				b.Tags = append(b.Tags, tag)
+			}
+		}
+		bookmarks = append(bookmarks, b)
+	}
+	return bookmarks, nil
+}
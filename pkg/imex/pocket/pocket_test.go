@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package pocket
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+)
+
+// testdata has the same bookmarks in both formats.
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"testdata/pocket1.html", "testdata/pocket1.csv"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			f, err := os.Open(name)
+			be.Equal(t, err, nil)
+			defer f.Close()
+
+			ok, err := Probe(f)
+			be.Equal(t, err, nil)
+			be.True(t, ok)
+
+			bookmarks, err := Read(f)
+			be.Equal(t, err, nil)
+			be.Equal(t, len(bookmarks), 3)
+
+			betula := bookmarks[0]
+			be.Equal(t, betula.URL, "https://joinbetula.org/")
+			be.Equal(t, betula.Title, "Betula")
+			be.Equal(t, betula.Tags, []string{"bookmarks", "software"})
+			be.Equal(t, betula.Added, time.Unix(1775421369, 0))
+			be.True(t, !betula.Archived)
+
+			be.Equal(t, bookmarks[1].Tags, []string(nil))
+
+			bouncepaw := bookmarks[2]
+			be.Equal(t, bouncepaw.URL, "https://bouncepaw.com/")
+			be.Equal(t, bouncepaw.Tags, []string{"people"})
+			be.True(t, bouncepaw.Archived)
+		})
+	}
+}
+
+func TestProbe(t *testing.T) {
+	t.Parallel()
+
+	ok, err := Probe(strings.NewReader("<!DOCTYPE NETSCAPE-Bookmark-file-1>"))
+	be.Equal(t, err, nil)
+	be.True(t, !ok)
+}
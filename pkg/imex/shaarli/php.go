@@ -0,0 +1,185 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package shaarli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var errBadSerialization = errors.New("shaarli: bad PHP serialization")
+
+// table is a PHP array or object. The keys keep their order. Object
+// property names are stripped of their visibility markers.
+type table struct {
+	class  string
+	keys   []string
+	values map[string]any
+}
+
+// unserialize parses the result of PHP's serialize. Values become nil, bool,
+// int64, float64, string or *table. References become nil, Shaarli does not
+// use them for anything important.
+func unserialize(data []byte) (any, error) {
+	p := &phpParser{data: data}
+	return p.value()
+}
+
+// maxDepth is how deep arrays and objects can be nested. Shaarli nests
+// them two or three levels deep.
+const maxDepth = 64
+
+type phpParser struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func (p *phpParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w at %d: %s", errBadSerialization, p.pos, fmt.Sprintf(format, args...))
+}
+
+// until returns everything up to the delimiter and moves past it.
+func (p *phpParser) until(delim byte) (string, error) {
+	i := bytes.IndexByte(p.data[p.pos:], delim)
+	if i < 0 {
+		return "", p.errorf("no %q", delim)
+	}
+	s := string(p.data[p.pos : p.pos+i])
+	p.pos += i + 1
+	return s, nil
+}
+
+func (p *phpParser) expect(s string) error {
+	if !bytes.HasPrefix(p.data[p.pos:], []byte(s)) {
+		return p.errorf("expected %q", s)
+	}
+	p.pos += len(s)
+	return nil
+}
+
+// str reads the part of s:5:"hello"; after the type, also used for class names.
+func (p *phpParser) str(end string) (string, error) {
+	lenStr, err := p.until(':')
+	if err != nil {
+		return "", err
+	}
+	n, err := strconv.Atoi(lenStr)
+	if err != nil || n < 0 || p.pos+n+2 > len(p.data) {
+		return "", p.errorf("bad string length %q", lenStr)
+	}
+	if err = p.expect(`"`); err != nil {
+		return "", err
+	}
+	s := string(p.data[p.pos : p.pos+n])
+	p.pos += n
+	return s, p.expect(`"` + end)
+}
+
+func (p *phpParser) value() (any, error) {
+	if p.pos+2 > len(p.data) {
+		return nil, p.errorf("unexpected end")
+	}
+	kind := p.data[p.pos]
+	p.pos++
+	if kind == 'N' {
+		return nil, p.expect(";")
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case 'b':
+		s, err := p.until(';')
+		return s == "1", err
+	case 'i':
+		s, err := p.until(';')
+		if err != nil {
+			return nil, err
+		}
+		return strconv.ParseInt(s, 10, 64)
+	case 'd':
+		s, err := p.until(';')
+		if err != nil {
+			return nil, err
+		}
+		return strconv.ParseFloat(s, 64)
+	case 's':
+		return p.str(";")
+	case 'r', 'R':
+		_, err := p.until(';')
+		return nil, err
+	case 'a':
+		return p.table("")
+	case 'O':
+		class, err := p.str(":")
+		if err != nil {
+			return nil, err
+		}
+		return p.table(class)
+	default:
+		return nil, p.errorf("unsupported type %q", kind)
+	}
+}
+
+// table reads the part of a:1:{i:0;s:1:"a";} after the type or the class name.
+func (p *phpParser) table(class string) (*table, error) {
+	countStr, err := p.until(':')
+	if err != nil {
+		return nil, err
+	}
+	// Every entry takes a few bytes, so there cannot be more entries than
+	// bytes left. The count is not to be trusted further than that.
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count < 0 || count > len(p.data)-p.pos {
+		return nil, p.errorf("bad count %q", countStr)
+	}
+	if p.depth++; p.depth > maxDepth {
+		return nil, p.errorf("nested too deep")
+	}
+	defer func() { p.depth-- }()
+	if err = p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	t := &table{class: class, values: make(map[string]any, count)}
+	for range count {
+		key, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		var name string
+		switch key := key.(type) {
+		case int64:
+			name = strconv.FormatInt(key, 10)
+		case string:
+			name = propertyName(key)
+		default:
+			return nil, p.errorf("bad key %v", key)
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		t.keys = append(t.keys, name)
+		t.values[name] = value
+	}
+	return t, p.expect("}")
+}
+
+// propertyName strips the markers of protected (\0*\0name) and private
+// (\0Class\0name) properties.
+func propertyName(key string) string {
+	if strings.HasPrefix(key, "\x00") {
+		if i := strings.IndexByte(key[1:], 0); i >= 0 {
+			return key[i+2:]
+		}
+	}
+	return key
+}
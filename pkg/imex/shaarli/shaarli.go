@@ -0,0 +1,177 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+// Package shaarli implements the Shaarli datastore, the data/datastore.php file.
+//
+// The datastore is a serialized PHP value, compressed and encoded in base64.
+// Old Shaarli versions store an array of arrays, new ones store Bookmark
+// objects, both are read. Shaarli's HTML export is a Netscape Bookmark File,
+// see package netscape.
+package shaarli
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+type Bookmark struct {
+	URL         string
+	Title       string
+	Description string
+	Tags        []string
+	Created     time.Time
+	Private     bool
+}
+
+const (
+	prefix = "<?php /* "
+	suffix = " */ ?>"
+
+	// maxSerializedSize is the size of the biggest datastore, uncompressed,
+	// we agree to read. A few compressed bytes can unpack into gigabytes.
+	maxSerializedSize = 256 << 20
+)
+
+var errTooBig = errors.New("shaarli: datastore is too big")
+
+// Probe reports whether r contains a Shaarli datastore by checking its
+// prefix. It seeks r back to the start before returning, so the caller can
+// pass the same reader to Read.
+func Probe(r io.ReadSeeker) (bool, error) {
+	buf := make([]byte, 64)
+	n, err := r.Read(buf)
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	return strings.HasPrefix(string(buf[:n]), prefix), nil
+}
+
+// Read parses a Shaarli datastore from r and returns the bookmarks.
+func Read(r io.Reader) ([]Bookmark, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+	data, ok := bytes.CutPrefix(data, []byte(prefix))
+	if !ok {
+		return nil, errors.New("shaarli: not a datastore")
+	}
+	data, _ = bytes.CutSuffix(data, []byte(suffix))
+
+	compressed, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return nil, err
+	}
+	serialized, err := io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(compressed)), maxSerializedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(serialized) > maxSerializedSize {
+		return nil, errTooBig
+	}
+	value, err := unserialize(serialized)
+	if err != nil {
+		return nil, err
+	}
+
+	var bookmarks []Bookmark
+	collectBookmarks(value, &bookmarks)
+	return bookmarks, nil
+}
+
+// collectBookmarks finds the bookmarks in the value, wherever they are.
+// A bookmark is an array or an object with a url and a creation date.
+func collectBookmarks(value any, bookmarks *[]Bookmark) {
+	t, ok := value.(*table)
+	if !ok {
+		return
+	}
+	if bookmark, ok := bookmarkOf(t); ok {
+		*bookmarks = append(*bookmarks, bookmark)
+		return
+	}
+	for _, key := range t.keys {
+		collectBookmarks(t.values[key], bookmarks)
+	}
+}
+
+func bookmarkOf(t *table) (Bookmark, bool) {
+	url, ok := t.values["url"].(string)
+	if !ok {
+		return Bookmark{}, false
+	}
+	_, legacy := t.values["linkdate"]
+	_, modern := t.values["created"]
+	if !legacy && !modern {
+		return Bookmark{}, false
+	}
+
+	bookmark := Bookmark{
+		URL:         url,
+		Title:       stringOf(t.values["title"]),
+		Description: stringOf(t.values["description"]),
+		Private:     truthy(t.values["private"]),
+	}
+	switch tags := t.values["tags"].(type) {
+	case string:
+		bookmark.Tags = strings.Fields(tags)
+	case *table:
+		for _, key := range tags.keys {
+			if tag := stringOf(tags.values[key]); tag != "" {
+				bookmark.Tags = append(bookmark.Tags, tag)
+			}
+		}
+	}
+	if legacy {
+		bookmark.Created, _ = time.ParseInLocation("20060102_150405", stringOf(t.values["linkdate"]), time.Local)
+	} else {
+		bookmark.Created = dateTimeOf(t.values["created"])
+	}
+	return bookmark, true
+}
+
+// dateTimeOf converts a PHP DateTime object. Returns zero time on failure.
+func dateTimeOf(value any) time.Time {
+	t, ok := value.(*table)
+	if !ok {
+		return time.Time{}
+	}
+	loc := time.UTC
+	if name := stringOf(t.values["timezone"]); name != "" {
+		if l, err := time.LoadLocation(name); err == nil {
+			loc = l
+		} else if offset, err := time.Parse("-07:00", name); err == nil {
+			loc = offset.Location()
+		}
+	}
+	date, _ := time.ParseInLocation("2006-01-02 15:04:05.999999", stringOf(t.values["date"]), loc)
+	return date
+}
+
+func stringOf(value any) string {
+	s, _ := value.(string)
+	return s
+}
+
+func truthy(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case int64:
+		return v != 0
+	case string:
+		return v != "" && v != "0"
+	default:
+		return false
+	}
+}
@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package shaarli
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+)
+
+// testdata/datastore.php has Bookmark objects like Shaarli 0.11 and newer
+// write. testdata/datastore-legacy.php has arrays like older versions write.
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("testdata/datastore.php")
+	be.Equal(t, err, nil)
+	defer f.Close()
+
+	ok, err := Probe(f)
+	be.Equal(t, err, nil)
+	be.True(t, ok)
+
+	bookmarks, err := Read(f)
+	be.Equal(t, err, nil)
+	be.Equal(t, len(bookmarks), 2)
+
+	mycorrhiza := bookmarks[0]
+	be.Equal(t, mycorrhiza.URL, "https://mycorrhiza.wiki/")
+	be.Equal(t, mycorrhiza.Title, "Mycorrhiza Wiki")
+	be.Equal(t, mycorrhiza.Description, "A wiki engine.")
+	be.Equal(t, mycorrhiza.Tags, []string{"wiki", "software"})
+	be.True(t, mycorrhiza.Created.Equal(time.Date(2026, 4, 5, 20, 34, 6, 0, time.UTC)))
+	be.True(t, !mycorrhiza.Private)
+
+	bouncepaw := bookmarks[1]
+	be.Equal(t, bouncepaw.Tags, []string(nil))
+	be.True(t, bouncepaw.Created.Equal(time.Date(2026, 2, 2, 12, 54, 18, 0, time.UTC)))
+	be.True(t, bouncepaw.Private)
+}
+
+func TestReadLegacy(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("testdata/datastore-legacy.php")
+	be.Equal(t, err, nil)
+	defer f.Close()
+
+	bookmarks, err := Read(f)
+	be.Equal(t, err, nil)
+	be.Equal(t, len(bookmarks), 2)
+
+	betula := bookmarks[0]
+	be.Equal(t, betula.URL, "https://joinbetula.org/")
+	be.Equal(t, betula.Description, "Bookmarking engine — federated")
+	be.Equal(t, betula.Tags, []string{"bookmarks", "software"})
+	be.Equal(t, betula.Created, time.Date(2026, 4, 5, 20, 36, 9, 0, time.Local))
+	be.True(t, !betula.Private)
+	be.True(t, bookmarks[1].Private)
+}
+
+func TestUnserialize(t *testing.T) {
+	t.Parallel()
+
+	value, err := unserialize([]byte(`a:3:{i:0;N;s:1:"b";d:1.5;s:3:"` + "\x00*\x00" + `";b:1;}`))
+	be.Equal(t, err, nil)
+	tbl := value.(*table)
+	be.Equal(t, tbl.keys, []string{"0", "b", ""})
+	be.Equal(t, tbl.values["b"], any(1.5))
+
+	_, err = unserialize([]byte(`s:10:"short";`))
+	be.Err(t, err, errBadSerialization)
+	_, err = unserialize([]byte(`a:2147483647:{}`))
+	be.Err(t, err, errBadSerialization)
+	_, err = unserialize([]byte(strings.Repeat(`a:1:{i:0;`, maxDepth+1) + "N;" + strings.Repeat("}", maxDepth+1)))
+	be.Err(t, err, errBadSerialization)
+	_, err = unserialize([]byte(strings.Repeat(`a:1:{i:0;`, maxDepth) + "N;" + strings.Repeat("}", maxDepth)))
+	be.Err(t, err, nil)
+	_, err = Read(strings.NewReader("<?php /* not base64 */ ?>"))
+	be.True(t, err != nil)
+}
@@ -0,0 +1 @@
+<?php /* fY9bbsIwEEW3Es0CiG2SFCZ/3QhyEpO6CXZkO+UDRWIRrJCVMLaoSh/0x5rHveN7JAo8eeQlgmCiYgUrd4KtK7aFWmIVd7QKOowKak8DeFVhHmVs1gizG2MlqHwLYfKY5+9WmyZpVtb1eVxzjtAp3zo9BW1N8gq6ZO1wkG7Qps+U6bVR2fV8yfaqU04G1UXdC8Lk9Ae1UGtkNNkgjNoMXRo9Se6xoNCy90lBjub+lc+83YejdORdHsz07LgoC775E3sbw86mVZM8/iAXX+TNp2TV2sMzcIbwm4v/w/UY7RtXurQsNw== */ ?>
\ No newline at end of file
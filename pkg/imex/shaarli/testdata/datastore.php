@@ -0,0 +1 @@
+<?php /* vVPRTsIwFOVTlj5qgK4biJcniD4qD2p8MTHdqKwZW5eukwzCv3vbMjFBifHBZUu609N7zr0nXUBEgTxknOu1fJkrlRdc55+Lmda8JRDBroYrIL2LXi7amkw5MNhJoFMJ4eGj030NY8eRyyPFb3VUpITMcZKDwtdiC2CjM2YIhKE1MjqIEFeytqgF6kxp86TXBKEYCE/S0C69pcbjDDcyY6oahsOiTZXWmdzywUbmcmj3J45spFkL+xui1N0nLXhGmoNjR1uKOtWyMlKVHToLbKlAlCtZioFF/dQMX31t1BncuGq+BdSt1ZvZcI26xyGZrCmSkkv0nrhj175TI9O87bCQOjDVghuBQ1nYaje4fpSFOGSHcktE3AhwIIyycZ/GfToKGIMoBjoeUPe4RiIgBs9uVSleTVsJ6zLyLju84902WlViOBeYWOmteztNtfR27o8WKy3fnQtrfG87/0Pi9Fzi9DRxdkw8UU2ZiopvBqkqvssbxzvvOD8Hjc2cJkth91+5MXwDVBrFEE7+kBsWuqQU7KFf54VXd7//AA== */ ?>
\ No newline at end of file
@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+// Package wallabag implements the wallabag JSON export format.
+package wallabag
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+)
+
+// Flag is a boolean that wallabag writes either as 0 and 1 or as false and true.
+type Flag bool
+
+func (f *Flag) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case "1", "true":
+		*f = true
+	default:
+		*f = false
+	}
+	return nil
+}
+
+// Time is a time that wallabag writes like 2017-06-26T09:49:34+0200.
+// Unparsable times become zero.
+type Time time.Time
+
+func (t *Time) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		*t = Time{}
+		return nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05-0700", time.RFC3339} {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			*t = Time(parsed)
+			return nil
+		}
+	}
+	*t = Time{}
+	return nil
+}
+
+// Entry is a saved article as it appears in a JSON export.
+type Entry struct {
+	URL   string   `json:"url"`
+	Title string   `json:"title"`
+	Tags  []string `json:"tags"`
+	// IsArchived is true for the read articles.
+	IsArchived Flag `json:"is_archived"`
+	IsStarred  Flag `json:"is_starred"`
+	CreatedAt  Time `json:"created_at"`
+}
+
+// Probe reports whether r contains a wallabag JSON export by checking that it
+// starts with [ and contains "is_archived". It seeks r back to the start
+// before returning, so the caller can pass the same reader to Read.
+func Probe(r io.ReadSeeker) (bool, error) {
+	buf := make([]byte, 512)
+	n, err := r.Read(buf)
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	s := strings.TrimSpace(string(buf[:n]))
+	return strings.HasPrefix(s, "[") && strings.Contains(s, `"is_archived"`), nil
+}
+
+// Read parses a wallabag JSON export from r and returns the entries.
+func Read(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	return entries, json.NewDecoder(r).Decode(&entries)
+}
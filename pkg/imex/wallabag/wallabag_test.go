@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package wallabag
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+)
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("testdata/wallabag1.json")
+	be.Equal(t, err, nil)
+	defer f.Close()
+
+	ok, err := Probe(f)
+	be.Equal(t, err, nil)
+	be.True(t, ok)
+
+	entries, err := Read(f)
+	be.Equal(t, err, nil)
+	be.Equal(t, len(entries), 2)
+
+	betula := entries[0]
+	be.Equal(t, betula.URL, "https://joinbetula.org/")
+	be.Equal(t, betula.Title, "Betula")
+	be.Equal(t, betula.Tags, []string{"software", "bookmarks"})
+	be.Equal(t, betula.IsArchived, Flag(true))
+	be.Equal(t, betula.IsStarred, Flag(false))
+	be.True(t, time.Time(betula.CreatedAt).Equal(time.Unix(1775421369, 0)))
+
+	mycorrhiza := entries[1]
+	be.Equal(t, mycorrhiza.IsArchived, Flag(false))
+	be.Equal(t, mycorrhiza.IsStarred, Flag(true))
+	be.True(t, time.Time(mycorrhiza.CreatedAt).Equal(time.Unix(1775421246, 0)))
+}
+
+func TestProbe(t *testing.T) {
+	t.Parallel()
+
+	ok, err := Probe(strings.NewReader(`[{"href": "https://example.org"}]`))
+	be.Equal(t, err, nil)
+	be.True(t, !ok)
+}
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+// Package jsonfeed has types for making a JSON Feed 1.1, see
+// https://www.jsonfeed.org/version/1.1/
+package jsonfeed
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Version is the URL of the version of the format the feed uses.
+const Version = "https://jsonfeed.org/version/1.1"
+
+type Feed struct {
+	Version     string    `json:"version"`
+	Title       string    `json:"title"`
+	HomePageURL string    `json:"home_page_url,omitempty"`
+	FeedURL     string    `json:"feed_url,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Authors     []*Author `json:"authors,omitempty"`
+	Items       []*Item   `json:"items"`
+}
+
+type Author struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+type Item struct {
+	ID            string    `json:"id"`
+	URL           string    `json:"url,omitempty"`
+	ExternalURL   string    `json:"external_url,omitempty"`
+	Title         string    `json:"title,omitempty"`
+	ContentHTML   string    `json:"content_html,omitempty"`
+	DatePublished string    `json:"date_published,omitempty"`
+	Authors       []*Author `json:"authors,omitempty"`
+	Tags          []string  `json:"tags,omitempty"`
+}
+
+// Write the Feed as JSON.
+func (fd *Feed) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(fd)
+}
@@ -0,0 +1,312 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+// Package readability finds the main text of web pages, leaving menus,
+// sidebars, scripts and other clutter out.
+//
+// The approach is the one of Arc90's Readability: paragraphs give points
+// to their parents and grandparents, and the element with the most points,
+// adjusted for link density, is the article.
+package readability
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ErrNoArticle is returned when the page has no text worth keeping.
+var ErrNoArticle = errors.New("readability: no article found")
+
+// Article is the main text of a page.
+type Article struct {
+	Title string
+	// Content is cleaned HTML. It has only harmless tags and attributes,
+	// all links and images in it are absolute.
+	Content string
+}
+
+// minParagraph is the length of the shortest text that counts as a paragraph.
+const minParagraph = 25
+
+var (
+	reUnlikely = regexp.MustCompile(`(?i)comment|sidebar|footer|header|menu|nav|share|social|advert|\bads?\b|promo|related|cookie|banner|popup|subscribe|breadcrumb|pagination`)
+	reLikely   = regexp.MustCompile(`(?i)article|content|main|post|entry|text|body|story`)
+)
+
+// dropped are the elements removed with everything inside.
+var dropped = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Iframe: true, atom.Object: true, atom.Embed: true, atom.Svg: true,
+	atom.Form: true, atom.Button: true, atom.Input: true, atom.Select: true, atom.Textarea: true,
+	atom.Nav: true, atom.Header: true, atom.Footer: true, atom.Aside: true,
+	atom.Link: true, atom.Meta: true, atom.Head: true,
+}
+
+// kept are the elements that make it to the article. The rest are
+// replaced with their contents.
+var kept = map[atom.Atom]bool{
+	atom.P: true, atom.Br: true, atom.Hr: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Ul: true, atom.Ol: true, atom.Li: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.Blockquote: true, atom.Pre: true, atom.Code: true, atom.Kbd: true, atom.Samp: true,
+	atom.Em: true, atom.I: true, atom.Strong: true, atom.B: true, atom.U: true, atom.S: true,
+	atom.Del: true, atom.Ins: true, atom.Mark: true, atom.Sub: true, atom.Sup: true, atom.Q: true,
+	atom.Abbr: true, atom.Cite: true, atom.Time: true,
+	atom.A: true, atom.Img: true, atom.Figure: true, atom.Figcaption: true,
+	atom.Table: true, atom.Thead: true, atom.Tbody: true, atom.Tfoot: true,
+	atom.Tr: true, atom.Th: true, atom.Td: true, atom.Caption: true,
+}
+
+// Extract finds the article in the HTML document. Relative links are
+// resolved against base.
+func Extract(r io.Reader, base *url.URL) (Article, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return Article{}, err
+	}
+
+	var article = Article{Title: title(doc)}
+	prune(doc)
+
+	var top = bestCandidate(doc)
+	if top == nil {
+		return article, ErrNoArticle
+	}
+
+	var buf bytes.Buffer
+	for _, n := range clean(top, base) {
+		if err = html.Render(&buf, n); err != nil {
+			return article, err
+		}
+	}
+	article.Content = strings.TrimSpace(buf.String())
+	return article, nil
+}
+
+func title(doc *html.Node) string {
+	var titleText, ogTitle string
+	walk(doc, func(n *html.Node) bool {
+		switch {
+		case n.DataAtom == atom.Title && titleText == "":
+			titleText = textContent(n)
+		case n.DataAtom == atom.Meta && attr(n, "property") == "og:title" && ogTitle == "":
+			ogTitle = attr(n, "content")
+		}
+		return true
+	})
+	// og:title is usually free of the site name suffix.
+	if ogTitle != "" {
+		return strings.TrimSpace(ogTitle)
+	}
+	return strings.TrimSpace(titleText)
+}
+
+// prune removes the elements that are never part of the article.
+func prune(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		var next = c.NextSibling
+		switch {
+		case c.Type == html.CommentNode:
+			n.RemoveChild(c)
+		case c.Type != html.ElementNode:
+		case dropped[c.DataAtom], unlikely(c):
+			n.RemoveChild(c)
+		default:
+			prune(c)
+		}
+		c = next
+	}
+}
+
+func unlikely(n *html.Node) bool {
+	if n.DataAtom == atom.Body || n.DataAtom == atom.Html || n.DataAtom == atom.Article || n.DataAtom == atom.Main {
+		return false
+	}
+	var names = attr(n, "class") + " " + attr(n, "id")
+	return reUnlikely.MatchString(names) && !reLikely.MatchString(names)
+}
+
+func bestCandidate(doc *html.Node) *html.Node {
+	var scores = map[*html.Node]float64{}
+	walk(doc, func(n *html.Node) bool {
+		if n.DataAtom != atom.P && n.DataAtom != atom.Pre && n.DataAtom != atom.Blockquote && n.DataAtom != atom.Td {
+			return true
+		}
+		var text = textContent(n)
+		if len([]rune(text)) < minParagraph {
+			return false
+		}
+		var score = 1 + float64(strings.Count(text, ",")) + min(float64(len(text))/100, 3)
+		if parent := n.Parent; parent != nil && parent.Type == html.ElementNode {
+			scores[parent] += score
+			if grand := parent.Parent; grand != nil && grand.Type == html.ElementNode {
+				scores[grand] += score / 2
+			}
+		}
+		return false
+	})
+
+	var (
+		best      *html.Node
+		bestScore float64
+	)
+	for n, score := range scores {
+		score = (score + weight(n)) * (1 - linkDensity(n))
+		if best == nil || score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	if best == nil {
+		// No paragraphs at all. The body is better than nothing if it
+		// has some text.
+		walk(doc, func(n *html.Node) bool {
+			if n.DataAtom == atom.Body {
+				best = n
+			}
+			return best == nil
+		})
+		if best == nil || textContent(best) == "" {
+			return nil
+		}
+	}
+	return best
+}
+
+func weight(n *html.Node) float64 {
+	var w float64
+	switch n.DataAtom {
+	case atom.Article, atom.Main:
+		w += 10
+	}
+	var names = attr(n, "class") + " " + attr(n, "id")
+	if reLikely.MatchString(names) {
+		w += 25
+	}
+	if reUnlikely.MatchString(names) {
+		w -= 25
+	}
+	return w
+}
+
+func linkDensity(n *html.Node) float64 {
+	var total = len(textContent(n))
+	if total == 0 {
+		return 0
+	}
+	var links int
+	walk(n, func(c *html.Node) bool {
+		if c.DataAtom == atom.A {
+			links += len(textContent(c))
+			return false
+		}
+		return true
+	})
+	return float64(links) / float64(total)
+}
+
+// clean returns copies of n's children with only the kept elements
+// and attributes left.
+func clean(n *html.Node, base *url.URL) []*html.Node {
+	var nodes []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == html.TextNode:
+			nodes = append(nodes, &html.Node{Type: html.TextNode, Data: c.Data})
+		case c.Type != html.ElementNode:
+		case kept[c.DataAtom]:
+			var copied = &html.Node{Type: html.ElementNode, DataAtom: c.DataAtom, Data: c.Data}
+			copied.Attr = cleanAttrs(c, base)
+			if c.DataAtom == atom.Img && len(copied.Attr) == 0 {
+				continue
+			}
+			for _, child := range clean(c, base) {
+				copied.AppendChild(child)
+			}
+			nodes = append(nodes, copied)
+		default:
+			nodes = append(nodes, clean(c, base)...)
+		}
+	}
+	return nodes
+}
+
+func cleanAttrs(n *html.Node, base *url.URL) []html.Attribute {
+	switch n.DataAtom {
+	case atom.A:
+		if href := absolute(attr(n, "href"), base); href != "" {
+			return []html.Attribute{{Key: "href", Val: href}}
+		}
+	case atom.Img:
+		var src = absolute(attr(n, "src"), base)
+		if src == "" {
+			return nil
+		}
+		return []html.Attribute{{Key: "src", Val: src}, {Key: "alt", Val: attr(n, "alt")}}
+	case atom.Td, atom.Th:
+		var attrs []html.Attribute
+		for _, a := range n.Attr {
+			if a.Key == "colspan" || a.Key == "rowspan" {
+				attrs = append(attrs, html.Attribute{Key: a.Key, Val: a.Val})
+			}
+		}
+		return attrs
+	}
+	return nil
+}
+
+// absolute resolves the address against base. Only web and mail
+// addresses are allowed, others turn into an empty string.
+func absolute(addr string, base *url.URL) string {
+	u, err := url.Parse(strings.TrimSpace(addr))
+	if err != nil || addr == "" {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	switch u.Scheme {
+	case "http", "https", "mailto":
+		return u.String()
+	default:
+		return ""
+	}
+}
+
+// walk visits n and its descendants in document order. Children are
+// not visited if visit returns false.
+func walk(n *html.Node, visit func(*html.Node) bool) {
+	if !visit(n) {
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, visit)
+	}
+}
+
+func textContent(n *html.Node) string {
+	var buf strings.Builder
+	walk(n, func(c *html.Node) bool {
+		if c.Type == html.TextNode {
+			buf.WriteString(c.Data)
+		}
+		return true
+	})
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
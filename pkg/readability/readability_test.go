@@ -0,0 +1,59 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package readability
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/nalgeon/be"
+)
+
+const page = `<!doctype html>
+<html>
+<head>
+	<title>Mushrooms | Forest blog</title>
+	<meta property="og:title" content="Mushrooms">
+	<script>track()</script>
+</head>
+<body>
+	<nav><a href="/">Home</a> <a href="/about">About</a></nav>
+	<div class="sidebar"><p>Subscribe to our newsletter, it is really great, trust us.</p></div>
+	<div id="content">
+		<h1>Mushrooms</h1>
+		<p style="color: red" onclick="evil()">Mushrooms grow in forests, fields and, sometimes, in bathrooms.</p>
+		<p>Some of them are <a href="/edible" class="x">edible</a>, others are not, so be careful.</p>
+		<img src="/img/amanita.jpg" alt="Amanita" width="100">
+		<p><a href="javascript:alert(1)">Click me, I am a very safe link.</a></p>
+	</div>
+	<footer>Copyright, all rights reserved, whatever that means.</footer>
+</body>
+</html>`
+
+func TestExtract(t *testing.T) {
+	base, _ := url.Parse("https://forest.example/blog/mushrooms")
+	article, err := Extract(strings.NewReader(page), base)
+	be.Err(t, err, nil)
+	be.Equal(t, article.Title, "Mushrooms")
+
+	for _, want := range []string{
+		`<h1>Mushrooms</h1>`,
+		`<p>Mushrooms grow in forests, fields and, sometimes, in bathrooms.</p>`,
+		`<a href="https://forest.example/edible">edible</a>`,
+		`<img src="https://forest.example/img/amanita.jpg" alt="Amanita"/>`,
+		`<a>Click me, I am a very safe link.</a>`,
+	} {
+		be.True(t, strings.Contains(article.Content, want))
+	}
+	for _, unwanted := range []string{"Home", "newsletter", "Copyright", "track", "evil", "style"} {
+		be.True(t, !strings.Contains(article.Content, unwanted))
+	}
+}
+
+func TestExtractEmpty(t *testing.T) {
+	_, err := Extract(strings.NewReader(`<html><body><script>1</script></body></html>`), nil)
+	be.Err(t, err, ErrNoArticle)
+}
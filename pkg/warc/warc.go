@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+// Package warc writes WARC 1.1 files, as understood by pywb and the like.
+// Every record is a separate gzip member, so the output is a valid .warc.gz.
+package warc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // WARC tools expect SHA-1 digests.
+	"encoding/base32"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Record types.
+const (
+	TypeWarcinfo = "warcinfo"
+	TypeResource = "resource"
+)
+
+// Record is a WARC record.
+type Record struct {
+	Type string
+	// TargetURI is the original address of the content. Not set for
+	// warcinfo records.
+	TargetURI   string
+	Date        time.Time
+	ContentType string
+	Content     []byte
+}
+
+// Writer writes records into the underlying writer.
+type Writer struct {
+	w io.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// WriteInfo writes a warcinfo record that describes the file.
+func (w *Writer) WriteInfo(software string) error {
+	return w.WriteRecord(Record{
+		Type:        TypeWarcinfo,
+		Date:        time.Now(),
+		ContentType: "application/warc-fields",
+		Content:     fmt.Appendf(nil, "software: %s\r\nformat: WARC File Format 1.1\r\n", software),
+	})
+}
+
+// WriteRecord writes the record as a gzip member.
+func (w *Writer) WriteRecord(rec Record) error {
+	id, err := newRecordID()
+	if err != nil {
+		return err
+	}
+
+	var header bytes.Buffer
+	header.WriteString("WARC/1.1\r\n")
+	fmt.Fprintf(&header, "WARC-Type: %s\r\n", rec.Type)
+	fmt.Fprintf(&header, "WARC-Record-ID: <%s>\r\n", id)
+	fmt.Fprintf(&header, "WARC-Date: %s\r\n", rec.Date.UTC().Format(time.RFC3339))
+	if rec.TargetURI != "" {
+		fmt.Fprintf(&header, "WARC-Target-URI: %s\r\n", rec.TargetURI)
+	}
+	if rec.ContentType != "" {
+		fmt.Fprintf(&header, "Content-Type: %s\r\n", rec.ContentType)
+	}
+	if rec.Type == TypeResource {
+		digest := Digest(rec.Content)
+		fmt.Fprintf(&header, "WARC-Block-Digest: %s\r\n", digest)
+		fmt.Fprintf(&header, "WARC-Payload-Digest: %s\r\n", digest)
+	}
+	fmt.Fprintf(&header, "Content-Length: %d\r\n\r\n", len(rec.Content))
+
+	gz := gzip.NewWriter(w.w)
+	for _, part := range [][]byte{header.Bytes(), rec.Content, []byte("\r\n\r\n")} {
+		if _, err := gz.Write(part); err != nil {
+			return err
+		}
+	}
+	return gz.Close()
+}
+
+// Digest returns the SHA-1 digest of the content in the form WARC uses.
+func Digest(content []byte) string {
+	sum := sha1.Sum(content) //nolint:gosec // See the import.
+	return "sha1:" + base32.StdEncoding.EncodeToString(sum[:])
+}
+
+// newRecordID returns a random UUID URN.
+func newRecordID() (string, error) {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		return "", err
+	}
+	u[6] = u[6]&0x0f | 0x40 // Version 4.
+	u[8] = u[8]&0x3f | 0x80 // RFC 4122 variant.
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16]), nil
+}
@@ -0,0 +1,50 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package warc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+)
+
+func TestWriteRecord(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	be.Err(t, w.WriteInfo("Betula"), nil)
+	be.Err(t, w.WriteRecord(Record{
+		Type:        TypeResource,
+		TargetURI:   "https://example.org/",
+		Date:        time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC),
+		ContentType: "text/html",
+		Content:     []byte("<p>Hi</p>"),
+	}), nil)
+
+	// Every record is a gzip member, and they read as one stream.
+	r, err := gzip.NewReader(&buf)
+	be.Err(t, err, nil)
+	data, err := io.ReadAll(r)
+	be.Err(t, err, nil)
+
+	records := strings.Split(strings.TrimSuffix(string(data), "\r\n\r\n"), "\r\n\r\nWARC/1.1\r\n")
+	be.Equal(t, len(records), 2)
+	be.True(t, strings.HasPrefix(records[0], "WARC/1.1\r\nWARC-Type: warcinfo\r\n"))
+
+	resource := records[1]
+	be.True(t, strings.HasPrefix(resource, "WARC-Type: resource\r\nWARC-Record-ID: <urn:uuid:"))
+	be.True(t, strings.Contains(resource, "WARC-Date: 2026-03-04T05:06:07Z\r\n"))
+	be.True(t, strings.Contains(resource, "WARC-Target-URI: https://example.org/\r\n"))
+	be.True(t, strings.Contains(resource, "WARC-Block-Digest: "+Digest([]byte("<p>Hi</p>"))+"\r\n"))
+	be.True(t, strings.HasSuffix(resource, "Content-Length: 9\r\n\r\n<p>Hi</p>"))
+}
+
+func TestDigest(t *testing.T) {
+	be.Equal(t, Digest([]byte("")), "sha1:3I42H3S6NNFQ2MSVX7XZKYAYSCX5QBYJ")
+}
@@ -65,13 +65,28 @@ type (
 		// KeyPemByID returns the public key PEM for the given key ID, or an
 		// empty string if there is none.
 		KeyPemByID(ctx context.Context, keyID string) (string, error)
+		// KeyOwnerByID returns the ID of the actor the key with the given ID
+		// belongs to, or an empty string if there is no such key.
+		KeyOwnerByID(ctx context.Context, keyID string) (string, error)
 
-		AddFollower(ctx context.Context, id string) error
+		// AddFollower adds the actor to the followers. added is false if
+		// they were a follower already.
+		AddFollower(ctx context.Context, id string) (added bool, err error)
 		RemoveFollower(ctx context.Context, id string) error
 		AddPendingFollowing(ctx context.Context, id string) error
 		MarkAsSurelyFollowing(ctx context.Context, id string) error
 		StopFollowing(ctx context.Context, id string) error
 
+		// AddFollowRequest remembers a Follow activity from the actor that
+		// waits for approval. A newer request replaces the older one.
+		AddFollowRequest(ctx context.Context, actorID string, activity Dict) error
+		// FollowRequest returns the pending Follow activity from the actor,
+		// or sql.ErrNoRows if there is none.
+		FollowRequest(ctx context.Context, actorID string) (Dict, error)
+		RemoveFollowRequest(ctx context.Context, actorID string) error
+		// GetFollowRequests returns actors that asked to follow us, oldest first.
+		GetFollowRequests(context.Context) ([]types.Actor, error)
+
 		CountFollowing(context.Context) (uint, error)
 		CountFollowers(context.Context) (uint, error)
 
@@ -97,5 +112,7 @@ type (
 		UpdateNote(bookmark types.Bookmark) (json.RawMessage, error)
 		UpdateNoteWithLikes(bookmark types.Bookmark, likeCounter int) (json.RawMessage, error)
 		NoteFromBookmark(bookmark types.Bookmark) (Dict, error)
+		Outbox(totalItems uint) (json.RawMessage, error)
+		OutboxPage(page, totalItems uint, bookmarks []types.Bookmark) (json.RawMessage, error)
 	}
 )
@@ -4,10 +4,92 @@
 
 package archivingports
 
-import "git.sr.ht/~bouncepaw/betula/types"
+import (
+	"errors"
+	"io"
+
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+var (
+	ErrNoArchive     = errors.New("archivingports: no such archive")
+	ErrQuotaExceeded = errors.New("archivingports: archive quota exceeded")
+)
+
+// Quota limits how much space archives may take.
+type Quota struct {
+	// Size is the limit in bytes. 0 means no limit.
+	Size int64
+	// Evict makes room for new archives by deleting the oldest ones.
+	// Otherwise, new archives are refused with ErrQuotaExceeded.
+	Evict bool
+}
+
+// Usage is how much space archives take.
+type Usage struct {
+	// Stored is the size of all artifacts. An artifact shared by several
+	// archives counts once.
+	Stored int64
+	// Quoted is the part of Stored the quota counts, see TotalSize.
+	Quoted int64
+	// Archived is how much the archives would take if they did not
+	// share artifacts.
+	Archived  int64
+	Archives  int
+	Artifacts int
+	// Garbage is how much the next garbage collection would free.
+	Garbage    int64
+	ByMimeType []MimeTypeUsage
+	// ByBookmark lists the bookmarks with the biggest archives.
+	ByBookmark []BookmarkUsage
+}
+
+// Saved is how much space sharing artifacts saves.
+func (u Usage) Saved() int64 {
+	return u.Archived - u.Stored
+}
+
+type MimeTypeUsage struct {
+	MimeType  string
+	Artifacts int
+	Size      int64
+}
+
+type BookmarkUsage struct {
+	BookmarkID int
+	Title      string
+	Archives   int
+	Size       int64
+}
+
+// Collected is what garbage collection removed.
+type Collected struct {
+	Archives  int
+	Artifacts int
+	Freed     int64
+}
 
 type Service interface {
-	Archive(types.Bookmark) (int64, error)
+	// Archive makes a new archive of the given kind for the bookmark.
+	// ArchiveAuto picks the kind by the document's MIME type.
+	Archive(types.Bookmark, types.ArchiveKind) (int64, error)
+	// WriteWARC writes the archives into w as a .warc.gz file. If archiveID
+	// is not 0, only that archive is written, and ErrNoArchive is returned
+	// before writing anything if there is no such archive.
+	WriteWARC(w io.Writer, archiveID int64) error
+	Usage() (Usage, error)
+	// CollectGarbage removes archives and images of deleted bookmarks and
+	// artifacts that nothing refers to, then compacts the database file.
+	CollectGarbage() (Collected, error)
+	// SaveImages downloads and stores the bookmark's site icon and preview
+	// image. Empty addresses are skipped. Documents that are not pictures
+	// are refused.
+	SaveImages(bookmarkID int64, iconURL, previewURL string) error
+	// FillIcons sets IconID of the bookmarks that have a site icon.
+	FillIcons([]types.RenderedLocalBookmark) error
+	// IndexArchives extracts the text of at most limit archives that were
+	// made before search in archives existed. Returns how many were indexed.
+	IndexArchives(limit int) (int, error)
 }
 
 // Fetcher fetches documents.
@@ -17,6 +99,12 @@ type Fetcher interface {
 	Fetch(url string) ([]byte, string, error)
 }
 
+// Prober finds out what a document is without downloading it.
+type Prober interface {
+	// ContentType returns the MIME type of the document identified by URL.
+	ContentType(url string) (string, error)
+}
+
 type ArtifactsRepo interface {
 	Fetch(string) (*types.Artifact, error)
 }
@@ -27,4 +115,48 @@ type ArchivesRepo interface {
 	Store(bookmarkID int64, artifact *types.Artifact) (int64, error)
 	FetchForBookmark(bookmarkID int64) ([]types.Archive, error)
 	DeleteArchive(archiveID int64) error
+	// BookmarksWithoutArchives returns the bookmarks that have no archive
+	// yet, oldest first. Only ID and URL are set.
+	BookmarksWithoutArchives() ([]types.Bookmark, error)
+
+	// SetArchivesPublic sets whether the bookmark's archives are shown
+	// to visitors.
+	SetArchivesPublic(bookmarkID int64, public bool) error
+	// ArchivesPublic is true if the bookmark's archives are shown to visitors.
+	// The bookmark's own visibility is not taken into account.
+	ArchivesPublic(bookmarkID int64) (bool, error)
+	// ArtifactPublic is true if the artifact belongs to a public archive of
+	// a bookmark that is not private.
+	ArtifactPublic(artifactID string) (bool, error)
+	// EachArchivedPage calls fn for every archive with its data, oldest
+	// first. If archiveID is not 0, only that archive is passed. No query
+	// is open while fn runs, so it can take its time and use the database.
+	EachArchivedPage(archiveID int64, fn func(types.ArchivedPage) error) error
+
+	Usage() (Usage, error)
+	// TotalSize is the size of the artifacts of archives. Bookmark images
+	// and garbage are not counted, evicting archives does not free them.
+	TotalSize() (int64, error)
+	// OldestArchive returns the ID of the oldest archive, or sql.ErrNoRows
+	// if there are no archives.
+	OldestArchive() (int64, error)
+	// CollectGarbage removes archives and images of deleted bookmarks and
+	// artifacts that nothing refers to.
+	CollectGarbage() (Collected, error)
+	// Vacuum compacts the database file, giving the free space back
+	// to the system. It takes a while for big databases.
+	Vacuum() error
+
+	// StoreText stores the archive's plain text for search.
+	StoreText(archiveID int64, text string) error
+	// ArchivesWithoutText returns the IDs of at most limit archives that
+	// have no text stored yet. Archives of deleted bookmarks are skipped.
+	ArchivesWithoutText(limit int) ([]int64, error)
+
+	// StoreImage stores the bookmark's image of the given kind, replacing
+	// the previous one.
+	StoreImage(bookmarkID int64, kind types.ImageKind, artifact *types.Artifact) error
+	// ImagesOf returns the images of the given bookmarks. Bookmarks without
+	// images are not in the map.
+	ImagesOf(bookmarkIDs []int) (map[int]types.BookmarkImages, error)
 }
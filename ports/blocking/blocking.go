@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package blockingports
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrBadTarget = errors.New("blockingports: neither an actor ID nor a domain")
+
+// Block stops an actor or everyone on a domain from interacting with us.
+type Block struct {
+	// Target is an actor ID if Domain is false, a domain otherwise.
+	// A domain block covers its subdomains too.
+	Target    string
+	Domain    bool
+	Reason    string
+	CreatedAt time.Time
+}
+
+type Repository interface {
+	// AddBlock adds the block, replacing the one with the same target.
+	AddBlock(ctx context.Context, block Block) error
+	RemoveBlock(ctx context.Context, target string) error
+	// Blocks returns all blocks, newest first.
+	Blocks(ctx context.Context) ([]Block, error)
+	// IsBlocked tells if the actor is blocked, on its own or by domain.
+	IsBlocked(ctx context.Context, actorID string) (bool, error)
+	// DeleteTracesOf deletes likes, remarks and notifications by the blocked.
+	DeleteTracesOf(ctx context.Context, block Block) error
+}
+
+type Service interface {
+	// Block blocks the target, which is an actor ID or a domain. Followers
+	// that fall under the block are removed and sent a Reject.
+	Block(ctx context.Context, target, reason string) error
+	Unblock(ctx context.Context, target string) error
+	Blocks(ctx context.Context) ([]Block, error)
+	IsBlocked(ctx context.Context, actorID string) (bool, error)
+}
@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package duplicatesports
+
+import (
+	"context"
+	"errors"
+
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+// ErrBadMerge is returned when there is nothing to merge, or the survivor
+// is not among the merged bookmarks, or some of them do not exist.
+var ErrBadMerge = errors.New("duplicatesports: bad merge")
+
+// Candidate is a bookmark with its canonical URL.
+type Candidate struct {
+	types.Bookmark
+	CanonicalURL string
+}
+
+// Group is a set of bookmarks that are likely the same, oldest first.
+type Group struct {
+	// Exact is true if all the bookmarks have the same canonical URL.
+	// Otherwise, they share the host and the path only.
+	Exact     bool
+	Bookmarks []types.Bookmark
+}
+
+// MergeResult tells what a merge did.
+type MergeResult struct {
+	// Survivor is the bookmark after the merge, tags included.
+	Survivor types.Bookmark
+	// Removed are the merged bookmarks as they were before the merge.
+	Removed []types.Bookmark
+}
+
+type Repository interface {
+	// Candidates returns all non-deleted bookmarks, oldest first.
+	// Tags are not populated.
+	Candidates(ctx context.Context) ([]Candidate, error)
+	// BookmarksByIDs returns the non-deleted bookmarks with the given IDs,
+	// tags included, oldest first.
+	BookmarksByIDs(ctx context.Context, ids []int) ([]types.Bookmark, error)
+	// Merge sets the survivor's title, description, creation time and tags,
+	// moves archives, images, likes and reposts of the removed bookmarks to
+	// the survivor, and deletes the removed bookmarks. All in one transaction.
+	Merge(ctx context.Context, survivor types.Bookmark, removedIDs []int) error
+}
+
+type Service interface {
+	// Groups returns the groups of likely duplicate bookmarks.
+	Groups(ctx context.Context) ([]Group, error)
+	// Merge merges the bookmarks with the given IDs into the survivor.
+	// The description is taken from the bookmark descriptionFrom, or,
+	// if it is 0, the descriptions of all bookmarks are concatenated.
+	Merge(ctx context.Context, survivorID int, ids []int, descriptionFrom int) (MergeResult, error)
+}
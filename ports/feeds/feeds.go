@@ -4,9 +4,87 @@
 
 package feedsports
 
-import "git.sr.ht/~bouncepaw/betula/pkg/rss"
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	likingports "git.sr.ht/~bouncepaw/betula/ports/liking"
+)
+
+// ErrEmptyQuery is returned when a search feed is asked for without a query.
+var ErrEmptyQuery = errors.New("feedsports: empty search query")
+
+// Format is how a feed is written.
+type Format string
+
+const (
+	FormatRSS  Format = "rss"
+	FormatAtom Format = "atom"
+	FormatJSON Format = "json"
+)
+
+// ContentType is the MIME type of feeds in the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatAtom:
+		return "application/atom+xml; charset=utf-8"
+	case FormatJSON:
+		return "application/feed+json; charset=utf-8"
+	default:
+		return "application/rss+xml; charset=utf-8"
+	}
+}
+
+// Feed is a feed regardless of the format.
+type Feed struct {
+	Title       string
+	Description string
+	// Link is the page the feed is about.
+	Link string
+	// Self is the address of the feed itself. It is set by the caller,
+	// because the service does not know where the feed is served.
+	Self   string
+	Author string
+	// Updated is when the newest item was published. It is zero if there
+	// are no items.
+	Updated time.Time
+	// Modified is when the items last changed: a bookmark in the feed was
+	// saved or edited, or any bookmark was deleted. It is zero if unknown.
+	Modified time.Time
+	Items    []Item
+}
+
+type Item struct {
+	// ID is the permalink of the item, it never changes.
+	ID    string
+	Title string
+	// Link is where the item leads, like the bookmarked page.
+	Link        string
+	ContentHTML string
+	Categories  []string
+	Published   time.Time
+}
+
+type Repository interface {
+	likingports.LocalBookmarkRepository
+	// LastChange returns when the bookmarks with the ids were last saved
+	// or edited, or when any bookmark was last deleted, whichever is later.
+	LastChange(ctx context.Context, ids []int) (time.Time, error)
+}
 
 type Service interface {
-	DigestFeed() (*rss.Feed, error)
-	BookmarksFeed() (*rss.Feed, error)
+	// DigestFeed has an item for each of the last days with all public
+	// bookmarks saved that day.
+	DigestFeed() (Feed, error)
+	// BookmarksFeed has the public bookmarks of the last days.
+	BookmarksFeed() (Feed, error)
+	// TagFeed has the latest public bookmarks with the tag.
+	TagFeed(tagName string) (Feed, error)
+	// SearchFeed has the latest public bookmarks found by the query.
+	// Returns ErrEmptyQuery if the query is empty.
+	SearchFeed(query string) (Feed, error)
+	// Write writes the feed in the format.
+	Write(w io.Writer, feed Feed, format Format) error
 }
@@ -15,7 +15,7 @@ type (
 	Service interface {
 		// Import returns errors.ErrUnsupported if no importer supports the format.
 		Import(context.Context, ImportParams, io.ReadSeeker) (uint, error)
-		// Export returns
+		// Export returns an error if the format is unknown.
 		Export(context.Context, ExportParams, io.Writer) error
 	}
 
@@ -23,10 +23,19 @@ type (
 		AddTags       []string
 		KeepDuplicate bool
 		MakePublic    bool
+		// RestoreSettings applies the site settings from a Betula archive.
+		// Other formats have no settings.
+		RestoreSettings bool
+		// RestoreKey replaces the actor's private key with the one from
+		// a Betula archive, if it has one.
+		RestoreKey bool
 	}
 	ExportParams struct {
 		IncludePrivate bool
 		Format         ExportFormat
+		// IncludeKey puts the actor's private key in a Betula archive.
+		// Other formats have no place for it.
+		IncludeKey bool
 	}
 	ExportFormat string
 )
@@ -35,6 +44,10 @@ const (
 	ExportFormatNetscape ExportFormat = "netscape"
 	ExportFormatPinboard ExportFormat = "pinboard"
 	ExportFormatRaindrop ExportFormat = "raindrop"
+	// ExportFormatBetula is the native format. Unlike the others, it keeps
+	// everything Betula knows: remarks, tag descriptions, archives, likes,
+	// followers and settings. See package pkg/imex/betula.
+	ExportFormatBetula ExportFormat = "betula"
 )
 
 func (f ExportFormat) FileExtension() string {
@@ -45,6 +58,8 @@ func (f ExportFormat) FileExtension() string {
 		return "json"
 	case ExportFormatRaindrop:
 		return "csv"
+	case ExportFormatBetula:
+		return "zip"
 	}
 	return ""
 }
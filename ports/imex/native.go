@@ -0,0 +1,161 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package imexports
+
+import (
+	"context"
+	"encoding/json"
+
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+// The records of the native format, see package pkg/imex/betula for the
+// container. Times are in types.TimeLayout, like in the database.
+type (
+	NativeBookmark struct {
+		ID             int              `json:"id"`
+		CreationTime   string           `json:"creationTime"`
+		URL            string           `json:"url"`
+		Title          string           `json:"title"`
+		Description    string           `json:"description"`
+		Visibility     types.Visibility `json:"visibility"`
+		Tags           []string         `json:"tags,omitempty"`
+		RemarkedID     *string          `json:"remarkedID,omitempty"`
+		RemarkText     *string          `json:"remarkText,omitempty"`
+		OriginalAuthor *string          `json:"originalAuthor,omitempty"`
+		ArchivesPublic bool             `json:"archivesPublic,omitempty"`
+	}
+
+	NativeTag struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+
+	// NativeArtifact is the metadata of an artifact. The data is stored
+	// next to the records.
+	NativeArtifact struct {
+		ID        string            `json:"id"`
+		MimeType  string            `json:"mimeType"`
+		IsGzipped bool              `json:"isGzipped"`
+		Kind      types.ArchiveKind `json:"kind"`
+	}
+
+	NativeArchive struct {
+		BookmarkID int    `json:"bookmarkID"`
+		ArtifactID string `json:"artifactID"`
+		SavedAt    string `json:"savedAt"`
+	}
+
+	NativeImage struct {
+		BookmarkID int             `json:"bookmarkID"`
+		Kind       types.ImageKind `json:"kind"`
+		ArtifactID string          `json:"artifactID"`
+	}
+
+	// NativeLike is a like of ours if ActorID is nil. Otherwise, it is
+	// someone's like of our bookmark, and ObjectID is the bookmark's ID.
+	NativeLike struct {
+		ID         *string         `json:"id,omitempty"`
+		ActorID    *string         `json:"actorID,omitempty"`
+		ObjectID   string          `json:"objectID"`
+		SavedAt    string          `json:"savedAt"`
+		SourceJSON json.RawMessage `json:"source,omitempty"`
+	}
+
+	// NativeActor is a known actor with its public key.
+	NativeActor struct {
+		ID                string `json:"id"`
+		PreferredUsername string `json:"preferredUsername"`
+		Inbox             string `json:"inbox"`
+		SharedInbox       string `json:"sharedInbox,omitempty"`
+		DisplayedName     string `json:"name"`
+		Summary           string `json:"summary"`
+		Domain            string `json:"domain"`
+		PublicKeyID       string `json:"publicKeyID,omitempty"`
+		PublicKeyPEM      string `json:"publicKeyPem,omitempty"`
+	}
+
+	// NativeFollow is a follower or a followed account.
+	NativeFollow struct {
+		ActorID      string `json:"actorID"`
+		SubscribedAt string `json:"subscribedAt"`
+		// Accepted is only meaningful for the accounts we follow.
+		Accepted bool `json:"accepted,omitempty"`
+	}
+
+	NativeBlock struct {
+		Target    string `json:"target"`
+		Domain    bool   `json:"domain,omitempty"`
+		Reason    string `json:"reason,omitempty"`
+		CreatedAt string `json:"createdAt"`
+	}
+
+	// NativeSettings are the site settings. The network address is not
+	// included, it belongs to the machine, not to the site.
+	NativeSettings struct {
+		SiteName                  string `json:"siteName"`
+		SiteTitle                 string `json:"siteTitle"`
+		SiteDescriptionMycomarkup string `json:"siteDescription"`
+		SiteURL                   string `json:"siteURL"`
+		CustomCSS                 string `json:"customCSS"`
+		FederationEnabled         bool   `json:"federationEnabled"`
+		PublicCustomJS            string `json:"publicCustomJS"`
+		PrivateCustomJS           string `json:"privateCustomJS"`
+		BackfillPages             uint   `json:"backfillPages"`
+		ManuallyApproveFollowers  bool   `json:"manuallyApproveFollowers"`
+		AutoArchive               bool   `json:"autoArchive"`
+		AutoArchiveTags           string `json:"autoArchiveTags"`
+		AutoArchiveSharedOnly     bool   `json:"autoArchiveSharedOnly"`
+		ArchiveQuota              uint   `json:"archiveQuota"`
+		ArchiveQuotaEvict         bool   `json:"archiveQuotaEvict"`
+		TrackingParams            string `json:"trackingParams"`
+	}
+
+	// NativeKey is the private key of the actor. The public key is made
+	// from it.
+	NativeKey struct {
+		PrivateKeyPEM string `json:"privateKeyPem"`
+	}
+)
+
+// NativeRepository reads and writes everything the native format carries.
+//
+// The restoring methods keep what is in the database already: a tag
+// description, an actor, a follow or a block of the same key is left as is.
+type NativeRepository interface {
+	// NativeBookmarks returns all bookmarks, except the deleted ones, with
+	// their tags, oldest first. Private bookmarks are included if asked.
+	NativeBookmarks(ctx context.Context, includePrivate bool) ([]NativeBookmark, error)
+	TagDescriptions(ctx context.Context) ([]NativeTag, error)
+	Archives(ctx context.Context) ([]NativeArchive, error)
+	Images(ctx context.Context) ([]NativeImage, error)
+	// Artifact returns the artifact and its data.
+	Artifact(ctx context.Context, id string) (NativeArtifact, []byte, error)
+	Likes(ctx context.Context) ([]NativeLike, error)
+	Actors(ctx context.Context) ([]NativeActor, error)
+	Followers(ctx context.Context) ([]NativeFollow, error)
+	Following(ctx context.Context) ([]NativeFollow, error)
+	Blocks(ctx context.Context) ([]NativeBlock, error)
+	// PrivateKey returns the PEM of the actor's private key, empty if
+	// there is none yet.
+	PrivateKey(ctx context.Context) (string, error)
+
+	// RestoreBookmark inserts the bookmark with its tags. It keeps the
+	// bookmark's ID if it is free, otherwise a new one is given. Returns
+	// the ID the bookmark got.
+	RestoreBookmark(ctx context.Context, bm NativeBookmark) (int, error)
+	RestoreTagDescription(ctx context.Context, tag NativeTag) error
+	// RestoreArtifact does nothing if there is an artifact with the same ID.
+	RestoreArtifact(ctx context.Context, artifact NativeArtifact, data []byte) error
+	RestoreArchive(ctx context.Context, archive NativeArchive) error
+	RestoreImage(ctx context.Context, image NativeImage) error
+	RestoreLike(ctx context.Context, like NativeLike) error
+	RestoreActor(ctx context.Context, actor NativeActor) error
+	RestoreFollower(ctx context.Context, follow NativeFollow) error
+	RestoreFollowing(ctx context.Context, follow NativeFollow) error
+	RestoreBlock(ctx context.Context, block NativeBlock) error
+	// RestorePrivateKey replaces the actor's private key.
+	RestorePrivateKey(ctx context.Context, pem string) error
+}
@@ -6,16 +6,32 @@ package jobsports
 
 import (
 	"context"
+	"time"
 
 	"git.sr.ht/~bouncepaw/betula/jobs/jobtype"
 )
 
 type Repository interface {
 	// PlanJob puts a new job into the database and returns the id of the new job.
+	// The job is due immediately, unless its Due is set.
 	PlanJob(ctx context.Context, job jobtype.Job) (int64, error)
 	// DropJob removes the job specified by id from the database.
 	// Call after the job is done.
 	DropJob(ctx context.Context, id int64) error
-	// LoadAllJobs reads all jobs in the database. Call on startup once.
-	LoadAllJobs(ctx context.Context) ([]jobtype.Job, error)
+	// LoadDueJobs reads the jobs that are not failed and whose time has come,
+	// oldest first.
+	LoadDueJobs(ctx context.Context) ([]jobtype.Job, error)
+	// PostponeJob counts a failed attempt of the job and makes it due
+	// after the given delay.
+	PostponeJob(ctx context.Context, id int64, delay time.Duration, lastError string) error
+	// DelayJob makes the job due after the given delay. It is not counted
+	// as an attempt.
+	DelayJob(ctx context.Context, id int64, delay time.Duration) error
+	// FailJob counts a failed attempt of the job and parks it as failed.
+	FailJob(ctx context.Context, id int64, lastError string) error
+	// RetryJob makes a failed job pending again with zero attempts. It is due
+	// immediately.
+	RetryJob(ctx context.Context, id int64) error
+	// Jobs returns all jobs, pending and failed, in the order they are due.
+	Jobs(ctx context.Context) ([]jobtype.Job, error)
 }
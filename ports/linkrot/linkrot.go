@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package linkrotports
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+// LinkCheck is the result of the last check of a bookmark's URL.
+type LinkCheck struct {
+	BookmarkID int
+	// URL and Title are the bookmark's current ones.
+	URL   string
+	Title string
+	// Status is the HTTP status of the response, 0 if there was none.
+	Status int
+	// Error is set if no response was received, like when the domain is gone.
+	Error string
+	// FinalURL is where the URL led after redirects.
+	FinalURL  string
+	CheckedAt time.Time
+	// ArtifactID is the artifact of the latest archive of the bookmark,
+	// empty if there is none.
+	ArtifactID string
+}
+
+// Dead is true if the page is gone or the server is failing.
+func (c LinkCheck) Dead() bool {
+	return c.Error != "" ||
+		c.Status == http.StatusNotFound ||
+		c.Status == http.StatusGone ||
+		c.Status >= http.StatusInternalServerError
+}
+
+// Redirected is true if the page is alive, but lives somewhere else now.
+// A trailing slash added or removed does not count.
+func (c LinkCheck) Redirected() bool {
+	return !c.Dead() && c.FinalURL != "" &&
+		strings.TrimSuffix(c.FinalURL, "/") != strings.TrimSuffix(c.URL, "/")
+}
+
+type Repository interface {
+	// StaleBookmarks returns up to limit bookmarks whose URLs were not
+	// checked since checkedBefore, the never checked ones first. Only ID
+	// and URL are set.
+	StaleBookmarks(ctx context.Context, checkedBefore time.Time, limit int) ([]types.Bookmark, error)
+	// StoreCheck stores the check, replacing the previous one for the bookmark.
+	StoreCheck(ctx context.Context, check LinkCheck) error
+	// Checks returns the last checks of all bookmarks, newest bookmarks first.
+	Checks(ctx context.Context) ([]LinkCheck, error)
+}
+
+type Service interface {
+	// CheckStale checks up to limit bookmark URLs that were not checked
+	// recently. Returns how many were checked.
+	CheckStale(ctx context.Context, limit int) (int, error)
+	// Problems returns the checks of dead and redirected links.
+	Problems(ctx context.Context) ([]LinkCheck, error)
+}
@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package searchingports
+
+import (
+	"time"
+
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+// Expr is a node of a parsed search query. The nodes are listed below.
+// A nil Expr matches every bookmark.
+type Expr interface {
+	isExpr()
+}
+
+type (
+	// And matches bookmarks that match all of its operands.
+	And []Expr
+
+	// Or matches bookmarks that match any of its operands.
+	Or []Expr
+
+	// Not matches bookmarks that do not match Expr.
+	Not struct {
+		Expr Expr
+	}
+
+	// Text matches bookmarks that contain Text in Field. Matching is
+	// case-insensitive. If Phrase is set, Text came in quotes.
+	Text struct {
+		Field  Field
+		Text   string
+		Phrase bool
+	}
+
+	// Tag matches bookmarks tagged with the tag. The name is canonical.
+	Tag string
+
+	// Site matches bookmarks whose URL host is the given host or
+	// a subdomain of it.
+	Site string
+
+	// Before matches bookmarks created before the given day.
+	Before time.Time
+
+	// After matches bookmarks created after the given day.
+	After time.Time
+
+	// Is matches bookmarks of the given visibility.
+	Is types.Visibility
+
+	// Has matches bookmarks with the given feature.
+	Has Feature
+
+	// Remark matches remarks. If URL is not empty, only remarks of the
+	// bookmark with this ID or of bookmarks by the author with this ID
+	// are matched.
+	Remark struct {
+		URL string
+	}
+)
+
+// Field is the part of a bookmark a Text is looked for in.
+type Field int
+
+const (
+	// FieldAny is title, description, URL or remark text.
+	FieldAny Field = iota
+	FieldTitle
+	FieldURL
+	// FieldArchive is the text of the bookmark's archives.
+	FieldArchive
+)
+
+// Feature is something a bookmark might have or not.
+type Feature int
+
+const (
+	FeatureArchive Feature = iota
+	FeatureDescription
+)
+
+func (And) isExpr()    {}
+func (Or) isExpr()     {}
+func (Not) isExpr()    {}
+func (Text) isExpr()   {}
+func (Tag) isExpr()    {}
+func (Site) isExpr()   {}
+func (Before) isExpr() {}
+func (After) isExpr()  {}
+func (Is) isExpr()     {}
+func (Has) isExpr()    {}
+func (Remark) isExpr() {}
@@ -13,15 +13,10 @@ import (
 type (
 	// Query describes a page-based search over local bookmarks.
 	Query struct {
-		// Text is the free-text part of the query.
-		Text string
-		// IncludedTags and ExcludedTags filter results by tag.
-		IncludedTags []string
-		ExcludedTags []string
-		// RemarksOnly keeps only remarks when set.
-		RemarksOnly bool
-		// Authorized controls visibility: private bookmarks are included only
-		// when set.
+		// Expr is the parsed query. Nil matches everything.
+		Expr Expr
+		// Authorized controls visibility: private bookmarks, and archives
+		// not shown to visitors, are searched only when set.
 		Authorized bool
 		// Page is 1-based.
 		Page uint
@@ -30,11 +25,8 @@ type (
 	// OffsetQuery describes an offset/limit search over public bookmarks, used
 	// for federated search.
 	OffsetQuery struct {
-		// Text is the free-text part of the query.
-		Text string
-		// IncludedTags and ExcludedTags filter results by tag.
-		IncludedTags []string
-		ExcludedTags []string
+		// Expr is the parsed query. Nil matches everything.
+		Expr Expr
 		// Offset and Limit paginate the results.
 		Offset uint
 		Limit  uint
@@ -46,6 +38,10 @@ type Repository interface {
 	Search(ctx context.Context, query Query) (bookmarksInPage []types.Bookmark, totalBookmarks uint, err error)
 	// SearchOffset runs an offset/limit search over public bookmarks.
 	SearchOffset(ctx context.Context, query OffsetQuery) (bookmarks []types.Bookmark, totalBookmarks uint, err error)
+	// ArchiveMatches finds the latest archive matching the query's archive
+	// text for each of the bookmarks. Bookmarks without such an archive
+	// are not in the map.
+	ArchiveMatches(ctx context.Context, query Query, bookmarkIDs []int) (map[int]types.ArchiveMatch, error)
 }
 
 type Service interface {
@@ -55,4 +51,9 @@ type Service interface {
 
 	// ForFederated runs a federated search with offset/limit pagination.
 	ForFederated(query string, offset, limit uint) (bookmarks []types.Bookmark, totalBookmarks uint)
+
+	// ArchiveMatches returns the archives of the found bookmarks that match
+	// the query, keyed by bookmark ID. It is empty unless the query looks
+	// in archives.
+	ArchiveMatches(query string, authorized bool, bookmarks []types.Bookmark) map[int]types.ArchiveMatch
 }
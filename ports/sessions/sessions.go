@@ -16,4 +16,13 @@ type Repository interface {
 	StopSession(ctx context.Context, token string) error
 	StopAllSessions(ctx context.Context, excludeToken string) error
 	Sessions(ctx context.Context) ([]types.Session, error)
+
+	// AddAccessToken stores a new access token by the hash of its value.
+	AddAccessToken(ctx context.Context, name, tokenHash string, scopes []types.TokenScope) (int64, error)
+	// AccessTokenByHash returns the token with the hash and marks it used.
+	// Returns sql.ErrNoRows if there is no such token.
+	AccessTokenByHash(ctx context.Context, tokenHash string) (types.AccessToken, error)
+	// AccessTokens returns all access tokens, newest first.
+	AccessTokens(ctx context.Context) ([]types.AccessToken, error)
+	RevokeAccessToken(ctx context.Context, id int64) error
 }
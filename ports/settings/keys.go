@@ -25,6 +25,15 @@ const (
 	BetulaMetaEnableFederation  BetulaMetaKey = "Federation enabled"
 	BetulaMetaPublicCustomJS    BetulaMetaKey = "Public custom JS"
 	BetulaMetaPrivateCustomJS   BetulaMetaKey = "Private custom JS"
+	BetulaMetaBackfillPages     BetulaMetaKey = "Backfill pages"
+	BetulaMetaApproveFollowers  BetulaMetaKey = "Manually approve followers"
+	BetulaMetaTrackingParams    BetulaMetaKey = "Tracking parameters"
+
+	BetulaMetaAutoArchive           BetulaMetaKey = "Auto-archive / Enabled"
+	BetulaMetaAutoArchiveTags       BetulaMetaKey = "Auto-archive / Tags"
+	BetulaMetaAutoArchiveSharedOnly BetulaMetaKey = "Auto-archive / Shared only"
+	BetulaMetaArchiveQuota          BetulaMetaKey = "Archive quota / MiB"
+	BetulaMetaArchiveQuotaEvict     BetulaMetaKey = "Archive quota / Evict"
 
 	BetulaMetaLoggingMethod   BetulaMetaKey = "Logging / Method"
 	BetulaMetaLoggingURL      BetulaMetaKey = "Logging / URL"
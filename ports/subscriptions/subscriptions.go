@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package subscriptionsports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+var (
+	ErrAlreadySubscribed = errors.New("subscriptionsports: already subscribed to the feed")
+	// ErrNoFeedFound is returned when neither the address nor the page
+	// there links to a feed.
+	ErrNoFeedFound = errors.New("subscriptionsports: no feed found")
+)
+
+// Subscription is an external RSS or Atom feed.
+type Subscription struct {
+	ID int64
+	// URL is the address of the feed.
+	URL   string
+	Title string
+	// SiteURL is the site the feed is about.
+	SiteURL      string
+	CreationTime time.Time
+	// LastPolled is zero if the feed was never polled.
+	LastPolled time.Time
+	// LastError is why the last poll failed, empty if it did not.
+	LastError string
+	// Unread is how many suggestions from the feed are waiting.
+	Unread int
+}
+
+type SuggestionState string
+
+const (
+	SuggestionUnread    SuggestionState = "unread"
+	SuggestionSaved     SuggestionState = "saved"
+	SuggestionDismissed SuggestionState = "dismissed"
+)
+
+// Suggestion is a feed entry that might be worth bookmarking.
+type Suggestion struct {
+	ID             int64
+	SubscriptionID int64
+	// FeedTitle is the title of the subscription. It is set when reading.
+	FeedTitle string
+	// EntryID identifies the entry in its feed, so it is suggested once.
+	EntryID string
+	URL     string
+	Title   string
+	// Tags are the feed's categories of the entry.
+	Tags []types.Tag
+	// Published is zero if the feed does not say when.
+	Published time.Time
+	State     SuggestionState
+}
+
+type Repository interface {
+	// AddSubscription stores the subscription and returns its ID.
+	// Returns ErrAlreadySubscribed if there is one with the URL.
+	AddSubscription(ctx context.Context, sub Subscription) (int64, error)
+	// Subscriptions returns all subscriptions, sorted by title.
+	Subscriptions(ctx context.Context) ([]Subscription, error)
+	// RemoveSubscription removes the subscription with all its suggestions.
+	RemoveSubscription(ctx context.Context, id int64) error
+	// StorePoll records that the subscription was polled now. The title and
+	// the site address are updated if not empty. pollErr is empty on success.
+	StorePoll(ctx context.Context, id int64, title, siteURL, pollErr string) error
+	// AddSuggestions stores the suggestions that are new to their
+	// subscription and returns how many were new.
+	AddSuggestions(ctx context.Context, suggestions []Suggestion) (int, error)
+	// UnreadSuggestions returns the unread suggestions, newest first.
+	UnreadSuggestions(ctx context.Context) ([]Suggestion, error)
+	// SuggestionByID returns the suggestion or sql.ErrNoRows.
+	SuggestionByID(ctx context.Context, id int64) (Suggestion, error)
+	// SetSuggestionState changes the state of the suggestion.
+	SetSuggestionState(ctx context.Context, id int64, state SuggestionState) error
+	// DismissAll dismisses all unread suggestions.
+	DismissAll(ctx context.Context) error
+}
+
+type Service interface {
+	// Subscribe subscribes to the feed at the address. If there is a web
+	// page there, the feed it links to is subscribed to. The feed is
+	// polled right away.
+	Subscribe(ctx context.Context, addr string) (Subscription, error)
+	Unsubscribe(ctx context.Context, id int64) error
+	Subscriptions(ctx context.Context) ([]Subscription, error)
+	// PollAll polls the feeds that were not polled recently. If the
+	// results of one poll are not stored, the rest are still polled.
+	// Returns how many new suggestions there are.
+	PollAll(ctx context.Context) (int, error)
+
+	// Suggestions returns the unread suggestions, newest first.
+	Suggestions(ctx context.Context) ([]Suggestion, error)
+	Suggestion(ctx context.Context, id int64) (Suggestion, error)
+	// MarkSaved removes the suggestion from the inbox, because it was saved.
+	MarkSaved(ctx context.Context, id int64) error
+	// Dismiss removes the suggestion from the inbox without saving.
+	Dismiss(ctx context.Context, id int64) error
+	// DismissAll empties the inbox.
+	DismissAll(ctx context.Context) error
+}
@@ -0,0 +1,202 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package webhooksports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+var (
+	// ErrBadWebhook is returned when a webhook has no web address or no events.
+	ErrBadWebhook = errors.New("webhooksports: a webhook needs an http or https address and at least one event")
+	// ErrRejected is returned when the webhook answered with a client error.
+	// Delivering the same payload again will not help.
+	ErrRejected = errors.New("webhooksports: delivery rejected")
+)
+
+// Event is what happened. The values are sent to webhooks, do not change them.
+type Event string
+
+const (
+	EventBookmarkCreated Event = "bookmark.created"
+	EventBookmarkEdited  Event = "bookmark.edited"
+	EventBookmarkDeleted Event = "bookmark.deleted"
+	EventTagRenamed      Event = "tag.renamed"
+	EventLikeReceived    Event = "like.received"
+	EventRemarkReceived  Event = "remark.received"
+	EventFollowerGained  Event = "follower.gained"
+)
+
+// Events are all events, in the order they are shown.
+var Events = []Event{
+	EventBookmarkCreated,
+	EventBookmarkEdited,
+	EventBookmarkDeleted,
+	EventTagRenamed,
+	EventLikeReceived,
+	EventRemarkReceived,
+	EventFollowerGained,
+}
+
+// Webhook is an address Betula posts events to.
+type Webhook struct {
+	ID   int64
+	Name string
+	URL  string
+	// Secret signs the payloads, see the help page.
+	Secret string
+	Events []Event
+	// IncludePrivate is true if the events of private bookmarks are sent
+	// too. They are not by default, the webhook might be a team chat.
+	IncludePrivate bool
+	CreationTime   time.Time
+}
+
+type DeliveryState string
+
+const (
+	// DeliveryPending deliveries are waiting for their first or next attempt.
+	DeliveryPending   DeliveryState = "pending"
+	DeliveryDelivered DeliveryState = "delivered"
+	// DeliveryFailed deliveries are not attempted anymore.
+	DeliveryFailed DeliveryState = "failed"
+)
+
+// Delivery is an event sent or to be sent to a webhook.
+type Delivery struct {
+	ID        int64
+	WebhookID int64
+	// WebhookName is set when reading.
+	WebhookName string
+	Event       Event
+	// Payload is the JSON body of the request.
+	Payload  []byte
+	State    DeliveryState
+	Attempts int
+	// LastStatus is the HTTP status of the last attempt, 0 if there was no answer.
+	LastStatus int
+	// LastError is why the last attempt failed, empty if it did not.
+	LastError    string
+	CreationTime time.Time
+	// LastAttempt is zero if there was no attempt yet.
+	LastAttempt time.Time
+}
+
+// Payload is sent to webhooks as JSON. Only the fields that make sense for
+// the event are set.
+type Payload struct {
+	Event Event     `json:"event"`
+	Time  time.Time `json:"time"`
+	// Bookmark is the created, edited, deleted, liked or remarked bookmark.
+	Bookmark *Bookmark `json:"bookmark,omitempty"`
+	// Tag is set for tag.renamed.
+	Tag *TagRename `json:"tag,omitempty"`
+	// ActorID is the fediverse actor who liked, remarked or followed.
+	ActorID string `json:"actor_id,omitempty"`
+	// RemarkURL is the address of the remark.
+	RemarkURL string `json:"remark_url,omitempty"`
+}
+
+// Bookmark has the fields of types.Bookmark.
+type Bookmark struct {
+	ID           int      `json:"id"`
+	URL          string   `json:"url"`
+	Title        string   `json:"title"`
+	Description  string   `json:"description"`
+	Visibility   string   `json:"visibility"`
+	Tags         []string `json:"tags"`
+	CreationTime string   `json:"creation_time"`
+	// Permalink is the address of the bookmark on this Betula.
+	Permalink string `json:"permalink"`
+	// RemarkedID is the address of the remarked bookmark, if this is a remark.
+	RemarkedID string `json:"remarked_id,omitempty"`
+	RemarkText string `json:"remark_text,omitempty"`
+	// OriginalAuthor is the ID of the author of the remarked bookmark.
+	OriginalAuthor string `json:"original_author,omitempty"`
+}
+
+type TagRename struct {
+	OldName string `json:"old_name"`
+	NewName string `json:"new_name"`
+}
+
+// BookmarkOf returns the payload version of the bookmark. Permalink is
+// filled in by the service.
+func BookmarkOf(bookmark types.Bookmark) *Bookmark {
+	tags := []string{}
+	for _, tag := range bookmark.Tags {
+		if tag.Name != "" {
+			tags = append(tags, tag.Name)
+		}
+	}
+	b := &Bookmark{
+		ID:             bookmark.ID,
+		URL:            bookmark.URL,
+		Title:          bookmark.Title,
+		Description:    bookmark.Description,
+		Visibility:     bookmark.Visibility.String(),
+		Tags:           tags,
+		CreationTime:   bookmark.CreationTime,
+		RemarkText:     bookmark.RemarkTextString(),
+		OriginalAuthor: bookmark.OriginalAuthor.String,
+	}
+	if bookmark.RemarkedID != nil {
+		b.RemarkedID = *bookmark.RemarkedID
+	}
+	return b
+}
+
+type Repository interface {
+	// AddWebhook stores the webhook and returns its ID.
+	AddWebhook(ctx context.Context, hook Webhook) (int64, error)
+	// Webhooks returns all webhooks, oldest first.
+	Webhooks(ctx context.Context) ([]Webhook, error)
+	// WebhookByID returns the webhook or sql.ErrNoRows.
+	WebhookByID(ctx context.Context, id int64) (Webhook, error)
+	// RemoveWebhook removes the webhook with its deliveries.
+	RemoveWebhook(ctx context.Context, id int64) error
+
+	// AddDelivery stores a pending delivery and returns its ID.
+	AddDelivery(ctx context.Context, webhookID int64, event Event, payload []byte) (int64, error)
+	// DeliveryByID returns the delivery or sql.ErrNoRows.
+	DeliveryByID(ctx context.Context, id int64) (Delivery, error)
+	// StoreAttempt records an attempt of the delivery and sets its state.
+	StoreAttempt(ctx context.Context, id int64, state DeliveryState, status int, attemptErr string) error
+	// SetDeliveryState changes the state of the delivery.
+	SetDeliveryState(ctx context.Context, id int64, state DeliveryState) error
+	// Deliveries returns up to limit latest deliveries, newest first.
+	Deliveries(ctx context.Context, limit int) ([]Delivery, error)
+
+	// BookmarkByID returns the bookmark with its tags or sql.ErrNoRows.
+	BookmarkByID(ctx context.Context, id int) (types.Bookmark, error)
+}
+
+type Service interface {
+	// AddWebhook adds a webhook for the events. If the secret is empty,
+	// a random one is made.
+	AddWebhook(ctx context.Context, name, url, secret string, events []Event, includePrivate bool) (Webhook, error)
+	Webhooks(ctx context.Context) ([]Webhook, error)
+	RemoveWebhook(ctx context.Context, id int64) error
+
+	// Fire schedules delivering the payload to the webhooks that want its
+	// event. Events of private bookmarks go only to the webhooks that
+	// include them. Failures are logged, not returned, because the event
+	// happened anyway.
+	Fire(ctx context.Context, payload Payload)
+	// FireForBookmark is Fire with the bookmark set to the one with the ID.
+	FireForBookmark(ctx context.Context, bookmarkID int, payload Payload)
+	// Deliver makes an attempt to deliver the delivery. If it fails and
+	// lastAttempt is true, the delivery is marked as failed.
+	// Returns ErrRejected if retrying will not help.
+	Deliver(ctx context.Context, id int64, lastAttempt bool) error
+	// Redeliver schedules the delivery again.
+	Redeliver(ctx context.Context, id int64) error
+	// Deliveries returns the latest deliveries, newest first.
+	Deliveries(ctx context.Context) ([]Delivery, error)
+}
@@ -8,6 +8,8 @@ import (
 	"errors"
 	"html/template"
 	"net/url"
+
+	"git.sr.ht/~bouncepaw/betula/pkg/feedparse"
 )
 
 var (
@@ -19,8 +21,40 @@ var (
 type WorldWideWeb interface {
 	// TitleOfPage returns <title> value for the given web page.
 	TitleOfPage(addr string) (string, error)
+	// MetadataOfPage reads what the web page says about itself: OpenGraph
+	// and Twitter Card properties, <meta> tags and <link> relations.
+	MetadataOfPage(addr string) (PageMetadata, error)
 	// RelAlternates returns all <link rel="alternate"> found on the web page.
 	RelAlternates(addr string) ([]RelAlternate, error)
+	// CheckLink requests the page and reports how the server answered.
+	// An error means there was no answer at all.
+	CheckLink(addr string) (LinkStatus, error)
+	// FetchFeed reads the RSS or Atom feed at the address. Returns
+	// feedparse.ErrNotFeed if there is something else there.
+	FetchFeed(addr string) (feedparse.Feed, error)
+}
+
+// PageMetadata is what a web page says about itself. Empty strings
+// stand for missing values. All addresses are absolute.
+type PageMetadata struct {
+	Title        string
+	Description  string
+	CanonicalURL string
+	Author       string
+	// Published is the publication date as the page has it, usually RFC 3339.
+	Published string
+	// IconURL is the address of the site icon. It is /favicon.ico
+	// of the site if the page does not name an icon.
+	IconURL string
+	// ImageURL is the address of the preview image.
+	ImageURL string
+}
+
+// LinkStatus is how a server answered a request for a page.
+type LinkStatus struct {
+	StatusCode int
+	// FinalURL is the address of the page after following redirects.
+	FinalURL string
 }
 
 type RelAlternate struct {
@@ -21,9 +21,11 @@ import (
 	"log/slog"
 	"net/url"
 	"os"
+	"strings"
 
 	"git.sr.ht/~bouncepaw/betula/pkg/bxstr"
 	"git.sr.ht/~bouncepaw/betula/pkg/myco"
+	"git.sr.ht/~bouncepaw/betula/ports/archiving"
 	"git.sr.ht/~bouncepaw/betula/ports/settings"
 
 	"git.sr.ht/~bouncepaw/betula/db"
@@ -155,8 +157,47 @@ func Index() {
 	} else {
 		cache.FederationEnabled = false
 	}
+
+	backfillPages := mustRead(settingsRepo.MetaEntryNullInt64(ctx, settingsports.BetulaMetaBackfillPages))
+	if backfillPages.Valid && backfillPages.Int64 >= 0 {
+		cache.BackfillPages = uint(backfillPages.Int64)
+	} else {
+		cache.BackfillPages = DefaultBackfillPages
+	}
+
+	approveFollowers := mustRead(settingsRepo.MetaEntryNullInt64(ctx, settingsports.BetulaMetaApproveFollowers))
+	cache.ManuallyApproveFollowers = approveFollowers.Valid && approveFollowers.Int64 != 0
+
+	autoArchive := mustRead(settingsRepo.MetaEntryNullInt64(ctx, settingsports.BetulaMetaAutoArchive))
+	cache.AutoArchive = autoArchive.Valid && autoArchive.Int64 != 0
+	cache.AutoArchiveTags = mustRead(settingsRepo.MetaEntryNullString(ctx, settingsports.BetulaMetaAutoArchiveTags)).String
+	autoArchiveSharedOnly := mustRead(settingsRepo.MetaEntryNullInt64(ctx, settingsports.BetulaMetaAutoArchiveSharedOnly))
+	cache.AutoArchiveSharedOnly = autoArchiveSharedOnly.Valid && autoArchiveSharedOnly.Int64 != 0
+
+	archiveQuota := mustRead(settingsRepo.MetaEntryNullInt64(ctx, settingsports.BetulaMetaArchiveQuota))
+	if archiveQuota.Valid && archiveQuota.Int64 > 0 {
+		cache.ArchiveQuota = uint(archiveQuota.Int64)
+	} else {
+		cache.ArchiveQuota = 0
+	}
+	archiveQuotaEvict := mustRead(settingsRepo.MetaEntryNullInt64(ctx, settingsports.BetulaMetaArchiveQuotaEvict))
+	cache.ArchiveQuotaEvict = archiveQuotaEvict.Valid && archiveQuotaEvict.Int64 != 0
+
+	trackingParams := mustRead(settingsRepo.MetaEntryNullString(ctx, settingsports.BetulaMetaTrackingParams))
+	if trackingParams.Valid {
+		cache.TrackingParams = trackingParams.String
+	} else {
+		cache.TrackingParams = strings.Join(bxstr.DefaultTrackingParams, ", ")
+	}
+	if err := db.SetTrackingParams(ctx, TrackingParams()); err != nil {
+		slog.Error("Failed to update canonical URLs", "err", err)
+	}
 }
 
+// DefaultBackfillPages is used when the admin has not set the number of
+// outbox pages to backfill.
+const DefaultBackfillPages = 2
+
 func AdminUsername() string              { return adminUsername }
 func SiteURL() string                    { return cache.SiteURL }
 func NetworkPort() uint                  { return cache.NetworkPort }
@@ -169,6 +210,32 @@ func CustomCSS() string                  { return cache.CustomCSS }
 func FederationEnabled() bool            { return cache.FederationEnabled }
 func PublicCustomJS() string             { return cache.PublicCustomJS }
 func PrivateCustomJS() string            { return cache.PrivateCustomJS }
+func BackfillPages() uint                { return cache.BackfillPages }
+func ManuallyApproveFollowers() bool     { return cache.ManuallyApproveFollowers }
+func AutoArchive() bool                  { return cache.AutoArchive }
+func AutoArchiveTags() string            { return cache.AutoArchiveTags }
+func AutoArchiveSharedOnly() bool        { return cache.AutoArchiveSharedOnly }
+func ArchiveQuotaMiB() uint              { return cache.ArchiveQuota }
+func ArchiveQuotaEvict() bool            { return cache.ArchiveQuotaEvict }
+func TrackingParamsString() string       { return cache.TrackingParams }
+
+// All returns all settings at once.
+func All() types.Settings { return cache }
+
+// TrackingParams returns the query parameters that are ignored when looking
+// for duplicate bookmarks.
+func TrackingParams() []string {
+	return bxstr.CommaSeparated(cache.TrackingParams)
+}
+
+// ArchiveQuota returns the archive quota in the form the archiving
+// service understands.
+func ArchiveQuota() archivingports.Quota {
+	return archivingports.Quota{
+		Size:  int64(cache.ArchiveQuota) * 1024 * 1024,
+		Evict: cache.ArchiveQuotaEvict,
+	}
+}
 
 func SiteDomain() string {
 	if SiteURL() == "" {
@@ -196,6 +263,14 @@ func SetSettings(settings types.Settings) {
 	mustWrite(settingsRepo.SetMetaEntryBool(ctx, settingsports.BetulaMetaEnableFederation, settings.FederationEnabled))
 	mustWrite(settingsRepo.SetMetaEntryString(ctx, settingsports.BetulaMetaPublicCustomJS, settings.PublicCustomJS))
 	mustWrite(settingsRepo.SetMetaEntryString(ctx, settingsports.BetulaMetaPrivateCustomJS, settings.PrivateCustomJS))
+	mustWrite(settingsRepo.SetMetaEntryUint(ctx, settingsports.BetulaMetaBackfillPages, settings.BackfillPages))
+	mustWrite(settingsRepo.SetMetaEntryBool(ctx, settingsports.BetulaMetaApproveFollowers, settings.ManuallyApproveFollowers))
+	mustWrite(settingsRepo.SetMetaEntryBool(ctx, settingsports.BetulaMetaAutoArchive, settings.AutoArchive))
+	mustWrite(settingsRepo.SetMetaEntryString(ctx, settingsports.BetulaMetaAutoArchiveTags, settings.AutoArchiveTags))
+	mustWrite(settingsRepo.SetMetaEntryBool(ctx, settingsports.BetulaMetaAutoArchiveSharedOnly, settings.AutoArchiveSharedOnly))
+	mustWrite(settingsRepo.SetMetaEntryUint(ctx, settingsports.BetulaMetaArchiveQuota, settings.ArchiveQuota))
+	mustWrite(settingsRepo.SetMetaEntryBool(ctx, settingsports.BetulaMetaArchiveQuotaEvict, settings.ArchiveQuotaEvict))
+	mustWrite(settingsRepo.SetMetaEntryString(ctx, settingsports.BetulaMetaTrackingParams, settings.TrackingParams))
 	Index()
 }
 
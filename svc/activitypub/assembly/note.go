@@ -170,11 +170,8 @@ func (asm *Assembler) NoteFromBookmark(bookmark types.Bookmark) (apports.Dict, e
 		"id":           fmt.Sprintf("%s/%d", asm.siteURLFn(), bookmark.ID),
 		"actor":        asm.actor(),
 		"attributedTo": asm.actor(),
-		"to": []string{
-			publicAudience,
-			fmt.Sprintf("%s/followers", asm.siteURLFn()),
-		},
-		"published": published,
+		"to":           asm.audience(bookmark.Visibility),
+		"published":    published,
 
 		// https://codeberg.org/fediverse/fep/src/branch/main/fep/044f/fep-044f.md#advertising-a-quote-policy
 		"interactionPolicy": apports.Dict{
@@ -204,6 +201,16 @@ func (asm *Assembler) NoteFromBookmark(bookmark types.Bookmark) (apports.Dict, e
 	return object, nil
 }
 
+// audience returns the addressees of a note with the given visibility.
+// Unlisted notes are addressed to followers only.
+func (asm *Assembler) audience(visibility types.Visibility) []string {
+	followers := fmt.Sprintf("%s/followers", asm.siteURLFn())
+	if visibility == types.Unlisted {
+		return []string{followers}
+	}
+	return []string{publicAudience, followers}
+}
+
 func (asm *Assembler) makeNoteAction(bookmark types.Bookmark) (apports.Dict, error) {
 	object, err := asm.NoteFromBookmark(bookmark)
 	if err != nil {
@@ -220,13 +227,21 @@ func (asm *Assembler) makeNoteAction(bookmark types.Bookmark) (apports.Dict, err
 	return activity, nil
 }
 
-func (asm *Assembler) CreateNote(bookmark types.Bookmark) (json.RawMessage, error) {
+func (asm *Assembler) createNote(bookmark types.Bookmark) (apports.Dict, error) {
 	activity, err := asm.makeNoteAction(bookmark)
 	if err != nil {
 		return nil, err
 	}
 	activity["type"] = "Create"
 	activity["id"] = fmt.Sprintf("%s/%d?create", asm.siteURLFn(), bookmark.ID)
+	return activity, nil
+}
+
+func (asm *Assembler) CreateNote(bookmark types.Bookmark) (json.RawMessage, error) {
+	activity, err := asm.createNote(bookmark)
+	if err != nil {
+		return nil, err
+	}
 	return json.Marshal(activity)
 }
 
@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package assembly
+
+import (
+	"encoding/json"
+	"fmt"
+
+	apports "git.sr.ht/~bouncepaw/betula/ports/activitypub"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+// Outbox returns the outbox OrderedCollection. It has no items,
+// they are served in pages, see OutboxPage.
+func (asm *Assembler) Outbox(totalItems uint) (json.RawMessage, error) {
+	outboxID := asm.siteURLFn() + "/outbox"
+	collection := apports.Dict{
+		"@context":   atContext,
+		"id":         outboxID,
+		"type":       "OrderedCollection",
+		"totalItems": totalItems,
+		"first":      outboxID + "?page=1",
+	}
+	if lastPage := outboxPages(totalItems); lastPage > 0 {
+		collection["last"] = fmt.Sprintf("%s?page=%d", outboxID, lastPage)
+	}
+	return json.Marshal(collection)
+}
+
+// OutboxPage returns the given 1-based page of the outbox, made of
+// Create{Note} activities for the bookmarks, newest first.
+func (asm *Assembler) OutboxPage(page, totalItems uint, bookmarks []types.Bookmark) (json.RawMessage, error) {
+	outboxID := asm.siteURLFn() + "/outbox"
+	items := make([]apports.Dict, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		activity, err := asm.createNote(bookmark)
+		if err != nil {
+			return nil, err
+		}
+		delete(activity, "@context")
+		activity["published"] = activity["object"].(apports.Dict)["published"]
+		activity["to"] = activity["object"].(apports.Dict)["to"]
+		items = append(items, activity)
+	}
+
+	collectionPage := apports.Dict{
+		"@context":     theCoolContext,
+		"id":           fmt.Sprintf("%s?page=%d", outboxID, page),
+		"type":         "OrderedCollectionPage",
+		"partOf":       outboxID,
+		"totalItems":   totalItems,
+		"orderedItems": items,
+	}
+	if page > 1 {
+		collectionPage["prev"] = fmt.Sprintf("%s?page=%d", outboxID, page-1)
+	}
+	if page < outboxPages(totalItems) {
+		collectionPage["next"] = fmt.Sprintf("%s?page=%d", outboxID, page+1)
+	}
+	return json.Marshal(collectionPage)
+}
+
+func outboxPages(totalItems uint) uint {
+	return (totalItems + types.BookmarksPerPage - 1) / types.BookmarksPerPage
+}
@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package assembly
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nalgeon/be"
+
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+func TestOutbox(t *testing.T) {
+	asm := New(func() string { return "https://links.example.org" }, func() string { return "bob" })
+
+	data, err := asm.Outbox(types.BookmarksPerPage + 1)
+	be.Err(t, err, nil)
+	var collection map[string]any
+	be.Err(t, json.Unmarshal(data, &collection), nil)
+	be.Equal(t, collection["type"], "OrderedCollection")
+	be.Equal(t, collection["first"], "https://links.example.org/outbox?page=1")
+	be.Equal(t, collection["last"], "https://links.example.org/outbox?page=2")
+
+	data, err = asm.OutboxPage(1, types.BookmarksPerPage+1, []types.Bookmark{{
+		ID:           42,
+		URL:          "https://example.org",
+		Title:        "Example",
+		Visibility:   types.Public,
+		CreationTime: "2026-01-02 03:04:05",
+	}})
+	be.Err(t, err, nil)
+	var page struct {
+		Type         string
+		Next         string
+		Prev         string
+		OrderedItems []struct {
+			ID     string
+			Type   string
+			Object struct {
+				ID string
+			}
+		}
+	}
+	be.Err(t, json.Unmarshal(data, &page), nil)
+	be.Equal(t, page.Type, "OrderedCollectionPage")
+	be.Equal(t, page.Next, "https://links.example.org/outbox?page=2")
+	be.Equal(t, page.Prev, "")
+	be.Equal(t, len(page.OrderedItems), 1)
+	be.Equal(t, page.OrderedItems[0].Type, "Create")
+	be.Equal(t, page.OrderedItems[0].Object.ID, "https://links.example.org/42")
+}
+
+func TestNoteAudience(t *testing.T) {
+	asm := New(func() string { return "https://links.example.org" }, func() string { return "bob" })
+	bookmark := types.Bookmark{
+		ID:           42,
+		URL:          "https://example.org",
+		Title:        "Example",
+		Visibility:   types.Unlisted,
+		CreationTime: "2026-01-02 03:04:05",
+	}
+
+	note, err := asm.NoteFromBookmark(bookmark)
+	be.Err(t, err, nil)
+	be.Equal(t, note["to"].([]string), []string{"https://links.example.org/followers"})
+
+	bookmark.Visibility = types.Public
+	note, err = asm.NoteFromBookmark(bookmark)
+	be.Err(t, err, nil)
+	be.Equal(t, note["to"].([]string), []string{publicAudience, "https://links.example.org/followers"})
+}
@@ -5,42 +5,144 @@
 package archivingsvc
 
 import (
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"strings"
+	"time"
+
+	"git.sr.ht/~bouncepaw/betula/pkg/warc"
 	archivingports "git.sr.ht/~bouncepaw/betula/ports/archiving"
 	"git.sr.ht/~bouncepaw/betula/types"
-	"log/slog"
 )
 
 type Service struct {
-	fetcher      archivingports.Fetcher
+	fetchers     Fetchers
 	archivesRepo archivingports.ArchivesRepo
+	quotaFn      func() archivingports.Quota
 }
 
 var _ archivingports.Service = &Service{}
 
+// Fetchers are the fetchers for every kind of archive.
+type Fetchers struct {
+	Full     archivingports.Fetcher
+	Readable archivingports.Fetcher
+	Raw      archivingports.Fetcher
+	// Prober is asked about the document when the kind is ArchiveAuto.
+	Prober archivingports.Prober
+}
+
+// NewFetchers returns the usual fetchers: Obelisk for full pages,
+// readability for readable ones, and plain HTTP for the rest.
+func NewFetchers(userAgentFn func() string) Fetchers {
+	var raw = NewRawFetcher(userAgentFn)
+	return Fetchers{
+		Full:     NewObeliskFetcher(),
+		Readable: NewReadableFetcher(raw),
+		Raw:      raw,
+		Prober:   raw,
+	}
+}
+
 func New(
-	fetcher archivingports.Fetcher,
+	fetchers Fetchers,
 	archivesRepo archivingports.ArchivesRepo,
+	quotaFn func() archivingports.Quota,
 ) *Service {
 	return &Service{
-		fetcher:      fetcher,
+		fetchers:     fetchers,
 		archivesRepo: archivesRepo,
+		quotaFn:      quotaFn,
+	}
+}
+
+// chooseKind turns ArchiveAuto into a real kind. PDFs, images and other
+// things that are not web pages are kept as they are, and it is also
+// the case for web pages that are asked to be readable. Everything else
+// is a full page, Obelisk knows best what to do with it.
+func (svc *Service) chooseKind(url string, kind types.ArchiveKind) types.ArchiveKind {
+	if kind == types.ArchiveFull || kind == types.ArchiveRaw {
+		return kind
+	}
+
+	contentType, err := svc.fetchers.Prober.ContentType(url)
+	switch {
+	case err != nil && kind == types.ArchiveAuto:
+		slog.Warn("Failed to find out the document type, archiving the full page",
+			"url", url, "err", err)
+		return types.ArchiveFull
+	case err != nil:
+		return kind
+	}
+
+	switch {
+	case passthrough(contentType):
+		// There is nothing to make readable in a PDF or a picture.
+		return types.ArchiveRaw
+	case kind == types.ArchiveAuto:
+		return types.ArchiveFull
+	default:
+		return kind
 	}
 }
 
-func (svc *Service) Archive(bookmark types.Bookmark) (int64, error) {
-	var bytes, mime, err = svc.fetcher.Fetch(bookmark.URL)
+// passthrough is true for the documents that are better kept as they are.
+// SVG is not one of them, it may carry scripts, just like a web page.
+func passthrough(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
 	if err != nil {
-		slog.Error("Obelisk failed to fetch an archive of the page",
-			"url", bookmark.URL, "err", err)
+		return false
+	}
+	switch {
+	case mediaType == "image/svg+xml":
+		return false
+	case mediaType == "application/pdf", mediaType == "text/plain":
+		return true
+	case strings.HasPrefix(mediaType, "image/"),
+		strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "video/"):
+		return true
+	default:
+		return false
+	}
+}
+
+func (svc *Service) fetcher(kind types.ArchiveKind) archivingports.Fetcher {
+	switch kind {
+	case types.ArchiveReadable:
+		return svc.fetchers.Readable
+	case types.ArchiveRaw:
+		return svc.fetchers.Raw
+	default:
+		return svc.fetchers.Full
+	}
+}
+
+func (svc *Service) Archive(bookmark types.Bookmark, kind types.ArchiveKind) (int64, error) {
+	kind = svc.chooseKind(bookmark.URL, kind)
+	var bytes, contentType, err = svc.fetcher(kind).Fetch(bookmark.URL)
+	if err != nil {
+		slog.Error("Failed to fetch an archive of the page",
+			"url", bookmark.URL, "kind", kind, "err", err)
 		return 0, err
 	}
 
-	artifact, err := types.NewCompressedDocumentArtifact(bytes, mime)
+	artifact, err := types.NewCompressedDocumentArtifact(bytes, contentType)
 	if err != nil {
 		slog.Error("Failed to compress the new archive",
 			"url", bookmark.URL, "err", err)
 		return 0, err
 	}
+	artifact.Kind = kind
+
+	if err = svc.makeRoom(int64(len(artifact.Data))); err != nil {
+		slog.Error("No room for the new archive",
+			"url", bookmark.URL, "err", err)
+		return 0, err
+	}
 
 	archiveID, err := svc.archivesRepo.Store(int64(bookmark.ID), artifact)
 	if err != nil {
@@ -49,5 +151,151 @@ func (svc *Service) Archive(bookmark types.Bookmark) (int64, error) {
 		return 0, err
 	}
 
+	// The archive is there even if search does not find it.
+	_ = svc.index(archiveID, artifact)
 	return archiveID, nil
 }
+
+// index stores the text of the archive for search.
+func (svc *Service) index(archiveID int64, artifact *types.Artifact) error {
+	text, err := documentText(artifact)
+	if err != nil {
+		// Storing the empty text anyway, so it is not tried again.
+		slog.Warn("Failed to extract text of archive",
+			"archiveID", archiveID, "err", err)
+	}
+	if err = svc.archivesRepo.StoreText(archiveID, text); err != nil {
+		slog.Error("Failed to store text of archive",
+			"archiveID", archiveID, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (svc *Service) IndexArchives(limit int) (int, error) {
+	archiveIDs, err := svc.archivesRepo.ArchivesWithoutText(limit)
+	if err != nil {
+		return 0, err
+	}
+	for _, archiveID := range archiveIDs {
+		// Indexing writes to the database, so it waits until the page
+		// is read: there is only one connection.
+		var pages []types.ArchivedPage
+		err = svc.archivesRepo.EachArchivedPage(archiveID, func(page types.ArchivedPage) error {
+			pages = append(pages, page)
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+		for _, page := range pages {
+			if err = svc.index(page.ID, &page.Artifact); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return len(archiveIDs), nil
+}
+
+// makeRoom checks that size more bytes fit in the quota, evicting
+// the oldest archives if the quota says so.
+func (svc *Service) makeRoom(size int64) error {
+	var quota = svc.quotaFn()
+	switch {
+	case quota.Size == 0:
+		return nil
+	case size > quota.Size:
+		// Evicting everything would not help.
+		return archivingports.ErrQuotaExceeded
+	}
+
+	for {
+		used, err := svc.archivesRepo.TotalSize()
+		if err != nil {
+			return err
+		}
+		if used+size <= quota.Size {
+			return nil
+		}
+		if !quota.Evict {
+			return archivingports.ErrQuotaExceeded
+		}
+
+		// Only archives are counted, so once they are all evicted,
+		// used is 0, and size fits, as checked above.
+		oldestID, err := svc.archivesRepo.OldestArchive()
+		if errors.Is(err, sql.ErrNoRows) {
+			return archivingports.ErrQuotaExceeded
+		} else if err != nil {
+			return err
+		}
+		if err = svc.archivesRepo.DeleteArchive(oldestID); err != nil {
+			return err
+		}
+		slog.Info("Evicted the oldest archive to stay within the quota",
+			"archiveID", oldestID, "quota", quota.Size)
+	}
+}
+
+func (svc *Service) Usage() (archivingports.Usage, error) {
+	return svc.archivesRepo.Usage()
+}
+
+func (svc *Service) CollectGarbage() (archivingports.Collected, error) {
+	collected, err := svc.archivesRepo.CollectGarbage()
+	if err != nil {
+		slog.Error("Failed to collect archive garbage", "err", err)
+		return collected, err
+	}
+	slog.Info("Collected archive garbage",
+		"archives", collected.Archives,
+		"artifacts", collected.Artifacts,
+		"freed", collected.Freed)
+
+	if err = svc.archivesRepo.Vacuum(); err != nil {
+		slog.Error("Failed to vacuum the database", "err", err)
+		return collected, err
+	}
+	return collected, nil
+}
+
+func (svc *Service) WriteWARC(w io.Writer, archiveID int64) error {
+	var (
+		warcw = warc.NewWriter(w)
+		empty = true
+	)
+	err := svc.archivesRepo.EachArchivedPage(archiveID, func(page types.ArchivedPage) error {
+		contents, err := page.Artifact.Contents()
+		if err != nil {
+			slog.Error("Failed to read archive for WARC",
+				"archiveID", page.ID, "err", err)
+			return err
+		}
+
+		if empty {
+			if err = warcw.WriteInfo("Betula"); err != nil {
+				return err
+			}
+			empty = false
+		}
+
+		// SavedAt is always set by the database.
+		savedAt, _ := time.Parse(types.TimeLayout, page.SavedAt.String)
+		return warcw.WriteRecord(warc.Record{
+			Type:        warc.TypeResource,
+			TargetURI:   page.URL,
+			Date:        savedAt,
+			ContentType: page.Artifact.MimeType,
+			Content:     contents,
+		})
+	})
+	switch {
+	case err != nil:
+		return err
+	case empty && archiveID != 0:
+		return archivingports.ErrNoArchive
+	case empty:
+		return warcw.WriteInfo("Betula")
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package archivingsvc
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+
+	"git.sr.ht/~bouncepaw/betula/db"
+	archivingports "git.sr.ht/~bouncepaw/betula/ports/archiving"
+	searchingports "git.sr.ht/~bouncepaw/betula/ports/searching"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+type fakeProber map[string]string
+
+func (p fakeProber) ContentType(url string) (string, error) {
+	if contentType, ok := p[url]; ok {
+		return contentType, nil
+	}
+	return "", errors.New("no such document")
+}
+
+func TestChooseKind(t *testing.T) {
+	svc := New(Fetchers{Prober: fakeProber{
+		"https://example.org/":         "text/html; charset=utf-8",
+		"https://example.org/book.pdf": "application/pdf",
+		"https://example.org/cat.jpg":  "image/jpeg",
+		"https://example.org/notes":    "text/plain",
+		"https://example.org/logo.svg": "image/svg+xml",
+	}}, nil, nil)
+
+	for _, tc := range []struct {
+		url  string
+		kind types.ArchiveKind
+		want types.ArchiveKind
+	}{
+		{"https://example.org/", types.ArchiveAuto, types.ArchiveFull},
+		{"https://example.org/", types.ArchiveReadable, types.ArchiveReadable},
+		{"https://example.org/", types.ArchiveRaw, types.ArchiveRaw},
+		{"https://example.org/book.pdf", types.ArchiveAuto, types.ArchiveRaw},
+		{"https://example.org/book.pdf", types.ArchiveReadable, types.ArchiveRaw},
+		{"https://example.org/cat.jpg", types.ArchiveAuto, types.ArchiveRaw},
+		{"https://example.org/notes", types.ArchiveAuto, types.ArchiveRaw},
+		{"https://example.org/logo.svg", types.ArchiveAuto, types.ArchiveFull},
+		{"https://example.org/logo.svg", types.ArchiveReadable, types.ArchiveReadable},
+		{"https://example.org/book.pdf", types.ArchiveFull, types.ArchiveFull},
+		{"https://example.org/gone", types.ArchiveAuto, types.ArchiveFull},
+		{"https://example.org/gone", types.ArchiveReadable, types.ArchiveReadable},
+	} {
+		be.Equal(t, svc.chooseKind(tc.url, tc.kind), tc.want)
+	}
+}
+
+// fakeArchives has archives of the given sizes, oldest first.
+type fakeArchives struct {
+	archivingports.ArchivesRepo
+	sizes []int64
+}
+
+func (repo *fakeArchives) TotalSize() (int64, error) {
+	var total int64
+	for _, size := range repo.sizes {
+		total += size
+	}
+	return total, nil
+}
+
+func (repo *fakeArchives) OldestArchive() (int64, error) {
+	if len(repo.sizes) == 0 {
+		return 0, sql.ErrNoRows
+	}
+	return 1, nil
+}
+
+func (repo *fakeArchives) DeleteArchive(int64) error {
+	repo.sizes = repo.sizes[1:]
+	return nil
+}
+
+func TestMakeRoom(t *testing.T) {
+	withQuota := func(repo *fakeArchives, quota archivingports.Quota) *Service {
+		return New(Fetchers{}, repo, func() archivingports.Quota { return quota })
+	}
+
+	repo := &fakeArchives{sizes: []int64{30, 30, 30}}
+	be.Err(t, withQuota(repo, archivingports.Quota{}).makeRoom(1000), nil)
+	be.Err(t, withQuota(repo, archivingports.Quota{Size: 100}).makeRoom(10), nil)
+	be.Err(t, withQuota(repo, archivingports.Quota{Size: 100}).makeRoom(20), archivingports.ErrQuotaExceeded)
+	be.Equal(t, len(repo.sizes), 3)
+
+	be.Err(t, withQuota(repo, archivingports.Quota{Size: 100, Evict: true}).makeRoom(50), nil)
+	be.Equal(t, repo.sizes, []int64{30})
+	be.Err(t, withQuota(repo, archivingports.Quota{Size: 100, Evict: true}).makeRoom(200), archivingports.ErrQuotaExceeded)
+	be.Equal(t, len(repo.sizes), 1)
+}
+
+func TestIndexArchives(t *testing.T) {
+	db.InitInMemoryDB()
+	repo := db.NewArchivesRepo()
+	svc := New(Fetchers{}, repo, nil)
+
+	artifact, err := types.NewCompressedDocumentArtifact([]byte("<p>Mycorrhiza talks</p>"), "text/html")
+	be.Err(t, err, nil)
+	_, err = repo.Store(2, artifact)
+	be.Err(t, err, nil)
+
+	done := make(chan struct{})
+	var indexed int
+	go func() {
+		indexed, err = svc.IndexArchives(10)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("IndexArchives did not return")
+	}
+	be.Err(t, err, nil)
+	be.Equal(t, indexed, 1)
+
+	_, total, err := db.NewSearchRepo().Search(t.Context(), searchingports.Query{
+		Expr:       searchingports.Text{Field: searchingports.FieldArchive, Text: "mycorrhiza"},
+		Authorized: true,
+		Page:       1,
+	})
+	be.Err(t, err, nil)
+	be.Equal(t, total, uint(1))
+}
+
+func TestDocumentText(t *testing.T) {
+	for _, tc := range []struct {
+		document, mime, want string
+	}{
+		{`<html><head><title>Fungi</title><style>p { color: red }</style></head><body><p>Mush<b>room</b></p><p>Spore</p>Hy<br>pha<div>My<i>cel</i>ium</div><script>alert(1)</script></body></html>`,
+			"text/html; charset=utf-8", "Fungi Mushroom Spore Hy pha Mycelium"},
+		{"Just\ttext", "text/plain", "Just\ttext"},
+		{"%PDF-1.7", "application/pdf", ""},
+	} {
+		artifact, err := types.NewCompressedDocumentArtifact([]byte(tc.document), tc.mime)
+		be.Err(t, err, nil)
+		text, err := documentText(artifact)
+		be.Err(t, err, nil)
+		be.Equal(t, text, tc.want)
+	}
+
+	be.Equal(t, truncateText("ёжик", 3), "ё")
+}
@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package archivingsvc
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+// maxImageSize is the size of the biggest icon or preview image we keep.
+// They are shown small, there is no need for huge ones.
+const maxImageSize = 2 * 1024 * 1024
+
+var errNotImage = errors.New("archivingsvc: document is not a picture")
+
+func (svc *Service) SaveImages(bookmarkID int64, iconURL, previewURL string) error {
+	var errs []error
+	for kind, addr := range map[types.ImageKind]string{
+		types.ImageIcon:    iconURL,
+		types.ImagePreview: previewURL,
+	} {
+		if addr == "" {
+			continue
+		}
+		if err := svc.saveImage(bookmarkID, kind, addr); err != nil {
+			slog.Warn("Failed to save bookmark image",
+				"bookmarkID", bookmarkID, "kind", kind, "url", addr, "err", err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (svc *Service) saveImage(bookmarkID int64, kind types.ImageKind, addr string) error {
+	data, contentType, err := svc.fetchers.Raw.Fetch(addr)
+	if err != nil {
+		return err
+	}
+	if len(data) > maxImageSize {
+		return errTooBig
+	}
+
+	mimeType, err := imageType(data, contentType)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, addr)
+	}
+
+	artifact, err := types.NewArtifact(data, mimeType)
+	if err != nil {
+		return err
+	}
+	return svc.archivesRepo.StoreImage(bookmarkID, kind, artifact)
+}
+
+// imageType returns the MIME type of the picture. Servers are not always
+// right about the types of icons, so the data has the last word. SVG is
+// refused, because it may carry scripts, and the images are served from
+// Betula's own address.
+func imageType(data []byte, contentType string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.HasPrefix(mediaType, "image/") {
+		mediaType = http.DetectContentType(data)
+	}
+	switch {
+	case mediaType == "image/svg+xml":
+		return "", errNotImage
+	case strings.HasPrefix(mediaType, "image/"):
+		return mediaType, nil
+	default:
+		return "", errNotImage
+	}
+}
+
+func (svc *Service) FillIcons(bookmarks []types.RenderedLocalBookmark) error {
+	var ids = make([]int, len(bookmarks))
+	for i, bookmark := range bookmarks {
+		ids[i] = bookmark.ID
+	}
+
+	images, err := svc.archivesRepo.ImagesOf(ids)
+	if err != nil {
+		return err
+	}
+	for i, bookmark := range bookmarks {
+		bookmarks[i].IconID = images[bookmark.ID].IconID
+	}
+	return nil
+}
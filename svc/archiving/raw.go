@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package archivingsvc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	archivingports "git.sr.ht/~bouncepaw/betula/ports/archiving"
+)
+
+// maxRawSize is the size of the biggest document we agree to keep.
+const maxRawSize = 50 * 1024 * 1024
+
+var errTooBig = errors.New("archivingsvc: document is too big")
+
+var rawClient = http.Client{
+	Timeout: time.Minute,
+}
+
+// RawFetcher fetches documents as they are served, without changing them.
+type RawFetcher struct {
+	userAgentFn func() string
+}
+
+var (
+	_ archivingports.Fetcher = &RawFetcher{}
+	_ archivingports.Prober  = &RawFetcher{}
+)
+
+func NewRawFetcher(userAgentFn func() string) *RawFetcher {
+	return &RawFetcher{userAgentFn: userAgentFn}
+}
+
+func (f *RawFetcher) Fetch(url string) ([]byte, string, error) {
+	resp, err := f.do(http.MethodGet, url)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.ContentLength > maxRawSize {
+		return nil, "", errTooBig
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRawSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxRawSize {
+		return nil, "", errTooBig
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+func (f *RawFetcher) ContentType(url string) (string, error) {
+	// Some servers do not support HEAD, GET without reading the body
+	// is the next cheapest thing.
+	resp, err := f.do(http.MethodHead, url)
+	if err != nil {
+		resp, err = f.do(http.MethodGet, url)
+		if err != nil {
+			return "", err
+		}
+	}
+	resp.Body.Close()
+	return resp.Header.Get("Content-Type"), nil
+}
+
+func (f *RawFetcher) do(method, url string) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", f.userAgentFn())
+
+	resp, err := rawClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("archivingsvc: %s %s: status %d", method, url, resp.StatusCode)
+	}
+	return resp, nil
+}
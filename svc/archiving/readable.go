@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package archivingsvc
+
+import (
+	"bytes"
+	"html/template"
+	"mime"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+
+	"git.sr.ht/~bouncepaw/betula/pkg/readability"
+	archivingports "git.sr.ht/~bouncepaw/betula/ports/archiving"
+)
+
+// ReadableFetcher keeps only the main text of the page.
+type ReadableFetcher struct {
+	raw archivingports.Fetcher
+}
+
+var _ archivingports.Fetcher = &ReadableFetcher{}
+
+// NewReadableFetcher makes a ReadableFetcher that downloads pages with raw.
+func NewReadableFetcher(raw archivingports.Fetcher) *ReadableFetcher {
+	return &ReadableFetcher{raw: raw}
+}
+
+var readableTemplate = template.Must(template.New("readable").Parse(`<!doctype html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>{{.Title}}</title>
+	<style>
+		body { max-width: 40em; margin: 2em auto; padding: 0 1em; font-family: serif; line-height: 1.5; }
+		img { max-width: 100%; height: auto; }
+		pre { overflow-x: auto; }
+		.source { font-family: sans-serif; font-size: smaller; color: #555; }
+	</style>
+</head>
+<body>
+	<p class="source">Readable copy of <a href="{{.URL}}">{{.URL}}</a></p>
+	{{if .ShowTitle}}<h1>{{.Title}}</h1>{{end}}
+	{{.Content}}
+</body>
+</html>
+`))
+
+func (f *ReadableFetcher) Fetch(addr string) ([]byte, string, error) {
+	data, contentType, err := f.raw.Fetch(addr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	base, err := url.Parse(addr)
+	if err != nil {
+		return nil, "", err
+	}
+	r, err := charset.NewReader(bytes.NewReader(data), contentType)
+	if err != nil {
+		return nil, "", err
+	}
+	article, err := readability.Extract(r, base)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	err = readableTemplate.Execute(&buf, map[string]any{
+		"Title": article.Title,
+		"URL":   addr,
+		// Most articles start with their own title.
+		"ShowTitle": article.Title != "" && !strings.Contains(article.Content, "<h1"),
+		// The content is cleaned up by readability.
+		"Content": template.HTML(article.Content),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), mime.FormatMediaType("text/html", map[string]string{"charset": "utf-8"}), nil
+}
@@ -0,0 +1,129 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package archivingsvc
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+// maxTextSize is how many bytes of an archive's text are indexed.
+// Long books are found by their beginning.
+const maxTextSize = 256 * 1024
+
+// documentText returns the plain text of the artifact for search.
+// Documents that are not text, like pictures and PDFs, have no text.
+func documentText(artifact *types.Artifact) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(artifact.MimeType)
+	if err != nil {
+		return "", nil
+	}
+
+	var text string
+	switch {
+	case mediaType == "text/html", mediaType == "application/xhtml+xml":
+		contents, err := artifact.Contents()
+		if err != nil {
+			return "", err
+		}
+		text, err = htmlText(contents, artifact.MimeType)
+		if err != nil {
+			return "", err
+		}
+	case strings.HasPrefix(mediaType, "text/"):
+		contents, err := artifact.Contents()
+		if err != nil {
+			return "", err
+		}
+		text = strings.ToValidUTF8(string(contents), "")
+	}
+	return truncateText(text, maxTextSize), nil
+}
+
+// blockTags break words: <p>paragraphs</p><p>like this</p> are not glued
+// together. Other tags do not, so w<b>or</b>d stays a word.
+var blockTags = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Aside: true, atom.Blockquote: true,
+	atom.Body: true, atom.Br: true, atom.Caption: true, atom.Dd: true, atom.Details: true,
+	atom.Div: true, atom.Dl: true, atom.Dt: true, atom.Fieldset: true, atom.Figcaption: true,
+	atom.Figure: true, atom.Footer: true, atom.Form: true, atom.H1: true, atom.H2: true,
+	atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true, atom.Head: true,
+	atom.Header: true, atom.Hr: true, atom.Img: true, atom.Li: true, atom.Main: true,
+	atom.Nav: true, atom.Ol: true, atom.Option: true, atom.P: true, atom.Pre: true,
+	atom.Section: true, atom.Summary: true, atom.Table: true, atom.Td: true, atom.Th: true,
+	atom.Title: true, atom.Tr: true, atom.Ul: true,
+}
+
+// htmlText returns the text of the HTML document, without scripts and
+// styles, with whitespace collapsed.
+func htmlText(contents []byte, contentType string) (string, error) {
+	r, err := charset.NewReader(bytes.NewReader(contents), contentType)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		tokenizer = html.NewTokenizer(r)
+		buf       strings.Builder
+		skipping  atom.Atom
+	)
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if err = tokenizer.Err(); err != io.EOF {
+				return "", err
+			}
+			return strings.Join(strings.Fields(buf.String()), " "), nil
+		case html.StartTagToken:
+			name, _ := tokenizer.TagName()
+			a := atom.Lookup(name)
+			switch a {
+			case atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Svg:
+				skipping = a
+			}
+			if blockTags[a] {
+				buf.WriteByte(' ')
+			}
+		case html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			if blockTags[atom.Lookup(name)] {
+				buf.WriteByte(' ')
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			a := atom.Lookup(name)
+			if a == skipping {
+				skipping = 0
+			}
+			if blockTags[a] {
+				buf.WriteByte(' ')
+			}
+		case html.TextToken:
+			if skipping == 0 {
+				buf.Write(tokenizer.Text())
+			}
+		}
+	}
+}
+
+// truncateText cuts text to at most size bytes without breaking runes.
+func truncateText(text string, size int) string {
+	if len(text) <= size {
+		return text
+	}
+	for size > 0 && !utf8.RuneStart(text[size]) {
+		size--
+	}
+	return text[:size]
+}
@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+// Package blockingsvc provides the blocking service.
+package blockingsvc
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	apports "git.sr.ht/~bouncepaw/betula/ports/activitypub"
+	blockingports "git.sr.ht/~bouncepaw/betula/ports/blocking"
+)
+
+type Service struct {
+	logger      *slog.Logger
+	repo        blockingports.Repository
+	actorRepo   apports.ActorRepository
+	activityPub apports.ActivityPub
+	asm         apports.Assembly
+	ourIDFn     func() string
+}
+
+var _ blockingports.Service = &Service{}
+
+func New(
+	repo blockingports.Repository,
+	actorRepo apports.ActorRepository,
+	activityPub apports.ActivityPub,
+	asm apports.Assembly,
+	ourIDFn func() string,
+) *Service {
+	return &Service{
+		logger:      slog.Default(),
+		repo:        repo,
+		actorRepo:   actorRepo,
+		activityPub: activityPub,
+		asm:         asm,
+		ourIDFn:     ourIDFn,
+	}
+}
+
+func (svc *Service) Block(ctx context.Context, target, reason string) error {
+	block, err := parseTarget(target)
+	if err != nil {
+		return err
+	}
+	block.Reason = strings.TrimSpace(reason)
+
+	if err := svc.repo.AddBlock(ctx, block); err != nil {
+		return err
+	}
+	svc.logger.Info("Blocked", "target", block.Target, "domain", block.Domain)
+
+	if err := svc.rejectBlockedFollowers(ctx); err != nil {
+		svc.logger.Error("Failed to remove blocked followers", "err", err)
+	}
+	return svc.repo.DeleteTracesOf(ctx, block)
+}
+
+// rejectBlockedFollowers removes the followers that are blocked now and
+// tells them so.
+func (svc *Service) rejectBlockedFollowers(ctx context.Context) error {
+	followers, err := svc.actorRepo.GetFollowers(ctx)
+	if err != nil {
+		return err
+	}
+	for _, follower := range followers {
+		blocked, err := svc.repo.IsBlocked(ctx, follower.ID)
+		if err != nil {
+			return err
+		}
+		if !blocked {
+			continue
+		}
+		if err := svc.actorRepo.RemoveFollower(ctx, follower.ID); err != nil {
+			return err
+		}
+		svc.logger.Info("Removed blocked follower", "actorID", follower.ID)
+
+		// Best effort. We have removed them anyway.
+		if err := svc.sendReject(ctx, follower.ID); err != nil {
+			svc.logger.Warn("Failed to send Reject to blocked follower", "actorID", follower.ID, "err", err)
+		}
+	}
+	return nil
+}
+
+func (svc *Service) sendReject(ctx context.Context, followerID string) error {
+	activity, err := svc.asm.NewReject(apports.Dict{
+		"type":   "Follow",
+		"actor":  followerID,
+		"object": svc.ourIDFn(),
+	})
+	if err != nil {
+		return err
+	}
+	actor, err := svc.activityPub.ActorByID(ctx, followerID, apports.GetActorsOpts{})
+	if err != nil {
+		return err
+	}
+	return actor.SendSerializedActivity(activity)
+}
+
+func (svc *Service) Unblock(ctx context.Context, target string) error {
+	return svc.repo.RemoveBlock(ctx, target)
+}
+
+func (svc *Service) Blocks(ctx context.Context) ([]blockingports.Block, error) {
+	return svc.repo.Blocks(ctx)
+}
+
+func (svc *Service) IsBlocked(ctx context.Context, actorID string) (bool, error) {
+	return svc.repo.IsBlocked(ctx, actorID)
+}
+
+// parseTarget makes a block out of an actor ID or a domain. A leading *. in
+// a domain is dropped, because subdomains are blocked anyway.
+func parseTarget(target string) (blockingports.Block, error) {
+	target = strings.TrimSpace(target)
+	if strings.HasPrefix(target, "https://") || strings.HasPrefix(target, "http://") {
+		if u, err := url.Parse(target); err != nil || u.Host == "" {
+			return blockingports.Block{}, blockingports.ErrBadTarget
+		}
+		return blockingports.Block{Target: target}, nil
+	}
+
+	domain := strings.TrimPrefix(strings.ToLower(target), "*.")
+	domain = strings.TrimSuffix(domain, ".")
+	if domain == "" || strings.ContainsAny(domain, "/@:?# \t") || !strings.Contains(domain, ".") {
+		return blockingports.Block{}, blockingports.ErrBadTarget
+	}
+	return blockingports.Block{Target: domain, Domain: true}, nil
+}
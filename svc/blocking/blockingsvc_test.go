@@ -0,0 +1,30 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package blockingsvc
+
+import (
+	"testing"
+
+	"github.com/nalgeon/be"
+
+	blockingports "git.sr.ht/~bouncepaw/betula/ports/blocking"
+)
+
+func TestParseTarget(t *testing.T) {
+	for target, expected := range map[string]blockingports.Block{
+		"https://example.org/@bob": {Target: "https://example.org/@bob"},
+		" Example.ORG ":            {Target: "example.org", Domain: true},
+		"*.example.org":            {Target: "example.org", Domain: true},
+	} {
+		block, err := parseTarget(target)
+		be.Err(t, err, nil)
+		be.Equal(t, block, expected)
+	}
+
+	for _, target := range []string{"", "bob", "@bob@example.org", "example.org/path", "https://"} {
+		_, err := parseTarget(target)
+		be.Err(t, err, blockingports.ErrBadTarget)
+	}
+}
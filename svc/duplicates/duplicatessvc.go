@@ -0,0 +1,179 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+// Package duplicatessvc finds bookmarks of the same page and merges them.
+package duplicatessvc
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+
+	duplicatesports "git.sr.ht/~bouncepaw/betula/ports/duplicates"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+type Service struct {
+	logger *slog.Logger
+	repo   duplicatesports.Repository
+}
+
+var _ duplicatesports.Service = &Service{}
+
+func New(repo duplicatesports.Repository) *Service {
+	return &Service{
+		logger: slog.Default(),
+		repo:   repo,
+	}
+}
+
+// pageOf is the canonical URL without the query and the fragment,
+// which leaves the scheme, the host and the path.
+func pageOf(canonicalURL string) string {
+	if i := strings.IndexAny(canonicalURL, "?#"); i >= 0 {
+		return canonicalURL[:i]
+	}
+	return canonicalURL
+}
+
+func (svc *Service) Groups(ctx context.Context) ([]duplicatesports.Group, error) {
+	candidates, err := svc.repo.Candidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		pages  []string
+		byPage = map[string][]duplicatesports.Candidate{}
+	)
+	for _, c := range candidates {
+		page := pageOf(c.CanonicalURL)
+		if _, seen := byPage[page]; !seen {
+			pages = append(pages, page)
+		}
+		byPage[page] = append(byPage[page], c)
+	}
+
+	var ids []int
+	for _, page := range pages {
+		if len(byPage[page]) < 2 {
+			continue
+		}
+		for _, c := range byPage[page] {
+			ids = append(ids, c.ID)
+		}
+	}
+	bookmarks, err := svc.repo.BookmarksByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	withTags := map[int]types.Bookmark{}
+	for _, bm := range bookmarks {
+		withTags[bm.ID] = bm
+	}
+
+	var groups []duplicatesports.Group
+	for _, page := range pages {
+		cs := byPage[page]
+		if len(cs) < 2 {
+			continue
+		}
+		group := duplicatesports.Group{Exact: true}
+		for _, c := range cs {
+			group.Exact = group.Exact && c.CanonicalURL == cs[0].CanonicalURL
+			group.Bookmarks = append(group.Bookmarks, withTags[c.ID])
+		}
+		groups = append(groups, group)
+	}
+	// Exact duplicates are the surest ones, show them first.
+	slices.SortStableFunc(groups, func(a, b duplicatesports.Group) int {
+		switch {
+		case a.Exact == b.Exact:
+			return 0
+		case a.Exact:
+			return -1
+		default:
+			return 1
+		}
+	})
+	return groups, nil
+}
+
+func (svc *Service) Merge(
+	ctx context.Context,
+	survivorID int,
+	ids []int,
+	descriptionFrom int,
+) (duplicatesports.MergeResult, error) {
+	var result duplicatesports.MergeResult
+
+	ids = slices.Compact(slices.Sorted(slices.Values(ids)))
+	if len(ids) < 2 || !slices.Contains(ids, survivorID) {
+		return result, duplicatesports.ErrBadMerge
+	}
+	if descriptionFrom != 0 && !slices.Contains(ids, descriptionFrom) {
+		return result, duplicatesports.ErrBadMerge
+	}
+	bookmarks, err := svc.repo.BookmarksByIDs(ctx, ids)
+	if err != nil {
+		return result, err
+	}
+	if len(bookmarks) != len(ids) {
+		return result, duplicatesports.ErrBadMerge
+	}
+
+	var removedIDs []int
+	for _, bm := range bookmarks {
+		if bm.ID == survivorID {
+			result.Survivor = bm
+		} else {
+			result.Removed = append(result.Removed, bm)
+			removedIDs = append(removedIDs, bm.ID)
+		}
+	}
+
+	survivor := &result.Survivor
+	// The bookmarks are sorted oldest first.
+	survivor.CreationTime = bookmarks[0].CreationTime
+	survivor.Tags = mergeTags(bookmarks)
+	survivor.Description = mergeDescriptions(bookmarks, descriptionFrom)
+
+	if err = svc.repo.Merge(ctx, *survivor, removedIDs); err != nil {
+		return result, err
+	}
+	svc.logger.Info("Merged duplicate bookmarks",
+		"survivorID", survivorID, "removedIDs", removedIDs)
+	return result, nil
+}
+
+// mergeTags returns the tags of all bookmarks, each once, sorted by name.
+func mergeTags(bookmarks []types.Bookmark) []types.Tag {
+	var tags []types.Tag
+	for _, bm := range bookmarks {
+		for _, tag := range bm.Tags {
+			if !slices.ContainsFunc(tags, func(t types.Tag) bool { return t.Name == tag.Name }) {
+				tags = append(tags, types.Tag{Name: tag.Name})
+			}
+		}
+	}
+	slices.SortFunc(tags, func(a, b types.Tag) int { return strings.Compare(a.Name, b.Name) })
+	return tags
+}
+
+// mergeDescriptions returns the description of the bookmark descriptionFrom,
+// or all different non-empty descriptions separated by blank lines if it is 0.
+func mergeDescriptions(bookmarks []types.Bookmark, descriptionFrom int) string {
+	var descriptions []string
+	for _, bm := range bookmarks {
+		if bm.ID == descriptionFrom {
+			return bm.Description
+		}
+		description := strings.TrimSpace(bm.Description)
+		if description != "" && !slices.Contains(descriptions, description) {
+			descriptions = append(descriptions, description)
+		}
+	}
+	return strings.Join(descriptions, "\n\n")
+}
@@ -0,0 +1,38 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package duplicatessvc
+
+import (
+	"testing"
+
+	"github.com/nalgeon/be"
+
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+func TestPageOf(t *testing.T) {
+	for canonicalURL, expected := range map[string]string{
+		"https://example.org/a":         "https://example.org/a",
+		"https://example.org/a?id=1":    "https://example.org/a",
+		"https://example.org/#!/thread": "https://example.org/",
+		"gemini://example.org/a":        "gemini://example.org/a",
+	} {
+		be.Equal(t, pageOf(canonicalURL), expected)
+	}
+}
+
+func TestMergeBookmarkFields(t *testing.T) {
+	bookmarks := []types.Bookmark{
+		{ID: 3, Description: "A wiki engine", Tags: []types.Tag{{Name: "wiki"}, {Name: "go"}}},
+		{ID: 1, Description: "", Tags: []types.Tag{{Name: "software"}}},
+		{ID: 2, Description: "A wiki engine\n", Tags: []types.Tag{{Name: "wiki"}}},
+		{ID: 4, Description: "Made by Bouncepaw"},
+	}
+
+	be.Equal(t, mergeTags(bookmarks), []types.Tag{{Name: "go"}, {Name: "software"}, {Name: "wiki"}})
+	be.Equal(t, mergeDescriptions(bookmarks, 0), "A wiki engine\n\nMade by Bouncepaw")
+	be.Equal(t, mergeDescriptions(bookmarks, 4), "Made by Bouncepaw")
+	be.Equal(t, mergeDescriptions(bookmarks, 1), "")
+}
@@ -13,110 +13,176 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"strings"
 	"time"
 
 	"git.sr.ht/~bouncepaw/betula/pkg/myco"
-	"git.sr.ht/~bouncepaw/betula/pkg/rss"
 	feedsports "git.sr.ht/~bouncepaw/betula/ports/feeds"
-	likingports "git.sr.ht/~bouncepaw/betula/ports/liking"
+	searchingports "git.sr.ht/~bouncepaw/betula/ports/searching"
 	"git.sr.ht/~bouncepaw/betula/settings"
 	"git.sr.ht/~bouncepaw/betula/types"
 )
 
 type Service struct {
-	bmRepo likingports.LocalBookmarkRepository
+	bmRepo    feedsports.Repository
+	searching searchingports.Service
 }
 
 var _ feedsports.Service = &Service{}
 
-func New(bmRepo likingports.LocalBookmarkRepository) *Service {
+func New(bmRepo feedsports.Repository, searching searchingports.Service) *Service {
 	return &Service{
-		bmRepo: bmRepo,
+		bmRepo:    bmRepo,
+		searching: searching,
 	}
 }
 
-func (svc *Service) DigestFeed() (*rss.Feed, error) {
+func (svc *Service) DigestFeed() (feedsports.Feed, error) {
 	slog.Info("Generating a digest feed")
-	author := settings.AdminUsername()
 
-	now := time.Now()
-	days, dayStamps, dayBookmarks, err := svc.fiveLastDays(now)
+	days, dayStamps, dayBookmarks, err := svc.fiveLastDays(time.Now())
 	if err != nil {
-		return nil, err
+		return feedsports.Feed{}, err
 	}
 
-	feed := rss.Feed{
+	feed := feedsports.Feed{
 		Title:       fmt.Sprintf("%s daily digest", settings.SiteName()),
 		Link:        settings.SiteURL(),
 		Description: "Every day, a list of all bookmarks published that day is sent.",
-		PubDate:     now.Format(rssTimeFormat),
-		Items:       []*rss.Item{},
+		Author:      settings.AdminUsername(),
 	}
 
+	var all []types.Bookmark
 	for i, bookmarks := range dayBookmarks {
 		if bookmarks == nil {
 			continue
 		}
-		var entry = &rss.Item{
-			Title:  fmt.Sprintf("%s %s", settings.SiteName(), dayStamps[i]),
-			Link:   fmt.Sprintf("%s/day/%s", settings.SiteURL(), dayStamps[i]),
-			Author: author,
-			Description: rss.CData{
-				Data: descriptionFromBookmarks(bookmarks),
-			},
-			PubDate: days[i].Format(rssTimeFormat),
-		}
-		feed.Items = append(feed.Items, entry)
+		all = append(all, bookmarks...)
+		dayURL := fmt.Sprintf("%s/day/%s", settings.SiteURL(), dayStamps[i])
+		feed.Items = append(feed.Items, feedsports.Item{
+			ID:          dayURL,
+			Title:       fmt.Sprintf("%s %s", settings.SiteName(), dayStamps[i]),
+			Link:        dayURL,
+			ContentHTML: descriptionFromBookmarks(bookmarks),
+			Published:   days[i],
+		})
 	}
-
-	return &feed, nil
+	feed.Updated = newestItem(feed.Items)
+	feed.Modified, err = svc.lastChange(all)
+	return feed, err
 }
 
-func (svc *Service) BookmarksFeed() (*rss.Feed, error) {
+func (svc *Service) BookmarksFeed() (feedsports.Feed, error) {
 	slog.Info("Generating a bookmarks feed")
-	author := settings.AdminUsername()
 
-	now := time.Now().AddDate(0, 0, 1)
-	_, _, dayBookmarks, err := svc.fiveLastDays(now)
+	_, _, dayBookmarks, err := svc.fiveLastDays(time.Now().AddDate(0, 0, 1))
 	if err != nil {
-		return nil, err
+		return feedsports.Feed{}, err
 	}
 
-	feed := rss.Feed{
+	feed := feedsports.Feed{
 		Title:       fmt.Sprintf("%s bookmarks", settings.SiteName()),
 		Link:        settings.SiteURL(),
 		Description: "All public bookmarks are sent to this feed.",
-		PubDate:     now.Format(rssTimeFormat),
-		Items:       []*rss.Item{},
+		Author:      settings.AdminUsername(),
 	}
-
+	var all []types.Bookmark
 	for _, bookmarks := range dayBookmarks {
-		for _, bm := range bookmarks {
-			creationTime, err := time.Parse(types.TimeLayout, bm.CreationTime)
-			if err != nil {
-				slog.Error("Invalid creation time in bookmarks feed",
-					"bookmarkID", bm.ID, "title", bm.Title, "creationTime", bm.CreationTime)
-				continue
-			}
+		all = append(all, bookmarks...)
+	}
+	feed.Items = bookmarkItems(all)
+	feed.Updated = newestItem(feed.Items)
+	feed.Modified, err = svc.lastChange(all)
+	return feed, err
+}
 
-			var entry = &rss.Item{
-				Title:  bm.Title,
-				Link:   bm.URL,
-				Author: author,
-				Description: rss.CData{
-					bookmarkDescription(bm),
-				},
-				PubDate: creationTime.Format(rssTimeFormat),
-			}
-			feed.Items = append(feed.Items, entry)
+func (svc *Service) TagFeed(tagName string) (feedsports.Feed, error) {
+	slog.Info("Generating a tag feed", "tag", tagName)
+
+	bookmarks, _, err := svc.bmRepo.BookmarksWithTag(context.Background(), false, tagName, 1)
+	if err != nil {
+		return feedsports.Feed{}, err
+	}
+
+	feed := feedsports.Feed{
+		Title:       fmt.Sprintf("%s: #%s", settings.SiteName(), tagName),
+		Link:        fmt.Sprintf("%s/tag/%s", settings.SiteURL(), url.PathEscape(tagName)),
+		Description: fmt.Sprintf("Public bookmarks tagged #%s are sent to this feed.", tagName),
+		Author:      settings.AdminUsername(),
+		Items:       bookmarkItems(bookmarks),
+	}
+	feed.Updated = newestItem(feed.Items)
+	feed.Modified, err = svc.lastChange(bookmarks)
+	return feed, err
+}
+
+func (svc *Service) SearchFeed(query string) (feedsports.Feed, error) {
+	if strings.TrimSpace(query) == "" {
+		return feedsports.Feed{}, feedsports.ErrEmptyQuery
+	}
+	slog.Info("Generating a search feed", "query", query)
+
+	bookmarks, _ := svc.searching.For(query, false, 1)
+
+	feed := feedsports.Feed{
+		Title:       fmt.Sprintf("%s: %s", settings.SiteName(), query),
+		Link:        fmt.Sprintf("%s/search?q=%s", settings.SiteURL(), url.QueryEscape(query)),
+		Description: fmt.Sprintf("Public bookmarks found by the query “%s” are sent to this feed.", query),
+		Author:      settings.AdminUsername(),
+		Items:       bookmarkItems(bookmarks),
+	}
+	feed.Updated = newestItem(feed.Items)
+	var err error
+	feed.Modified, err = svc.lastChange(bookmarks)
+	return feed, err
+}
+
+// bookmarkItems makes an item for each bookmark. The item's ID is the
+// bookmark's permalink.
+func bookmarkItems(bookmarks []types.Bookmark) []feedsports.Item {
+	var items []feedsports.Item
+	for _, bm := range bookmarks {
+		creationTime, err := time.Parse(types.TimeLayout, bm.CreationTime)
+		if err != nil {
+			slog.Error("Invalid creation time in bookmarks feed",
+				"bookmarkID", bm.ID, "title", bm.Title, "creationTime", bm.CreationTime)
+			continue
+		}
+
+		item := feedsports.Item{
+			ID:          fmt.Sprintf("%s/%d", settings.SiteURL(), bm.ID),
+			Title:       bm.Title,
+			Link:        bm.URL,
+			ContentHTML: bookmarkDescription(bm),
+			Published:   creationTime,
+		}
+		for _, tag := range bm.Tags {
+			item.Categories = append(item.Categories, tag.Name)
 		}
+		items = append(items, item)
 	}
+	return items
+}
 
-	return &feed, nil
+// lastChange is when the feed made of the bookmarks last changed.
+func (svc *Service) lastChange(bookmarks []types.Bookmark) (time.Time, error) {
+	ids := make([]int, 0, len(bookmarks))
+	for _, bm := range bookmarks {
+		ids = append(ids, bm.ID)
+	}
+	return svc.bmRepo.LastChange(context.Background(), ids)
 }
 
-const rssTimeFormat = time.RFC822
+func newestItem(items []feedsports.Item) time.Time {
+	var newest time.Time
+	for _, item := range items {
+		if item.Published.After(newest) {
+			newest = item.Published
+		}
+	}
+	return newest
+}
 
 func (svc *Service) fiveLastDays(now time.Time) (days []time.Time, dayStamps []string, dayBookmarks [][]types.Bookmark, err error) {
 	days = make([]time.Time, 5)
@@ -7,17 +7,22 @@
 package feedssvc
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"git.sr.ht/~bouncepaw/betula/db"
+	feedsports "git.sr.ht/~bouncepaw/betula/ports/feeds"
+	"git.sr.ht/~bouncepaw/betula/settings"
+	"git.sr.ht/~bouncepaw/betula/types"
 	"github.com/nalgeon/be"
 )
 
 func TestFiveLastDays(t *testing.T) {
 	db.InitInMemoryDB()
 	db.MoreTestingBookmarks()
-	svc := New(db.NewLocalBookmarksRepo())
+	svc := New(db.NewLocalBookmarksRepo(), nil)
 	days, dayStamps, dayBookmarks, err := svc.fiveLastDays(
 		time.Date(2023, 3, 21, 0, 0, 0, 0, time.UTC))
 	be.Err(t, err, nil)
@@ -34,3 +39,70 @@ func TestFiveLastDays(t *testing.T) {
 		be.Equal(t, correctBookmarkCounts[i], len(bookmarks))
 	}
 }
+
+func TestTagFeed(t *testing.T) {
+	db.InitInMemoryDB()
+	repo := db.NewLocalBookmarksRepo()
+	id, err := repo.InsertBookmark(t.Context(), types.Bookmark{
+		URL:          "https://mycorrhiza.wiki/help",
+		Title:        "Mycorrhiza help",
+		Visibility:   types.Public,
+		CreationTime: "2023-03-18 10:00:00",
+		Tags:         []types.Tag{{Name: "wiki"}, {Name: "docs"}},
+	})
+	be.Err(t, err, nil)
+
+	feed, err := New(repo, nil).TagFeed("wiki")
+	be.Err(t, err, nil)
+	be.Equal(t, len(feed.Items), 1)
+	be.Equal(t, feed.Items[0].ID, fmt.Sprintf("%s/%d", settings.SiteURL(), id))
+	be.Equal(t, feed.Items[0].Link, "https://mycorrhiza.wiki/help")
+	be.Equal(t, feed.Items[0].Categories, []string{"docs", "wiki"})
+	be.Equal(t, feed.Updated, time.Date(2023, 3, 18, 10, 0, 0, 0, time.UTC))
+}
+
+func TestWrite(t *testing.T) {
+	feed := feedsports.Feed{
+		Title:   "Bookmarks",
+		Link:    "https://links.example.org",
+		Self:    "https://links.example.org/bookmarks-atom",
+		Author:  "bob",
+		Updated: time.Date(2023, 3, 18, 10, 0, 0, 0, time.UTC),
+		Items: []feedsports.Item{{
+			ID:          "https://links.example.org/4",
+			Title:       "Mycorrhiza help",
+			Link:        "https://mycorrhiza.wiki/help",
+			ContentHTML: "<p>Help</p>",
+			Categories:  []string{"docs", "wiki"},
+			Published:   time.Date(2023, 3, 18, 10, 0, 0, 0, time.UTC),
+		}},
+	}
+	svc := New(nil, nil)
+
+	for format, parts := range map[feedsports.Format][]string{
+		feedsports.FormatRSS: {
+			`<guid isPermaLink="true">https://links.example.org/4</guid>`,
+			`<category>docs</category>`,
+			`<link>https://mycorrhiza.wiki/help</link>`,
+		},
+		feedsports.FormatAtom: {
+			`<feed xmlns="http://www.w3.org/2005/Atom">`,
+			`<id>https://links.example.org/4</id>`,
+			`<updated>2023-03-18T10:00:00Z</updated>`,
+			`<category term="wiki"></category>`,
+			`<link rel="self" type="application/atom+xml; charset=utf-8" href="https://links.example.org/bookmarks-atom"></link>`,
+		},
+		feedsports.FormatJSON: {
+			`"version": "https://jsonfeed.org/version/1.1"`,
+			`"id": "https://links.example.org/4"`,
+			`"external_url": "https://mycorrhiza.wiki/help"`,
+			`"date_published": "2023-03-18T10:00:00Z"`,
+		},
+	} {
+		var buf strings.Builder
+		be.Err(t, svc.Write(&buf, feed, format), nil)
+		for _, part := range parts {
+			be.True(t, strings.Contains(buf.String(), part))
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package feedssvc
+
+import (
+	"io"
+	"time"
+
+	"git.sr.ht/~bouncepaw/betula/pkg/atom"
+	"git.sr.ht/~bouncepaw/betula/pkg/jsonfeed"
+	"git.sr.ht/~bouncepaw/betula/pkg/rss"
+	feedsports "git.sr.ht/~bouncepaw/betula/ports/feeds"
+)
+
+const rssTimeFormat = time.RFC822
+
+func (svc *Service) Write(w io.Writer, feed feedsports.Feed, format feedsports.Format) error {
+	switch format {
+	case feedsports.FormatAtom:
+		return atomFeed(feed).Write(w)
+	case feedsports.FormatJSON:
+		return jsonFeed(feed).Write(w)
+	default:
+		return rssFeed(feed).Write(w)
+	}
+}
+
+// formatTime formats t in the layout, or returns an empty string if t is zero.
+func formatTime(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+func rssFeed(feed feedsports.Feed) *rss.Feed {
+	fd := &rss.Feed{
+		Title:         feed.Title,
+		Link:          feed.Link,
+		Description:   feed.Description,
+		PubDate:       formatTime(feed.Updated, rssTimeFormat),
+		LastBuildDate: formatTime(feed.Updated, rssTimeFormat),
+		Items:         []*rss.Item{},
+	}
+	for _, item := range feed.Items {
+		fd.Items = append(fd.Items, &rss.Item{
+			Title:       item.Title,
+			Link:        item.Link,
+			Author:      feed.Author,
+			Description: rss.CData{Data: item.ContentHTML},
+			Category:    item.Categories,
+			PubDate:     item.Published.Format(rssTimeFormat),
+			Guid:        &rss.Guid{IsPermaLink: true, Value: item.ID},
+		})
+	}
+	return fd
+}
+
+func atomFeed(feed feedsports.Feed) *atom.Feed {
+	// An Atom feed must have the time of update. An empty feed was never
+	// updated, so it gets the beginning of time.
+	updated := feed.Updated
+	if updated.IsZero() {
+		updated = time.Unix(0, 0)
+	}
+
+	fd := &atom.Feed{
+		ID:       feed.Link,
+		Title:    feed.Title,
+		Subtitle: feed.Description,
+		Updated:  updated.UTC().Format(time.RFC3339),
+		Author:   &atom.Person{Name: feed.Author},
+		Links:    []atom.Link{{Rel: "alternate", Type: "text/html", Href: feed.Link}},
+	}
+	if feed.Self != "" {
+		fd.ID = feed.Self
+		fd.Links = append(fd.Links, atom.Link{Rel: "self", Type: feedsports.FormatAtom.ContentType(), Href: feed.Self})
+	}
+	for _, item := range feed.Items {
+		entry := &atom.Entry{
+			ID:        item.ID,
+			Title:     item.Title,
+			Updated:   item.Published.UTC().Format(time.RFC3339),
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Links:     []atom.Link{{Rel: "alternate", Href: item.Link}},
+			Content:   &atom.Content{Type: "html", Body: item.ContentHTML},
+		}
+		if item.Link != item.ID {
+			entry.Links = append(entry.Links, atom.Link{Rel: "related", Type: "text/html", Href: item.ID})
+		}
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atom.Category{Term: category})
+		}
+		fd.Entries = append(fd.Entries, entry)
+	}
+	return fd
+}
+
+func jsonFeed(feed feedsports.Feed) *jsonfeed.Feed {
+	fd := &jsonfeed.Feed{
+		Version:     jsonfeed.Version,
+		Title:       feed.Title,
+		HomePageURL: feed.Link,
+		FeedURL:     feed.Self,
+		Description: feed.Description,
+		Authors:     []*jsonfeed.Author{{Name: feed.Author}},
+		Items:       []*jsonfeed.Item{},
+	}
+	for _, item := range feed.Items {
+		it := &jsonfeed.Item{
+			ID:            item.ID,
+			URL:           item.ID,
+			Title:         item.Title,
+			ContentHTML:   item.ContentHTML,
+			DatePublished: item.Published.UTC().Format(time.RFC3339),
+			Tags:          item.Categories,
+		}
+		if item.Link != item.ID {
+			it.ExternalURL = item.Link
+		}
+		fd.Items = append(fd.Items, it)
+	}
+	return fd
+}
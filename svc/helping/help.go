@@ -38,6 +38,8 @@ func New() *Service {
 		{"fedisearch", "Federated search"},
 		{"archival", "Bookmark archival"},
 		{"imex", "Bookmark import and export"},
+		{"api", "JSON API"},
+		{"webhooks", "Webhooks"},
 		{"errors", "Error codes"},
 		{"miniflux", "Miniflux integration"},
 		{"logging", "Logging & log server integration"},
@@ -20,6 +20,10 @@ func (svc *Service) Export(
 	params imexports.ExportParams,
 	w io.Writer,
 ) error {
+	if params.Format == imexports.ExportFormatBetula {
+		return svc.exportNative(ctx, params, w)
+	}
+
 	exp, err := svc.pickExporter(params)
 	if err != nil {
 		return err
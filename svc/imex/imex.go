@@ -22,7 +22,11 @@ type (
 		importers []importer
 		exporters map[imexports.ExportFormat]exporter
 		bmRepo    likingports.LocalBookmarkRepository
+		native    imexports.NativeRepository
 		www       wwwports.WorldWideWeb
+
+		settingsFn    func() types.Settings
+		setSettingsFn func(types.Settings)
 	}
 	importer interface {
 		Probe(io.ReadSeeker) (bool, error)
@@ -37,16 +41,28 @@ var _ imexports.Service = &Service{}
 
 func New(
 	bmRepo likingports.LocalBookmarkRepository,
+	native imexports.NativeRepository,
 	www wwwports.WorldWideWeb,
 	siteNameFn func() string,
+	settingsFn func() types.Settings,
+	setSettingsFn func(types.Settings),
 ) *Service {
 	return &Service{
-		bmRepo: bmRepo,
-		www:    www,
+		bmRepo:        bmRepo,
+		native:        native,
+		www:           www,
+		settingsFn:    settingsFn,
+		setSettingsFn: setSettingsFn,
 		importers: []importer{
 			importers.NewNetscapeImporter(),
 			importers.NewPinboardImporter(),
 			importers.NewRaindropImporter(),
+			importers.NewFirefoxImporter(),
+			importers.NewChromeImporter(),
+			importers.NewPocketImporter(),
+			importers.NewWallabagImporter(),
+			importers.NewShaarliImporter(),
+			importers.NewLinkdingImporter(),
 			importers.NewPlainImporter(10, www), // Fallback format, always matches.
 		},
 		exporters: map[imexports.ExportFormat]exporter{
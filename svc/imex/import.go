@@ -12,6 +12,7 @@ import (
 	"io"
 	"log/slog"
 
+	"git.sr.ht/~bouncepaw/betula/pkg/imex/betula"
 	imexports "git.sr.ht/~bouncepaw/betula/ports/imex"
 	"git.sr.ht/~bouncepaw/betula/types"
 )
@@ -21,6 +22,14 @@ func (svc *Service) Import(
 	params imexports.ImportParams,
 	seeker io.ReadSeeker,
 ) (uint, error) {
+	native, err := betula.Probe(seeker)
+	if err != nil {
+		return 0, err
+	}
+	if native {
+		return svc.importNative(ctx, params, seeker)
+	}
+
 	imp, err := svc.pickImporter(seeker)
 	if err != nil {
 		return 0, err
@@ -36,6 +36,11 @@ func (n NetscapeExporter) Export(bookmarks iter.Seq[types.Bookmark], w io.Writer
 			Added:    time.Now().UTC(),
 			Modified: time.Now().UTC(),
 		}
+		unlistedFolder = &netscape.Folder{
+			Title:    "Unlisted bookmarks",
+			Added:    time.Now().UTC(),
+			Modified: time.Now().UTC(),
+		}
 	)
 
 	for bookmark := range bookmarks {
@@ -45,6 +50,8 @@ func (n NetscapeExporter) Export(bookmarks iter.Seq[types.Bookmark], w io.Writer
 			publicFolder.Items = append(publicFolder.Items, nb)
 		case types.Private:
 			privateFolder.Items = append(privateFolder.Items, nb)
+		case types.Unlisted:
+			unlistedFolder.Items = append(unlistedFolder.Items, nb)
 		}
 	}
 
@@ -54,6 +61,9 @@ func (n NetscapeExporter) Export(bookmarks iter.Seq[types.Bookmark], w io.Writer
 		Modified: now.UTC(),
 		Items:    []netscape.Item{publicFolder},
 	}
+	if len(unlistedFolder.Items) > 0 {
+		rootFolder.Items = append(rootFolder.Items, unlistedFolder)
+	}
 	if len(privateFolder.Items) > 0 {
 		rootFolder.Items = append(rootFolder.Items, privateFolder)
 	}
@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package importers
+
+import (
+	"io"
+	"iter"
+	"slices"
+
+	"git.sr.ht/~bouncepaw/betula/pkg/imex/chrome"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+// chromeRoots are the built-in folders in the order the browser shows them.
+var chromeRoots = []string{"bookmark_bar", "other", "synced"}
+
+type ChromeImporter struct{}
+
+func NewChromeImporter() *ChromeImporter {
+	return &ChromeImporter{}
+}
+
+func (c *ChromeImporter) Probe(seeker io.ReadSeeker) (bool, error) {
+	return chrome.Probe(seeker)
+}
+
+func (c *ChromeImporter) Import(r io.Reader) (iter.Seq2[types.Bookmark, error], error) {
+	file, err := chrome.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	return func(yield func(types.Bookmark, error) bool) {
+		for _, name := range chromeRoots {
+			// The built-in folders are not part of the path.
+			for _, child := range file.Roots[name].Children {
+				if !importChromeNode(child, nil, yield) {
+					return
+				}
+			}
+		}
+	}, nil
+}
+
+func importChromeNode(node chrome.Node, path []string, yield func(types.Bookmark, error) bool) bool {
+	switch node.Type {
+	case chrome.TypeURL:
+		return yield(types.Bookmark{
+			CreationTime: node.Added().UTC().Format(types.TimeLayout),
+			URL:          node.URL,
+			Title:        node.Name,
+			Tags:         tagsFromNames(path),
+			Visibility:   types.Private,
+		}, nil)
+	case chrome.TypeFolder:
+		path = append(slices.Clip(path), node.Name)
+		for _, child := range node.Children {
+			if !importChromeNode(child, path, yield) {
+				return false
+			}
+		}
+	}
+	return true
+}
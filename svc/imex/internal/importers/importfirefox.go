@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package importers
+
+import (
+	"io"
+	"iter"
+	"slices"
+
+	"git.sr.ht/~bouncepaw/betula/pkg/bxstr"
+	"git.sr.ht/~bouncepaw/betula/pkg/imex/firefox"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+type FirefoxImporter struct{}
+
+func NewFirefoxImporter() *FirefoxImporter {
+	return &FirefoxImporter{}
+}
+
+func (f *FirefoxImporter) Probe(seeker io.ReadSeeker) (bool, error) {
+	return firefox.Probe(seeker)
+}
+
+func (f *FirefoxImporter) Import(r io.Reader) (iter.Seq2[types.Bookmark, error], error) {
+	root, err := firefox.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	return func(yield func(types.Bookmark, error) bool) {
+		importFirefoxNode(root, nil, yield)
+	}, nil
+}
+
+// importFirefoxNode yields the bookmarks in the node. The built-in folders,
+// like the toolbar, are not part of the path, the folders made by the user are.
+func importFirefoxNode(node firefox.Node, path []string, yield func(types.Bookmark, error) bool) bool {
+	switch node.Type {
+	case firefox.TypeBookmark:
+		return yield(firefoxBookmarkToDomain(node, path), nil)
+	case firefox.TypeFolder:
+		if node.Root == "" {
+			path = append(slices.Clip(path), node.Title)
+		}
+		for _, child := range node.Children {
+			if !importFirefoxNode(child, path, yield) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func firefoxBookmarkToDomain(node firefox.Node, path []string) types.Bookmark {
+	return types.Bookmark{
+		CreationTime: node.Added().UTC().Format(types.TimeLayout),
+		URL:          node.URI,
+		Title:        node.Title,
+		Description:  node.Description(),
+		Tags:         tagsFromNames(bxstr.CommaSeparated(node.Tags), path),
+		Visibility:   types.Private,
+	}
+}
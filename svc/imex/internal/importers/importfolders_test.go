@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package importers
+
+import (
+	"os"
+	"testing"
+
+	"github.com/nalgeon/be"
+
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+// The bookmark files are the ones the packages in pkg/imex are tested with.
+func TestFolderPathsAsTags(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Firefox", func(t *testing.T) {
+		t.Parallel()
+		f, err := os.Open("../../../../pkg/imex/firefox/testdata/firefox1.json")
+		be.Equal(t, err, nil)
+		defer f.Close()
+
+		seq, err := NewFirefoxImporter().Import(f)
+		be.Equal(t, err, nil)
+		bookmarks, err := collectSortBookmarks(seq)
+		be.Equal(t, err, nil)
+		be.Equal(t, len(bookmarks), 3)
+
+		be.Equal(t, bookmarks[0].URL, "https://bouncepaw.com/")
+		be.Equal(t, bookmarks[0].Tags, []types.Tag(nil))
+		be.Equal(t, bookmarks[2].URL, "https://mycorrhiza.wiki/")
+		be.Equal(t, bookmarks[2].Description, "A wiki engine.")
+		be.Equal(t, bookmarks[2].Tags, []types.Tag{{Name: "wiki"}, {Name: "software"}, {Name: "wikis"}, {Name: "engines"}})
+	})
+
+	t.Run("Chrome", func(t *testing.T) {
+		t.Parallel()
+		f, err := os.Open("../../../../pkg/imex/chrome/testdata/Bookmarks")
+		be.Equal(t, err, nil)
+		defer f.Close()
+
+		seq, err := NewChromeImporter().Import(f)
+		be.Equal(t, err, nil)
+		bookmarks, err := collectSortBookmarks(seq)
+		be.Equal(t, err, nil)
+		be.Equal(t, len(bookmarks), 3)
+
+		be.Equal(t, bookmarks[1].URL, "https://joinbetula.org/")
+		be.Equal(t, bookmarks[1].Tags, []types.Tag(nil))
+		be.Equal(t, bookmarks[2].Tags, []types.Tag{{Name: "wikis"}, {Name: "engines"}})
+		be.Equal(t, bookmarks[2].CreationTime, "2026-04-05 20:34:06")
+	})
+}
+
+func TestReadStatesAsTags(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("../../../../pkg/imex/linkding/testdata/linkding1.json")
+	be.Equal(t, err, nil)
+	defer f.Close()
+
+	seq, err := NewLinkdingImporter().Import(f)
+	be.Equal(t, err, nil)
+	bookmarks, err := collectSortBookmarks(seq)
+	be.Equal(t, err, nil)
+	be.Equal(t, len(bookmarks), 3)
+
+	betula := bookmarks[1]
+	be.Equal(t, betula.Title, "Betula")
+	be.Equal(t, betula.Description, "A bookmarking engine.\n\nFederated too.")
+	be.Equal(t, betula.Visibility, types.Public)
+	be.Equal(t, betula.Tags, []types.Tag{{Name: "bookmarks"}, {Name: "software"}, {Name: tagUnread}})
+
+	mycorrhiza := bookmarks[2]
+	be.Equal(t, mycorrhiza.Visibility, types.Private)
+	be.Equal(t, mycorrhiza.Tags, []types.Tag{{Name: tagArchived}})
+}
@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package importers
+
+import (
+	"cmp"
+	"io"
+	"iter"
+	"strings"
+
+	"git.sr.ht/~bouncepaw/betula/pkg/imex/linkding"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+type LinkdingImporter struct{}
+
+func NewLinkdingImporter() *LinkdingImporter {
+	return &LinkdingImporter{}
+}
+
+func (l *LinkdingImporter) Probe(seeker io.ReadSeeker) (bool, error) {
+	return linkding.Probe(seeker)
+}
+
+func (l *LinkdingImporter) Import(r io.Reader) (iter.Seq2[types.Bookmark, error], error) {
+	bookmarks, err := linkding.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	return func(yield func(types.Bookmark, error) bool) {
+		for _, b := range bookmarks {
+			if !yield(linkdingBookmarkToDomain(b), nil) {
+				return
+			}
+		}
+	}, nil
+}
+
+func linkdingBookmarkToDomain(b linkding.Bookmark) types.Bookmark {
+	// linkding shows the page's own title and description when the user
+	// did not set theirs.
+	title := cmp.Or(b.Title, b.WebsiteTitle)
+	description := cmp.Or(b.Description, b.WebsiteDescription)
+	if b.Notes != "" {
+		description = strings.TrimSpace(description + "\n\n" + b.Notes)
+	}
+
+	var states []string
+	if b.Unread {
+		states = append(states, tagUnread)
+	}
+	if b.IsArchived {
+		states = append(states, tagArchived)
+	}
+
+	visibility := types.Private
+	if b.Shared {
+		visibility = types.Public
+	}
+
+	return types.Bookmark{
+		CreationTime: b.DateAdded.UTC().Format(types.TimeLayout),
+		URL:          b.URL,
+		Title:        title,
+		Description:  description,
+		Tags:         tagsFromNames(b.TagNames, states),
+		Visibility:   visibility,
+	}
+}
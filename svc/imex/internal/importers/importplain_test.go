@@ -14,6 +14,7 @@ import (
 
 	"github.com/nalgeon/be"
 
+	"git.sr.ht/~bouncepaw/betula/pkg/feedparse"
 	wwwports "git.sr.ht/~bouncepaw/betula/ports/www"
 	"git.sr.ht/~bouncepaw/betula/types"
 )
@@ -26,10 +27,22 @@ func (f fakeWWW) TitleOfPage(addr string) (string, error) {
 	return f.titles[addr], nil
 }
 
+func (f fakeWWW) MetadataOfPage(addr string) (wwwports.PageMetadata, error) {
+	return wwwports.PageMetadata{Title: f.titles[addr]}, nil
+}
+
 func (f fakeWWW) RelAlternates(addr string) ([]wwwports.RelAlternate, error) {
 	return nil, nil
 }
 
+func (f fakeWWW) CheckLink(addr string) (wwwports.LinkStatus, error) {
+	return wwwports.LinkStatus{StatusCode: 200, FinalURL: addr}, nil
+}
+
+func (f fakeWWW) FetchFeed(addr string) (feedparse.Feed, error) {
+	return feedparse.Feed{}, feedparse.ErrNotFeed
+}
+
 type fakeErringReader struct {
 	err error
 }
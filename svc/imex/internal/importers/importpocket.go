@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package importers
+
+import (
+	"io"
+	"iter"
+
+	"git.sr.ht/~bouncepaw/betula/pkg/imex/pocket"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+type PocketImporter struct{}
+
+func NewPocketImporter() *PocketImporter {
+	return &PocketImporter{}
+}
+
+func (p *PocketImporter) Probe(seeker io.ReadSeeker) (bool, error) {
+	return pocket.Probe(seeker)
+}
+
+func (p *PocketImporter) Import(r io.Reader) (iter.Seq2[types.Bookmark, error], error) {
+	bookmarks, err := pocket.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	return func(yield func(types.Bookmark, error) bool) {
+		for _, b := range bookmarks {
+			if !yield(pocketBookmarkToDomain(b), nil) {
+				return
+			}
+		}
+	}, nil
+}
+
+func pocketBookmarkToDomain(b pocket.Bookmark) types.Bookmark {
+	state := tagUnread
+	if b.Archived {
+		state = tagArchived
+	}
+	return types.Bookmark{
+		CreationTime: b.Added.UTC().Format(types.TimeLayout),
+		URL:          b.URL,
+		Title:        b.Title,
+		Tags:         tagsFromNames(b.Tags, []string{state}),
+		Visibility:   types.Private,
+	}
+}
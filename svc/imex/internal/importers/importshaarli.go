@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package importers
+
+import (
+	"io"
+	"iter"
+
+	"git.sr.ht/~bouncepaw/betula/pkg/imex/shaarli"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+type ShaarliImporter struct{}
+
+func NewShaarliImporter() *ShaarliImporter {
+	return &ShaarliImporter{}
+}
+
+func (s *ShaarliImporter) Probe(seeker io.ReadSeeker) (bool, error) {
+	return shaarli.Probe(seeker)
+}
+
+func (s *ShaarliImporter) Import(r io.Reader) (iter.Seq2[types.Bookmark, error], error) {
+	bookmarks, err := shaarli.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	return func(yield func(types.Bookmark, error) bool) {
+		for _, b := range bookmarks {
+			if !yield(shaarliBookmarkToDomain(b), nil) {
+				return
+			}
+		}
+	}, nil
+}
+
+func shaarliBookmarkToDomain(b shaarli.Bookmark) types.Bookmark {
+	visibility := types.Public
+	if b.Private {
+		visibility = types.Private
+	}
+	return types.Bookmark{
+		CreationTime: b.Created.UTC().Format(types.TimeLayout),
+		URL:          b.URL,
+		Title:        b.Title,
+		Description:  b.Description,
+		Tags:         tagsFromNames(b.Tags),
+		Visibility:   visibility,
+	}
+}
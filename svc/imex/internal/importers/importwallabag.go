@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package importers
+
+import (
+	"io"
+	"iter"
+	"time"
+
+	"git.sr.ht/~bouncepaw/betula/pkg/imex/wallabag"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+type WallabagImporter struct{}
+
+func NewWallabagImporter() *WallabagImporter {
+	return &WallabagImporter{}
+}
+
+func (w *WallabagImporter) Probe(seeker io.ReadSeeker) (bool, error) {
+	return wallabag.Probe(seeker)
+}
+
+func (w *WallabagImporter) Import(r io.Reader) (iter.Seq2[types.Bookmark, error], error) {
+	entries, err := wallabag.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	return func(yield func(types.Bookmark, error) bool) {
+		for _, e := range entries {
+			if !yield(wallabagEntryToDomain(e), nil) {
+				return
+			}
+		}
+	}, nil
+}
+
+// wallabagEntryToDomain converts the entry. The article text is not kept,
+// it is not a description. Archive the bookmark to keep the text.
+func wallabagEntryToDomain(e wallabag.Entry) types.Bookmark {
+	state := tagUnread
+	if e.IsArchived {
+		state = tagArchived
+	}
+	return types.Bookmark{
+		CreationTime: time.Time(e.CreatedAt).UTC().Format(types.TimeLayout),
+		URL:          e.URL,
+		Title:        e.Title,
+		Tags:         tagsFromNames(e.Tags, []string{state}),
+		Visibility:   types.Private,
+	}
+}
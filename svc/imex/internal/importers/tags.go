@@ -0,0 +1,28 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package importers
+
+import "git.sr.ht/~bouncepaw/betula/types"
+
+// Betula has no read state, so the services that have it get it
+// kept as tags.
+const (
+	tagUnread   = "unread"
+	tagArchived = "archived"
+)
+
+// tagsFromNames makes canonical tags of the names, skipping the empty ones.
+// Folder paths are passed here too, each folder becomes a tag.
+func tagsFromNames(names ...[]string) []types.Tag {
+	var tags []types.Tag
+	for _, group := range names {
+		for _, name := range group {
+			if name = types.CanonicalTagName(name); name != "" {
+				tags = append(tags, types.Tag{Name: name})
+			}
+		}
+	}
+	return tags
+}
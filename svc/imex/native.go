@@ -0,0 +1,452 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package imexsvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+
+	"git.sr.ht/~bouncepaw/betula/pkg/httpsig"
+	"git.sr.ht/~bouncepaw/betula/pkg/imex/betula"
+	imexports "git.sr.ht/~bouncepaw/betula/ports/imex"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+// Files of a Betula archive.
+const (
+	fileBookmarks = "bookmarks.jsonl"
+	fileTags      = "tags.jsonl"
+	fileArtifacts = "artifacts.jsonl"
+	fileArchives  = "archives.jsonl"
+	fileImages    = "images.jsonl"
+	fileLikes     = "likes.jsonl"
+	fileActors    = "actors.jsonl"
+	fileFollowers = "followers.jsonl"
+	fileFollowing = "following.jsonl"
+	fileBlocks    = "blocks.jsonl"
+	fileSettings  = "settings.jsonl"
+	fileKey       = "key.jsonl"
+
+	artifactsDir = "artifacts/"
+)
+
+// writeAll writes the records as a JSON Lines file.
+func writeAll[T any](w *betula.Writer, name string, records []T) error {
+	return w.WriteLines(name, func(write func(any) error) error {
+		for _, record := range records {
+			if err := write(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// readAll reads a JSON Lines file.
+func readAll[T any](r *betula.Reader, name string) ([]T, error) {
+	var records []T
+	err := r.ReadLines(name, func(decode func(any) error) error {
+		var record T
+		if err := decode(&record); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		records = append(records, record)
+		return nil
+	})
+	return records, err
+}
+
+func (svc *Service) exportNative(
+	ctx context.Context,
+	params imexports.ExportParams,
+	w io.Writer,
+) error {
+	current := svc.settingsFn()
+	aw, err := betula.NewWriter(w, betula.Manifest{
+		Created: time.Now().UTC(),
+		Site:    current.SiteURL,
+	})
+	if err != nil {
+		return err
+	}
+
+	bookmarks, err := svc.native.NativeBookmarks(ctx, params.IncludePrivate)
+	if err != nil {
+		return fmt.Errorf("failed to read bookmarks: %w", err)
+	}
+	exported := make(map[int]bool, len(bookmarks))
+	for _, bm := range bookmarks {
+		exported[bm.ID] = true
+	}
+
+	// Archives, images and likes of the bookmarks left out are left out too.
+	archives, err := svc.native.Archives(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read archives: %w", err)
+	}
+	archives = slices.DeleteFunc(archives, func(a imexports.NativeArchive) bool { return !exported[a.BookmarkID] })
+	images, err := svc.native.Images(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read bookmark images: %w", err)
+	}
+	images = slices.DeleteFunc(images, func(i imexports.NativeImage) bool { return !exported[i.BookmarkID] })
+	likes, err := svc.native.Likes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read likes: %w", err)
+	}
+	likes = slices.DeleteFunc(likes, func(like imexports.NativeLike) bool {
+		if like.ActorID == nil {
+			return false
+		}
+		id, err := strconv.Atoi(like.ObjectID)
+		return err != nil || !exported[id]
+	})
+
+	tags, err := svc.native.TagDescriptions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read tag descriptions: %w", err)
+	}
+	actors, err := svc.native.Actors(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read actors: %w", err)
+	}
+	followers, err := svc.native.Followers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read followers: %w", err)
+	}
+	following, err := svc.native.Following(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read following: %w", err)
+	}
+	blocks, err := svc.native.Blocks(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read blocks: %w", err)
+	}
+
+	err = errors.Join(
+		writeAll(aw, fileBookmarks, bookmarks),
+		writeAll(aw, fileTags, tags),
+		writeAll(aw, fileArchives, archives),
+		writeAll(aw, fileImages, images),
+		writeAll(aw, fileLikes, likes),
+		writeAll(aw, fileActors, actors),
+		writeAll(aw, fileFollowers, followers),
+		writeAll(aw, fileFollowing, following),
+		writeAll(aw, fileBlocks, blocks),
+		writeAll(aw, fileSettings, []imexports.NativeSettings{nativeSettingsOf(current)}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write records: %w", err)
+	}
+	if params.IncludeKey {
+		if err = svc.exportKey(ctx, aw); err != nil {
+			return err
+		}
+	}
+
+	// The artifacts are read one by one, they might be big.
+	var artifacts []imexports.NativeArtifact
+	for _, id := range artifactIDs(archives, images) {
+		artifact, data, err := svc.native.Artifact(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to read artifact %s: %w", id, err)
+		}
+		if err = aw.WriteBlob(artifactsDir+id, data); err != nil {
+			return fmt.Errorf("failed to write artifact %s: %w", id, err)
+		}
+		artifacts = append(artifacts, artifact)
+	}
+	if err = writeAll(aw, fileArtifacts, artifacts); err != nil {
+		return fmt.Errorf("failed to write artifacts: %w", err)
+	}
+
+	slog.Info("Exported Betula archive",
+		"bookmarkCount", len(bookmarks), "archiveCount", len(archives), "artifactCount", len(artifacts))
+	return aw.Close()
+}
+
+// exportKey writes the actor's private key, if there is one. Whoever has
+// the archive can then sign requests as the actor.
+func (svc *Service) exportKey(ctx context.Context, aw *betula.Writer) error {
+	pem, err := svc.native.PrivateKey(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read private key: %w", err)
+	}
+	if pem == "" {
+		return nil
+	}
+	if err = writeAll(aw, fileKey, []imexports.NativeKey{{PrivateKeyPEM: pem}}); err != nil {
+		return fmt.Errorf("failed to write private key: %w", err)
+	}
+	return nil
+}
+
+// artifactIDs returns the IDs of the artifacts used by the archives and the
+// images, each once.
+func artifactIDs(archives []imexports.NativeArchive, images []imexports.NativeImage) []string {
+	var ids []string
+	for _, archive := range archives {
+		ids = append(ids, archive.ArtifactID)
+	}
+	for _, image := range images {
+		ids = append(ids, image.ArtifactID)
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+// importNative restores a Betula archive. Bookmarks keep their IDs unless
+// they are taken, in which case everything referring them is updated.
+// Actors, follows, blocks and tag descriptions already present are kept.
+// params.MakePublic is ignored, the archive knows the visibility already.
+func (svc *Service) importNative(
+	ctx context.Context,
+	params imexports.ImportParams,
+	seeker io.ReadSeeker,
+) (uint, error) {
+	archive, err := betula.Open(seeker)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		errs      []error
+		okCount   uint
+		skipCount uint
+		// newIDs maps the IDs from the archive to the IDs in the database.
+		newIDs = make(map[int]int)
+	)
+	bookmarks, err := readAll[imexports.NativeBookmark](archive, fileBookmarks)
+	if err != nil {
+		return 0, err
+	}
+	for _, bm := range bookmarks {
+		bm.Tags = append(bm.Tags, params.AddTags...)
+
+		if !params.KeepDuplicate {
+			free, err := svc.bookmarkURLIsFree(ctx, bm.URL)
+			if err != nil {
+				slog.Warn("Failed to check if URL is already bookmarked", "url", bm.URL, "err", err)
+				errs = append(errs, fmt.Errorf("failed to check if url %s is bookmarked already: %w", bm.URL, err))
+				continue
+			}
+			if !free {
+				slog.Info("Skipping duplicate bookmark", "url", bm.URL)
+				skipCount++
+				continue
+			}
+		}
+
+		id, err := svc.native.RestoreBookmark(ctx, bm)
+		if err != nil {
+			slog.Warn("Failed to restore bookmark", "url", bm.URL, "err", err)
+			errs = append(errs, fmt.Errorf("failed to restore bookmark %s: %w", bm.URL, err))
+			continue
+		}
+		newIDs[bm.ID] = id
+		okCount++
+	}
+
+	errs = append(errs,
+		svc.restoreArchives(ctx, archive, newIDs),
+		svc.restoreLikes(ctx, archive, newIDs),
+		restoreEach(ctx, archive, fileTags, svc.native.RestoreTagDescription),
+		restoreEach(ctx, archive, fileActors, svc.native.RestoreActor),
+		restoreEach(ctx, archive, fileFollowers, svc.native.RestoreFollower),
+		restoreEach(ctx, archive, fileFollowing, svc.native.RestoreFollowing),
+		restoreEach(ctx, archive, fileBlocks, svc.native.RestoreBlock),
+	)
+	if params.RestoreSettings {
+		errs = append(errs, svc.restoreSettings(archive))
+	}
+	if params.RestoreKey {
+		errs = append(errs, svc.restoreKey(ctx, archive))
+	}
+
+	slog.Info("Betula archive import done",
+		"site", archive.Manifest().Site, "okCount", okCount, "skipCount", skipCount)
+	return okCount, errors.Join(errs...)
+}
+
+// restoreEach restores every record of the file.
+func restoreEach[T any](
+	ctx context.Context,
+	archive *betula.Reader,
+	name string,
+	restore func(context.Context, T) error,
+) error {
+	records, err := readAll[T](archive, name)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, record := range records {
+		if err = restore(ctx, record); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore a record of %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// restoreArchives restores the archives and the images of the imported
+// bookmarks, with the artifacts they use.
+func (svc *Service) restoreArchives(ctx context.Context, archive *betula.Reader, newIDs map[int]int) error {
+	archives, err := readAll[imexports.NativeArchive](archive, fileArchives)
+	if err != nil {
+		return err
+	}
+	images, err := readAll[imexports.NativeImage](archive, fileImages)
+	if err != nil {
+		return err
+	}
+	artifacts, err := readAll[imexports.NativeArtifact](archive, fileArtifacts)
+	if err != nil {
+		return err
+	}
+
+	var restored []imexports.NativeArchive
+	for _, a := range archives {
+		if id, ok := newIDs[a.BookmarkID]; ok {
+			a.BookmarkID = id
+			restored = append(restored, a)
+		}
+	}
+	var restoredImages []imexports.NativeImage
+	for _, i := range images {
+		if id, ok := newIDs[i.BookmarkID]; ok {
+			i.BookmarkID = id
+			restoredImages = append(restoredImages, i)
+		}
+	}
+
+	var errs []error
+	needed := artifactIDs(restored, restoredImages)
+	for _, artifact := range artifacts {
+		if _, found := slices.BinarySearch(needed, artifact.ID); !found {
+			continue
+		}
+		data, err := archive.ReadBlob(artifactsDir + artifact.ID)
+		if err == nil {
+			err = svc.native.RestoreArtifact(ctx, artifact, data)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore artifact %s: %w", artifact.ID, err))
+		}
+	}
+	for _, a := range restored {
+		if err = svc.native.RestoreArchive(ctx, a); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore archive of bookmark %d: %w", a.BookmarkID, err))
+		}
+	}
+	for _, i := range restoredImages {
+		if err = svc.native.RestoreImage(ctx, i); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore image of bookmark %d: %w", i.BookmarkID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// restoreLikes restores our likes and the likes of the imported bookmarks.
+func (svc *Service) restoreLikes(ctx context.Context, archive *betula.Reader, newIDs map[int]int) error {
+	likes, err := readAll[imexports.NativeLike](archive, fileLikes)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, like := range likes {
+		if like.ActorID != nil {
+			oldID, err := strconv.Atoi(like.ObjectID)
+			newID, ok := newIDs[oldID]
+			if err != nil || !ok {
+				continue
+			}
+			like.ObjectID = strconv.Itoa(newID)
+		}
+		if err = svc.native.RestoreLike(ctx, like); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore like of %s: %w", like.ObjectID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// restoreSettings applies the settings from the archive. The network
+// address stays as it is.
+func (svc *Service) restoreSettings(archive *betula.Reader) error {
+	records, err := readAll[imexports.NativeSettings](archive, fileSettings)
+	if err != nil || len(records) == 0 {
+		return err
+	}
+	current := svc.settingsFn()
+	s := records[0]
+	svc.setSettingsFn(types.Settings{
+		NetworkHost:               current.NetworkHost,
+		NetworkPort:               current.NetworkPort,
+		SiteName:                  s.SiteName,
+		SiteTitle:                 template.HTML(s.SiteTitle),
+		SiteDescriptionMycomarkup: s.SiteDescriptionMycomarkup,
+		SiteURL:                   s.SiteURL,
+		CustomCSS:                 s.CustomCSS,
+		FederationEnabled:         s.FederationEnabled,
+		PublicCustomJS:            s.PublicCustomJS,
+		PrivateCustomJS:           s.PrivateCustomJS,
+		BackfillPages:             s.BackfillPages,
+		ManuallyApproveFollowers:  s.ManuallyApproveFollowers,
+		AutoArchive:               s.AutoArchive,
+		AutoArchiveTags:           s.AutoArchiveTags,
+		AutoArchiveSharedOnly:     s.AutoArchiveSharedOnly,
+		ArchiveQuota:              s.ArchiveQuota,
+		ArchiveQuotaEvict:         s.ArchiveQuotaEvict,
+		TrackingParams:            s.TrackingParams,
+	})
+	slog.Info("Restored settings from Betula archive")
+	return nil
+}
+
+// restoreKey replaces the actor's private key with the one from the
+// archive, so other servers keep trusting the signatures without fetching
+// the key anew. Archives made without the key change nothing.
+func (svc *Service) restoreKey(ctx context.Context, archive *betula.Reader) error {
+	records, err := readAll[imexports.NativeKey](archive, fileKey)
+	if err != nil || len(records) == 0 {
+		return err
+	}
+	pem := records[0].PrivateKeyPEM
+	if _, _, err = httpsig.DecodeKey(pem); err != nil {
+		return fmt.Errorf("bad private key in the archive: %w", err)
+	}
+	if err = svc.native.RestorePrivateKey(ctx, pem); err != nil {
+		return fmt.Errorf("failed to restore private key: %w", err)
+	}
+	slog.Info("Restored private key from Betula archive")
+	return nil
+}
+
+func nativeSettingsOf(s types.Settings) imexports.NativeSettings {
+	return imexports.NativeSettings{
+		SiteName:                  s.SiteName,
+		SiteTitle:                 string(s.SiteTitle),
+		SiteDescriptionMycomarkup: s.SiteDescriptionMycomarkup,
+		SiteURL:                   s.SiteURL,
+		CustomCSS:                 s.CustomCSS,
+		FederationEnabled:         s.FederationEnabled,
+		PublicCustomJS:            s.PublicCustomJS,
+		PrivateCustomJS:           s.PrivateCustomJS,
+		BackfillPages:             s.BackfillPages,
+		ManuallyApproveFollowers:  s.ManuallyApproveFollowers,
+		AutoArchive:               s.AutoArchive,
+		AutoArchiveTags:           s.AutoArchiveTags,
+		AutoArchiveSharedOnly:     s.AutoArchiveSharedOnly,
+		ArchiveQuota:              s.ArchiveQuota,
+		ArchiveQuotaEvict:         s.ArchiveQuotaEvict,
+		TrackingParams:            s.TrackingParams,
+	}
+}
@@ -0,0 +1,132 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package imexsvc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"database/sql"
+	"testing"
+
+	"github.com/nalgeon/be"
+
+	"git.sr.ht/~bouncepaw/betula/db"
+	"git.sr.ht/~bouncepaw/betula/pkg/httpsig"
+	imexports "git.sr.ht/~bouncepaw/betula/ports/imex"
+	likingports "git.sr.ht/~bouncepaw/betula/ports/liking"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+func TestNativeRoundtrip(t *testing.T) {
+	ctx := t.Context()
+	var restored types.Settings
+	svc := New(db.NewLocalBookmarksRepo(), db.NewNativeRepo(), nil,
+		func() string { return "Betula" },
+		func() types.Settings {
+			return types.Settings{SiteURL: "https://links.example", SiteName: "Links", NetworkPort: 1738}
+		},
+		func(s types.Settings) { restored = s })
+
+	db.InitInMemoryDB()
+	remark := "So true"
+	_, err := db.NewLocalBookmarksRepo().InsertBookmark(ctx, types.Bookmark{
+		URL: "https://remarked.example", Title: "Remarked", Visibility: types.Public,
+		RemarkText: &remark, Tags: []types.Tag{{Name: "remarks"}},
+	})
+	be.Err(t, err, nil)
+	_, err = db.NewArchivesRepo().Store(1, &types.Artifact{
+		ID: "abc", MimeType: "text/html", Data: []byte("<p>Archived</p>"), Kind: types.ArchiveRaw,
+	})
+	be.Err(t, err, nil)
+	be.Err(t, db.NewLikeRepo().InsertLike(ctx, likingports.LikeModel{
+		ID:       sql.NullString{String: "https://remote.example/likes/1", Valid: true},
+		ActorID:  sql.NullString{String: "https://remote.example/@alice", Valid: true},
+		ObjectID: "2",
+	}), nil)
+	be.Err(t, db.NewTagsRepo().SetTagDescription(ctx, "remarks", "What others said"), nil)
+
+	var buf bytes.Buffer
+	be.Err(t, svc.Export(ctx, imexports.ExportParams{Format: imexports.ExportFormatBetula, IncludePrivate: true}, &buf), nil)
+
+	// Importing into a Betula with the same testing bookmarks: only the new one is not a duplicate.
+	db.InitInMemoryDB()
+	count, err := svc.Import(ctx, imexports.ImportParams{}, bytes.NewReader(buf.Bytes()))
+	be.Err(t, err, nil)
+	be.Equal(t, count, uint(1))
+
+	// Keeping the duplicates: the IDs are taken, so 1, 2 and 4 become 4, 5 and 6.
+	db.InitInMemoryDB()
+	count, err = svc.Import(ctx, imexports.ImportParams{KeepDuplicate: true, RestoreSettings: true}, bytes.NewReader(buf.Bytes()))
+	be.Err(t, err, nil)
+	be.Equal(t, count, uint(3))
+	be.Equal(t, restored.SiteName, "Links")
+	be.Equal(t, restored.NetworkPort, uint(1738))
+
+	native := db.NewNativeRepo()
+	bookmarks, err := native.NativeBookmarks(ctx, true)
+	be.Err(t, err, nil)
+	be.Equal(t, len(bookmarks), 5)
+	be.Equal(t, bookmarks[4].ID, 6)
+	be.Equal(t, *bookmarks[4].RemarkText, remark)
+	be.Equal(t, bookmarks[4].Tags, []string{"remarks"})
+
+	archives, err := native.Archives(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(archives), 1)
+	be.Equal(t, archives[0].BookmarkID, 4)
+	_, data, err := native.Artifact(ctx, "abc")
+	be.Err(t, err, nil)
+	be.Equal(t, string(data), "<p>Archived</p>")
+
+	likes, err := native.Likes(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(likes), 1)
+	be.Equal(t, likes[0].ObjectID, "5")
+
+	tags, err := native.TagDescriptions(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, tags, []imexports.NativeTag{{Name: "remarks", Description: "What others said"}})
+}
+
+func TestNativeKey(t *testing.T) {
+	ctx := t.Context()
+	svc := New(db.NewLocalBookmarksRepo(), db.NewNativeRepo(), nil,
+		func() string { return "Betula" },
+		func() types.Settings { return types.Settings{} },
+		func(types.Settings) {})
+	export := func(includeKey bool) []byte {
+		var buf bytes.Buffer
+		be.Err(t, svc.Export(ctx, imexports.ExportParams{Format: imexports.ExportFormatBetula, IncludeKey: includeKey}, &buf), nil)
+		return buf.Bytes()
+	}
+
+	db.InitInMemoryDB()
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	be.Err(t, err, nil)
+	pem, err := httpsig.EncodeKey(key)
+	be.Err(t, err, nil)
+	native := db.NewNativeRepo()
+	be.Err(t, native.RestorePrivateKey(ctx, pem), nil)
+	withKey, withoutKey := export(true), export(false)
+
+	// The key is restored only from an archive that has it, and only if asked.
+	for _, tc := range []struct {
+		archive    []byte
+		restoreKey bool
+		want       string
+	}{
+		{withKey, false, ""},
+		{withoutKey, true, ""},
+		{withKey, true, pem},
+	} {
+		db.InitInMemoryDB()
+		_, err = svc.Import(ctx, imexports.ImportParams{RestoreKey: tc.restoreKey}, bytes.NewReader(tc.archive))
+		be.Err(t, err, nil)
+		got, err := native.PrivateKey(ctx)
+		be.Err(t, err, nil)
+		be.Equal(t, got, tc.want)
+	}
+}
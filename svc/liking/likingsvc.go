@@ -16,6 +16,7 @@ import (
 	apports "git.sr.ht/~bouncepaw/betula/ports/activitypub"
 	likingports "git.sr.ht/~bouncepaw/betula/ports/liking"
 	notifports "git.sr.ht/~bouncepaw/betula/ports/notif"
+	webhooksports "git.sr.ht/~bouncepaw/betula/ports/webhooks"
 	"git.sr.ht/~bouncepaw/betula/types"
 )
 
@@ -25,6 +26,7 @@ type Service struct {
 	likeCollectionRepo likingports.LikeCollectionRepository
 	localBookmarkRepo  likingports.LocalBookmarkRepository
 	notifRepo          notifports.Repository
+	webhooks           webhooksports.Service
 
 	activityPub apports.ActivityPub
 	asm         apports.Assembly
@@ -37,6 +39,7 @@ func New(
 	likeCollectionRepo likingports.LikeCollectionRepository,
 	localBookmarkRepo likingports.LocalBookmarkRepository,
 	notifRepo notifports.Repository,
+	webhooks webhooksports.Service,
 
 	activityPub apports.ActivityPub,
 	asm apports.Assembly,
@@ -47,6 +50,7 @@ func New(
 		likeCollectionRepo: likeCollectionRepo,
 		localBookmarkRepo:  localBookmarkRepo,
 		notifRepo:          notifRepo,
+		webhooks:           webhooks,
 
 		activityPub: activityPub,
 		asm:         asm,
@@ -11,7 +11,7 @@ import (
 
 	"git.sr.ht/~bouncepaw/betula/pkg/bxstr"
 	likingports "git.sr.ht/~bouncepaw/betula/ports/liking"
-	"git.sr.ht/~bouncepaw/betula/types"
+	webhooksports "git.sr.ht/~bouncepaw/betula/ports/webhooks"
 	notiftypes "git.sr.ht/~bouncepaw/betula/types/notif"
 )
 
@@ -25,9 +25,9 @@ func (svc *Service) ReceiveLike(ctx context.Context, event likingports.EventLike
 	if err != nil {
 		return err
 	}
-	// Only public bookmarks are to be liked.
-	if bookmark.Visibility != types.Public {
-		return fmt.Errorf("local bookmark %d is not public", localBookmarkID)
+	// Only federated bookmarks are to be liked.
+	if !bookmark.Visibility.Federated() {
+		return fmt.Errorf("local bookmark %d is not federated", localBookmarkID)
 	}
 
 	likeModel := likingports.LikeModel{
@@ -47,6 +47,10 @@ func (svc *Service) ReceiveLike(ctx context.Context, event likingports.EventLike
 	if err != nil {
 		return err
 	}
+	svc.webhooks.FireForBookmark(ctx, localBookmarkID, webhooksports.Payload{
+		Event:   webhooksports.EventLikeReceived,
+		ActorID: event.ActorID,
+	})
 
 	go svc.broadcastBookmarkUpdate(localBookmarkID)
 
@@ -93,7 +97,7 @@ func (svc *Service) broadcastBookmarkAsUpdated(
 	if err != nil {
 		return err
 	}
-	if bookmark.Visibility != types.Public {
+	if !bookmark.Visibility.Federated() {
 		return nil
 	}
 
@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+// Package linkrotsvc finds bookmarks whose links died or moved.
+package linkrotsvc
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	linkrotports "git.sr.ht/~bouncepaw/betula/ports/linkrot"
+	wwwports "git.sr.ht/~bouncepaw/betula/ports/www"
+)
+
+// RecheckAfter is how old a check has to be for the link to be checked again.
+const RecheckAfter = 7 * 24 * time.Hour
+
+type Service struct {
+	logger *slog.Logger
+	repo   linkrotports.Repository
+	www    wwwports.WorldWideWeb
+}
+
+var _ linkrotports.Service = &Service{}
+
+func New(repo linkrotports.Repository, www wwwports.WorldWideWeb) *Service {
+	return &Service{
+		logger: slog.Default(),
+		repo:   repo,
+		www:    www,
+	}
+}
+
+func (svc *Service) CheckStale(ctx context.Context, limit int) (int, error) {
+	bookmarks, err := svc.repo.StaleBookmarks(ctx, time.Now().Add(-RecheckAfter), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, bookmark := range bookmarks {
+		check := linkrotports.LinkCheck{BookmarkID: bookmark.ID}
+		status, err := svc.www.CheckLink(bookmark.URL)
+		if err != nil {
+			check.Error = err.Error()
+		} else {
+			check.Status = status.StatusCode
+			check.FinalURL = status.FinalURL
+		}
+		svc.logger.Debug("Checked link", "bookmarkID", bookmark.ID, "url", bookmark.URL,
+			"status", check.Status, "finalURL", check.FinalURL, "err", check.Error)
+
+		if err := svc.repo.StoreCheck(ctx, check); err != nil {
+			return 0, err
+		}
+	}
+	return len(bookmarks), nil
+}
+
+func (svc *Service) Problems(ctx context.Context) ([]linkrotports.LinkCheck, error) {
+	checks, err := svc.repo.Checks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []linkrotports.LinkCheck
+	for _, check := range checks {
+		if check.Dead() || check.Redirected() {
+			problems = append(problems, check)
+		}
+	}
+	return problems, nil
+}
@@ -63,6 +63,9 @@ var (
 </div>`))
 	followNotificationTemplate = template.Must(template.New("follow notification").Parse(`<div class="notif" notif-cat="follow">
 	<span class="actor-link">{{.Author}}</span> followed you!
+</div>`))
+	followRequestNotificationTemplate = template.Must(template.New("follow request notification").Parse(`<div class="notif" notif-cat="follow">
+	<span class="actor-link">{{.Author}}</span> asked to follow you. <a href="/followers">Review follow requests.</a>
 </div>`))
 	remarkNotificationTemplate = template.Must(template.New("remark notification").Parse(`<div class="notif" notif-cat="remark">
 	<span class="actor-link">{{.Author}}</span> <a href="{{.RemarkURL}}">remarked</a> <a href="/{{.BookmarkID}}">{{.BookmarkID}}.</a>{{if .RemarkText}}<blockquote>{{.RemarkText}}</blockquote>{{end}}
@@ -86,7 +89,9 @@ func (n *renderedNotification) AsHTML() template.HTML {
 	case notiftypes.KindLike:
 		html, err = n.likeAsHTML()
 	case notiftypes.KindFollow:
-		html, err = n.followAsHTML()
+		html, err = n.followAsHTML(followNotificationTemplate)
+	case notiftypes.KindFollowRequest:
+		html, err = n.followAsHTML(followRequestNotificationTemplate)
 	case notiftypes.KindRemark:
 		html, err = n.remarkAsHTML()
 	}
@@ -118,7 +123,7 @@ func (n *renderedNotification) likeAsHTML() (template.HTML, error) {
 	)
 }
 
-func (n *renderedNotification) followAsHTML() (template.HTML, error) {
+func (n *renderedNotification) followAsHTML(tmpl *template.Template) (template.HTML, error) {
 	var payload notiftypes.FollowPayload
 	if err := json.Unmarshal(n.Payload, &payload); err != nil {
 		return "", err
@@ -129,7 +134,7 @@ func (n *renderedNotification) followAsHTML() (template.HTML, error) {
 		return "", err
 	}
 	return renderTemplate(
-		followNotificationTemplate,
+		tmpl,
 		notificationTemplateData{
 			Author: author,
 		},
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	remarkingports "git.sr.ht/~bouncepaw/betula/ports/remarking"
+	webhooksports "git.sr.ht/~bouncepaw/betula/ports/webhooks"
 	"git.sr.ht/~bouncepaw/betula/types"
 	notiftypes "git.sr.ht/~bouncepaw/betula/types/notif"
 )
@@ -19,7 +20,7 @@ func (svc *Service) BroadcastCreateRemark(ctx context.Context, bookmark types.Bo
 	switch {
 	case !svc.federationEnabledFn():
 		return nil
-	case bookmark.Visibility != types.Public:
+	case !bookmark.Visibility.Federated():
 		return nil
 	case bookmark.RemarkedID == nil:
 		return fmt.Errorf("bookmark %d. of %q is not a remark", bookmark.ID, bookmark.URL)
@@ -100,6 +101,11 @@ func (svc *Service) ReceiveCreateRemark(
 	if err != nil {
 		return fmt.Errorf("failed to store remark notification: %w", err)
 	}
+	svc.webhooks.FireForBookmark(ctx, id, webhooksports.Payload{
+		Event:     webhooksports.EventRemarkReceived,
+		ActorID:   event.Bookmark.ActorID,
+		RemarkURL: event.Bookmark.RepresentationURL(),
+	})
 
 	return nil
 }
@@ -9,6 +9,7 @@ import (
 	notifports "git.sr.ht/~bouncepaw/betula/ports/notif"
 	remarkingports "git.sr.ht/~bouncepaw/betula/ports/remarking"
 	remotebookmarksports "git.sr.ht/~bouncepaw/betula/ports/remotebookmarks"
+	webhooksports "git.sr.ht/~bouncepaw/betula/ports/webhooks"
 )
 
 type Service struct {
@@ -18,6 +19,7 @@ type Service struct {
 	actorRepo           apports.ActorRepository
 	assembly            apports.Assembly
 	notifRepo           notifports.Repository
+	webhooks            webhooksports.Service
 
 	federationEnabledFn func() bool
 }
@@ -31,6 +33,7 @@ func New(
 	actorRepo apports.ActorRepository,
 	assembly apports.Assembly,
 	notifRepo notifports.Repository,
+	webhooks webhooksports.Service,
 	federationEnabledFn func() bool,
 ) *Service {
 	return &Service{
@@ -40,6 +43,7 @@ func New(
 		actorRepo:           actorRepo,
 		assembly:            assembly,
 		notifRepo:           notifRepo,
+		webhooks:            webhooks,
 		federationEnabledFn: federationEnabledFn,
 	}
 }
@@ -0,0 +1,241 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package searchingsvc
+
+import (
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	searchingports "git.sr.ht/~bouncepaw/betula/ports/searching"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+// token is a single whitespace-separated piece of a query, with the
+// negation, prefix and quotes already recognized.
+type token struct {
+	raw     string // As written, used when the token turns out to be plain text.
+	negated bool
+	prefix  string // Lowercase, without the colon. Empty if none.
+	value   string
+	quoted  bool
+}
+
+// tokenize splits the query into tokens. Quoted parts may contain spaces.
+// An unterminated quote runs till the end of the query.
+func tokenize(query string) (tokens []token) {
+	var rs = []rune(query)
+	for i := 0; i < len(rs); {
+		if unicode.IsSpace(rs[i]) {
+			i++
+			continue
+		}
+
+		var start = i
+		var tok token
+		if rs[i] == '-' && i+1 < len(rs) && !unicode.IsSpace(rs[i+1]) {
+			tok.negated = true
+			i++
+		}
+
+		var valueStart = i
+		for i < len(rs) && !unicode.IsSpace(rs[i]) && rs[i] != '"' && rs[i] != ':' {
+			i++
+		}
+		if i < len(rs) && rs[i] == ':' && isKnownPrefix(string(rs[valueStart:i])) {
+			tok.prefix = strings.ToLower(string(rs[valueStart:i]))
+			i++
+			valueStart = i
+		} else {
+			i = valueStart
+		}
+
+		if i < len(rs) && rs[i] == '"' {
+			i++
+			var closing = i
+			for closing < len(rs) && rs[closing] != '"' {
+				closing++
+			}
+			tok.value = string(rs[i:closing])
+			tok.quoted = true
+			i = min(closing+1, len(rs))
+		} else {
+			for i < len(rs) && !unicode.IsSpace(rs[i]) {
+				i++
+			}
+			tok.value = string(rs[valueStart:i])
+		}
+
+		tok.raw = string(rs[start:i])
+		tokens = append(tokens, tok)
+	}
+	return tokens
+}
+
+var knownPrefixes = []string{"title", "url", "site", "before", "after", "is", "has", "remark", "in"}
+
+func isKnownPrefix(s string) bool {
+	for _, prefix := range knownPrefixes {
+		if strings.EqualFold(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (tok token) isOr() bool {
+	return tok.raw == "OR"
+}
+
+// isPunctuation reports whether the token is plain text with no letters or
+// digits, like a lone dash. Such text matches nearly everything, so it is dropped.
+func (tok token) isPunctuation() bool {
+	if tok.prefix != "" || tok.quoted {
+		return false
+	}
+	return !strings.ContainsFunc(tok.value, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	})
+}
+
+func (tok token) isInArchive() bool {
+	return tok.prefix == "in" && !tok.negated && !tok.quoted && strings.EqualFold(tok.value, "archive")
+}
+
+// parse parses the query into an expression. Terms are joined with AND,
+// which binds tighter than OR. It never fails: whatever is not understood is
+// looked up as text, except for bare punctuation, which is dropped. An empty
+// query yields nil.
+//
+// If the query has in:archive anywhere, plain text is looked up in
+// the archives instead. Without other terms, in:archive is has:archive.
+func parse(query string) searchingports.Expr {
+	var (
+		tokens   = tokenize(query)
+		scope    = searchingports.FieldAny
+		branches searchingports.Or
+		current  searchingports.And
+	)
+	tokens = slices.DeleteFunc(tokens, token.isPunctuation)
+	if slices.ContainsFunc(tokens, token.isInArchive) {
+		scope = searchingports.FieldArchive
+		tokens = slices.DeleteFunc(tokens, token.isInArchive)
+		if len(tokens) == 0 {
+			return searchingports.Has(searchingports.FeatureArchive)
+		}
+	}
+	for i, tok := range tokens {
+		// OR is an operator only between two terms. Otherwise, it is text.
+		if tok.isOr() && len(current) > 0 && i+1 < len(tokens) && !tokens[i+1].isOr() {
+			branches = append(branches, simplifyAnd(current))
+			current = nil
+			continue
+		}
+		current = append(current, exprFromToken(tok, scope))
+	}
+	if len(current) > 0 {
+		branches = append(branches, simplifyAnd(current))
+	}
+
+	switch len(branches) {
+	case 0:
+		return nil
+	case 1:
+		return branches[0]
+	default:
+		return branches
+	}
+}
+
+func simplifyAnd(and searchingports.And) searchingports.Expr {
+	if len(and) == 1 {
+		return and[0]
+	}
+	return and
+}
+
+func exprFromToken(tok token, scope searchingports.Field) searchingports.Expr {
+	var expr = positiveExprFromToken(tok, scope)
+	if tok.negated {
+		return searchingports.Not{Expr: expr}
+	}
+	return expr
+}
+
+// positiveExprFromToken makes an expression of the token, ignoring the minus.
+// Plain text is looked up in scope.
+func positiveExprFromToken(tok token, scope searchingports.Field) searchingports.Expr {
+	var asText = searchingports.Text{
+		Field:  scope,
+		Text:   strings.TrimPrefix(tok.raw, "-"),
+		Phrase: tok.quoted && tok.prefix == "",
+	}
+	if tok.prefix == "" {
+		if !tok.quoted && strings.HasPrefix(tok.value, "#") {
+			if name := types.CanonicalTagName(tok.value[1:]); name != "" {
+				return searchingports.Tag(name)
+			}
+		}
+		asText.Text = tok.value
+		return asText
+	}
+
+	var value = strings.TrimSpace(tok.value)
+	switch tok.prefix {
+	case "title", "url":
+		if value == "" {
+			return asText
+		}
+		var field = searchingports.FieldTitle
+		if tok.prefix == "url" {
+			field = searchingports.FieldURL
+		}
+		return searchingports.Text{Field: field, Text: value, Phrase: tok.quoted}
+	case "site":
+		if host := siteHost(value); host != "" {
+			return searchingports.Site(host)
+		}
+	case "before", "after":
+		day, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return asText
+		}
+		if tok.prefix == "before" {
+			return searchingports.Before(day)
+		}
+		return searchingports.After(day)
+	case "is":
+		switch strings.ToLower(value) {
+		case "private":
+			return searchingports.Is(types.Private)
+		case "public":
+			return searchingports.Is(types.Public)
+		case "unlisted":
+			return searchingports.Is(types.Unlisted)
+		}
+	case "has":
+		switch strings.ToLower(value) {
+		case "archive":
+			return searchingports.Has(searchingports.FeatureArchive)
+		case "description":
+			return searchingports.Has(searchingports.FeatureDescription)
+		}
+	case "remark":
+		return searchingports.Remark{URL: value}
+	}
+	return asText
+}
+
+// siteHost turns the argument of site: into a lowercase host. Protocol,
+// path and a trailing slash are allowed and dropped.
+func siteHost(value string) string {
+	value = strings.ToLower(value)
+	if _, after, found := strings.Cut(value, "://"); found {
+		value = after
+	}
+	value, _, _ = strings.Cut(value, "/")
+	return value
+}
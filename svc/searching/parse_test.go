@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package searchingsvc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+
+	searchingports "git.sr.ht/~bouncepaw/betula/ports/searching"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		query    string
+		expected searchingports.Expr
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"granny", searchingports.Text{Text: "granny"}},
+		{"granny smith", searchingports.And{searchingports.Text{Text: "granny"}, searchingports.Text{Text: "smith"}}},
+		{`"granny smith"`, searchingports.Text{Text: "granny smith", Phrase: true}},
+		{`"granny smith`, searchingports.Text{Text: "granny smith", Phrase: true}},
+		{"#apple -#Pear", searchingports.And{searchingports.Tag("apple"), searchingports.Not{Expr: searchingports.Tag("pear")}}},
+		{"-granny", searchingports.Not{Expr: searchingports.Text{Text: "granny"}}},
+		{"- granny", searchingports.Text{Text: "granny"}},
+		{"granny - smith", searchingports.And{searchingports.Text{Text: "granny"}, searchingports.Text{Text: "smith"}}},
+		{"- — & ...", nil},
+		{`"-"`, searchingports.Text{Text: "-", Phrase: true}},
+		{`title:"granny smith"`, searchingports.Text{Field: searchingports.FieldTitle, Text: "granny smith", Phrase: true}},
+		{"URL:apple", searchingports.Text{Field: searchingports.FieldURL, Text: "apple"}},
+		{"site:https://Example.org/path", searchingports.Site("example.org")},
+		{"before:2024-01-01", searchingports.Before(day)},
+		{"after:2024-01-01", searchingports.After(day)},
+		{"after:yesterday", searchingports.Text{Text: "after:yesterday"}},
+		{"is:private", searchingports.Is(types.Private)},
+		{"is:public", searchingports.Is(types.Public)},
+		{"is:unlisted", searchingports.Is(types.Unlisted)},
+		{"is:cute", searchingports.Text{Text: "is:cute"}},
+		{"has:archive", searchingports.Has(searchingports.FeatureArchive)},
+		{"has:description", searchingports.Has(searchingports.FeatureDescription)},
+		{"remark:", searchingports.Remark{}},
+		{"remark:https://example.org/1", searchingports.Remark{URL: "https://example.org/1"}},
+		{"https://example.org", searchingports.Text{Text: "https://example.org"}},
+		{"apple OR pear", searchingports.Or{searchingports.Text{Text: "apple"}, searchingports.Text{Text: "pear"}}},
+		{"granny smith OR #pear", searchingports.Or{
+			searchingports.And{searchingports.Text{Text: "granny"}, searchingports.Text{Text: "smith"}},
+			searchingports.Tag("pear"),
+		}},
+		{"OR apple", searchingports.And{searchingports.Text{Text: "OR"}, searchingports.Text{Text: "apple"}}},
+		{"apple OR", searchingports.And{searchingports.Text{Text: "apple"}, searchingports.Text{Text: "OR"}}},
+		{"apple or pear", searchingports.And{searchingports.Text{Text: "apple"}, searchingports.Text{Text: "or"}, searchingports.Text{Text: "pear"}}},
+		{"in:archive", searchingports.Has(searchingports.FeatureArchive)},
+		{"apple in:archive title:pear", searchingports.And{
+			searchingports.Text{Field: searchingports.FieldArchive, Text: "apple"},
+			searchingports.Text{Field: searchingports.FieldTitle, Text: "pear"},
+		}},
+		{`in:Archive -"granny smith"`, searchingports.Not{Expr: searchingports.Text{Field: searchingports.FieldArchive, Text: "granny smith", Phrase: true}}},
+		{"in:attic", searchingports.Text{Text: "in:attic"}},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			t.Parallel()
+			be.Equal(t, parse(test.query), test.expected)
+		})
+	}
+}
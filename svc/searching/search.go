@@ -11,20 +11,11 @@ package searchingsvc
 import (
 	"context"
 	"log/slog"
-	"regexp"
 
 	searchingports "git.sr.ht/~bouncepaw/betula/ports/searching"
 	"git.sr.ht/~bouncepaw/betula/types"
 )
 
-var (
-	// TODO: Exclude more characters
-	excludeTagRe = regexp.MustCompile(`-#([^?!:#@<>*|'"&%{}\\\s]+)\s*`)
-	includeTagRe = regexp.MustCompile(`#([^?!:#@<>*|'"&%{}\\\s]+)\s*`)
-	// TODO: argument will be added in a future version
-	includeRemarkRe = regexp.MustCompile(`\bremark:()\s*`)
-)
-
 type Service struct {
 	repo searchingports.Repository
 }
@@ -40,15 +31,10 @@ func (svc *Service) ForFederated(query string, offset, limit uint) (bookmarks []
 		limit = types.BookmarksPerPage
 	}
 
-	query, excludedTags := svc.extractWithRegex(query, excludeTagRe)
-	query, includedTags := svc.extractWithRegex(query, includeTagRe)
-
 	bookmarks, totalBookmarks, err := svc.repo.SearchOffset(context.Background(), searchingports.OffsetQuery{
-		Text:         query,
-		IncludedTags: includedTags,
-		ExcludedTags: excludedTags,
-		Offset:       offset,
-		Limit:        limit,
+		Expr:   parse(query),
+		Offset: offset,
+		Limit:  limit,
 	})
 	if err != nil {
 		slog.Error("Failed to run federated search", "query", query, "err", err)
@@ -58,17 +44,10 @@ func (svc *Service) ForFederated(query string, offset, limit uint) (bookmarks []
 }
 
 func (svc *Service) For(query string, authorized bool, page uint) (bookmarksInPage []types.Bookmark, totalBookmarks uint) {
-	query, excludedTags := svc.extractWithRegex(query, excludeTagRe)
-	query, includedTags := svc.extractWithRegex(query, includeTagRe)
-	query, includedRemarkMarkers := svc.extractWithRegex(query, includeRemarkRe)
-
 	bookmarksInPage, totalBookmarks, err := svc.repo.Search(context.Background(), searchingports.Query{
-		Text:         query,
-		IncludedTags: includedTags,
-		ExcludedTags: excludedTags,
-		RemarksOnly:  len(includedRemarkMarkers) != 0,
-		Authorized:   authorized,
-		Page:         page,
+		Expr:       parse(query),
+		Authorized: authorized,
+		Page:       page,
 	})
 	if err != nil {
 		slog.Error("Failed to run search", "query", query, "err", err)
@@ -77,11 +56,22 @@ func (svc *Service) For(query string, authorized bool, page uint) (bookmarksInPa
 	return bookmarksInPage, totalBookmarks
 }
 
-func (svc *Service) extractWithRegex(query string, regex *regexp.Regexp) (string, []string) {
-	var extracted []string
-	for _, result := range regex.FindAllStringSubmatch(query, -1) {
-		extracted = append(extracted, result[1])
+func (svc *Service) ArchiveMatches(query string, authorized bool, bookmarks []types.Bookmark) map[int]types.ArchiveMatch {
+	if len(bookmarks) == 0 {
+		return nil
+	}
+	var ids = make([]int, len(bookmarks))
+	for i, bookmark := range bookmarks {
+		ids[i] = bookmark.ID
+	}
+
+	matches, err := svc.repo.ArchiveMatches(context.Background(), searchingports.Query{
+		Expr:       parse(query),
+		Authorized: authorized,
+	}, ids)
+	if err != nil {
+		slog.Error("Failed to find matching archives", "query", query, "err", err)
+		return nil
 	}
-	query = regex.ReplaceAllString(query, "")
-	return query, extracted
+	return matches
 }
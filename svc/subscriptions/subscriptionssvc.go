@@ -0,0 +1,223 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+// Package subscriptionssvc polls external feeds and suggests their
+// entries for bookmarking.
+package subscriptionssvc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"git.sr.ht/~bouncepaw/betula/pkg/feedparse"
+	subscriptionsports "git.sr.ht/~bouncepaw/betula/ports/subscriptions"
+	wwwports "git.sr.ht/~bouncepaw/betula/ports/www"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+// RepollAfter is how old a poll has to be for the feed to be polled again.
+const RepollAfter = time.Hour
+
+// feedTypes are the types of <link rel="alternate"> that lead to feeds.
+var feedTypes = []string{"application/rss+xml", "application/atom+xml"}
+
+type Service struct {
+	logger *slog.Logger
+	repo   subscriptionsports.Repository
+	www    wwwports.WorldWideWeb
+}
+
+var _ subscriptionsports.Service = &Service{}
+
+func New(repo subscriptionsports.Repository, www wwwports.WorldWideWeb) *Service {
+	return &Service{
+		logger: slog.Default(),
+		repo:   repo,
+		www:    www,
+	}
+}
+
+func (svc *Service) Subscribe(ctx context.Context, addr string) (subscriptionsports.Subscription, error) {
+	addr = strings.TrimSpace(addr)
+	feedURL, feed, err := svc.discover(addr)
+	if err != nil {
+		return subscriptionsports.Subscription{}, err
+	}
+
+	sub := subscriptionsports.Subscription{
+		URL:     feedURL,
+		Title:   feed.Title,
+		SiteURL: resolve(feedURL, feed.Link),
+	}
+	if sub.Title == "" {
+		sub.Title = feedURL
+	}
+	sub.ID, err = svc.repo.AddSubscription(ctx, sub)
+	if err != nil {
+		return sub, err
+	}
+	svc.logger.Info("Subscribed to feed", "id", sub.ID, "url", feedURL)
+
+	if _, err = svc.store(ctx, sub, feed, nil); err != nil {
+		return sub, err
+	}
+	return sub, nil
+}
+
+// discover returns the feed at the address, or the first feed the web page
+// at the address links to.
+func (svc *Service) discover(addr string) (string, feedparse.Feed, error) {
+	feed, err := svc.www.FetchFeed(addr)
+	if err == nil {
+		return addr, feed, nil
+	} else if !errors.Is(err, feedparse.ErrNotFeed) {
+		return "", feed, err
+	}
+
+	alternates, err := svc.www.RelAlternates(addr)
+	if err != nil {
+		return "", feed, err
+	}
+	for _, alt := range alternates {
+		if !isFeedType(alt.Type) || alt.Href == "" {
+			continue
+		}
+		href := alt.ResolveHref(addr)
+		svc.logger.Info("Found feed link", "page", addr, "href", href)
+		feed, err = svc.www.FetchFeed(href)
+		return href, feed, err
+	}
+	return "", feed, subscriptionsports.ErrNoFeedFound
+}
+
+func isFeedType(typ string) bool {
+	typ, _, _ = strings.Cut(typ, ";")
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	for _, feedType := range feedTypes {
+		if typ == feedType {
+			return true
+		}
+	}
+	return false
+}
+
+func (svc *Service) Unsubscribe(ctx context.Context, id int64) error {
+	return svc.repo.RemoveSubscription(ctx, id)
+}
+
+func (svc *Service) Subscriptions(ctx context.Context) ([]subscriptionsports.Subscription, error) {
+	return svc.repo.Subscriptions(ctx)
+}
+
+func (svc *Service) PollAll(ctx context.Context) (int, error) {
+	subs, err := svc.repo.Subscriptions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int
+	for _, sub := range subs {
+		if time.Since(sub.LastPolled) < RepollAfter {
+			continue
+		}
+		feed, pollErr := svc.www.FetchFeed(sub.URL)
+		added, err := svc.store(ctx, sub, feed, pollErr)
+		if err != nil {
+			// The other subscriptions might be fine.
+			svc.logger.Error("Failed to store poll", "id", sub.ID, "url", sub.URL, "err", err)
+			continue
+		}
+		total += added
+	}
+	return total, nil
+}
+
+// store records the poll of the subscription and adds the entries of the
+// feed as suggestions, unless polling failed.
+func (svc *Service) store(ctx context.Context, sub subscriptionsports.Subscription, feed feedparse.Feed, pollErr error) (int, error) {
+	if pollErr != nil {
+		svc.logger.Warn("Failed to poll feed", "id", sub.ID, "url", sub.URL, "err", pollErr)
+		return 0, svc.repo.StorePoll(ctx, sub.ID, "", "", pollErr.Error())
+	}
+
+	err := svc.repo.StorePoll(ctx, sub.ID, feed.Title, resolve(sub.URL, feed.Link), "")
+	if err != nil {
+		return 0, err
+	}
+	added, err := svc.repo.AddSuggestions(ctx, suggestionsOf(sub, feed))
+	if err != nil {
+		return 0, err
+	}
+	svc.logger.Info("Polled feed", "id", sub.ID, "url", sub.URL, "entries", len(feed.Entries), "new", added)
+	return added, nil
+}
+
+// suggestionsOf turns the entries with web links into suggestions.
+func suggestionsOf(sub subscriptionsports.Subscription, feed feedparse.Feed) []subscriptionsports.Suggestion {
+	var suggestions []subscriptionsports.Suggestion
+	for _, entry := range feed.Entries {
+		link := resolve(sub.URL, entry.Link)
+		if !webURL(link) || entry.ID == "" {
+			continue
+		}
+		suggestion := subscriptionsports.Suggestion{
+			SubscriptionID: sub.ID,
+			EntryID:        entry.ID,
+			URL:            link,
+			Title:          entry.Title,
+			Published:      entry.Published,
+		}
+		if suggestion.Title == "" {
+			suggestion.Title = link
+		}
+		seen := make(map[string]bool)
+		for _, category := range entry.Categories {
+			name := types.CanonicalTagName(category)
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			suggestion.Tags = append(suggestion.Tags, types.Tag{Name: name})
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions
+}
+
+// resolve makes the possibly relative link absolute.
+func resolve(base, link string) string {
+	if link == "" {
+		return ""
+	}
+	return wwwports.RelAlternate{Href: link}.ResolveHref(base)
+}
+
+func webURL(addr string) bool {
+	u, err := url.ParseRequestURI(addr)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func (svc *Service) Suggestions(ctx context.Context) ([]subscriptionsports.Suggestion, error) {
+	return svc.repo.UnreadSuggestions(ctx)
+}
+
+func (svc *Service) Suggestion(ctx context.Context, id int64) (subscriptionsports.Suggestion, error) {
+	return svc.repo.SuggestionByID(ctx, id)
+}
+
+func (svc *Service) MarkSaved(ctx context.Context, id int64) error {
+	return svc.repo.SetSuggestionState(ctx, id, subscriptionsports.SuggestionSaved)
+}
+
+func (svc *Service) Dismiss(ctx context.Context, id int64) error {
+	return svc.repo.SetSuggestionState(ctx, id, subscriptionsports.SuggestionDismissed)
+}
+
+func (svc *Service) DismissAll(ctx context.Context) error {
+	return svc.repo.DismissAll(ctx)
+}
@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package subscriptionssvc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+
+	"git.sr.ht/~bouncepaw/betula/db"
+	"git.sr.ht/~bouncepaw/betula/pkg/feedparse"
+	subscriptionsports "git.sr.ht/~bouncepaw/betula/ports/subscriptions"
+	wwwports "git.sr.ht/~bouncepaw/betula/ports/www"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+// fakeWWW has a blog page linking to its feed.
+type fakeWWW struct {
+	wwwports.WorldWideWeb
+	feed feedparse.Feed
+}
+
+func (f *fakeWWW) FetchFeed(addr string) (feedparse.Feed, error) {
+	if addr != "https://blog.example.org/feed.xml" {
+		return feedparse.Feed{}, feedparse.ErrNotFeed
+	}
+	return f.feed, nil
+}
+
+func (f *fakeWWW) RelAlternates(addr string) ([]wwwports.RelAlternate, error) {
+	if addr != "https://blog.example.org" {
+		return nil, nil
+	}
+	return []wwwports.RelAlternate{
+		{Type: "application/activity+json", Href: "https://blog.example.org/actor"},
+		{Type: "application/rss+xml", Href: "/feed.xml"},
+	}, nil
+}
+
+func TestSubscribe(t *testing.T) {
+	db.InitInMemoryDB()
+	ctx := t.Context()
+	www := &fakeWWW{feed: feedparse.Feed{
+		Title: "Example blog",
+		Link:  "/",
+		Entries: []feedparse.Entry{
+			{ID: "1", Title: "First", Link: "/1", Categories: []string{"Web Development", "web development"}},
+			{ID: "2", Title: "", Link: "https://blog.example.org/2", Published: time.Now()},
+			{ID: "3", Title: "Not a web page", Link: "mailto:blog@example.org"},
+		},
+	}}
+	svc := New(db.NewSubscriptionsRepo(), www)
+
+	_, err := svc.Subscribe(ctx, "https://example.org")
+	be.Err(t, err, subscriptionsports.ErrNoFeedFound)
+
+	sub, err := svc.Subscribe(ctx, "https://blog.example.org")
+	be.Err(t, err, nil)
+	be.Equal(t, sub.URL, "https://blog.example.org/feed.xml")
+	be.Equal(t, sub.SiteURL, "https://blog.example.org/")
+	_, err = svc.Subscribe(ctx, "https://blog.example.org/feed.xml")
+	be.Err(t, err, subscriptionsports.ErrAlreadySubscribed)
+
+	suggestions, err := svc.Suggestions(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(suggestions), 2)
+	be.Equal(t, suggestions[0].Title, "https://blog.example.org/2")
+	be.Equal(t, suggestions[1].URL, "https://blog.example.org/1")
+	be.Equal(t, suggestions[1].FeedTitle, "Example blog")
+	be.Equal(t, suggestions[1].Tags, []types.Tag{{Name: "web_development"}})
+
+	// Polled just now, so nothing to poll. Suggestions are never repeated.
+	added, err := svc.PollAll(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, added, 0)
+	be.Err(t, svc.Dismiss(ctx, suggestions[0].ID), nil)
+	www.feed.Entries = append(www.feed.Entries, feedparse.Entry{ID: "4", Title: "Fourth", Link: "/4"})
+	added, err = svc.store(ctx, sub, www.feed, nil)
+	be.Err(t, err, nil)
+	be.Equal(t, added, 1)
+
+	subs, err := svc.Subscriptions(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(subs), 1)
+	be.Equal(t, subs[0].Unread, 2)
+
+	be.Err(t, svc.Unsubscribe(ctx, sub.ID), nil)
+	suggestions, err = svc.Suggestions(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, len(suggestions), 0)
+}
+
+// failingRepo fails to store the polls of the subscription with failID.
+type failingRepo struct {
+	subscriptionsports.Repository
+	failID int64
+	stored []int64
+}
+
+func (r *failingRepo) Subscriptions(context.Context) ([]subscriptionsports.Subscription, error) {
+	return []subscriptionsports.Subscription{
+		{ID: 1, URL: "https://blog.example.org/feed.xml"},
+		{ID: 2, URL: "https://blog.example.org/feed.xml"},
+	}, nil
+}
+
+func (r *failingRepo) StorePoll(_ context.Context, id int64, _, _, _ string) error {
+	if id == r.failID {
+		return errors.New("disk full")
+	}
+	r.stored = append(r.stored, id)
+	return nil
+}
+
+func (r *failingRepo) AddSuggestions(_ context.Context, suggestions []subscriptionsports.Suggestion) (int, error) {
+	return len(suggestions), nil
+}
+
+func TestPollAllGoesOn(t *testing.T) {
+	repo := &failingRepo{failID: 1}
+	www := &fakeWWW{feed: feedparse.Feed{
+		Entries: []feedparse.Entry{{ID: "1", Title: "First", Link: "/1"}},
+	}}
+
+	added, err := New(repo, www).PollAll(t.Context())
+	be.Err(t, err, nil)
+	be.Equal(t, added, 1)
+	be.Equal(t, repo.stored, []int64{2})
+}
@@ -0,0 +1,256 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+// Package webhookssvc posts Betula's events to webhooks.
+package webhookssvc
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	webhooksports "git.sr.ht/~bouncepaw/betula/ports/webhooks"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+const (
+	// SignatureHeader has the HMAC-SHA256 of the body, like sha256=0123abcd.
+	SignatureHeader = "X-Betula-Signature"
+	EventHeader     = "X-Betula-Event"
+	DeliveryHeader  = "X-Betula-Delivery"
+
+	// deliveryLogSize is how many latest deliveries are shown.
+	deliveryLogSize = 50
+)
+
+type Service struct {
+	logger      *slog.Logger
+	repo        webhooksports.Repository
+	client      *http.Client
+	siteURL     func() string
+	userAgentFn func() string
+	// schedule plans an attempt of the delivery through the job queue.
+	schedule func(deliveryID int64)
+}
+
+var _ webhooksports.Service = &Service{}
+
+func New(
+	repo webhooksports.Repository,
+	siteURL func() string,
+	userAgentFn func() string,
+	schedule func(deliveryID int64),
+) *Service {
+	return &Service{
+		logger:      slog.Default(),
+		repo:        repo,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		siteURL:     siteURL,
+		userAgentFn: userAgentFn,
+		schedule:    schedule,
+	}
+}
+
+func (svc *Service) AddWebhook(ctx context.Context, name, addr, secret string, events []webhooksports.Event, includePrivate bool) (webhooksports.Webhook, error) {
+	u, err := url.ParseRequestURI(strings.TrimSpace(addr))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return webhooksports.Webhook{}, webhooksports.ErrBadWebhook
+	}
+	var known []webhooksports.Event
+	for _, event := range events {
+		if slices.Contains(webhooksports.Events, event) && !slices.Contains(known, event) {
+			known = append(known, event)
+		}
+	}
+	if len(known) == 0 {
+		return webhooksports.Webhook{}, webhooksports.ErrBadWebhook
+	}
+
+	hook := webhooksports.Webhook{
+		Name:           strings.TrimSpace(name),
+		URL:            u.String(),
+		Secret:         strings.TrimSpace(secret),
+		Events:         known,
+		IncludePrivate: includePrivate,
+	}
+	if hook.Name == "" {
+		hook.Name = u.Host
+	}
+	if hook.Secret == "" {
+		hook.Secret = randomSecret()
+	}
+	hook.ID, err = svc.repo.AddWebhook(ctx, hook)
+	if err != nil {
+		return hook, err
+	}
+	svc.logger.Info("Added webhook", "id", hook.ID, "url", hook.URL, "events", hook.Events)
+	return hook, nil
+}
+
+func randomSecret() string {
+	bytes := make([]byte, 24)
+	_, _ = rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
+
+func (svc *Service) Webhooks(ctx context.Context) ([]webhooksports.Webhook, error) {
+	return svc.repo.Webhooks(ctx)
+}
+
+func (svc *Service) RemoveWebhook(ctx context.Context, id int64) error {
+	return svc.repo.RemoveWebhook(ctx, id)
+}
+
+func (svc *Service) Fire(ctx context.Context, payload webhooksports.Payload) {
+	hooks, err := svc.repo.Webhooks(ctx)
+	if err != nil {
+		svc.logger.Error("Failed to load webhooks", "event", payload.Event, "err", err)
+		return
+	}
+
+	var (
+		body    []byte
+		private = payload.Bookmark != nil && payload.Bookmark.Visibility == types.Private.String()
+	)
+	for _, hook := range hooks {
+		if !slices.Contains(hook.Events, payload.Event) || (private && !hook.IncludePrivate) {
+			continue
+		}
+		if body == nil {
+			if body, err = svc.marshal(payload); err != nil {
+				svc.logger.Error("Failed to marshal webhook payload", "event", payload.Event, "err", err)
+				return
+			}
+		}
+		id, err := svc.repo.AddDelivery(ctx, hook.ID, payload.Event, body)
+		if err != nil {
+			svc.logger.Error("Failed to store webhook delivery", "webhookID", hook.ID, "event", payload.Event, "err", err)
+			continue
+		}
+		svc.schedule(id)
+	}
+}
+
+func (svc *Service) FireForBookmark(ctx context.Context, bookmarkID int, payload webhooksports.Payload) {
+	bookmark, err := svc.repo.BookmarkByID(ctx, bookmarkID)
+	if err != nil {
+		svc.logger.Error("Failed to load bookmark for webhooks", "bookmarkID", bookmarkID, "event", payload.Event, "err", err)
+		return
+	}
+	payload.Bookmark = webhooksports.BookmarkOf(bookmark)
+	svc.Fire(ctx, payload)
+}
+
+// marshal fills the time and the permalink in and makes the JSON body.
+func (svc *Service) marshal(payload webhooksports.Payload) ([]byte, error) {
+	if payload.Time.IsZero() {
+		payload.Time = time.Now().UTC().Truncate(time.Second)
+	}
+	if payload.Bookmark != nil && payload.Bookmark.Permalink == "" {
+		bookmark := *payload.Bookmark
+		bookmark.Permalink = fmt.Sprintf("%s/%d", svc.siteURL(), bookmark.ID)
+		payload.Bookmark = &bookmark
+	}
+	return json.Marshal(payload)
+}
+
+// Sign returns the signature of the body made with the secret, the way
+// it is sent in SignatureHeader.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (svc *Service) Deliver(ctx context.Context, id int64, lastAttempt bool) error {
+	delivery, err := svc.repo.DeliveryByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		svc.logger.Info("Webhook delivery is gone, skipping", "deliveryID", id)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	hook, err := svc.repo.WebhookByID(ctx, delivery.WebhookID)
+	if errors.Is(err, sql.ErrNoRows) {
+		svc.logger.Info("Webhook is gone, skipping delivery", "deliveryID", id, "webhookID", delivery.WebhookID)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	status, postErr := svc.post(ctx, hook, delivery)
+	var (
+		state      = webhooksports.DeliveryDelivered
+		attemptErr string
+	)
+	if postErr != nil {
+		attemptErr = postErr.Error()
+		state = webhooksports.DeliveryPending
+		if lastAttempt || errors.Is(postErr, webhooksports.ErrRejected) {
+			state = webhooksports.DeliveryFailed
+		}
+	}
+	if err = svc.repo.StoreAttempt(ctx, id, state, status, attemptErr); err != nil {
+		return err
+	}
+	svc.logger.Info("Attempted webhook delivery", "deliveryID", id, "webhookID", hook.ID,
+		"event", delivery.Event, "status", status, "state", state, "err", attemptErr)
+	return postErr
+}
+
+// post sends the delivery and returns the status of the answer.
+func (svc *Service) post(ctx context.Context, hook webhooksports.Webhook, delivery webhooksports.Delivery) (int, error) {
+	rq, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", webhooksports.ErrRejected, err)
+	}
+	rq.Header.Set("Content-Type", "application/json")
+	rq.Header.Set("User-Agent", svc.userAgentFn())
+	rq.Header.Set(EventHeader, string(delivery.Event))
+	rq.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	rq.Header.Set(SignatureHeader, Sign(hook.Secret, delivery.Payload))
+
+	resp, err := svc.client.Do(rq)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return resp.StatusCode, nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout &&
+		resp.StatusCode != http.StatusTooManyRequests:
+		return resp.StatusCode, fmt.Errorf("%w: status %d", webhooksports.ErrRejected, resp.StatusCode)
+	default:
+		return resp.StatusCode, fmt.Errorf("status %d", resp.StatusCode)
+	}
+}
+
+func (svc *Service) Redeliver(ctx context.Context, id int64) error {
+	if err := svc.repo.SetDeliveryState(ctx, id, webhooksports.DeliveryPending); err != nil {
+		return err
+	}
+	svc.schedule(id)
+	return nil
+}
+
+func (svc *Service) Deliveries(ctx context.Context) ([]webhooksports.Delivery, error) {
+	return svc.repo.Deliveries(ctx, deliveryLogSize)
+}
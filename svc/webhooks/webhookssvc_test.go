@@ -0,0 +1,131 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package webhookssvc
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nalgeon/be"
+
+	"git.sr.ht/~bouncepaw/betula/db"
+	webhooksports "git.sr.ht/~bouncepaw/betula/ports/webhooks"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+func TestDeliver(t *testing.T) {
+	db.InitInMemoryDB()
+	ctx := t.Context()
+
+	var (
+		status   = http.StatusNoContent
+		received webhooksports.Payload
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, rq *http.Request) {
+		body, _ := io.ReadAll(rq.Body)
+		if rq.Header.Get(SignatureHeader) != Sign("secret", body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	var scheduled []int64
+	svc := New(db.NewWebhooksRepo(),
+		func() string { return "https://links.example.org" },
+		func() string { return "Betula" },
+		func(id int64) { scheduled = append(scheduled, id) })
+
+	_, err := svc.AddWebhook(ctx, "", "ftp://example.org", "", webhooksports.Events, false)
+	be.Err(t, err, webhooksports.ErrBadWebhook)
+	hook, err := svc.AddWebhook(ctx, "", server.URL, "secret", []webhooksports.Event{webhooksports.EventBookmarkEdited}, false)
+	be.Err(t, err, nil)
+	be.True(t, hook.Name != "")
+
+	svc.FireForBookmark(ctx, 2, webhooksports.Payload{Event: webhooksports.EventBookmarkDeleted})
+	be.Equal(t, len(scheduled), 0)
+	// Bookmark 1 is private.
+	svc.FireForBookmark(ctx, 1, webhooksports.Payload{Event: webhooksports.EventBookmarkEdited})
+	be.Equal(t, len(scheduled), 0)
+	svc.FireForBookmark(ctx, 2, webhooksports.Payload{Event: webhooksports.EventBookmarkEdited})
+	be.Equal(t, len(scheduled), 1)
+
+	be.Err(t, svc.Deliver(ctx, scheduled[0], false), nil)
+	be.Equal(t, received.Event, webhooksports.EventBookmarkEdited)
+	be.Equal(t, received.Bookmark.Permalink, "https://links.example.org/2")
+
+	status = http.StatusServiceUnavailable
+	be.True(t, svc.Deliver(ctx, scheduled[0], false) != nil)
+	deliveries, err := svc.Deliveries(ctx)
+	be.Err(t, err, nil)
+	be.Equal(t, deliveries[0].State, webhooksports.DeliveryPending)
+	be.True(t, svc.Deliver(ctx, scheduled[0], true) != nil)
+	deliveries, _ = svc.Deliveries(ctx)
+	be.Equal(t, deliveries[0].State, webhooksports.DeliveryFailed)
+	be.Equal(t, deliveries[0].Attempts, 3)
+
+	status = http.StatusGone
+	be.Err(t, svc.Redeliver(ctx, scheduled[0]), nil)
+	be.Equal(t, len(scheduled), 2)
+	be.Err(t, svc.Deliver(ctx, scheduled[1], false), webhooksports.ErrRejected)
+	deliveries, _ = svc.Deliveries(ctx)
+	be.Equal(t, deliveries[0].State, webhooksports.DeliveryFailed)
+	be.Equal(t, deliveries[0].LastStatus, http.StatusGone)
+}
+
+func TestFirePrivate(t *testing.T) {
+	db.InitInMemoryDB()
+	ctx := t.Context()
+
+	var scheduled []int64
+	svc := New(db.NewWebhooksRepo(),
+		func() string { return "https://links.example.org" },
+		func() string { return "Betula" },
+		func(id int64) { scheduled = append(scheduled, id) })
+	_, err := svc.AddWebhook(ctx, "Chat", "https://chat.example", "", webhooksports.Events, false)
+	be.Err(t, err, nil)
+	wiki, err := svc.AddWebhook(ctx, "Wiki", "https://wiki.example", "", webhooksports.Events, true)
+	be.Err(t, err, nil)
+
+	// Bookmark 1 is private, deleting it is not told to the chat either.
+	private := webhooksports.Payload{Event: webhooksports.EventBookmarkDeleted, Bookmark: &webhooksports.Bookmark{ID: 1, Visibility: "private"}}
+	svc.Fire(ctx, private)
+	svc.FireForBookmark(ctx, 1, webhooksports.Payload{Event: webhooksports.EventBookmarkEdited})
+	be.Equal(t, len(scheduled), 2)
+	deliveries, err := svc.Deliveries(ctx)
+	be.Err(t, err, nil)
+	for _, delivery := range deliveries {
+		be.Equal(t, delivery.WebhookID, wiki.ID)
+	}
+
+	svc.FireForBookmark(ctx, 2, webhooksports.Payload{Event: webhooksports.EventBookmarkEdited})
+	be.Equal(t, len(scheduled), 4)
+}
+
+func TestBookmarkOfRemark(t *testing.T) {
+	remarkedID := "https://other.example/12"
+	remarkText := "So true"
+	b := webhooksports.BookmarkOf(types.Bookmark{
+		ID:             3,
+		URL:            "https://mycorrhiza.wiki",
+		RemarkedID:     &remarkedID,
+		RemarkText:     &remarkText,
+		OriginalAuthor: sql.NullString{String: "https://other.example/@alice", Valid: true},
+	})
+	be.Equal(t, b.RemarkedID, remarkedID)
+	be.Equal(t, b.RemarkText, remarkText)
+	be.Equal(t, b.OriginalAuthor, "https://other.example/@alice")
+
+	data, err := json.Marshal(webhooksports.BookmarkOf(types.Bookmark{ID: 2}))
+	be.Err(t, err, nil)
+	be.True(t, !strings.Contains(string(data), "remark"))
+}
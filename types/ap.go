@@ -37,6 +37,11 @@ type Actor struct {
 		Owner        string `json:"owner"`
 		PublicKeyPEM string `json:"publicKeyPem"`
 	} `json:"publicKey"`
+	Endpoints struct {
+		// SharedInbox is the inbox shared by the actors on the same server.
+		// Might be empty.
+		SharedInbox string `json:"sharedInbox,omitempty"`
+	} `json:"endpoints"`
 
 	SubscriptionStatus SubscriptionRelation `json:"-"` // Set manually
 	Domain             string               `json:"-"` // Set manually
@@ -48,6 +53,15 @@ func (a Actor) Valid() bool {
 	return urlsOK && nonEmpty
 }
 
+// DeliveryInbox returns the inbox to deliver public activities to: the shared
+// inbox if the actor has a valid one, their own inbox otherwise.
+func (a Actor) DeliveryInbox() string {
+	if bxstr.IsValidURL(a.Endpoints.SharedInbox) {
+		return a.Endpoints.SharedInbox
+	}
+	return a.Inbox
+}
+
 func (a Actor) Acct() string {
 	return fmt.Sprintf("@%s@%s", a.PreferredUsername, a.Domain)
 }
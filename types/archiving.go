@@ -11,6 +11,8 @@ import (
 	"database/sql"
 	"encoding/base64"
 	"fmt"
+	"html/template"
+	"io"
 	"regexp"
 	"strings"
 )
@@ -22,39 +24,62 @@ type Artifact struct {
 	Data      []byte
 	IsGzipped bool
 	Size      int
+	Kind      ArchiveKind
+}
+
+// ArchiveKind is how an archive copy is made.
+type ArchiveKind string
+
+const (
+	// ArchiveAuto picks the kind by the document's MIME type. It is only
+	// used when asking for an archive, artifacts always have a real kind.
+	ArchiveAuto ArchiveKind = "auto"
+	// ArchiveFull is the page with everything it needs inlined.
+	ArchiveFull ArchiveKind = "full"
+	// ArchiveReadable is the main text of the page, without the clutter.
+	ArchiveReadable ArchiveKind = "readable"
+	// ArchiveRaw is the document exactly as it was served.
+	ArchiveRaw ArchiveKind = "raw"
+)
+
+// ArchiveKindFromString returns the kind with the given name.
+// Unknown names mean ArchiveAuto.
+func ArchiveKindFromString(s string) ArchiveKind {
+	switch kind := ArchiveKind(s); kind {
+	case ArchiveFull, ArchiveReadable, ArchiveRaw:
+		return kind
+	default:
+		return ArchiveAuto
+	}
+}
+
+func (k ArchiveKind) HumanName() string {
+	switch k {
+	case ArchiveFull:
+		return "full page"
+	case ArchiveReadable:
+		return "readable text"
+	case ArchiveRaw:
+		return "original file"
+	default:
+		return "automatic"
+	}
 }
 
 // NewCompressedDocumentArtifact makes an Artifact from the given
 // uncompressed document. Artifact.ID is a base64 representation
 // of an SHA-256 hash sum of the document contents. Artifact.MimeType
 // is the source MIME type. Artifact.IsGzipped is true.
-// Artifact.Data is gzipped document contents.
+// Artifact.Data is gzipped document contents. Artifact.Kind is
+// ArchiveFull, set it yourself if the document is something else.
 //
 // Gzip was chosen because it's the most widely accepted content
 // compression algorithm in browsers. This way, we can deliver
 // the document without intermediary recompression.
 func NewCompressedDocumentArtifact(b []byte, mime string) (*Artifact, error) {
-	var id string
-	{
-		var hash = sha256.New()
-		var _, err = hash.Write(b)
-		if err != nil {
-			return nil, fmt.Errorf("failed to write bytes to sha256: %w", err)
-		}
-
-		var buf strings.Builder
-		var encoder = base64.NewEncoder(base64.RawURLEncoding, &buf)
-
-		_, err = encoder.Write(hash.Sum(nil))
-		if err != nil {
-			return nil, fmt.Errorf("failed to calculate base64 hash sum: %w", err)
-		}
-		err = encoder.Close()
-		if err != nil {
-			return nil, fmt.Errorf("failed to calculate base64 hash sum: %w", err)
-		}
-
-		id = buf.String()
+	id, err := artifactID(b)
+	if err != nil {
+		return nil, err
 	}
 
 	var gzipped []byte
@@ -79,19 +104,81 @@ func NewCompressedDocumentArtifact(b []byte, mime string) (*Artifact, error) {
 		MimeType:  mime,
 		Data:      gzipped,
 		IsGzipped: true,
+		Kind:      ArchiveFull,
 	}, nil
 }
 
+// NewArtifact makes an Artifact from the given document without
+// compressing it, which is the way for already compressed documents,
+// like pictures. Artifact.ID is made like in NewCompressedDocumentArtifact.
+// Artifact.Kind is ArchiveRaw.
+func NewArtifact(b []byte, mime string) (*Artifact, error) {
+	id, err := artifactID(b)
+	if err != nil {
+		return nil, err
+	}
+	return &Artifact{
+		ID:       id,
+		MimeType: mime,
+		Data:     b,
+		Size:     len(b),
+		Kind:     ArchiveRaw,
+	}, nil
+}
+
+// artifactID is a base64 representation of an SHA-256 hash sum of b.
+func artifactID(b []byte) (string, error) {
+	var hash = sha256.New()
+	var _, err = hash.Write(b)
+	if err != nil {
+		return "", fmt.Errorf("failed to write bytes to sha256: %w", err)
+	}
+
+	var buf strings.Builder
+	var encoder = base64.NewEncoder(base64.RawURLEncoding, &buf)
+
+	_, err = encoder.Write(hash.Sum(nil))
+	if err != nil {
+		return "", fmt.Errorf("failed to calculate base64 hash sum: %w", err)
+	}
+	err = encoder.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to calculate base64 hash sum: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// Contents returns the document, decompressed if needed.
+func (a *Artifact) Contents() ([]byte, error) {
+	if !a.IsGzipped {
+		return a.Data, nil
+	}
+	r, err := gzip.NewReader(bytes.NewReader(a.Data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress artifact: %w", err)
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
 func (a *Artifact) HumanSize() string {
+	return HumanSize(int64(a.Size))
+}
+
+// HumanSize formats the size in bytes for people.
+func HumanSize(size int64) string {
 	switch {
-	case a.Size == 0:
+	case size == 0:
 		return "empty"
-	case a.Size < 1024:
-		return fmt.Sprintf("%d B", a.Size)
-	case a.Size < 1024*1024:
-		return fmt.Sprintf("%.2f KiB", float64(a.Size)/float64(1024))
+	case size < 1024:
+		return fmt.Sprintf("%d B", size)
+	case size < 1024*1024:
+		return fmt.Sprintf("%.2f KiB", float64(size)/float64(1024))
+	case size < 1024*1024*1024:
+		return fmt.Sprintf("%.2f MiB", float64(size)/float64(1024*1024))
 	default:
-		return fmt.Sprintf("%.2f MiB", float64(a.Size)/float64(1024*1024))
+		return fmt.Sprintf("%.2f GiB", float64(size)/float64(1024*1024*1024))
 	}
 }
 
@@ -110,3 +197,36 @@ type Archive struct {
 	Artifact Artifact
 	SavedAt  sql.NullString
 }
+
+// ArchiveMatch is an archive whose text matches a search query.
+type ArchiveMatch struct {
+	ArchiveID  int64
+	ArtifactID string
+	// Snippet is the matching passage, with the matches in <mark>.
+	Snippet template.HTML
+}
+
+// ArchivedPage is an archive along with the address of the page it is
+// a copy of.
+type ArchivedPage struct {
+	Archive
+	BookmarkID int64
+	URL        string
+}
+
+// ImageKind is what a bookmark image is for.
+type ImageKind string
+
+const (
+	// ImageIcon is the site icon, shown next to the bookmark title.
+	ImageIcon ImageKind = "icon"
+	// ImagePreview is the picture the page chose to represent itself.
+	ImagePreview ImageKind = "preview"
+)
+
+// BookmarkImages are the IDs of the artifacts with the bookmark's images.
+// Empty IDs mean there is no such image.
+type BookmarkImages struct {
+	IconID    string
+	PreviewID string
+}
@@ -52,4 +52,7 @@ const (
 	KindLike   Kind = "like"
 	KindRemark Kind = "remark"
 	KindFollow Kind = "follow"
+	// KindFollowRequest uses FollowPayload. It is stored when a follow
+	// waits for the admin's approval.
+	KindFollowRequest Kind = "follow request"
 )
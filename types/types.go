@@ -20,6 +20,7 @@ import (
 	"html/template"
 	"math"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -31,9 +32,6 @@ import (
 )
 
 // Visibility determines where the bookmark is seen.
-//
-// Perhaps in the future Unlisted visibility will also be introduced,
-// see https://todo.sr.ht/~bouncepaw/betula/65
 type Visibility int
 
 const (
@@ -41,6 +39,9 @@ const (
 	Private Visibility = iota
 	// Public bookmarks are seen by everyone, and are federated.
 	Public
+	// Unlisted bookmarks are seen by everyone who has the link, and are
+	// federated to followers only. They are not listed anywhere.
+	Unlisted
 )
 
 // VisibilityFromString turns a string into a Visbility.
@@ -48,11 +49,30 @@ func VisibilityFromString(s string) Visibility {
 	switch s {
 	case "private":
 		return Private
+	case "unlisted":
+		return Unlisted
 	default:
 		return Public
 	}
 }
 
+// String returns the value understood by VisibilityFromString.
+func (v Visibility) String() string {
+	switch v {
+	case Private:
+		return "private"
+	case Unlisted:
+		return "unlisted"
+	default:
+		return "public"
+	}
+}
+
+// Federated is true if bookmarks of this visibility are sent to followers.
+func (v Visibility) Federated() bool {
+	return v == Public || v == Unlisted
+}
+
 // Bookmark is a link, along with some data.
 type Bookmark struct {
 	// ID is a unique identifier of the bookmark. Do not set this field by yourself.
@@ -100,6 +120,11 @@ type RenderedLocalBookmark struct {
 
 	LikeCounter int
 	LikedByUs   bool
+	// ArchiveMatch is set in search results when an archive of the bookmark
+	// matches the query.
+	ArchiveMatch *ArchiveMatch
+	// IconID is the ID of the artifact with the site icon, if there is one.
+	IconID string
 }
 
 type LocalBookmarkGroup struct {
@@ -202,6 +227,27 @@ type Settings struct {
 	FederationEnabled         bool
 	PublicCustomJS            string
 	PrivateCustomJS           string
+	// BackfillPages is how many outbox pages are fetched for a newly
+	// followed actor. 0 disables backfilling.
+	BackfillPages uint
+	// ManuallyApproveFollowers makes incoming follows wait for the admin's
+	// decision instead of being accepted right away.
+	ManuallyApproveFollowers bool
+	// AutoArchive makes an archive copy of every new bookmark in the background.
+	AutoArchive bool
+	// AutoArchiveTags, if not empty, is a comma-separated list of tags.
+	// Only bookmarks with at least one of them are archived automatically.
+	AutoArchiveTags string
+	// AutoArchiveSharedOnly skips private bookmarks when archiving automatically.
+	AutoArchiveSharedOnly bool
+	// ArchiveQuota is how many MiB archives may take. 0 means no limit.
+	ArchiveQuota uint
+	// ArchiveQuotaEvict deletes the oldest archives when the quota is
+	// reached, instead of refusing to make new ones.
+	ArchiveQuotaEvict bool
+	// TrackingParams is a comma-separated list of query parameters that
+	// are ignored when looking for duplicate bookmarks.
+	TrackingParams string
 }
 
 type Session struct {
@@ -211,6 +257,37 @@ type Session struct {
 	Current   bool
 }
 
+// TokenScope is what an access token lets its bearer do.
+type TokenScope string
+
+const (
+	// ScopeRead lets read everything the admin sees.
+	ScopeRead TokenScope = "read"
+	// ScopeWrite lets save, edit and delete bookmarks, with their tags, and
+	// make archives of them. Tags themselves are not renamed or described.
+	ScopeWrite TokenScope = "write"
+)
+
+// TokenScopes are all known scopes.
+var TokenScopes = []TokenScope{ScopeRead, ScopeWrite}
+
+// AccessToken is a personal access token for the JSON API. The token itself
+// is not stored.
+type AccessToken struct {
+	ID     int64
+	Name   string
+	Scopes []TokenScope
+	// CreationTime and LastUsed are like 2006-01-02 15:04:05.
+	// LastUsed is empty if the token was never used.
+	CreationTime string
+	LastUsed     string
+}
+
+// Allows is true if the token has the scope.
+func (t AccessToken) Allows(scope TokenScope) bool {
+	return slices.Contains(t.Scopes, scope)
+}
+
 type Page struct {
 	Number    uint
 	URL       string
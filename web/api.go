@@ -0,0 +1,452 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package web
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.sr.ht/~bouncepaw/betula/auth"
+	"git.sr.ht/~bouncepaw/betula/db"
+	"git.sr.ht/~bouncepaw/betula/jobs"
+	archivingports "git.sr.ht/~bouncepaw/betula/ports/archiving"
+	webhooksports "git.sr.ht/~bouncepaw/betula/ports/webhooks"
+	"git.sr.ht/~bouncepaw/betula/settings"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+// apiScope lets the request through if it has an access token with the scope.
+// The JSON API is authenticated by these tokens only, not by sessions.
+func apiScope(scope types.TokenScope, next func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, rq *http.Request) {
+		token, ok := auth.AccessTokenFromRequest(rq)
+		if !ok {
+			slog.Info("API request without a valid access token", "path", rq.URL.Path)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="Betula"`)
+			apiError(w, http.StatusUnauthorized, "a valid access token is required")
+			return
+		}
+		if !token.Allows(scope) {
+			slog.Info("API request with an access token without the scope",
+				"path", rq.URL.Path, "tokenID", token.ID, "scope", scope)
+			apiError(w, http.StatusForbidden, "the access token does not have the scope "+string(scope))
+			return
+		}
+		next(w, rq)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Failed to write JSON", "err", err)
+	}
+}
+
+func apiError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+type apiBookmark struct {
+	ID           int      `json:"id"`
+	URL          string   `json:"url"`
+	Title        string   `json:"title"`
+	Description  string   `json:"description"`
+	Visibility   string   `json:"visibility"`
+	Tags         []string `json:"tags"`
+	CreationTime string   `json:"creation_time"`
+	// RemarkedID is the address of the remarked bookmark, if this is a remark.
+	RemarkedID string `json:"remarked_id,omitempty"`
+}
+
+func apiBookmarkFrom(bookmark types.Bookmark) apiBookmark {
+	b := apiBookmark{
+		ID:           bookmark.ID,
+		URL:          bookmark.URL,
+		Title:        bookmark.Title,
+		Description:  bookmark.Description,
+		Visibility:   bookmark.Visibility.String(),
+		Tags:         []string{},
+		CreationTime: bookmark.CreationTime,
+	}
+	for _, tag := range bookmark.Tags {
+		b.Tags = append(b.Tags, tag.Name)
+	}
+	if bookmark.RemarkedID != nil {
+		b.RemarkedID = *bookmark.RemarkedID
+	}
+	return b
+}
+
+type apiBookmarkPage struct {
+	Bookmarks []apiBookmark `json:"bookmarks"`
+	Page      uint          `json:"page"`
+	Total     uint          `json:"total"`
+}
+
+func writeBookmarkPage(w http.ResponseWriter, bookmarks []types.Bookmark, page, total uint) {
+	data := apiBookmarkPage{
+		Bookmarks: []apiBookmark{},
+		Page:      page,
+		Total:     total,
+	}
+	for _, bookmark := range bookmarks {
+		data.Bookmarks = append(data.Bookmarks, apiBookmarkFrom(bookmark))
+	}
+	writeJSON(w, http.StatusOK, data)
+}
+
+// apiBookmarkByID returns the bookmark from the path with its tags, or
+// writes an error and returns false.
+func apiBookmarkByID(w http.ResponseWriter, rq *http.Request) (types.Bookmark, bool) {
+	id, err := strconv.Atoi(rq.PathValue("id"))
+	if err != nil {
+		apiError(w, http.StatusNotFound, "no such bookmark")
+		return types.Bookmark{}, false
+	}
+	bookmark, err := localBookmarks.GetBookmarkByID(rq.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		apiError(w, http.StatusNotFound, "no such bookmark")
+		return bookmark, false
+	} else if err != nil {
+		slog.Error("Failed to get bookmark", "bookmarkID", id, "err", err)
+		apiError(w, http.StatusInternalServerError, "failed to load bookmark")
+		return bookmark, false
+	}
+	bookmark.Tags, err = ctrl.RepoTags.TagsForBookmarkByID(rq.Context(), id)
+	if err != nil {
+		slog.Error("Failed to get tags for bookmark", "bookmarkID", id, "err", err)
+		apiError(w, http.StatusInternalServerError, "failed to load bookmark")
+		return bookmark, false
+	}
+	return bookmark, true
+}
+
+func getAPIBookmarks(w http.ResponseWriter, rq *http.Request) {
+	page := extractPage(rq)
+	bookmarks, total, err := localBookmarks.Bookmarks(rq.Context(), true, page)
+	if err != nil {
+		slog.Error("Failed to get bookmarks", "err", err)
+		apiError(w, http.StatusInternalServerError, "failed to load bookmarks")
+		return
+	}
+	writeBookmarkPage(w, bookmarks, page, total)
+}
+
+func getAPIBookmark(w http.ResponseWriter, rq *http.Request) {
+	if bookmark, ok := apiBookmarkByID(w, rq); ok {
+		writeJSON(w, http.StatusOK, apiBookmarkFrom(bookmark))
+	}
+}
+
+// apiBookmarkInput is what is sent to save or edit a bookmark.
+type apiBookmarkInput struct {
+	URL         string   `json:"url"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Visibility  string   `json:"visibility"`
+	Tags        []string `json:"tags"`
+}
+
+// readBookmarkInput reads the input into the bookmark, fetching the title
+// if there is none. Writes an error and returns false if the input is bad.
+func readBookmarkInput(w http.ResponseWriter, rq *http.Request, bookmark *types.Bookmark) bool {
+	var input apiBookmarkInput
+	if err := json.NewDecoder(rq.Body).Decode(&input); err != nil {
+		apiError(w, http.StatusBadRequest, "bad JSON: "+err.Error())
+		return false
+	}
+	if _, err := url.ParseRequestURI(input.URL); err != nil {
+		apiError(w, http.StatusUnprocessableEntity, "url is not a valid URL")
+		return false
+	}
+	visibility, ok := apiVisibility(input.Visibility, bookmark.Visibility)
+	if !ok {
+		apiError(w, http.StatusUnprocessableEntity, "visibility is not one of public, unlisted and private")
+		return false
+	}
+	if input.Title == "" {
+		meta, err := ctrl.WWW.MetadataOfPage(input.URL)
+		if err != nil || meta.Title == "" {
+			apiError(w, http.StatusUnprocessableEntity, "title is empty and could not be found on the page")
+			return false
+		}
+		input.Title = meta.Title
+	}
+
+	bookmark.URL = input.URL
+	bookmark.Title = input.Title
+	bookmark.Description = input.Description
+	bookmark.Visibility = visibility
+	bookmark.Tags = nil
+	for _, tag := range types.SplitTags(strings.Join(input.Tags, ",")) {
+		if tag.Name != "" {
+			bookmark.Tags = append(bookmark.Tags, tag)
+		}
+	}
+	return true
+}
+
+// apiVisibility parses the visibility. Unlike types.VisibilityFromString,
+// it does not take anything unknown for public: a typo must not make a
+// bookmark public. If there is no visibility, the current one is kept.
+func apiVisibility(s string, current types.Visibility) (types.Visibility, bool) {
+	switch s {
+	case "":
+		return current, true
+	case "public", "unlisted", "private":
+		return types.VisibilityFromString(s), true
+	default:
+		return current, false
+	}
+}
+
+// apiDuplicate writes a conflict and returns true if another bookmark has
+// the URL, unless duplicates are asked for.
+func apiDuplicate(w http.ResponseWriter, rq *http.Request, bookmark types.Bookmark) bool {
+	if rq.URL.Query().Get("duplicate") == "true" {
+		return false
+	}
+	existingID, err := localBookmarks.GetBookmarkIDByURL(rq.Context(), bookmark.URL)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		slog.Error("Failed to look up bookmark by URL", "url", bookmark.URL, "err", err)
+	}
+	if err != nil || existingID == bookmark.ID {
+		return false
+	}
+	writeJSON(w, http.StatusConflict, map[string]any{
+		"error": "a bookmark with this URL already exists, pass duplicate=true to save anyway",
+		"id":    existingID,
+	})
+	return true
+}
+
+func postAPIBookmark(w http.ResponseWriter, rq *http.Request) {
+	var bookmark types.Bookmark
+	if !readBookmarkInput(w, rq, &bookmark) || apiDuplicate(w, rq, bookmark) {
+		return
+	}
+
+	id, err := localBookmarks.InsertBookmark(rq.Context(), bookmark)
+	if err != nil {
+		slog.Error("Failed to insert bookmark", "err", err)
+		apiError(w, http.StatusInternalServerError, "failed to save bookmark")
+		return
+	}
+	bookmark.ID = int(id)
+	bookmark.CreationTime = time.Now().UTC().Format(types.TimeLayout)
+	slog.Info("Saved bookmark through the API", "bookmarkID", bookmark.ID)
+	jobs.ArchiveNewBookmark(bookmark)
+	jobs.SaveBookmarkImages(bookmark.ID, bookmark.URL, "", "")
+	fireBookmarkEvent(rq.Context(), webhooksports.EventBookmarkCreated, bookmark.ID)
+
+	w.Header().Set("Location", "/api/v1/bookmarks/"+strconv.Itoa(bookmark.ID))
+	writeJSON(w, http.StatusCreated, apiBookmarkFrom(bookmark))
+
+	if settings.FederationEnabled() {
+		go broadcastNewBookmark(bookmark)
+	}
+}
+
+func putAPIBookmark(w http.ResponseWriter, rq *http.Request) {
+	bookmark, ok := apiBookmarkByID(w, rq)
+	if !ok {
+		return
+	}
+	oldVisibility := bookmark.Visibility
+	if !readBookmarkInput(w, rq, &bookmark) || apiDuplicate(w, rq, bookmark) {
+		return
+	}
+
+	if err := localBookmarks.EditBookmark(rq.Context(), bookmark); err != nil {
+		slog.Error("Failed to edit bookmark", "bookmarkID", bookmark.ID, "err", err)
+		apiError(w, http.StatusInternalServerError, "failed to edit bookmark")
+		return
+	}
+	slog.Info("Edited bookmark through the API", "bookmarkID", bookmark.ID)
+	fireBookmarkEvent(rq.Context(), webhooksports.EventBookmarkEdited, bookmark.ID)
+	writeJSON(w, http.StatusOK, apiBookmarkFrom(bookmark))
+
+	if settings.FederationEnabled() {
+		go broadcastBookmarkEdit(bookmark, oldVisibility)
+	}
+}
+
+func deleteAPIBookmark(w http.ResponseWriter, rq *http.Request) {
+	bookmark, ok := apiBookmarkByID(w, rq)
+	if !ok {
+		return
+	}
+	if err := localBookmarks.DeleteBookmark(rq.Context(), bookmark.ID); err != nil {
+		slog.Error("Failed to delete bookmark", "bookmarkID", bookmark.ID, "err", err)
+		apiError(w, http.StatusInternalServerError, "failed to delete bookmark")
+		return
+	}
+	slog.Info("Deleted bookmark through the API", "bookmarkID", bookmark.ID)
+	fireBookmarkDeletion(rq.Context(), bookmark)
+	w.WriteHeader(http.StatusNoContent)
+
+	if settings.FederationEnabled() {
+		go broadcastBookmarkDeletion(bookmark)
+	}
+}
+
+type apiArchive struct {
+	ID         int64  `json:"id"`
+	SavedAt    string `json:"saved_at"`
+	ArtifactID string `json:"artifact_id"`
+	// URL is where the copy is, relative to the site address.
+	URL      string `json:"url"`
+	Kind     string `json:"kind"`
+	MimeType string `json:"mime_type"`
+	Size     int    `json:"size"`
+}
+
+func getAPIArchives(w http.ResponseWriter, rq *http.Request) {
+	bookmark, ok := apiBookmarkByID(w, rq)
+	if !ok {
+		return
+	}
+	archives, err := db.NewArchivesRepo().FetchForBookmark(int64(bookmark.ID))
+	if err != nil {
+		slog.Error("Failed to fetch archives for bookmark", "bookmarkID", bookmark.ID, "err", err)
+		apiError(w, http.StatusInternalServerError, "failed to load archives")
+		return
+	}
+
+	data := []apiArchive{}
+	for _, archive := range archives {
+		data = append(data, apiArchive{
+			ID:         archive.ID,
+			SavedAt:    archive.SavedAt.String,
+			ArtifactID: archive.Artifact.ID,
+			URL:        "/artifact/" + archive.Artifact.ID,
+			Kind:       string(archive.Artifact.Kind),
+			MimeType:   archive.Artifact.MimeType,
+			Size:       archive.Artifact.Size,
+		})
+	}
+	writeJSON(w, http.StatusOK, data)
+}
+
+func postAPIArchive(w http.ResponseWriter, rq *http.Request) {
+	bookmark, ok := apiBookmarkByID(w, rq)
+	if !ok {
+		return
+	}
+	kind := types.ArchiveKindFromString(rq.URL.Query().Get("kind"))
+
+	archiveID, err := ctrl.SvcArchiving.Archive(bookmark, kind)
+	if errors.Is(err, archivingports.ErrQuotaExceeded) {
+		apiError(w, http.StatusInsufficientStorage, "the archive quota is reached")
+		return
+	} else if err != nil {
+		slog.Error("Failed to archive bookmark", "bookmarkID", bookmark.ID, "err", err)
+		apiError(w, http.StatusBadGateway, "failed to archive: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]int64{"id": archiveID})
+}
+
+type apiTag struct {
+	Name          string `json:"name"`
+	BookmarkCount uint   `json:"bookmark_count"`
+}
+
+func getAPITags(w http.ResponseWriter, rq *http.Request) {
+	tags, err := ctrl.RepoTags.Tags(rq.Context(), true)
+	if err != nil {
+		slog.Error("Failed to load tags", "err", err)
+		apiError(w, http.StatusInternalServerError, "failed to load tags")
+		return
+	}
+	data := []apiTag{}
+	for _, tag := range tags {
+		data = append(data, apiTag{Name: tag.Name, BookmarkCount: tag.BookmarkCount})
+	}
+	writeJSON(w, http.StatusOK, data)
+}
+
+func getAPITag(w http.ResponseWriter, rq *http.Request) {
+	tagName := rq.PathValue("name")
+	page := extractPage(rq)
+	bookmarks, total, err := localBookmarks.BookmarksWithTag(rq.Context(), true, tagName, page)
+	if err != nil {
+		slog.Error("Failed to get bookmarks with tag", "tag", tagName, "err", err)
+		apiError(w, http.StatusInternalServerError, "failed to load bookmarks")
+		return
+	}
+	writeBookmarkPage(w, bookmarks, page, total)
+}
+
+func getAPISearch(w http.ResponseWriter, rq *http.Request) {
+	query := rq.FormValue("q")
+	if query == "" {
+		apiError(w, http.StatusBadRequest, "the query q is empty")
+		return
+	}
+	page := extractPage(rq)
+	bookmarks, total := ctrl.SvcSearching.For(query, true, page)
+	writeBookmarkPage(w, bookmarks, page, total)
+}
+
+type apiRemoteBookmark struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Title       string    `json:"title"`
+	WebURL      string    `json:"web_url"`
+	Author      string    `json:"author"`
+	AuthorName  string    `json:"author_name"`
+	Description string    `json:"description_html"`
+	Tags        []string  `json:"tags"`
+	PublishedAt time.Time `json:"published_at"`
+	RemarkedID  string    `json:"remarked_id,omitempty"`
+}
+
+func getAPITimeline(w http.ResponseWriter, rq *http.Request) {
+	if !settings.FederationEnabled() {
+		apiError(w, http.StatusNotFound, "federation is disabled")
+		return
+	}
+	page := extractPage(rq)
+	bookmarks, total := ctrl.RepoRemoteBookmark.GetRemoteBookmarks(page)
+	rendered, err := ctrl.SvcRemoteBookmarks.Render(rq.Context(), bookmarks)
+	if err != nil {
+		slog.Error("Failed to render remote bookmarks", "err", err)
+	}
+
+	data := struct {
+		Bookmarks []apiRemoteBookmark `json:"bookmarks"`
+		Page      uint                `json:"page"`
+		Total     uint                `json:"total"`
+	}{Bookmarks: []apiRemoteBookmark{}, Page: page, Total: total}
+	for _, bookmark := range rendered {
+		b := apiRemoteBookmark{
+			ID:          bookmark.ID,
+			URL:         bookmark.URL,
+			Title:       bookmark.Title,
+			WebURL:      bookmark.WebURL,
+			Author:      bookmark.AuthorAcct,
+			AuthorName:  bookmark.AuthorDisplayedName,
+			Description: string(bookmark.Description),
+			Tags:        []string{},
+			PublishedAt: bookmark.PublishedAt,
+			RemarkedID:  bookmark.RemarkedID.String,
+		}
+		for _, tag := range bookmark.Tags {
+			b.Tags = append(b.Tags, tag.Name)
+		}
+		data.Bookmarks = append(data.Bookmarks, b)
+	}
+	writeJSON(w, http.StatusOK, data)
+}
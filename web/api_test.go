@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nalgeon/be"
+
+	"git.sr.ht/~bouncepaw/betula/auth"
+	"git.sr.ht/~bouncepaw/betula/db"
+	"git.sr.ht/~bouncepaw/betula/settings"
+	webhookssvc "git.sr.ht/~bouncepaw/betula/svc/webhooks"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+func apiRequest(method, target, token, body string) *http.Response {
+	rq := httptest.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		rq.Header.Set("Authorization", "Bearer "+token)
+	}
+	rq.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, rq)
+	return w.Result()
+}
+
+func TestAPI(t *testing.T) {
+	db.InitInMemoryDB()
+	ctrl.RepoTags = db.NewTagsRepo()
+	ctrl.SvcWebhooks = webhookssvc.New(db.NewWebhooksRepo(), settings.SiteURL, settings.UserAgent, func(int64) {})
+
+	reader, err := auth.NewAccessToken("Reader", []types.TokenScope{types.ScopeRead})
+	be.Err(t, err, nil)
+	writer, err := auth.NewAccessToken("Writer", []types.TokenScope{types.ScopeRead, types.ScopeWrite})
+	be.Err(t, err, nil)
+
+	const newBookmark = `{"url": "https://example.org", "title": "Example", "visibility": "private", "tags": ["Test", "api"]}`
+	be.Equal(t, apiRequest("GET", "/api/v1/bookmarks", "", "").StatusCode, http.StatusUnauthorized)
+	be.Equal(t, apiRequest("GET", "/api/v1/bookmarks", "betula_nonsense", "").StatusCode, http.StatusUnauthorized)
+	be.Equal(t, apiRequest("POST", "/api/v1/bookmarks", reader, newBookmark).StatusCode, http.StatusForbidden)
+
+	res := apiRequest("POST", "/api/v1/bookmarks", writer, newBookmark)
+	be.Equal(t, res.StatusCode, http.StatusCreated)
+	var saved apiBookmark
+	be.Err(t, json.NewDecoder(res.Body).Decode(&saved), nil)
+	be.Equal(t, saved.Title, "Example")
+	be.Equal(t, saved.Visibility, "private")
+
+	res = apiRequest("POST", "/api/v1/bookmarks", writer, `{"url": "https://www.example.org/", "title": "Again"}`)
+	be.Equal(t, res.StatusCode, http.StatusConflict)
+
+	res = apiRequest("GET", "/api/v1/bookmarks/4", reader, "")
+	be.Equal(t, res.StatusCode, http.StatusOK)
+	var got apiBookmark
+	be.Err(t, json.NewDecoder(res.Body).Decode(&got), nil)
+	be.Equal(t, got.ID, saved.ID)
+	be.Equal(t, got.Tags, []string{"api", "test"})
+
+	// Unknown visibilities are refused, missing ones keep the bookmark private.
+	res = apiRequest("PUT", "/api/v1/bookmarks/4", writer, `{"url": "https://example.org", "title": "Example", "visibility": "Private"}`)
+	be.Equal(t, res.StatusCode, http.StatusUnprocessableEntity)
+	res = apiRequest("PUT", "/api/v1/bookmarks/4", writer, `{"url": "https://example.org", "title": "Edited"}`)
+	be.Equal(t, res.StatusCode, http.StatusOK)
+	be.Err(t, json.NewDecoder(res.Body).Decode(&saved), nil)
+	be.Equal(t, saved.Title, "Edited")
+	be.Equal(t, saved.Visibility, "private")
+	res = apiRequest("POST", "/api/v1/bookmarks", writer, `{"url": "https://example.net", "title": "Net"}`)
+	be.Equal(t, res.StatusCode, http.StatusCreated)
+	var net apiBookmark
+	be.Err(t, json.NewDecoder(res.Body).Decode(&net), nil)
+	be.Equal(t, net.Visibility, "private")
+
+	res = apiRequest("GET", "/api/v1/bookmarks", reader, "")
+	var page apiBookmarkPage
+	be.Err(t, json.NewDecoder(res.Body).Decode(&page), nil)
+	be.Equal(t, page.Total, 4)
+
+	be.Equal(t, apiRequest("DELETE", "/api/v1/bookmarks/4", writer, "").StatusCode, http.StatusNoContent)
+	be.Equal(t, apiRequest("GET", "/api/v1/bookmarks/4", reader, "").StatusCode, http.StatusNotFound)
+	be.Equal(t, apiRequest("GET", "/api/v1/nothing", reader, "").StatusCode, http.StatusNotFound)
+}
@@ -0,0 +1,36 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nalgeon/be"
+
+	"git.sr.ht/~bouncepaw/betula/db"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+func TestGetArtifactSandboxed(t *testing.T) {
+	db.InitInMemoryDB()
+	archives := db.NewArchivesRepo()
+	_, err := archives.Store(2, &types.Artifact{
+		ID: "page", MimeType: "text/html", Data: []byte("<script>alert(1)</script>"), Kind: types.ArchiveRaw,
+	})
+	be.Err(t, err, nil)
+	be.Err(t, archives.SetArchivesPublic(2, true), nil)
+
+	rq := httptest.NewRequest(http.MethodGet, "/artifact/page", nil)
+	rq.SetPathValue("slug", "page")
+	w := httptest.NewRecorder()
+	getArtifact(w, rq)
+
+	be.Equal(t, w.Code, http.StatusOK)
+	be.Equal(t, w.Header().Get("Content-Type"), "text/html")
+	be.Equal(t, w.Header().Get("Content-Security-Policy"), "sandbox")
+	be.Equal(t, w.Header().Get("X-Content-Type-Options"), "nosniff")
+}
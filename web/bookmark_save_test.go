@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	"git.sr.ht/~bouncepaw/betula/db"
+	wwwports "git.sr.ht/~bouncepaw/betula/ports/www"
 	"git.sr.ht/~bouncepaw/betula/types"
 	"github.com/nalgeon/be"
 )
@@ -77,3 +78,38 @@ func TestPostSaveBookmarkStripsPlaceholderDescription(t *testing.T) {
 		})
 	}
 }
+
+func TestMetadataQuote(t *testing.T) {
+	be.Equal(t, metadataQuote(wwwports.PageMetadata{}), "")
+	be.Equal(t, metadataQuote(wwwports.PageMetadata{
+		Author:    "Nobody",
+		Published: "2026-03-01",
+	}), "")
+	be.Equal(t, metadataQuote(wwwports.PageMetadata{
+		Description: "First line\n\n  Second line ",
+	}), "> First line\n> Second line")
+	be.Equal(t, metadataQuote(wwwports.PageMetadata{
+		Description: "About trees",
+		Author:      "Alice",
+		Published:   "2026-03-01T10:00:00Z",
+	}), "> About trees\n> — Alice, 2026-03-01")
+	be.Equal(t, metadataQuote(wwwports.PageMetadata{
+		Description: "About trees",
+		Published:   "yesterday",
+	}), "> About trees")
+}
+
+func TestFillFromMetadataCanonical(t *testing.T) {
+	for _, tc := range []struct {
+		canonical, want string
+	}{
+		{"https://example.org/post", "https://example.org/post"},
+		{"https://EXAMPLE.org/post", "https://EXAMPLE.org/post"},
+		{"https://elsewhere.example/post", "https://example.org/post?utm_source=feed"},
+		{"/post", "https://example.org/post?utm_source=feed"},
+	} {
+		bookmark := types.Bookmark{URL: "https://example.org/post?utm_source=feed"}
+		fillFromMetadata(&bookmark, wwwports.PageMetadata{CanonicalURL: tc.canonical})
+		be.Equal(t, bookmark.URL, tc.want)
+	}
+}
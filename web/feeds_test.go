@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nalgeon/be"
+
+	"git.sr.ht/~bouncepaw/betula/db"
+	feedssvc "git.sr.ht/~bouncepaw/betula/svc/feeds"
+)
+
+func TestFeedConditionalGet(t *testing.T) {
+	db.InitInMemoryDB()
+	ctrl.SvcFeeds = feedssvc.New(db.NewLocalBookmarksRepo(), nil)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/tag/octopus/atom", nil))
+	be.Equal(t, w.Code, http.StatusOK)
+	be.Equal(t, w.Header().Get("Content-Type"), "application/atom+xml; charset=utf-8")
+	etag := w.Header().Get("ETag")
+	be.True(t, etag != "")
+	// No bookmarks have the tag, so the last deletion is the last change.
+	lastModified := w.Header().Get("Last-Modified")
+	be.Equal(t, lastModified, "Sat, 18 Mar 2023 12:45:04 GMT")
+
+	rq := httptest.NewRequest("GET", "/tag/octopus/atom", nil)
+	rq.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, rq)
+	be.Equal(t, w.Code, http.StatusNotModified)
+
+	rq = httptest.NewRequest("GET", "/tag/octopus/atom", nil)
+	rq.Header.Set("If-Modified-Since", lastModified)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, rq)
+	be.Equal(t, w.Code, http.StatusNotModified)
+
+	be.Err(t, db.NewLocalBookmarksRepo().DeleteBookmark(t.Context(), 1), nil)
+	rq = httptest.NewRequest("GET", "/tag/octopus/atom", nil)
+	rq.Header.Set("If-Modified-Since", lastModified)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, rq)
+	be.Equal(t, w.Code, http.StatusOK)
+	be.True(t, w.Header().Get("Last-Modified") != lastModified)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/search/json?q=", nil))
+	be.Equal(t, w.Code, http.StatusBadRequest)
+}
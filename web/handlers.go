@@ -15,9 +15,12 @@
 package web
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"database/sql"
 	"embed"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -28,24 +31,29 @@ import (
 	"net/url"
 	"os"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"git.sr.ht/~bouncepaw/betula/pkg/bxstr"
-	"git.sr.ht/~bouncepaw/betula/pkg/rss"
 	apports "git.sr.ht/~bouncepaw/betula/ports/activitypub"
 	archivingports "git.sr.ht/~bouncepaw/betula/ports/archiving"
+	blockingports "git.sr.ht/~bouncepaw/betula/ports/blocking"
+	duplicatesports "git.sr.ht/~bouncepaw/betula/ports/duplicates"
 	feedsports "git.sr.ht/~bouncepaw/betula/ports/feeds"
 	helpingports "git.sr.ht/~bouncepaw/betula/ports/helping"
 	imexports "git.sr.ht/~bouncepaw/betula/ports/imex"
 	likingports "git.sr.ht/~bouncepaw/betula/ports/liking"
+	linkrotports "git.sr.ht/~bouncepaw/betula/ports/linkrot"
 	notifports "git.sr.ht/~bouncepaw/betula/ports/notif"
 	remarkingports "git.sr.ht/~bouncepaw/betula/ports/remarking"
 	remotebookmarksports "git.sr.ht/~bouncepaw/betula/ports/remotebookmarks"
 	searchingports "git.sr.ht/~bouncepaw/betula/ports/searching"
 	settingsports "git.sr.ht/~bouncepaw/betula/ports/settings"
+	subscriptionsports "git.sr.ht/~bouncepaw/betula/ports/subscriptions"
 	taggingports "git.sr.ht/~bouncepaw/betula/ports/tagging"
+	webhooksports "git.sr.ht/~bouncepaw/betula/ports/webhooks"
 	wwwports "git.sr.ht/~bouncepaw/betula/ports/www"
 	notiftypes "git.sr.ht/~bouncepaw/betula/types/notif"
 
@@ -68,18 +76,23 @@ var (
 )
 
 type Controller struct {
-	SvcNotif     notifports.Service
-	SvcArchiving archivingports.Service
-	SvcLiking    likingports.Service
-	SvcRemarking remarkingports.Service
-	SvcFeeds     feedsports.Service
-	SvcSearching searchingports.Service
-	SvcHelping   helpingports.Service
-	SvcSettings  settingsports.Service
-	SvcImEx      imexports.Service
-	SvcFollow    apports.FollowService
+	SvcNotif      notifports.Service
+	SvcArchiving  archivingports.Service
+	SvcLiking     likingports.Service
+	SvcRemarking  remarkingports.Service
+	SvcFeeds      feedsports.Service
+	SvcSearching  searchingports.Service
+	SvcHelping    helpingports.Service
+	SvcSettings   settingsports.Service
+	SvcImEx       imexports.Service
+	SvcFollow     apports.FollowService
+	SvcBlocking   blockingports.Service
+	SvcLinkRot    linkrotports.Service
+	SvcDuplicates duplicatesports.Service
 
 	SvcRemoteBookmarks remotebookmarksports.Service
+	SvcSubscriptions   subscriptionsports.Service
+	SvcWebhooks        webhooksports.Service
 
 	Assembly      apports.Assembly
 	Guesser       apports.Guesser
@@ -92,6 +105,7 @@ type Controller struct {
 	RepoActor          apports.ActorRepository
 	RepoRemarks        remarkingports.Repository
 	RepoTags           taggingports.Repository
+	RepoNotif          notifports.Repository
 }
 
 func init() {
@@ -105,18 +119,28 @@ func init() {
 	mux.HandleFunc("GET /help/en/", getEnglishHelp)
 	mux.HandleFunc("GET /help", getHelp)
 	mux.HandleFunc("GET /text/{id}", getText)
-	mux.HandleFunc("GET /digest-rss", getDigestRSS)
+	mux.HandleFunc("GET /digest-rss", getFeed(feedsports.FormatRSS, digestFeed))
+	mux.HandleFunc("GET /digest-atom", getFeed(feedsports.FormatAtom, digestFeed))
+	mux.HandleFunc("GET /digest-json", getFeed(feedsports.FormatJSON, digestFeed))
 	// NOTE(Danila Gorelko): deprecated.
-	mux.HandleFunc("GET /posts-rss", getBookmarksRSS)
-	mux.HandleFunc("GET /bookmarks-rss", getBookmarksRSS)
+	mux.HandleFunc("GET /posts-rss", getFeed(feedsports.FormatRSS, bookmarksFeed))
+	mux.HandleFunc("GET /bookmarks-rss", getFeed(feedsports.FormatRSS, bookmarksFeed))
+	mux.HandleFunc("GET /bookmarks-atom", getFeed(feedsports.FormatAtom, bookmarksFeed))
+	mux.HandleFunc("GET /bookmarks-json", getFeed(feedsports.FormatJSON, bookmarksFeed))
 	mux.HandleFunc("GET /go/{id}", getGo)
 	mux.HandleFunc("GET /about", getAbout)
 
 	mux.HandleFunc("GET /tag", handlerTags)
 	mux.HandleFunc("GET /tag/{name}", getTag)
+	mux.HandleFunc("GET /tag/{name}/rss", getFeed(feedsports.FormatRSS, tagFeed))
+	mux.HandleFunc("GET /tag/{name}/atom", getFeed(feedsports.FormatAtom, tagFeed))
+	mux.HandleFunc("GET /tag/{name}/json", getFeed(feedsports.FormatJSON, tagFeed))
 
 	mux.HandleFunc("GET /day/{dayStamp}", getDay)
 	mux.HandleFunc("GET /search", getSearch)
+	mux.HandleFunc("GET /search/rss", getFeed(feedsports.FormatRSS, searchFeed))
+	mux.HandleFunc("GET /search/atom", getFeed(feedsports.FormatAtom, searchFeed))
+	mux.HandleFunc("GET /search/json", getFeed(feedsports.FormatJSON, searchFeed))
 	mux.HandleFunc("GET /static/style.css", getStyle)
 	mux.HandleFunc("GET /static/private.js", getPrivateCustomJS)
 	mux.HandleFunc("GET /static/public.js", getPublicCustomJS)
@@ -137,6 +161,28 @@ func init() {
 	mux.HandleFunc("GET /sessions", adminOnly(getSessions))
 	mux.HandleFunc("POST /delete-session/{token}", adminOnly(deleteSession))
 	mux.HandleFunc("POST /delete-sessions/", adminOnly(deleteSessions))
+	mux.HandleFunc("POST /access-tokens", adminOnly(postAccessToken))
+	mux.HandleFunc("POST /access-tokens/{id}/revoke", adminOnly(postRevokeAccessToken))
+
+	mux.HandleFunc("GET /jobs", adminOnly(getJobs))
+	mux.HandleFunc("POST /jobs/{id}/retry", adminOnly(postRetryJob))
+	mux.HandleFunc("POST /jobs/{id}/discard", adminOnly(postDiscardJob))
+	mux.HandleFunc("POST /jobs/archive-everything", adminOnly(postArchiveEverything))
+	mux.HandleFunc("GET /broken-links", adminOnly(getBrokenLinks))
+	mux.HandleFunc("POST /broken-links/{id}/use-final-url", adminOnly(postUseFinalURL))
+	mux.HandleFunc("GET /duplicates", adminOnly(getDuplicates))
+	mux.HandleFunc("POST /duplicates/merge", adminOnly(postMergeDuplicates))
+	mux.HandleFunc("GET /suggestions", adminOnly(getSuggestions))
+	mux.HandleFunc("POST /suggestions/{id}/dismiss", adminOnly(postDismissSuggestion))
+	mux.HandleFunc("POST /suggestions/dismiss-all", adminOnly(postDismissAllSuggestions))
+	mux.HandleFunc("POST /subscriptions", adminOnly(postSubscribe))
+	mux.HandleFunc("POST /subscriptions/{id}/unsubscribe", adminOnly(postUnsubscribe))
+	mux.HandleFunc("GET /archive-storage", adminOnly(getArchiveStorage))
+	mux.HandleFunc("POST /archive-storage/collect-garbage", adminOnly(postCollectGarbage))
+	mux.HandleFunc("GET /webhooks", adminOnly(getWebhooks))
+	mux.HandleFunc("POST /webhooks", adminOnly(postWebhook))
+	mux.HandleFunc("POST /webhooks/{id}/delete", adminOnly(postDeleteWebhook))
+	mux.HandleFunc("POST /webhooks/deliveries/{id}/redeliver", adminOnly(postRedeliverWebhook))
 
 	mux.HandleFunc("GET /bookmarklet", adminOnly(getBookmarklet))
 
@@ -169,7 +215,10 @@ func init() {
 
 	// Archives
 	mux.HandleFunc("POST /make-new-archive/{id}", adminOnly(postMakeNewArchive))
-	mux.HandleFunc("GET /artifact/{slug}", adminOnly(getArtifact))
+	mux.HandleFunc("GET /artifact/{slug}", getArtifact)
+	mux.HandleFunc("POST /archives-visibility/{id}", adminOnly(postArchivesVisibility))
+	mux.HandleFunc("GET /warc/{id}", adminOnly(getArchiveWARC))
+	mux.HandleFunc("GET /archives.warc.gz", adminOnly(getArchivesWARC))
 	mux.HandleFunc("POST /delete-archive", adminOnly(postDeleteArchive))
 
 	// Federation interface
@@ -177,10 +226,17 @@ func init() {
 	mux.HandleFunc("POST /unfollow", adminOnly(federatedOnly(postUnfollow)))
 	mux.HandleFunc("GET /following", fediverseWebFork(nil, getFollowingWeb))
 	mux.HandleFunc("GET /followers", fediverseWebFork(nil, getFollowersWeb))
+	mux.HandleFunc("POST /follow-requests/accept", adminOnly(federatedOnly(postAcceptFollowRequest)))
+	mux.HandleFunc("POST /follow-requests/reject", adminOnly(federatedOnly(postRejectFollowRequest)))
 	mux.HandleFunc("GET /timeline", adminOnly(federatedOnly(getTimeline)))
 	mux.HandleFunc("POST /like", adminOnly(federatedOnly(postLike)))
 	mux.HandleFunc("POST /unlike", adminOnly(federatedOnly(postUnlike)))
 
+	// Blocks
+	mux.HandleFunc("GET /blocks", adminOnly(federatedOnly(getBlocks)))
+	mux.HandleFunc("POST /block", adminOnly(federatedOnly(postBlock)))
+	mux.HandleFunc("POST /unblock", adminOnly(federatedOnly(postUnblock)))
+
 	// Federated search
 	mux.HandleFunc("GET /fedisearch", adminOnly(federatedOnly(handlerFediSearch)))
 	mux.HandleFunc("POST /fedisearch", adminOnly(federatedOnly(handlerFediSearch)))
@@ -188,6 +244,23 @@ func init() {
 
 	// ActivityPub
 	mux.HandleFunc("POST /inbox", federatedOnly(postInbox))
+	mux.HandleFunc("GET /outbox", federatedOnly(getOutbox))
+
+	// JSON API, see api.go
+	mux.HandleFunc("GET /api/v1/bookmarks", apiScope(types.ScopeRead, getAPIBookmarks))
+	mux.HandleFunc("POST /api/v1/bookmarks", apiScope(types.ScopeWrite, postAPIBookmark))
+	mux.HandleFunc("GET /api/v1/bookmarks/{id}", apiScope(types.ScopeRead, getAPIBookmark))
+	mux.HandleFunc("PUT /api/v1/bookmarks/{id}", apiScope(types.ScopeWrite, putAPIBookmark))
+	mux.HandleFunc("DELETE /api/v1/bookmarks/{id}", apiScope(types.ScopeWrite, deleteAPIBookmark))
+	mux.HandleFunc("GET /api/v1/bookmarks/{id}/archives", apiScope(types.ScopeRead, getAPIArchives))
+	mux.HandleFunc("POST /api/v1/bookmarks/{id}/archives", apiScope(types.ScopeWrite, postAPIArchive))
+	mux.HandleFunc("GET /api/v1/tags", apiScope(types.ScopeRead, getAPITags))
+	mux.HandleFunc("GET /api/v1/tags/{name}", apiScope(types.ScopeRead, getAPITag))
+	mux.HandleFunc("GET /api/v1/search", apiScope(types.ScopeRead, getAPISearch))
+	mux.HandleFunc("GET /api/v1/timeline", apiScope(types.ScopeRead, getAPITimeline))
+	mux.HandleFunc("/api/", func(w http.ResponseWriter, rq *http.Request) {
+		apiError(w, http.StatusNotFound, "no such endpoint")
+	})
 
 	// NodeInfo
 	mux.HandleFunc("GET /.well-known/nodeinfo", getWellKnownNodeInfo)
@@ -313,11 +386,22 @@ func postDeleteArchive(w http.ResponseWriter, rq *http.Request) {
 }
 
 func getArtifact(w http.ResponseWriter, rq *http.Request) {
-	// TODO: when uses of artifacts other than archives emerge,
-	// implement access restrictions. Artifacts that belong to
-	// archives shall remain private. That would probably
-	// involve changing the database scheme.
+	// Artifacts belong to archives and bookmark images. Visitors see only
+	// those that belong to public archives or images of bookmarks that
+	// are not private.
 	var slug = rq.PathValue("slug")
+	if !auth.AuthorizedFromRequest(rq) {
+		public, err := db.NewArchivesRepo().ArtifactPublic(slug)
+		if err != nil {
+			slog.Error("Failed to check if artifact is public", "id", slug, "err", err)
+		}
+		if !public {
+			slog.Info("Unauthorized attempt to access", "path", rq.URL.Path, "status", http.StatusUnauthorized)
+			handlerUnauthorized(w, rq)
+			return
+		}
+	}
+
 	var artifact, err = db.NewArtifactsRepo().Fetch(slug)
 	if err != nil {
 		slog.Warn("Requested artifact does not exist", "id", slug)
@@ -326,6 +410,11 @@ func getArtifact(w http.ResponseWriter, rq *http.Request) {
 	}
 
 	slog.Info("Request artifact", "id", slug, "mime", artifact.MimeType)
+	// Archived pages come with their scripts, and images might be SVG.
+	// They are served from Betula's own address, so they must not run
+	// anything there, nor be taken for something else.
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
 	if !artifact.IsGzipped {
 		w.Header().Add("Content-Type", artifact.MimeType)
 		_, err = w.Write(artifact.Data)
@@ -351,10 +440,14 @@ func postMakeNewArchive(w http.ResponseWriter, rq *http.Request) {
 	if !ok {
 		return
 	}
-	slog.Info("Requesting to make a new archive", "bookmarkID", bookmark.ID)
+	var kind = types.ArchiveKindFromString(rq.FormValue("kind"))
+	slog.Info("Requesting to make a new archive", "bookmarkID", bookmark.ID, "kind", kind)
 
-	archiveID, err := ctrl.SvcArchiving.Archive(*bookmark)
-	if err != nil {
+	archiveID, err := ctrl.SvcArchiving.Archive(*bookmark, kind)
+	if errors.Is(err, archivingports.ErrQuotaExceeded) {
+		http.Redirect(w, rq, "/archive-storage?quota-exceeded=true", http.StatusSeeOther)
+		return
+	} else if err != nil {
 		handlerBadRequest(w, rq)
 		return
 	}
@@ -670,11 +763,21 @@ func getSearch(w http.ResponseWriter, rq *http.Request) {
 	if err := ctrl.SvcLiking.FillLikes(rq.Context(), renderedBookmarks, nil); err != nil {
 		slog.Error("Failed to fill likes for local bookmarks", "err", err)
 	}
+	if err := ctrl.SvcArchiving.FillIcons(renderedBookmarks); err != nil {
+		slog.Error("Failed to fill icons for local bookmarks", "err", err)
+	}
+	archiveMatches := ctrl.SvcSearching.ArchiveMatches(query, authed, bookmarks)
+	for i, bookmark := range renderedBookmarks {
+		if match, ok := archiveMatches[bookmark.ID]; ok {
+			renderedBookmarks[i].ArchiveMatch = &match
+		}
+	}
 	groups := types.GroupLocalBookmarksByDate(renderedBookmarks)
 
 	common := emptyCommon()
 	common.paginator = types.PaginatorFromURL(rq.URL, currentPage, totalBookmarks)
 	common.searchQuery = query
+	common.head = feedLinks("Bookmarks found by “"+query+"”", "/search/", "?q="+url.QueryEscape(query))
 	slog.Info("Searching", "query", query, "authorized", authed)
 	templateExec(w, rq, templateSearch, dataSearch{
 		dataCommon:           common,
@@ -696,26 +799,73 @@ func getText(w http.ResponseWriter, rq *http.Request) {
 	_, _ = io.WriteString(w, bookmark.Description)
 }
 
-func writeFeed(svcMethod func() (*rss.Feed, error), w http.ResponseWriter) {
-	feed, err := svcMethod()
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
+// getFeed serves the feed made by makeFeed in the format. Conditional
+// requests get 304 Not Modified if the feed did not change, judging by
+// the ETag or, if there is none in the request, by Last-Modified.
+func getFeed(
+	format feedsports.Format,
+	makeFeed func(*http.Request) (feedsports.Feed, error),
+) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, rq *http.Request) {
+		feed, err := makeFeed(rq)
+		if errors.Is(err, feedsports.ErrEmptyQuery) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if err != nil {
+			slog.Error("Failed to make feed", "path", rq.URL.Path, "err", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		feed.Self = settings.SiteURL() + rq.URL.RequestURI()
 
-	err = feed.Write(w)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		var buf bytes.Buffer
+		if err = ctrl.SvcFeeds.Write(&buf, feed, format); err != nil {
+			slog.Error("Failed to write feed", "path", rq.URL.Path, "err", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sum := sha256.Sum256(buf.Bytes())
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+		w.Header().Set("Content-Type", format.ContentType())
+		http.ServeContent(w, rq, "", feed.Modified, bytes.NewReader(buf.Bytes()))
 	}
 }
 
-func getBookmarksRSS(w http.ResponseWriter, _ *http.Request) {
-	writeFeed(ctrl.SvcFeeds.BookmarksFeed, w)
+func digestFeed(*http.Request) (feedsports.Feed, error) {
+	return ctrl.SvcFeeds.DigestFeed()
+}
+
+func bookmarksFeed(*http.Request) (feedsports.Feed, error) {
+	return ctrl.SvcFeeds.BookmarksFeed()
+}
+
+func tagFeed(rq *http.Request) (feedsports.Feed, error) {
+	return ctrl.SvcFeeds.TagFeed(rq.PathValue("name"))
 }
 
-func getDigestRSS(w http.ResponseWriter, _ *http.Request) {
-	writeFeed(ctrl.SvcFeeds.DigestFeed, w)
+func searchFeed(rq *http.Request) (feedsports.Feed, error) {
+	return ctrl.SvcFeeds.SearchFeed(rq.FormValue("q"))
+}
+
+// feedLinks returns the <link> elements for the feed in all formats. The
+// format names are appended to base, like /tag/wiki/ becomes /tag/wiki/rss.
+func feedLinks(title, base, query string) template.HTML {
+	var links strings.Builder
+	for _, format := range []struct {
+		feedsports.Format
+		name string
+	}{
+		{feedsports.FormatRSS, "RSS"},
+		{feedsports.FormatAtom, "Atom"},
+		{feedsports.FormatJSON, "JSON Feed"},
+	} {
+		links.WriteString(fmt.Sprintf(`
+	<link rel="alternate" type="%s" title="%s, %s" href="%s%s%s">`,
+			strings.Split(format.ContentType(), ";")[0],
+			template.HTMLEscapeString(title), format.name,
+			template.HTMLEscapeString(base), format.Format, template.HTMLEscapeString(query)))
+	}
+	return template.HTML(links.String())
 }
 
 var dayStampRegex = regexp.MustCompile("^[0-9]{4}-[0-9]{2}-[0-9]{2}$")
@@ -748,6 +898,9 @@ func getDay(w http.ResponseWriter, rq *http.Request) {
 	if err := ctrl.SvcLiking.FillLikes(rq.Context(), bookmarks, nil); err != nil {
 		slog.Error("Failed to fill likes for local bookmarks", "err", err)
 	}
+	if err := ctrl.SvcArchiving.FillIcons(bookmarks); err != nil {
+		slog.Error("Failed to fill icons for local bookmarks", "err", err)
+	}
 
 	templateExec(w, rq, templateDay, dataDay{
 		dataCommon: emptyCommon(),
@@ -778,6 +931,14 @@ func getSettings(w http.ResponseWriter, rq *http.Request) {
 			FederationEnabled:         settings.FederationEnabled(),
 			PublicCustomJS:            settings.PublicCustomJS(),
 			PrivateCustomJS:           settings.PrivateCustomJS(),
+			BackfillPages:             settings.BackfillPages(),
+			ManuallyApproveFollowers:  settings.ManuallyApproveFollowers(),
+			AutoArchive:               settings.AutoArchive(),
+			AutoArchiveTags:           settings.AutoArchiveTags(),
+			AutoArchiveSharedOnly:     settings.AutoArchiveSharedOnly(),
+			ArchiveQuota:              settings.ArchiveQuotaMiB(),
+			ArchiveQuotaEvict:         settings.ArchiveQuotaEvict(),
+			TrackingParams:            settings.TrackingParamsString(),
 		},
 		dataCommon:  emptyCommon(),
 		FirstRun:    isFirstRun,
@@ -798,6 +959,22 @@ func postSettings(w http.ResponseWriter, rq *http.Request) {
 		FederationEnabled:         rq.FormValue("enable-federation") == "true",
 		PublicCustomJS:            rq.FormValue("public-custom-js"),
 		PrivateCustomJS:           rq.FormValue("private-custom-js"),
+		BackfillPages:             settings.BackfillPages(),
+		ManuallyApproveFollowers:  rq.FormValue("approve-followers") == "true",
+		AutoArchive:               rq.FormValue("auto-archive") == "true",
+		AutoArchiveTags:           rq.FormValue("auto-archive-tags"),
+		AutoArchiveSharedOnly:     rq.FormValue("auto-archive-shared-only") == "true",
+		ArchiveQuota:              settings.ArchiveQuotaMiB(),
+		ArchiveQuotaEvict:         rq.FormValue("archive-quota-evict") == "true",
+		TrackingParams:            rq.FormValue("tracking-params"),
+	}
+	if pages, err := strconv.Atoi(rq.FormValue("backfill-pages")); err == nil && pages >= 0 {
+		newSettings.BackfillPages = uint(pages)
+	}
+	if quota := rq.FormValue("archive-quota"); quota == "" {
+		newSettings.ArchiveQuota = 0
+	} else if mib, err := strconv.Atoi(quota); err == nil && mib >= 0 {
+		newSettings.ArchiveQuota = uint(mib)
 	}
 
 	// If the port ≤ 0 or not really numeric, show error.
@@ -843,35 +1020,49 @@ func postDeleteBookmark(w http.ResponseWriter, rq *http.Request) {
 		handlerNotFound(w, rq)
 		return
 	}
+	// The tags are gone with the bookmark, the webhooks want them.
+	if bookmark.Tags, err = ctrl.RepoTags.TagsForBookmarkByID(rq.Context(), id); err != nil {
+		slog.Error("Failed to get tags of bookmark to delete", "bookmarkID", id, "err", err)
+	}
 
 	if err := localBookmarks.DeleteBookmark(rq.Context(), id); err != nil {
 		slog.Error("Failed to delete bookmark", "bookmarkID", id, "err", err)
 		http.Error(w, "Failed to delete bookmark", http.StatusInternalServerError)
 		return
 	}
+	fireBookmarkDeletion(rq.Context(), bookmark)
 	http.Redirect(w, rq, "/", http.StatusSeeOther)
 
 	if settings.FederationEnabled() {
-		go func(bookmark types.Bookmark) {
-			if bookmark.Visibility != types.Public {
-				return
-			}
-			data, err := ctrl.Assembly.DeleteNote(bookmark.ID)
-			if err != nil {
-				slog.Error("Failed to create Delete{Note} activity for bookmark", "bookmarkID", id, "err", err)
-				return
-			}
-			jobs.ScheduleDatum(jobtype.SendDeleteNote, data)
-		}(bookmark)
+		go broadcastBookmarkDeletion(bookmark)
 	}
 }
 
+// broadcastBookmarkDeletion tells the followers the bookmark is gone.
+func broadcastBookmarkDeletion(bookmark types.Bookmark) {
+	if !bookmark.Visibility.Federated() {
+		return
+	}
+	data, err := ctrl.Assembly.DeleteNote(bookmark.ID)
+	if err != nil {
+		slog.Error("Failed to create Delete{Note} activity for bookmark", "bookmarkID", bookmark.ID, "err", err)
+		return
+	}
+	jobs.ScheduleDatum(jobtype.SendDeleteNote, data)
+}
+
 type dataSessions struct {
-	Sessions []types.Session
+	Sessions     []types.Session
+	AccessTokens []types.AccessToken
+	Scopes       []types.TokenScope
 	*dataCommon
 }
 
 func getSessions(w http.ResponseWriter, rq *http.Request) {
+	renderSessions(w, rq, emptyCommon())
+}
+
+func renderSessions(w http.ResponseWriter, rq *http.Request, common *dataCommon) {
 	currentToken, err := auth.Token(rq)
 	if err != nil {
 		handlerUnauthorized(w, rq)
@@ -879,10 +1070,52 @@ func getSessions(w http.ResponseWriter, rq *http.Request) {
 	}
 	sessions := auth.MarkCurrentSession(currentToken, auth.Sessions())
 	templateExec(w, rq, templateSessions, dataSessions{
-		Sessions:   sessions,
-		dataCommon: emptyCommon(),
+		Sessions:     sessions,
+		AccessTokens: auth.AccessTokens(),
+		Scopes:       types.TokenScopes,
+		dataCommon:   common,
 	})
-	return
+}
+
+// postAccessToken makes a new access token and shows it once.
+func postAccessToken(w http.ResponseWriter, rq *http.Request) {
+	name := strings.TrimSpace(rq.FormValue("name"))
+	var scopes []types.TokenScope
+	for _, scope := range types.TokenScopes {
+		if slices.Contains(rq.Form["scope"], string(scope)) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if name == "" || len(scopes) == 0 {
+		renderSessions(w, rq, emptyCommon().withSystemNotifications(SystemNotification{
+			Category: NotificationFailure,
+			Body:     "Give the token a name and at least one scope.",
+		}))
+		return
+	}
+
+	token, err := auth.NewAccessToken(name, scopes)
+	if err != nil {
+		slog.Error("Failed to make access token", "err", err)
+		http.Error(w, "Failed to make access token", http.StatusInternalServerError)
+		return
+	}
+	renderSessions(w, rq, emptyCommon().withSystemNotifications(SystemNotification{
+		Category: NotificationSuccess,
+		Body: template.HTML(fmt.Sprintf(
+			`Made the token “%s”: <code>%s</code>. Copy it now, it will not be shown again.`,
+			template.HTMLEscapeString(name), token)),
+	}))
+}
+
+func postRevokeAccessToken(w http.ResponseWriter, rq *http.Request) {
+	id, err := strconv.ParseInt(rq.PathValue("id"), 10, 64)
+	if err != nil {
+		handlerNotFound(w, rq)
+		return
+	}
+	auth.RevokeAccessToken(id)
+	http.Redirect(w, rq, "/sessions", http.StatusSeeOther)
 }
 
 func deleteSession(w http.ResponseWriter, rq *http.Request) {
@@ -1047,6 +1280,9 @@ func getTag(w http.ResponseWriter, rq *http.Request) {
 	if err := ctrl.SvcLiking.FillLikes(rq.Context(), renderedBookmarks, nil); err != nil {
 		slog.Error("Failed to fill likes for local bookmarks", "err", err)
 	}
+	if err := ctrl.SvcArchiving.FillIcons(renderedBookmarks); err != nil {
+		slog.Error("Failed to fill icons for local bookmarks", "err", err)
+	}
 	groups := types.GroupLocalBookmarksByDate(renderedBookmarks)
 
 	description, err := ctrl.RepoTags.DescriptionForTag(rq.Context(), tagName)
@@ -1057,6 +1293,7 @@ func getTag(w http.ResponseWriter, rq *http.Request) {
 	}
 
 	common := emptyCommon()
+	common.head = feedLinks("Bookmarks tagged #"+tagName, "/tag/"+url.PathEscape(tagName)+"/", "")
 	common.searchQuery = "#" + tagName
 	common.paginator = types.PaginatorFromURL(rq.URL, currentPage, totalBookmarks)
 	templateExec(w, rq, templateTag, dataTag{
@@ -1118,6 +1355,7 @@ func postEditBookmarkTags(w http.ResponseWriter, rq *http.Request) {
 		http.Error(w, "Failed to edit remark", http.StatusInternalServerError)
 		return
 	}
+	fireBookmarkEvent(rq.Context(), webhooksports.EventBookmarkEdited, id)
 
 	next := rq.FormValue("next")
 	http.Redirect(w, rq, next, http.StatusSeeOther)
@@ -1131,7 +1369,7 @@ func postEditBookmarkTags(w http.ResponseWriter, rq *http.Request) {
 				slog.Error("Failed to federate bookmark", "bookmarkID", id, "err", err)
 				return
 			}
-			if bookmark.Visibility != types.Public {
+			if !bookmark.Visibility.Federated() {
 				return
 			}
 
@@ -1217,7 +1455,8 @@ func postEditBookmark(w http.ResponseWriter, rq *http.Request) {
 		if err != nil && !errors.Is(err, sql.ErrNoRows) {
 			slog.Error("Failed to look up bookmark by URL", "url", newURL, "err", err)
 		}
-		if err == nil {
+		// The canonical URL might stay the same, then the bookmark finds itself.
+		if err == nil && existingBookmarkID != bookmark.ID {
 			templateExec(w, rq, templateEditLink, dataEditLink{
 				Bookmark: *bookmark,
 				dataCommon: commonWithAutoCompletion().
@@ -1275,50 +1514,55 @@ func postEditBookmark(w http.ResponseWriter, rq *http.Request) {
 	}
 	http.Redirect(w, rq, fmt.Sprintf("/%d", bookmark.ID), http.StatusSeeOther)
 	slog.Info("Edited bookmark", "bookmarkID", bookmark.ID)
+	fireBookmarkEvent(rq.Context(), webhooksports.EventBookmarkEdited, bookmark.ID)
 
 	if settings.FederationEnabled() {
-		go func(bookmark types.Bookmark, oldVisibility types.Visibility) {
-			wasPublic := oldVisibility == types.Public
-			isPublic := bookmark.Visibility == types.Public
+		go broadcastBookmarkEdit(*bookmark, oldVisibility)
+	}
+}
 
-			// The bookmark remains private.
-			if !wasPublic && !isPublic {
-				return
-			}
+// broadcastBookmarkEdit tells the followers about the edited bookmark,
+// taking its old visibility into account.
+func broadcastBookmarkEdit(bookmark types.Bookmark, oldVisibility types.Visibility) {
+	wasFederated := oldVisibility.Federated()
+	isFederated := bookmark.Visibility.Federated()
 
-			// The bookmark was hidden by the author. Let's broadcast Delete.
-			if wasPublic && !isPublic {
-				data, err := ctrl.Assembly.DeleteNote(bookmark.ID)
-				if err != nil {
-					slog.Error("Failed to create Delete{Note} activity for bookmark", "bookmarkID", bookmark.ID, "err", err)
-					return
-				}
-				jobs.ScheduleDatum(jobtype.SendDeleteNote, data)
-				return
-			}
+	// The bookmark remains private.
+	if !wasFederated && !isFederated {
+		return
+	}
 
-			bookmark.CreationTime = time.Now().UTC().Format(types.TimeLayout) // It shall match the one generated in DB
+	// The bookmark was hidden by the author. Let's broadcast Delete.
+	if wasFederated && !isFederated {
+		data, err := ctrl.Assembly.DeleteNote(bookmark.ID)
+		if err != nil {
+			slog.Error("Failed to create Delete{Note} activity for bookmark", "bookmarkID", bookmark.ID, "err", err)
+			return
+		}
+		jobs.ScheduleDatum(jobtype.SendDeleteNote, data)
+		return
+	}
 
-			// The bookmark was unpublic, but became public. Let's broadcast Create.
-			if !wasPublic && isPublic {
-				data, err := ctrl.Assembly.CreateNote(bookmark)
-				if err != nil {
-					slog.Error("Failed to create Create{Note} activity for bookmark", "bookmarkID", bookmark.ID, "err", err)
-					return
-				}
-				jobs.ScheduleDatum(jobtype.SendCreateNote, data)
-				return
-			}
+	bookmark.CreationTime = time.Now().UTC().Format(types.TimeLayout) // It shall match the one generated in DB
 
-			// The bookmark remains public
-			data, err := ctrl.Assembly.UpdateNote(bookmark)
-			if err != nil {
-				slog.Error("Failed to create Update{Note} activity for bookmark", "bookmarkID", bookmark.ID, "err", err)
-				return
-			}
-			jobs.ScheduleDatum(jobtype.SendUpdateNote, data)
-		}(*bookmark, oldVisibility)
+	// The bookmark was private, but became federated. Let's broadcast Create.
+	if !wasFederated && isFederated {
+		data, err := ctrl.Assembly.CreateNote(bookmark)
+		if err != nil {
+			slog.Error("Failed to create Create{Note} activity for bookmark", "bookmarkID", bookmark.ID, "err", err)
+			return
+		}
+		jobs.ScheduleDatum(jobtype.SendCreateNote, data)
+		return
+	}
+
+	// The bookmark remains federated, perhaps with a different audience.
+	data, err := ctrl.Assembly.UpdateNote(bookmark)
+	if err != nil {
+		slog.Error("Failed to create Update{Note} activity for bookmark", "bookmarkID", bookmark.ID, "err", err)
+		return
 	}
+	jobs.ScheduleDatum(jobtype.SendUpdateNote, data)
 }
 
 type dataEditTag struct {
@@ -1412,6 +1656,10 @@ func postEditTag(w http.ResponseWriter, rq *http.Request) {
 	http.Redirect(w, rq, fmt.Sprintf("/tag/%s", newTag.Name), http.StatusSeeOther)
 	if oldTag.Name != newTag.Name {
 		slog.Info("Renamed tag", "oldName", oldTag.Name, "newName", newTag.Name)
+		ctrl.SvcWebhooks.Fire(rq.Context(), webhooksports.Payload{
+			Event: webhooksports.EventTagRenamed,
+			Tag:   &webhooksports.TagRename{OldName: oldTag.Name, NewName: newTag.Name},
+		})
 	}
 	if oldTag.Description != newTag.Description {
 		slog.Info("Set new description for tag", "tag", newTag.Name)
@@ -1461,6 +1709,14 @@ type dataSaveLink struct {
 	ErrorTitleNotFound bool
 
 	DuplicateBookmarkID int
+
+	// SuggestionID is the feed suggestion being saved, 0 if none.
+	SuggestionID int64
+
+	// IconURL and PreviewURL are the addresses of the pictures the page
+	// chose for itself, found when filling the form in.
+	IconURL    string
+	PreviewURL string
 }
 
 func getSaveBookmark(w http.ResponseWriter, rq *http.Request) {
@@ -1481,14 +1737,95 @@ func getSaveBookmark(w http.ResponseWriter, rq *http.Request) {
 		bookmark.Description = ""
 	}
 
-	// TODO: Document the param behaviour
-	templateExec(w, rq, templateSaveLink, dataSaveLink{
-		Bookmark:   bookmark,
+	var data = dataSaveLink{
 		dataCommon: commonWithAutoCompletion(),
-	})
+	}
+	data.SuggestionID, _ = strconv.ParseInt(rq.FormValue("suggestion"), 10, 64)
+	if webURL(bookmark.URL) {
+		meta, err := ctrl.WWW.MetadataOfPage(bookmark.URL)
+		if err != nil {
+			slog.Warn("Failed to read metadata of page to save", "url", bookmark.URL, "err", err)
+		} else {
+			fillFromMetadata(&bookmark, meta)
+			data.IconURL = meta.IconURL
+			data.PreviewURL = meta.ImageURL
+		}
+	}
+	data.Bookmark = bookmark
+
+	// TODO: Document the param behaviour
+	templateExec(w, rq, templateSaveLink, data)
 	return
 }
 
+// webURL is true for absolute http and https addresses.
+func webURL(addr string) bool {
+	u, err := url.ParseRequestURI(addr)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// fillFromMetadata fills the empty fields of the bookmark with what the page
+// says about itself. The canonical address replaces the given one, because
+// it is free of tracking parameters and the like, but only if it is on the
+// same website: a page is not to send the bookmark elsewhere.
+func fillFromMetadata(bookmark *types.Bookmark, meta wwwports.PageMetadata) {
+	if webURL(meta.CanonicalURL) && sameHost(bookmark.URL, meta.CanonicalURL) {
+		bookmark.URL = meta.CanonicalURL
+	}
+	if bookmark.Title == "" {
+		bookmark.Title = meta.Title
+	}
+	if bxstr.TrimRightSpace(bookmark.Description) == ">" || bookmark.Description == "" {
+		bookmark.Description = metadataQuote(meta)
+	}
+}
+
+func sameHost(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	return errA == nil && errB == nil && strings.EqualFold(ua.Hostname(), ub.Hostname())
+}
+
+// metadataQuote makes a Mycomarkup quote of the page's description, signed
+// with the author's name and the publication date, if known. It is the same
+// kind of quote the bookmarklet makes.
+func metadataQuote(meta wwwports.PageMetadata) string {
+	var lines []string
+	for line := range strings.SplitSeq(strings.TrimSpace(meta.Description), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	var signature []string
+	if meta.Author != "" {
+		signature = append(signature, meta.Author)
+	}
+	if published := humanDate(meta.Published); published != "" {
+		signature = append(signature, published)
+	}
+	if len(lines) > 0 && len(signature) > 0 {
+		lines = append(lines, "— "+strings.Join(signature, ", "))
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "> " + strings.Join(lines, "\n> ")
+}
+
+// humanDate returns the date part of a timestamp like 2026-03-01T10:00:00Z,
+// or an empty string if it does not look like one.
+func humanDate(timestamp string) string {
+	if len(timestamp) < len(time.DateOnly) {
+		return ""
+	}
+	var date = timestamp[:len(time.DateOnly)]
+	if _, err := time.Parse(time.DateOnly, date); err != nil {
+		return ""
+	}
+	return date
+}
+
 func postSaveBookmark(w http.ResponseWriter, rq *http.Request) {
 	var viewData dataSaveLink
 	var bookmark types.Bookmark
@@ -1506,6 +1843,7 @@ func postSaveBookmark(w http.ResponseWriter, rq *http.Request) {
 
 	// If this is true, a user can save a duplicate next time they click 'Save' button.
 	saveDuplicate := rq.FormValue("duplicate") == "true"
+	suggestionID, _ := strconv.ParseInt(rq.FormValue("suggestion"), 10, 64)
 
 	if bookmark.URL == "" && bookmark.Title == "" {
 		viewData.emptyUrl(bookmark, common, w, rq)
@@ -1519,17 +1857,21 @@ func postSaveBookmark(w http.ResponseWriter, rq *http.Request) {
 		return
 	}
 
+	var iconURL = rq.FormValue("icon-url")
 	if bookmark.Title == "" {
 		if _, err := url.ParseRequestURI(bookmark.URL); err != nil {
 			viewData.invalidUrl(bookmark, common, w, rq)
 			return
 		}
-		newTitle, err := ctrl.WWW.TitleOfPage(bookmark.URL)
-		if err != nil {
+		meta, err := ctrl.WWW.MetadataOfPage(bookmark.URL)
+		if err != nil || meta.Title == "" {
 			viewData.titleNotFound(bookmark, common, w, rq)
 			return
 		}
-		bookmark.Title = newTitle
+		bookmark.Title = meta.Title
+		if iconURL == "" {
+			iconURL = meta.IconURL
+		}
 	}
 
 	if _, err := url.ParseRequestURI(bookmark.URL); err != nil {
@@ -1554,6 +1896,7 @@ func postSaveBookmark(w http.ResponseWriter, rq *http.Request) {
 							Body:     template.HTML(fmt.Sprintf(`A bookmark with this URL <a href="%d">already exists</a>.`, existingBookmarkID)),
 						}),
 				DuplicateBookmarkID: existingBookmarkID,
+				SuggestionID:        suggestionID,
 			})
 			return
 		}
@@ -1567,6 +1910,20 @@ func postSaveBookmark(w http.ResponseWriter, rq *http.Request) {
 		return
 	}
 	bookmark.ID = int(id)
+	jobs.ArchiveNewBookmark(bookmark)
+	fireBookmarkEvent(rq.Context(), webhooksports.EventBookmarkCreated, bookmark.ID)
+
+	if suggestionID != 0 {
+		if err := ctrl.SvcSubscriptions.MarkSaved(rq.Context(), suggestionID); err != nil {
+			slog.Error("Failed to mark suggestion as saved", "suggestionID", suggestionID, "err", err)
+		}
+	}
+
+	var previewURL string
+	if rq.FormValue("save-preview") == "true" {
+		previewURL = rq.FormValue("preview-url")
+	}
+	jobs.SaveBookmarkImages(bookmark.ID, bookmark.URL, iconURL, previewURL)
 
 	another := rq.FormValue("another")
 	if another == "true" {
@@ -1583,19 +1940,22 @@ func postSaveBookmark(w http.ResponseWriter, rq *http.Request) {
 	http.Redirect(w, rq, fmt.Sprintf("/%d", id), http.StatusSeeOther)
 
 	if settings.FederationEnabled() {
-		go func(bookmark types.Bookmark) {
-			if bookmark.Visibility != types.Public {
-				return
-			}
-			bookmark.CreationTime = time.Now().UTC().Format(types.TimeLayout) // It shall match the one generated in DB
-			data, err := ctrl.Assembly.CreateNote(bookmark)
-			if err != nil {
-				slog.Error("Failed to create Create{Note} activity for bookmark", "bookmarkID", id, "err", err)
-				return
-			}
-			jobs.ScheduleDatum(jobtype.SendCreateNote, data)
-		}(bookmark)
+		go broadcastNewBookmark(bookmark)
+	}
+}
+
+// broadcastNewBookmark tells the followers about the new bookmark.
+func broadcastNewBookmark(bookmark types.Bookmark) {
+	if !bookmark.Visibility.Federated() {
+		return
 	}
+	bookmark.CreationTime = time.Now().UTC().Format(types.TimeLayout) // It shall match the one generated in DB
+	data, err := ctrl.Assembly.CreateNote(bookmark)
+	if err != nil {
+		slog.Error("Failed to create Create{Note} activity for bookmark", "bookmarkID", bookmark.ID, "err", err)
+		return
+	}
+	jobs.ScheduleDatum(jobtype.SendCreateNote, data)
 }
 
 type dataBookmark struct {
@@ -1608,6 +1968,9 @@ type dataBookmark struct {
 
 	Archives         []types.Archive
 	HighlightArchive int64
+	// ArchivesPublic is true if the admin shows the archives to visitors.
+	ArchivesPublic bool
+	Images         types.BookmarkImages
 	*dataCommon
 
 	Notifications []SystemNotification
@@ -1631,9 +1994,18 @@ func renderBookmark(
 ) dataBookmark {
 	var notifications []SystemNotification
 
-	// TODO: do not fetch for the unauthorized
 	archivesRepo := db.NewArchivesRepo()
-	archives, err := archivesRepo.FetchForBookmark(int64(bookmark.ID))
+	archivesPublic, err := archivesRepo.ArchivesPublic(int64(bookmark.ID))
+	if err != nil {
+		slog.Error("Failed to check if archives are public",
+			"bookmarkID", bookmark.ID,
+			"err", err)
+	}
+
+	var archives []types.Archive
+	if auth.AuthorizedFromRequest(rq) || (archivesPublic && bookmark.Visibility != types.Private) {
+		archives, err = archivesRepo.FetchForBookmark(int64(bookmark.ID))
+	}
 	if err != nil {
 		slog.Error("Failed to fetch archives for bookmark",
 			"bookmarkID", bookmark.ID,
@@ -1716,11 +2088,19 @@ func renderBookmark(
 		}
 	}
 
+	images, err := archivesRepo.ImagesOf([]int{bookmark.ID})
+	if err != nil {
+		slog.Warn("Failed to fetch images for bookmark",
+			"bookmarkID", bookmark.ID, "err", err)
+	}
+
 	return dataBookmark{
 		Bookmark:         bookmark,
 		Remarks:          remarks,
 		Archives:         archives,
 		HighlightArchive: highlightArchive,
+		ArchivesPublic:   archivesPublic,
+		Images:           images[bookmark.ID],
 		dataCommon:       common,
 		Notifications:    notifications,
 
@@ -1741,9 +2121,9 @@ type dataFeed struct {
 func getIndex(w http.ResponseWriter, rq *http.Request) {
 	authed := auth.AuthorizedFromRequest(rq)
 	common := emptyCommon()
-	common.head = template.HTML(fmt.Sprintf(`
-	<link rel="alternate" type="application/rss+xml" title="Daily digest feed (recommended)" href="/digest-rss">
-	<link rel="alternate" type="application/rss+xml" title="Individual bookmarks feed" href="/bookmarks-rss">
+	common.head = feedLinks("Daily digest feed (recommended)", "/digest-", "") +
+		feedLinks("Individual bookmarks feed", "/bookmarks-", "") +
+		template.HTML(fmt.Sprintf(`
 	<link rel="alternate" type='%[1]s' href="/@%[3]s">
 	<link rel="alternate" type='%[2]s' href="/@%[3]s">
 `, types.ActivityType, types.OtherActivityType, settings.AdminUsername()))
@@ -1759,6 +2139,9 @@ func getIndex(w http.ResponseWriter, rq *http.Request) {
 	if err := ctrl.SvcLiking.FillLikes(rq.Context(), renderedBookmarks, nil); err != nil {
 		slog.Error("Failed to fill likes for local bookmarks", "err", err)
 	}
+	if err := ctrl.SvcArchiving.FillIcons(renderedBookmarks); err != nil {
+		slog.Error("Failed to fill icons for local bookmarks", "err", err)
+	}
 	groups := types.GroupLocalBookmarksByDate(renderedBookmarks)
 
 	common.paginator = types.PaginatorFromURL(rq.URL, currentPage, totalBookmarks)
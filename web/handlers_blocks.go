@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package web
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	blockingports "git.sr.ht/~bouncepaw/betula/ports/blocking"
+)
+
+type dataBlocks struct {
+	*dataCommon
+
+	Blocks        []blockingports.Block
+	Notifications []SystemNotification
+}
+
+func getBlocks(w http.ResponseWriter, rq *http.Request) {
+	blocks, err := ctrl.SvcBlocking.Blocks(rq.Context())
+	if err != nil {
+		slog.Error("Failed to get blocks", "err", err)
+		http.Error(w, "Failed to get blocks", http.StatusInternalServerError)
+		return
+	}
+
+	var notifs []SystemNotification
+	switch rq.FormValue("status") {
+	case "blocked":
+		notifs = append(notifs, SystemNotification{
+			Category: NotificationSuccess,
+			Body:     `Blocked. Their likes, remarks and notifications are deleted.`,
+		})
+	case "bad-target":
+		notifs = append(notifs, SystemNotification{
+			Category: NotificationFailure,
+			Body:     `Enter an actor address starting with https:// or a domain like example.org.`,
+		})
+	}
+
+	templateExec(w, rq, templateBlocks, dataBlocks{
+		dataCommon:    emptyCommon(),
+		Blocks:        blocks,
+		Notifications: notifs,
+	})
+}
+
+func postBlock(w http.ResponseWriter, rq *http.Request) {
+	err := ctrl.SvcBlocking.Block(rq.Context(), rq.FormValue("target"), rq.FormValue("reason"))
+	if errors.Is(err, blockingports.ErrBadTarget) {
+		http.Redirect(w, rq, "/blocks?status=bad-target", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to block", "target", rq.FormValue("target"), "err", err)
+		http.Error(w, "Failed to block", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, rq, "/blocks?status=blocked", http.StatusSeeOther)
+}
+
+func postUnblock(w http.ResponseWriter, rq *http.Request) {
+	if err := ctrl.SvcBlocking.Unblock(rq.Context(), rq.FormValue("target")); err != nil {
+		slog.Error("Failed to unblock", "target", rq.FormValue("target"), "err", err)
+		http.Error(w, "Failed to unblock", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, rq, "/blocks", http.StatusSeeOther)
+}
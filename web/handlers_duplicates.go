@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	duplicatesports "git.sr.ht/~bouncepaw/betula/ports/duplicates"
+	webhooksports "git.sr.ht/~bouncepaw/betula/ports/webhooks"
+	"git.sr.ht/~bouncepaw/betula/settings"
+)
+
+type dataDuplicates struct {
+	*dataCommon
+	Groups []duplicatesports.Group
+}
+
+func getDuplicates(w http.ResponseWriter, rq *http.Request) {
+	groups, err := ctrl.SvcDuplicates.Groups(rq.Context())
+	if err != nil {
+		slog.Error("Failed to find duplicate bookmarks", "err", err)
+		http.Error(w, "Failed to find duplicate bookmarks", http.StatusInternalServerError)
+		return
+	}
+
+	common := emptyCommon()
+	if survivor := rq.FormValue("merged-into"); survivor != "" {
+		id, _ := strconv.Atoi(survivor)
+		common = common.withSystemNotifications(SystemNotification{
+			Category: NotificationSuccess,
+			Body: template.HTML(fmt.Sprintf(`Merged %s bookmarks into <a href="/%d">bookmark %d</a>.`,
+				template.HTMLEscapeString(rq.FormValue("merged")), id, id)),
+		})
+	}
+	templateExec(w, rq, templateDuplicates, dataDuplicates{
+		dataCommon: common,
+		Groups:     groups,
+	})
+}
+
+// postMergeDuplicates merges the chosen bookmarks of a group into one and
+// tells the fediverse the others are gone.
+func postMergeDuplicates(w http.ResponseWriter, rq *http.Request) {
+	if err := rq.ParseForm(); err != nil {
+		http.Error(w, "Bad form", http.StatusBadRequest)
+		return
+	}
+	var ids []int
+	for _, value := range rq.PostForm["id"] {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Bad bookmark ID", http.StatusBadRequest)
+			return
+		}
+		ids = append(ids, id)
+	}
+	survivorID, _ := strconv.Atoi(rq.FormValue("survivor"))
+	descriptionFrom, _ := strconv.Atoi(rq.FormValue("description"))
+
+	result, err := ctrl.SvcDuplicates.Merge(rq.Context(), survivorID, ids, descriptionFrom)
+	if errors.Is(err, duplicatesports.ErrBadMerge) {
+		http.Error(w, "Choose at least two bookmarks, including the one to keep", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		slog.Error("Failed to merge bookmarks", "survivorID", survivorID, "ids", ids, "err", err)
+		http.Error(w, "Failed to merge bookmarks", http.StatusInternalServerError)
+		return
+	}
+
+	for _, bookmark := range result.Removed {
+		fireBookmarkDeletion(rq.Context(), bookmark)
+	}
+	fireBookmarkEvent(rq.Context(), webhooksports.EventBookmarkEdited, result.Survivor.ID)
+
+	if settings.FederationEnabled() {
+		go broadcastMerge(result)
+	}
+
+	var query = url.Values{}
+	query.Set("merged-into", strconv.Itoa(result.Survivor.ID))
+	query.Set("merged", strconv.Itoa(len(result.Removed)+1))
+	http.Redirect(w, rq, "/duplicates?"+query.Encode(), http.StatusSeeOther)
+}
+
+func broadcastMerge(result duplicatesports.MergeResult) {
+	for _, bookmark := range result.Removed {
+		broadcastBookmarkDeletion(bookmark)
+	}
+	broadcastBookmarkEdit(result.Survivor, result.Survivor.Visibility)
+}
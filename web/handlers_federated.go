@@ -12,9 +12,11 @@ package web
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html/template"
 	"io"
 	"log/slog"
 	"net/http"
@@ -24,8 +26,10 @@ import (
 	"git.sr.ht/~bouncepaw/betula/fediverse"
 	"git.sr.ht/~bouncepaw/betula/fediverse/fedisearch"
 	"git.sr.ht/~bouncepaw/betula/fediverse/signing"
+	"git.sr.ht/~bouncepaw/betula/jobs"
 	"git.sr.ht/~bouncepaw/betula/pkg/bxstr"
 	apports "git.sr.ht/~bouncepaw/betula/ports/activitypub"
+	webhooksports "git.sr.ht/~bouncepaw/betula/ports/webhooks"
 	"git.sr.ht/~bouncepaw/betula/settings"
 	"git.sr.ht/~bouncepaw/betula/types"
 )
@@ -208,6 +212,8 @@ func getLocalActorObject(w http.ResponseWriter, rq *http.Request) {
 		"following": siteURL + "/following",
 		"outbox":    siteURL + "/outbox",
 		"url":       fediverse.OurID(),
+		// Mastodon shows a lock next to such accounts.
+		"manuallyApprovesFollowers": settings.ManuallyApproveFollowers(),
 		"icon": map[string]string{
 			"type":      "Image",
 			"mediaType": "image/png",
@@ -227,6 +233,43 @@ func getLocalActorObject(w http.ResponseWriter, rq *http.Request) {
 	}
 }
 
+// getOutbox serves the outbox collection. With the page parameter, the page
+// of Create{Note} activities for public bookmarks is served instead.
+func getOutbox(w http.ResponseWriter, rq *http.Request) {
+	var (
+		data []byte
+		err  error
+	)
+	if rq.URL.Query().Has("page") {
+		page := extractPage(rq)
+		bookmarks, total, bErr := localBookmarks.Bookmarks(rq.Context(), false, page)
+		if bErr != nil {
+			slog.Error("Failed to get bookmarks for outbox", "page", page, "err", bErr)
+			http.Error(w, "Failed to get bookmarks", http.StatusInternalServerError)
+			return
+		}
+		data, err = ctrl.Assembly.OutboxPage(page, total, bookmarks)
+	} else {
+		total, cErr := localBookmarks.BookmarkCount(rq.Context(), false)
+		if cErr != nil {
+			slog.Error("Failed to count bookmarks for outbox", "err", cErr)
+			http.Error(w, "Failed to count bookmarks", http.StatusInternalServerError)
+			return
+		}
+		data, err = ctrl.Assembly.Outbox(total)
+	}
+	if err != nil {
+		slog.Error("Failed to assemble outbox", "err", err)
+		http.Error(w, "Failed to assemble outbox", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", types.OtherActivityType)
+	if _, err = w.Write(data); err != nil {
+		slog.Error("Failed to serve outbox", "err", err)
+	}
+}
+
 func getNodeInfo(w http.ResponseWriter, rq *http.Request) {
 	// See:
 	// => https://github.com/jhass/nodeinfo/blob/main/schemas/2.0/example.json
@@ -305,6 +348,10 @@ type dataRemark struct {
 	RemarkText string
 	Visibility types.Visibility
 	CopyTags   bool
+
+	// DuplicateBookmarkID is the bookmark of the same page, if there is one.
+	// Remarking it anyway is allowed on the second try.
+	DuplicateBookmarkID int
 }
 
 func remarkFormData(rq *http.Request) dataRemark {
@@ -355,6 +402,23 @@ fetchRemoteBookmark:
 	return
 
 remarking:
+	if rq.FormValue("duplicate") != "true" {
+		existingBookmarkID, err := localBookmarks.GetBookmarkIDByURL(rq.Context(), bookmark.URL)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			slog.Error("Failed to look up bookmark by URL", "url", bookmark.URL, "err", err)
+		}
+		if err == nil {
+			formData.DuplicateBookmarkID = existingBookmarkID
+			formData.dataCommon = formData.dataCommon.withSystemNotifications(
+				SystemNotification{
+					Category: NotificationClarification,
+					Body:     template.HTML(fmt.Sprintf(`You have <a href="/%d">bookmarked this link</a> already.`, existingBookmarkID)),
+				})
+			templateExec(w, rq, templateRemark, formData)
+			return
+		}
+	}
+
 	if !formData.CopyTags {
 		bookmark.Tags = nil // 🐸
 	}
@@ -362,6 +426,7 @@ remarking:
 	if formData.RemarkText != "" {
 		bookmark.RemarkText = &formData.RemarkText
 	}
+	bookmark.Visibility = formData.Visibility
 
 	bookmark.CreationTime = time.Now().UTC().Format(types.TimeLayout)
 	id, err := localBookmarks.InsertBookmark(rq.Context(), *bookmark)
@@ -371,8 +436,10 @@ remarking:
 		return
 	}
 	bookmark.ID = int(id)
+	jobs.ArchiveNewBookmark(*bookmark)
+	fireBookmarkEvent(rq.Context(), webhooksports.EventBookmarkCreated, bookmark.ID)
 
-	if settings.FederationEnabled() && formData.Visibility == types.Public {
+	if settings.FederationEnabled() && formData.Visibility.Federated() {
 		err = ctrl.SvcRemarking.BroadcastCreateRemark(rq.Context(), *bookmark)
 		if err != nil {
 			slog.Error("Failed to broadcast remark", "err", err, "url", formData.URL, "id", id)
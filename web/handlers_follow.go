@@ -6,13 +6,20 @@
 package web
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"html/template"
 	"log/slog"
 	"net/http"
 	"net/url"
 
+	"git.sr.ht/~bouncepaw/betula/auth"
+	"git.sr.ht/~bouncepaw/betula/fediverse"
+	"git.sr.ht/~bouncepaw/betula/jobs"
+	"git.sr.ht/~bouncepaw/betula/jobs/jobtype"
 	"git.sr.ht/~bouncepaw/betula/pkg/bxstr"
+	apports "git.sr.ht/~bouncepaw/betula/ports/activitypub"
 	wwwports "git.sr.ht/~bouncepaw/betula/ports/www"
 	"git.sr.ht/~bouncepaw/betula/types"
 )
@@ -43,6 +50,18 @@ func followNotifications(rq *http.Request) []SystemNotification {
 			Body:     `Unfollowed.`,
 		})
 	}
+	switch rq.FormValue("follow-request") {
+	case "accepted":
+		notifs = append(notifs, SystemNotification{
+			Category: NotificationSuccess,
+			Body:     `Accepted the follow request.`,
+		})
+	case "rejected":
+		notifs = append(notifs, SystemNotification{
+			Category: NotificationSuccess,
+			Body:     `Rejected the follow request.`,
+		})
+	}
 	return notifs
 }
 
@@ -63,6 +82,8 @@ type dataActorList struct {
 	Actors        []renderedActor
 	Focus         bool
 	Notifications []SystemNotification
+	// FollowRequests are shown to the admin on the Followers page.
+	FollowRequests []renderedActor
 }
 
 func getFollowersWeb(w http.ResponseWriter, rq *http.Request) {
@@ -81,13 +102,64 @@ func getFollowersWeb(w http.ResponseWriter, rq *http.Request) {
 		}
 	}
 
+	var renderedRequests []renderedActor
+	if auth.AuthorizedFromRequest(rq) {
+		requests, err := ctrl.RepoActor.GetFollowRequests(rq.Context())
+		if err != nil {
+			slog.Error("Failed to get follow requests", "err", err)
+		}
+		for _, actor := range requests {
+			renderedRequests = append(renderedRequests, renderedActor{
+				Actor: actor,
+				Next:  "/followers#" + url.PathEscape(actor.Acct()),
+			})
+		}
+	}
+
 	templateExec(w, rq, templateFollowers, dataActorList{
-		dataCommon:    emptyCommon(),
-		Actors:        renderedActors,
-		Notifications: followNotifications(rq),
+		dataCommon:     emptyCommon(),
+		Actors:         renderedActors,
+		Notifications:  followNotifications(rq),
+		FollowRequests: renderedRequests,
 	})
 }
 
+func postAcceptFollowRequest(w http.ResponseWriter, rq *http.Request) {
+	answerFollowRequest(w, rq, jobtype.SendAcceptFollow, "accepted")
+}
+
+func postRejectFollowRequest(w http.ResponseWriter, rq *http.Request) {
+	answerFollowRequest(w, rq, jobtype.SendRejectFollow, "rejected")
+}
+
+// answerFollowRequest schedules the job that answers the pending follow from
+// the actor-id actor and forgets the request.
+func answerFollowRequest(w http.ResponseWriter, rq *http.Request, cat jobtype.JobCategory, outcome string) {
+	actorID := rq.FormValue("actor-id")
+	activity, err := ctrl.RepoActor.FollowRequest(rq.Context(), actorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		slog.Warn("No such follow request", "actorID", actorID)
+		handlerNotFound(w, rq)
+		return
+	} else if err != nil {
+		slog.Error("Failed to get follow request", "actorID", actorID, "err", err)
+		handlerBadRequest(w, rq)
+		return
+	}
+
+	jobs.ScheduleJSON(cat, apports.FollowReport{
+		ActorID:          actorID,
+		ObjectID:         fediverse.OurID(),
+		OriginalActivity: activity,
+	})
+	if err = ctrl.RepoActor.RemoveFollowRequest(rq.Context(), actorID); err != nil {
+		slog.Error("Failed to remove follow request", "actorID", actorID, "err", err)
+	}
+	slog.Info("Answered follow request", "actorID", actorID, "outcome", outcome)
+
+	http.Redirect(w, rq, "/followers?follow-request="+outcome, http.StatusSeeOther)
+}
+
 func getFollowingWeb(w http.ResponseWriter, rq *http.Request) {
 	actors, err := ctrl.RepoActor.GetFollowing(rq.Context())
 	if err != nil {
@@ -12,6 +12,8 @@ import (
 	"net/url"
 	"time"
 
+	"git.sr.ht/~bouncepaw/betula/fediverse/signing"
+	"git.sr.ht/~bouncepaw/betula/jobs"
 	imexports "git.sr.ht/~bouncepaw/betula/ports/imex"
 	"git.sr.ht/~bouncepaw/betula/types"
 )
@@ -45,6 +47,8 @@ func postImport(w http.ResponseWriter, rq *http.Request) {
 	}
 	params.KeepDuplicate = rq.FormValue("keep-duplicate") == "true"
 	params.MakePublic = rq.FormValue("make-public") == "true"
+	params.RestoreSettings = rq.FormValue("restore-settings") == "true"
+	params.RestoreKey = rq.FormValue("restore-key") == "true"
 
 	count, err := ctrl.SvcImEx.Import(rq.Context(), params, file)
 
@@ -61,6 +65,11 @@ func postImport(w http.ResponseWriter, rq *http.Request) {
 			Body:     template.HTML(fmt.Sprintf("Imported %d bookmarks.", count)),
 		}
 	}
+	// Archives from a Betula archive come without their text.
+	jobs.ScheduleArchiveIndexing(rq.Context())
+	if params.RestoreKey {
+		signing.EnsureKeysFromDatabase()
+	}
 
 	templateExec(w, rq, templateImport, dataImport{
 		dataCommon: emptyCommon().withSystemNotifications(notif),
@@ -88,13 +97,18 @@ func postExport(w http.ResponseWriter, rq *http.Request) {
 	params := imexports.ExportParams{
 		Format:         imexports.ExportFormat(rq.FormValue("format")),
 		IncludePrivate: rq.FormValue("include-private") == "true",
+		IncludeKey:     rq.FormValue("include-key") == "true",
 	}
 
 	filename := fmt.Sprintf(
 		"%s Betula bookmarks.%s",
 		time.Now().UTC().Format(time.DateOnly),
 		params.Format.FileExtension())
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if params.Format == imexports.ExportFormatBetula {
+		w.Header().Set("Content-Type", "application/zip")
+	} else {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	}
 	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
 
 	err := ctrl.SvcImEx.Export(rq.Context(), params, w)
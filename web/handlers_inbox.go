@@ -6,6 +6,7 @@
 package web
 
 import (
+	"encoding/json"
 	"errors"
 	"io"
 	"log/slog"
@@ -19,6 +20,8 @@ import (
 	apports "git.sr.ht/~bouncepaw/betula/ports/activitypub"
 	"git.sr.ht/~bouncepaw/betula/ports/liking"
 	"git.sr.ht/~bouncepaw/betula/ports/remarking"
+	"git.sr.ht/~bouncepaw/betula/settings"
+	notiftypes "git.sr.ht/~bouncepaw/betula/types/notif"
 )
 
 func postInbox(w http.ResponseWriter, rq *http.Request) {
@@ -29,6 +32,24 @@ func postInbox(w http.ResponseWriter, rq *http.Request) {
 		return
 	}
 
+	actorID := activityActorID(data)
+	blocked, err := ctrl.SvcBlocking.IsBlocked(rq.Context(), actorID)
+	if err != nil {
+		slog.Error("Failed to check if actor is blocked", "actorID", actorID, "err", err)
+		http.Error(w, "Failed to check the actor", http.StatusInternalServerError)
+		return
+	}
+	if blocked {
+		slog.Info("Dropped activity from blocked actor", "actorID", actorID)
+		return
+	}
+
+	if err := verifyInboxRequest(rq, data, actorID); err != nil {
+		slog.Warn("Failed to verify inbox request", "actorID", actorID, "err", err)
+		http.Error(w, "Failed to verify the request signature", http.StatusUnauthorized)
+		return
+	}
+
 	report, err := ctrl.Guesser.Guess(data)
 	if err != nil {
 		slog.Error("Failed to parse incoming activity", "err", err)
@@ -38,6 +59,12 @@ func postInbox(w http.ResponseWriter, rq *http.Request) {
 		// Ignored
 		return
 	}
+	if reportedID := reportActorID(report); reportedID != actorID {
+		slog.Warn("Activity is about another actor than its sender",
+			"actorID", actorID, "reportedActorID", reportedID)
+		http.Error(w, "The activity is not by its sender", http.StatusUnauthorized)
+		return
+	}
 
 	switch report := report.(type) {
 	case apports.CreateNoteReport:
@@ -112,16 +139,6 @@ func postInbox(w http.ResponseWriter, rq *http.Request) {
 		}
 
 	case apports.DeleteNoteReport:
-		_, err := fediverse.RequestActorByID(report.ActorID)
-		if err != nil {
-			slog.Error("Failed to fetch actor", "err", err)
-			return
-		}
-		if signedOK := signing.VerifyRequestSignature(rq, data); !signedOK {
-			slog.Error("Failed to verify signature", "actorID", report.ActorID)
-			return
-		}
-
 		slog.Info("Deleted remote bookmark", "actorID", report.ActorID, "bookmarkID", report.BookmarkID)
 		err = ctrl.RepoRemoteBookmark.Delete(rq.Context(), report.BookmarkID)
 		if err != nil {
@@ -129,16 +146,6 @@ func postInbox(w http.ResponseWriter, rq *http.Request) {
 		}
 
 	case apports.UndoAnnounceReport:
-		_, err := fediverse.RequestActorByID(report.ActorID)
-		if err != nil {
-			slog.Error("Failed to fetch actor", "err", err)
-			return
-		}
-		if signedOK := signing.VerifyRequestSignature(rq, data); !signedOK {
-			slog.Error("Failed to verify signature", "actorID", report.ActorID)
-			return
-		}
-
 		event := remarkingports.EventLegacyUnremark{
 			ActorID:    report.ActorID,
 			AnnounceID: report.AnnounceID,
@@ -149,16 +156,6 @@ func postInbox(w http.ResponseWriter, rq *http.Request) {
 		}
 
 	case apports.AnnounceReport:
-		_, err := fediverse.RequestActorByID(report.ActorID)
-		if err != nil {
-			slog.Error("Failed to fetch actor", "err", err)
-			return
-		}
-		if signedOK := signing.VerifyRequestSignature(rq, data); !signedOK {
-			slog.Error("Failed to verify signature", "actorID", report.ActorID)
-			return
-		}
-
 		event := remarkingports.EventLegacyRemark{
 			ActorID:        report.ActorID,
 			AnnounceID:     report.AnnounceID,
@@ -175,6 +172,9 @@ func postInbox(w http.ResponseWriter, rq *http.Request) {
 			slog.Info("Unfollow request for someone else, ignoring", "actorID", report.ActorID, "objectID", report.ObjectID)
 			return
 		}
+		if err := ctrl.RepoActor.RemoveFollowRequest(rq.Context(), report.ActorID); err != nil {
+			slog.Error("Failed to remove follow request", "actorID", report.ActorID, "err", err)
+		}
 		status, err := ctrl.RepoActor.SubscriptionStatus(rq.Context(), report.ActorID)
 		if err != nil {
 			slog.Error("Failed to get subscription status", "actorID", report.ActorID, "err", err)
@@ -191,35 +191,19 @@ func postInbox(w http.ResponseWriter, rq *http.Request) {
 		}
 
 	case apports.FollowReport:
-		_, err := fediverse.RequestActorByID(report.ActorID)
-		if err != nil {
-			slog.Error("Failed to fetch actor", "err", err)
-			return
-		}
-		if signedOK := signing.VerifyRequestSignature(rq, data); !signedOK {
-			slog.Error("Failed to verify signature", "actorID", report.ActorID)
-			return
-		}
-
-		if report.ObjectID == fediverse.OurID() {
-			slog.Info("Someone asked to follow us", "actorID", report.ActorID)
-			jobs.ScheduleJSON(jobtype.SendAcceptFollow, report)
-		} else {
+		if report.ObjectID != fediverse.OurID() {
 			slog.Info("Follow request for someone else", "actorID", report.ActorID, "objectID", report.ObjectID)
 			jobs.ScheduleJSON(jobtype.ReceiveRejectFollow, report)
-		}
-
-	case apports.AcceptReport:
-		_, err := fediverse.RequestActorByID(report.ActorID)
-		if err != nil {
-			slog.Error("Failed to fetch actor", "err", err)
 			return
 		}
-		if signedOK := signing.VerifyRequestSignature(rq, data); !signedOK {
-			slog.Error("Failed to verify signature", "actorID", report.ActorID)
+		slog.Info("Someone asked to follow us", "actorID", report.ActorID)
+		if settings.ManuallyApproveFollowers() {
+			queueFollowRequest(rq, report)
 			return
 		}
+		jobs.ScheduleJSON(jobtype.SendAcceptFollow, report)
 
+	case apports.AcceptReport:
 		switch report.Object["type"] {
 		case "Follow":
 			report := apports.FollowReport{
@@ -231,16 +215,6 @@ func postInbox(w http.ResponseWriter, rq *http.Request) {
 		}
 
 	case apports.RejectReport:
-		_, err := fediverse.RequestActorByID(report.ActorID)
-		if err != nil {
-			slog.Error("Failed to fetch actor", "err", err)
-			return
-		}
-		if signedOK := signing.VerifyRequestSignature(rq, data); !signedOK {
-			slog.Error("Failed to verify signature", "actorID", report.ActorID)
-			return
-		}
-
 		switch report.Object["type"] {
 		case "Follow":
 			report := apports.FollowReport{
@@ -252,16 +226,6 @@ func postInbox(w http.ResponseWriter, rq *http.Request) {
 		}
 
 	case apports.LikeReport:
-		_, err := fediverse.RequestActorByID(report.ActorID)
-		if err != nil {
-			slog.Error("Failed to fetch actor", "err", err)
-			return
-		}
-		if signedOK := signing.VerifyRequestSignature(rq, data); !signedOK {
-			slog.Error("Failed to verify signature", "actorID", report.ActorID)
-			return
-		}
-
 		event := likingports.EventLike{
 			LikeID:        report.ID,
 			ActorID:       report.ActorID,
@@ -277,16 +241,6 @@ func postInbox(w http.ResponseWriter, rq *http.Request) {
 		}
 
 	case apports.UndoLikeReport:
-		_, err := fediverse.RequestActorByID(report.Object.ActorID)
-		if err != nil {
-			slog.Error("Failed to fetch actor", "err", err)
-			return
-		}
-		if signedOK := signing.VerifyRequestSignature(rq, data); !signedOK {
-			slog.Error("Failed to verify signature", "actorID", report.Object.ActorID)
-			return
-		}
-
 		event := likingports.EventUndoLike{
 			UndoLikeID: report.ID,
 			ActorID:    report.Object.ActorID,
@@ -306,3 +260,95 @@ func postInbox(w http.ResponseWriter, rq *http.Request) {
 		slog.Error("Invalid report type; this is a bug")
 	}
 }
+
+// activityActorID returns the ID of the activity's actor, or an empty
+// string if there is none.
+func activityActorID(data []byte) string {
+	var activity struct {
+		Actor any `json:"actor"`
+	}
+	if err := json.Unmarshal(data, &activity); err != nil {
+		return ""
+	}
+	switch actor := activity.Actor.(type) {
+	case string:
+		return actor
+	case map[string]any:
+		return bxstr.StringifyAnything(actor["id"])
+	default:
+		return ""
+	}
+}
+
+// verifyInboxRequest checks that the request is signed by the actor of
+// the activity in it.
+func verifyInboxRequest(rq *http.Request, data []byte, actorID string) error {
+	if actorID == "" {
+		return errors.New("activity has no actor")
+	}
+
+	// Makes sure we know their key.
+	if _, err := fediverse.RequestActorByID(actorID); err != nil {
+		return err
+	}
+	return signing.VerifyActorSignature(rq, data, actorID)
+}
+
+// reportActorID returns the actor who did what is reported. It is the one
+// who has to sign the request.
+func reportActorID(report any) string {
+	switch report := report.(type) {
+	case apports.CreateNoteReport:
+		return report.Bookmark.ActorID
+	case apports.UpdateNoteReport:
+		// Only the author can update the bookmark.
+		return report.Bookmark.ActorID
+	case apports.DeleteNoteReport:
+		return report.ActorID
+	case apports.UndoAnnounceReport:
+		return report.ActorID
+	case apports.AnnounceReport:
+		return report.ActorID
+	case apports.UndoFollowReport:
+		return report.ActorID
+	case apports.FollowReport:
+		return report.ActorID
+	case apports.AcceptReport:
+		return report.ActorID
+	case apports.RejectReport:
+		return report.ActorID
+	case apports.LikeReport:
+		return report.ActorID
+	case apports.UndoLikeReport:
+		return report.Object.ActorID
+	default:
+		return ""
+	}
+}
+
+// queueFollowRequest leaves the follow for the admin to approve. Followers
+// that are already approved are accepted again, because their server might
+// have lost our Accept.
+func queueFollowRequest(rq *http.Request, report apports.FollowReport) {
+	status, err := ctrl.RepoActor.SubscriptionStatus(rq.Context(), report.ActorID)
+	if err != nil {
+		slog.Error("Failed to get subscription status", "actorID", report.ActorID, "err", err)
+		return
+	}
+	if status.TheyFollowUs() {
+		jobs.ScheduleJSON(jobtype.SendAcceptFollow, report)
+		return
+	}
+
+	if err = ctrl.RepoActor.AddFollowRequest(rq.Context(), report.ActorID, report.OriginalActivity); err != nil {
+		slog.Error("Failed to store follow request", "actorID", report.ActorID, "err", err)
+		return
+	}
+	err = ctrl.RepoNotif.Store(rq.Context(), notiftypes.KindFollowRequest, notiftypes.FollowPayload{
+		ActorID: report.ActorID,
+	})
+	if err != nil {
+		slog.Error("Failed to store follow request notification", "err", err)
+	}
+	ctrl.SvcNotif.InvalidateCache()
+}
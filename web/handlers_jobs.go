@@ -0,0 +1,94 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package web
+
+import (
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"git.sr.ht/~bouncepaw/betula/jobs"
+	"git.sr.ht/~bouncepaw/betula/jobs/jobtype"
+)
+
+type dataJobs struct {
+	*dataCommon
+	PendingJobs []jobtype.Job
+	FailedJobs  []jobtype.Job
+	MaxAttempts uint
+}
+
+func getJobs(w http.ResponseWriter, rq *http.Request) {
+	allJobs, err := jobs.Jobs(rq.Context())
+	if err != nil {
+		slog.Error("Failed to load jobs", "err", err)
+		http.Error(w, "Failed to load jobs", http.StatusInternalServerError)
+		return
+	}
+
+	common := emptyCommon()
+	if scheduled := rq.FormValue("archives-scheduled"); scheduled != "" {
+		common = common.withSystemNotifications(SystemNotification{
+			Category: NotificationSuccess,
+			Body:     template.HTML(fmt.Sprintf(`Scheduled archiving of %s bookmarks.`, template.HTMLEscapeString(scheduled))),
+		})
+	}
+
+	data := dataJobs{
+		dataCommon:  common,
+		MaxAttempts: jobs.MaxAttempts,
+	}
+	for _, job := range allJobs {
+		if job.Failed {
+			data.FailedJobs = append(data.FailedJobs, job)
+		} else {
+			data.PendingJobs = append(data.PendingJobs, job)
+		}
+	}
+	templateExec(w, rq, templateJobs, data)
+}
+
+func postRetryJob(w http.ResponseWriter, rq *http.Request) {
+	id, err := strconv.ParseInt(rq.PathValue("id"), 10, 64)
+	if err != nil {
+		handlerNotFound(w, rq)
+		return
+	}
+	if err := jobs.RetryJob(rq.Context(), id); err != nil {
+		slog.Error("Failed to retry job", "id", id, "err", err)
+		http.Error(w, "Failed to retry job", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, rq, "/jobs", http.StatusSeeOther)
+}
+
+func postDiscardJob(w http.ResponseWriter, rq *http.Request) {
+	id, err := strconv.ParseInt(rq.PathValue("id"), 10, 64)
+	if err != nil {
+		handlerNotFound(w, rq)
+		return
+	}
+	if err := jobs.DiscardJob(rq.Context(), id); err != nil {
+		slog.Error("Failed to discard job", "id", id, "err", err)
+		http.Error(w, "Failed to discard job", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, rq, "/jobs", http.StatusSeeOther)
+}
+
+// postArchiveEverything schedules archiving every bookmark that has no
+// archive yet.
+func postArchiveEverything(w http.ResponseWriter, rq *http.Request) {
+	scheduled, err := jobs.ArchiveEverything(rq.Context())
+	if err != nil {
+		slog.Error("Failed to schedule archiving", "err", err)
+		http.Error(w, "Failed to schedule archiving", http.StatusInternalServerError)
+		return
+	}
+	slog.Info("Scheduled archiving of bookmarks without archives", "count", scheduled)
+	http.Redirect(w, rq, fmt.Sprintf("/jobs?archives-scheduled=%d", scheduled), http.StatusSeeOther)
+}
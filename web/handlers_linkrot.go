@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package web
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	linkrotports "git.sr.ht/~bouncepaw/betula/ports/linkrot"
+	webhooksports "git.sr.ht/~bouncepaw/betula/ports/webhooks"
+	"git.sr.ht/~bouncepaw/betula/settings"
+)
+
+type dataBrokenLinks struct {
+	*dataCommon
+	Dead       []linkrotports.LinkCheck
+	Redirected []linkrotports.LinkCheck
+}
+
+func getBrokenLinks(w http.ResponseWriter, rq *http.Request) {
+	problems, err := ctrl.SvcLinkRot.Problems(rq.Context())
+	if err != nil {
+		slog.Error("Failed to get broken links", "err", err)
+		http.Error(w, "Failed to load broken links", http.StatusInternalServerError)
+		return
+	}
+
+	data := dataBrokenLinks{dataCommon: emptyCommon()}
+	for _, check := range problems {
+		if check.Dead() {
+			data.Dead = append(data.Dead, check)
+		} else {
+			data.Redirected = append(data.Redirected, check)
+		}
+	}
+	templateExec(w, rq, templateBrokenLinks, data)
+}
+
+// postUseFinalURL replaces the URL of the bookmark with the one it
+// redirects to.
+func postUseFinalURL(w http.ResponseWriter, rq *http.Request) {
+	bookmark, ok := extractBookmark(w, rq)
+	if !ok {
+		return
+	}
+
+	problems, err := ctrl.SvcLinkRot.Problems(rq.Context())
+	if err != nil {
+		slog.Error("Failed to get broken links", "err", err)
+		http.Error(w, "Failed to load broken links", http.StatusInternalServerError)
+		return
+	}
+	var finalURL string
+	for _, check := range problems {
+		if check.BookmarkID == bookmark.ID && check.Redirected() {
+			finalURL = check.FinalURL
+		}
+	}
+	if finalURL == "" {
+		slog.Warn("The bookmark's link did not move", "bookmarkID", bookmark.ID)
+		handlerNotFound(w, rq)
+		return
+	}
+
+	bookmark.URL = finalURL
+	if err := localBookmarks.EditBookmark(rq.Context(), *bookmark); err != nil {
+		slog.Error("Failed to edit bookmark", "bookmarkID", bookmark.ID, "err", err)
+		http.Error(w, "Failed to edit bookmark", http.StatusInternalServerError)
+		return
+	}
+	slog.Info("Moved bookmark to the new address", "bookmarkID", bookmark.ID, "url", finalURL)
+	fireBookmarkEvent(rq.Context(), webhooksports.EventBookmarkEdited, bookmark.ID)
+
+	if settings.FederationEnabled() {
+		go broadcastBookmarkEdit(*bookmark, bookmark.Visibility)
+	}
+	http.Redirect(w, rq, fmt.Sprintf("/%d", bookmark.ID), http.StatusSeeOther)
+}
@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package web
+
+import (
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	archivingports "git.sr.ht/~bouncepaw/betula/ports/archiving"
+	"git.sr.ht/~bouncepaw/betula/settings"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+type dataArchiveStorage struct {
+	*dataCommon
+	archivingports.Usage
+	Quota archivingports.Quota
+	// QuotaPercent is how much of the quota is used, 0 to 100.
+	QuotaPercent int
+}
+
+func getArchiveStorage(w http.ResponseWriter, rq *http.Request) {
+	usage, err := ctrl.SvcArchiving.Usage()
+	if err != nil {
+		slog.Error("Failed to get archive storage usage", "err", err)
+		http.Error(w, "Failed to load archive storage usage", http.StatusInternalServerError)
+		return
+	}
+
+	common := emptyCommon()
+	if freed := rq.FormValue("freed"); freed != "" {
+		size, _ := strconv.ParseInt(freed, 10, 64)
+		common = common.withSystemNotifications(SystemNotification{
+			Category: NotificationSuccess,
+			Body: template.HTML(fmt.Sprintf(`Removed %s archives and %s artifacts, freed %s.`,
+				template.HTMLEscapeString(rq.FormValue("archives")),
+				template.HTMLEscapeString(rq.FormValue("artifacts")),
+				types.HumanSize(size))),
+		})
+	}
+	if rq.FormValue("quota-exceeded") == "true" {
+		common = common.withSystemNotifications(SystemNotification{
+			Category: NotificationFailure,
+			Body:     `The archive was not made, because the archive quota is reached. Delete some archives or raise the quota in <a href="/settings">Settings</a>.`,
+		})
+	}
+
+	data := dataArchiveStorage{
+		dataCommon: common,
+		Usage:      usage,
+		Quota:      settings.ArchiveQuota(),
+	}
+	if data.Quota.Size > 0 {
+		data.QuotaPercent = int(min(100, usage.Quoted*100/data.Quota.Size))
+	}
+	templateExec(w, rq, templateArchiveStorage, data)
+}
+
+func postCollectGarbage(w http.ResponseWriter, rq *http.Request) {
+	collected, err := ctrl.SvcArchiving.CollectGarbage()
+	if err != nil {
+		http.Error(w, "Failed to collect garbage", http.StatusInternalServerError)
+		return
+	}
+
+	var query = url.Values{}
+	query.Set("archives", strconv.Itoa(collected.Archives))
+	query.Set("artifacts", strconv.Itoa(collected.Artifacts))
+	query.Set("freed", strconv.FormatInt(collected.Freed, 10))
+	http.Redirect(w, rq, "/archive-storage?"+query.Encode(), http.StatusSeeOther)
+}
@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	subscriptionsports "git.sr.ht/~bouncepaw/betula/ports/subscriptions"
+)
+
+type dataSuggestions struct {
+	*dataCommon
+	Suggestions   []subscriptionsports.Suggestion
+	Subscriptions []subscriptionsports.Subscription
+}
+
+func getSuggestions(w http.ResponseWriter, rq *http.Request) {
+	renderSuggestions(w, rq, emptyCommon())
+}
+
+func renderSuggestions(w http.ResponseWriter, rq *http.Request, common *dataCommon) {
+	suggestions, err := ctrl.SvcSubscriptions.Suggestions(rq.Context())
+	if err != nil {
+		slog.Error("Failed to load suggestions", "err", err)
+		http.Error(w, "Failed to load suggestions", http.StatusInternalServerError)
+		return
+	}
+	subs, err := ctrl.SvcSubscriptions.Subscriptions(rq.Context())
+	if err != nil {
+		slog.Error("Failed to load subscriptions", "err", err)
+		http.Error(w, "Failed to load subscriptions", http.StatusInternalServerError)
+		return
+	}
+	templateExec(w, rq, templateSuggestions, dataSuggestions{
+		dataCommon:    common,
+		Suggestions:   suggestions,
+		Subscriptions: subs,
+	})
+}
+
+func postSubscribe(w http.ResponseWriter, rq *http.Request) {
+	addr := rq.FormValue("url")
+	if !webURL(addr) {
+		renderSuggestions(w, rq, emptyCommon().withSystemNotifications(SystemNotification{
+			Category: NotificationFailure,
+			Body:     "Enter the address of a feed or a site, starting with http:// or https://.",
+		}))
+		return
+	}
+
+	sub, err := ctrl.SvcSubscriptions.Subscribe(rq.Context(), addr)
+	var notification SystemNotification
+	switch {
+	case errors.Is(err, subscriptionsports.ErrAlreadySubscribed):
+		notification = SystemNotification{
+			Category: NotificationClarification,
+			Body:     "You are subscribed to this feed already.",
+		}
+	case errors.Is(err, subscriptionsports.ErrNoFeedFound):
+		notification = SystemNotification{
+			Category: NotificationFailure,
+			Body:     "There is no feed at this address, and the page does not link to one.",
+		}
+	case err != nil:
+		slog.Warn("Failed to subscribe to feed", "url", addr, "err", err)
+		notification = SystemNotification{
+			Category: NotificationFailure,
+			Body:     template.HTML("Failed to subscribe: " + template.HTMLEscapeString(err.Error())),
+		}
+	default:
+		notification = SystemNotification{
+			Category: NotificationSuccess,
+			Body:     template.HTML(fmt.Sprintf("Subscribed to %s.", template.HTMLEscapeString(sub.Title))),
+		}
+	}
+	renderSuggestions(w, rq, emptyCommon().withSystemNotifications(notification))
+}
+
+func postUnsubscribe(w http.ResponseWriter, rq *http.Request) {
+	id, err := strconv.ParseInt(rq.PathValue("id"), 10, 64)
+	if err != nil {
+		handlerNotFound(w, rq)
+		return
+	}
+	if err = ctrl.SvcSubscriptions.Unsubscribe(rq.Context(), id); err != nil {
+		slog.Error("Failed to unsubscribe", "id", id, "err", err)
+		http.Error(w, "Failed to unsubscribe", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, rq, "/suggestions", http.StatusSeeOther)
+}
+
+func postDismissSuggestion(w http.ResponseWriter, rq *http.Request) {
+	id, err := strconv.ParseInt(rq.PathValue("id"), 10, 64)
+	if err != nil {
+		handlerNotFound(w, rq)
+		return
+	}
+	if err = ctrl.SvcSubscriptions.Dismiss(rq.Context(), id); err != nil {
+		slog.Error("Failed to dismiss suggestion", "id", id, "err", err)
+		http.Error(w, "Failed to dismiss suggestion", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, rq, "/suggestions", http.StatusSeeOther)
+}
+
+func postDismissAllSuggestions(w http.ResponseWriter, rq *http.Request) {
+	if err := ctrl.SvcSubscriptions.DismissAll(rq.Context()); err != nil {
+		slog.Error("Failed to dismiss suggestions", "err", err)
+		http.Error(w, "Failed to dismiss suggestions", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, rq, "/suggestions", http.StatusSeeOther)
+}
@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"git.sr.ht/~bouncepaw/betula/db"
+	archivingports "git.sr.ht/~bouncepaw/betula/ports/archiving"
+)
+
+// postArchivesVisibility shows or hides the bookmark's archives from visitors.
+func postArchivesVisibility(w http.ResponseWriter, rq *http.Request) {
+	bookmark, ok := extractBookmark(w, rq)
+	if !ok {
+		return
+	}
+
+	public := rq.FormValue("public") == "true"
+	if err := db.NewArchivesRepo().SetArchivesPublic(int64(bookmark.ID), public); err != nil {
+		slog.Error("Failed to set archives visibility", "bookmarkID", bookmark.ID, "err", err)
+		http.Error(w, "Failed to set archives visibility", http.StatusInternalServerError)
+		return
+	}
+	slog.Info("Set archives visibility", "bookmarkID", bookmark.ID, "public", public)
+	http.Redirect(w, rq, fmt.Sprintf("/%d", bookmark.ID), http.StatusSeeOther)
+}
+
+func getArchiveWARC(w http.ResponseWriter, rq *http.Request) {
+	archiveID, err := strconv.ParseInt(rq.PathValue("id"), 10, 64)
+	if err != nil || archiveID <= 0 {
+		handlerNotFound(w, rq)
+		return
+	}
+	writeWARC(w, rq, archiveID, fmt.Sprintf("archive-%d.warc.gz", archiveID))
+}
+
+func getArchivesWARC(w http.ResponseWriter, rq *http.Request) {
+	writeWARC(w, rq, 0, fmt.Sprintf("betula-archives-%s.warc.gz", time.Now().Format("2006-01-02")))
+}
+
+func writeWARC(w http.ResponseWriter, rq *http.Request, archiveID int64, filename string) {
+	// The headers are sent with the first write, which happens only if
+	// there is something to write.
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	err := ctrl.SvcArchiving.WriteWARC(w, archiveID)
+	if errors.Is(err, archivingports.ErrNoArchive) {
+		w.Header().Del("Content-Type")
+		w.Header().Del("Content-Disposition")
+		handlerNotFound(w, rq)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to write WARC", "archiveID", archiveID, "err", err)
+	}
+}
@@ -0,0 +1,132 @@
+// SPDX-FileCopyrightText: 2026 Timur Ismagilov <https://bouncepaw.com>
+//
+// SPDX-License-Identifier: AGPL-3.0-only
+
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	webhooksports "git.sr.ht/~bouncepaw/betula/ports/webhooks"
+	"git.sr.ht/~bouncepaw/betula/types"
+)
+
+// fireBookmarkEvent tells the webhooks about the saved or edited bookmark.
+// The bookmark is read anew, so the payload has its creation time and tags.
+func fireBookmarkEvent(ctx context.Context, event webhooksports.Event, bookmarkID int) {
+	ctrl.SvcWebhooks.FireForBookmark(ctx, bookmarkID, webhooksports.Payload{Event: event})
+}
+
+// fireBookmarkDeletion tells the webhooks about the deleted bookmark.
+// It cannot be read anymore, so it is passed as it was.
+func fireBookmarkDeletion(ctx context.Context, bookmark types.Bookmark) {
+	ctrl.SvcWebhooks.Fire(ctx, webhooksports.Payload{
+		Event:    webhooksports.EventBookmarkDeleted,
+		Bookmark: webhooksports.BookmarkOf(bookmark),
+	})
+}
+
+type dataWebhooks struct {
+	Webhooks   []webhooksports.Webhook
+	Deliveries []webhooksports.Delivery
+	Events     []webhooksports.Event
+	*dataCommon
+}
+
+func getWebhooks(w http.ResponseWriter, rq *http.Request) {
+	renderWebhooks(w, rq, emptyCommon())
+}
+
+func renderWebhooks(w http.ResponseWriter, rq *http.Request, common *dataCommon) {
+	hooks, err := ctrl.SvcWebhooks.Webhooks(rq.Context())
+	if err != nil {
+		slog.Error("Failed to get webhooks", "err", err)
+		http.Error(w, "Failed to get webhooks", http.StatusInternalServerError)
+		return
+	}
+	deliveries, err := ctrl.SvcWebhooks.Deliveries(rq.Context())
+	if err != nil {
+		slog.Error("Failed to get webhook deliveries", "err", err)
+		http.Error(w, "Failed to get webhook deliveries", http.StatusInternalServerError)
+		return
+	}
+	templateExec(w, rq, templateWebhooks, dataWebhooks{
+		Webhooks:   hooks,
+		Deliveries: deliveries,
+		Events:     webhooksports.Events,
+		dataCommon: common,
+	})
+}
+
+func postWebhook(w http.ResponseWriter, rq *http.Request) {
+	name := strings.TrimSpace(rq.FormValue("name"))
+	var events []webhooksports.Event
+	for _, event := range webhooksports.Events {
+		if slices.Contains(rq.Form["event"], string(event)) {
+			events = append(events, event)
+		}
+	}
+
+	hook, err := ctrl.SvcWebhooks.AddWebhook(
+		rq.Context(),
+		name,
+		strings.TrimSpace(rq.FormValue("url")),
+		strings.TrimSpace(rq.FormValue("secret")),
+		events,
+		rq.FormValue("include-private") == "true",
+	)
+	if errors.Is(err, webhooksports.ErrBadWebhook) {
+		renderWebhooks(w, rq, emptyCommon().withSystemNotifications(SystemNotification{
+			Category: NotificationFailure,
+			Body:     "Give the webhook an http or https address and at least one event.",
+		}))
+		return
+	} else if err != nil {
+		slog.Error("Failed to add webhook", "err", err)
+		http.Error(w, "Failed to add webhook", http.StatusInternalServerError)
+		return
+	}
+
+	renderWebhooks(w, rq, emptyCommon().withSystemNotifications(SystemNotification{
+		Category: NotificationSuccess,
+		Body: template.HTML(fmt.Sprintf(
+			`Added the webhook “%s”. Its secret is <code>%s</code>.`,
+			template.HTMLEscapeString(hook.Name), template.HTMLEscapeString(hook.Secret))),
+	}))
+}
+
+func postDeleteWebhook(w http.ResponseWriter, rq *http.Request) {
+	id, err := strconv.ParseInt(rq.PathValue("id"), 10, 64)
+	if err != nil {
+		handlerNotFound(w, rq)
+		return
+	}
+	if err := ctrl.SvcWebhooks.RemoveWebhook(rq.Context(), id); err != nil {
+		slog.Error("Failed to remove webhook", "id", id, "err", err)
+		http.Error(w, "Failed to remove webhook", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, rq, "/webhooks", http.StatusSeeOther)
+}
+
+func postRedeliverWebhook(w http.ResponseWriter, rq *http.Request) {
+	id, err := strconv.ParseInt(rq.PathValue("id"), 10, 64)
+	if err != nil {
+		handlerNotFound(w, rq)
+		return
+	}
+	if err := ctrl.SvcWebhooks.Redeliver(rq.Context(), id); err != nil {
+		slog.Error("Failed to redeliver webhook", "deliveryID", id, "err", err)
+		http.Error(w, "Failed to redeliver webhook", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, rq, "/webhooks", http.StatusSeeOther)
+}
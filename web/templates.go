@@ -102,6 +102,7 @@ var templateLoginForm = templateFrom(nil, "login-form")
 var templateLogoutForm = templateFrom(nil, "logout-form")
 var templateImport = templateFrom(nil, "import")
 var templateExport = templateFrom(nil, "export")
+var templateSuggestions = templateFrom(funcMapForForm, "suggestions")
 
 // Settings views.
 var (
@@ -109,6 +110,11 @@ var (
 	templateLoggingSettings = templateFrom(nil, "settings-tabs-fragment", "settings-logging")
 	templateBookmarklet     = templateFrom(nil, "settings-tabs-fragment", "bookmarklet")
 	templateSessions        = templateFrom(funcMapForTime, "settings-tabs-fragment", "sessions")
+	templateJobs            = templateFrom(nil, "settings-tabs-fragment", "jobs")
+	templateBrokenLinks     = templateFrom(nil, "settings-tabs-fragment", "broken-links")
+	templateDuplicates      = templateFrom(nil, "settings-tabs-fragment", "duplicates")
+	templateArchiveStorage  = templateFrom(funcMapForSizes, "settings-tabs-fragment", "archive-storage")
+	templateWebhooks        = templateFrom(nil, "settings-tabs-fragment", "webhooks")
 )
 
 // Sad views.
@@ -134,6 +140,7 @@ var (
 	templateRemoteProfile = templateFrom(funcMapForBookmarks, "follow-fragment", "paginator-fragment", "timeline", "remote-profile")
 	templateFollowing     = templateFrom(nil, "follow-fragment", "following")
 	templateFollowers     = templateFrom(nil, "follow-fragment", "followers")
+	templateBlocks        = templateFrom(nil, "follow-fragment", "blocks")
 	templateTimeline      = templateFrom(funcMapForBookmarks, "paginator-fragment", "timeline")
 	templateFedisearch    = templateFrom(funcMapForBookmarks, "fedisearch")
 	templateNotifications = templateFrom(funcMapForNotifications, "notifications")
@@ -193,6 +200,10 @@ var funcMapForTime = template.FuncMap{
 	},
 }
 
+var funcMapForSizes = template.FuncMap{
+	"humanSize": types.HumanSize,
+}
+
 var funcMapForNotifications = template.FuncMap{
 	"render": notiftypes.Render,
 }